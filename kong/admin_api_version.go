@@ -0,0 +1,65 @@
+package kong
+
+import (
+	"fmt"
+	"strings"
+)
+
+// knownKongReleases are the major.minor releases of kong whose admin api the provider knows, admin_api_version has to
+// be one of them (with any patch version) as the version gates can not tell what an unknown release accepts.
+var knownKongReleases = []string{
+	"0.13", "0.14", "0.15",
+	"1.0", "1.1", "1.2", "1.3", "1.4", "1.5",
+	"2.0", "2.1", "2.2", "2.3", "2.4", "2.5", "2.6", "2.7", "2.8",
+	"3.0", "3.1", "3.2", "3.3", "3.4", "3.5", "3.6", "3.7", "3.8", "3.9",
+}
+
+func validateAdminApiVersion(value interface{}, k string) ([]string, []error) {
+	if err := checkAdminApiVersion(value.(string)); err != nil {
+		return nil, []error{fmt.Errorf("%q: %v", k, err)}
+	}
+	return nil, nil
+}
+
+func checkAdminApiVersion(raw string) error {
+	if raw == "" {
+		return nil
+	}
+
+	kongVersion, err := parseKongVersion(raw)
+	if err != nil {
+		return err
+	}
+
+	segments := kongVersion.Segments()
+	release := fmt.Sprintf("%d.%d", segments[0], segments[1])
+	for _, known := range knownKongReleases {
+		if release == known {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("kong %s is not a known kong release, known releases are %s", raw, strings.Join(knownKongReleases, ", "))
+}
+
+// useAdminApiVersion makes the client build its requests for the kong version instead of the one the node reports, the
+// node's version is then never read. Enterprise versions are told apart the same way as a reported version, so
+// 3.6.1.0 is treated as kong enterprise and 3.6.1 as the open source edition.
+func (client *kongClient) useAdminApiVersion(raw string) error {
+	if err := checkAdminApiVersion(raw); err != nil {
+		return err
+	}
+
+	kongVersion, err := parseKongVersion(raw)
+	if err != nil {
+		return err
+	}
+
+	client.versionLock.Lock()
+	defer client.versionLock.Unlock()
+
+	client.kongVersion = kongVersion
+	// a konnect control plane is always kong enterprise
+	client.enterprise = client.enterprise || isEnterpriseNode(&nodeInformation{Version: raw})
+	return nil
+}
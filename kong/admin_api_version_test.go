@@ -0,0 +1,88 @@
+package kong
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func TestAdminApiVersionBuildsRequestsForPinnedVersion(t *testing.T) {
+
+	probes := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			probes++
+		}
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	cases := []struct {
+		adminApiVersion string
+		expected        string
+	}{
+		{"1.0.3", `{"username":"consumer"}`},
+		{"2.8.1", `{"username":"consumer","tags":["team-a"]}`},
+		{"3.6.1.0", `{"username":"consumer","tags":["team-a"],"meta":{"team":"a"}}`},
+	}
+
+	for _, c := range cases {
+		d := schema.TestResourceDataRaw(t, Provider().(*schema.Provider).Schema, map[string]interface{}{
+			"kong_admin_uri":    server.URL,
+			"admin_api_version": c.adminApiVersion,
+		})
+
+		meta, err := providerConfigure(d)
+		if err != nil {
+			t.Fatalf("kong %s: could not configure the provider: %v", c.adminApiVersion, err)
+		}
+
+		consumer := schema.TestResourceDataRaw(t, resourceKongConsumer().Schema, map[string]interface{}{
+			"username": "consumer",
+			"tags":     []interface{}{"team-a"},
+		})
+		if c.adminApiVersion == "3.6.1.0" {
+			consumer.Set("meta", map[string]interface{}{"team": "a"})
+		}
+
+		request, err := createKongConsumerRequestFromResourceData(meta.(*kongClient), consumer)
+		if err != nil {
+			t.Fatalf("kong %s: could not build consumer request: %v", c.adminApiVersion, err)
+		}
+
+		if body, _ := json.Marshal(request); string(body) != c.expected {
+			t.Errorf("kong %s: expected the consumer request %s but was %s", c.adminApiVersion, c.expected, body)
+		}
+	}
+
+	if probes != 0 {
+		t.Errorf("expected the kong version not to be read when admin_api_version is set, it was read %d times", probes)
+	}
+}
+
+func TestValidateAdminApiVersion(t *testing.T) {
+
+	cases := []struct {
+		value string
+		valid bool
+	}{
+		{"", true},
+		{"0.13.1", true},
+		{"2.8.1", true},
+		{"3.6.1.0", true},
+		{"3.6.1.0-enterprise-edition", true},
+		{"0.12.3", false},
+		{"4.0.0", false},
+		{"latest", false},
+	}
+
+	for _, c := range cases {
+		_, errs := validateAdminApiVersion(c.value, "admin_api_version")
+		if valid := len(errs) == 0; valid != c.valid {
+			t.Errorf("admin_api_version %q: expected valid to be %t but got errors: %v", c.value, c.valid, errs)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package kong
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// readAdminUrls parses the admin_urls of the provider, they have to be absolute urls of the kong admin api. A trailing
+// slash is dropped so every admin url joins the request paths the same way.
+func readAdminUrls(rawUrls []string) ([]*url.URL, error) {
+	adminUrls := []*url.URL{}
+	for _, rawUrl := range rawUrls {
+		adminUrl, err := url.Parse(rawUrl)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse admin url %s: %v", rawUrl, err)
+		}
+		if adminUrl.Scheme == "" || adminUrl.Host == "" {
+			return nil, fmt.Errorf("admin url %s must have a scheme and a host, e.g. http://localhost:8001", rawUrl)
+		}
+		adminUrl.Path = strings.TrimSuffix(adminUrl.Path, "/")
+		adminUrl.RawPath = ""
+		adminUrls = append(adminUrls, adminUrl)
+	}
+	return adminUrls, nil
+}
+
+// useAdminUrls sets the admin urls requests are failed over between, see failover
+func (t *adminTransport) useAdminUrls(adminUrls []*url.URL) {
+	t.adminUrls = adminUrls
+}
+
+func (t *adminTransport) activeAdminUrl() int {
+	t.adminUrlsLock.Lock()
+	defer t.adminUrlsLock.Unlock()
+
+	return t.activeAdminUrlIndex
+}
+
+func (t *adminTransport) setActiveAdminUrl(index int) {
+	t.adminUrlsLock.Lock()
+	defer t.adminUrlsLock.Unlock()
+
+	t.activeAdminUrlIndex = index
+}
+
+// failover sends r to the admin urls in turn starting at the active one, until one of them can be connected to. The
+// client sends every request to the first admin url, the url the last request went to is tried first so once a node is
+// down requests go straight to the next one instead of trying the node that is down every time. Only errors connecting
+// are failed over, nothing has been sent then, any other error or response is returned as it is.
+func (t *adminTransport) failover(r *http.Request) (*http.Response, error) {
+	primary := t.adminUrls[0]
+	if r.URL.Scheme != primary.Scheme || r.URL.Host != primary.Host || !strings.HasPrefix(r.URL.Path, primary.Path) {
+		return t.send(r)
+	}
+	path := strings.TrimPrefix(r.URL.Path, primary.Path)
+
+	first := t.activeAdminUrl()
+	var err error
+	for i := 0; i < len(t.adminUrls); i++ {
+		index := (first + i) % len(t.adminUrls)
+		adminUrl := t.adminUrls[index]
+
+		attempt := new(http.Request)
+		*attempt = *r
+		attemptUrl := *r.URL
+		attemptUrl.Scheme = adminUrl.Scheme
+		attemptUrl.Host = adminUrl.Host
+		attemptUrl.Path = adminUrl.Path + path
+		attemptUrl.RawPath = ""
+		attempt.URL = &attemptUrl
+		attempt.Host = adminUrl.Host
+
+		// the body of the previous attempt has been closed, a request that can not give its body again is not resent
+		if i > 0 && r.Body != nil && r.Body != http.NoBody {
+			if r.GetBody == nil {
+				return nil, err
+			}
+			body, bodyErr := r.GetBody()
+			if bodyErr != nil {
+				return nil, bodyErr
+			}
+			attempt.Body = body
+		}
+
+		var response *http.Response
+		response, err = t.send(attempt)
+		if err == nil || !isDialError(err) {
+			if err == nil && index != first {
+				t.setActiveAdminUrl(index)
+			}
+			return response, err
+		}
+
+		log.Printf("[WARN] could not connect to the kong admin api at %s, trying the next admin url: %v", adminUrl.Host, err)
+	}
+
+	return nil, err
+}
+
+// isDialError is true when no connection could be made, e.g. it was refused or the host does not resolve
+func isDialError(err error) bool {
+	if urlError, ok := err.(*url.Error); ok {
+		err = urlError.Err
+	}
+	if _, ok := err.(*net.DNSError); ok {
+		return true
+	}
+
+	opError, ok := err.(*net.OpError)
+	return ok && opError.Op == "dial"
+}
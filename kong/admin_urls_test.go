@@ -0,0 +1,152 @@
+package kong
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func TestAdminUrlsFailover(t *testing.T) {
+
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	downUrl := down.URL
+	down.Close()
+
+	var created map[string]interface{}
+	requests := 0
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/":
+			w.Write([]byte(`{"version":"3.6.1"}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/consumers":
+			json.NewDecoder(r.Body).Decode(&created)
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id":"consumer-id","username":"alice"}`))
+		case r.URL.Path == "/consumers/consumer-id":
+			w.Write([]byte(`{"id":"consumer-id","username":"alice"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer up.Close()
+
+	d := schema.TestResourceDataRaw(t, Provider().(*schema.Provider).Schema, map[string]interface{}{
+		"admin_urls": []interface{}{downUrl, up.URL},
+	})
+	meta, err := providerConfigure(d)
+	if err != nil {
+		t.Fatalf("could not configure provider: %v", err)
+	}
+	client := meta.(*kongClient)
+
+	// the body of a create is sent again to the next admin url
+	consumer := &consumer{}
+	if err := client.post("/consumers", map[string]string{"username": "alice"}, consumer); err != nil {
+		t.Fatalf("expected the create to fail over to %s, got: %v", up.URL, err)
+	}
+	if created["username"] != "alice" || consumer.Id != "consumer-id" {
+		t.Errorf("expected the consumer to be created on %s, sent: %v got: %v", up.URL, created, consumer)
+	}
+
+	if active := client.transport.(*adminTransport).activeAdminUrl(); active != 1 {
+		t.Errorf("expected the admin url that worked to be used next, the active one is: %d", active)
+	}
+
+	if _, err := client.Consumers().GetById("consumer-id"); err != nil {
+		t.Errorf("expected gokong requests to fail over as well, got: %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("expected 2 requests to reach %s, got: %d", up.URL, requests)
+	}
+
+	// kong answering with an error is not failed over
+	if found, err := client.get("/consumers/missing-id", nil); err != nil || found {
+		t.Errorf("expected a missing consumer to be reported by %s, found: %t error: %v", up.URL, found, err)
+	}
+
+	invalid := schema.TestResourceDataRaw(t, Provider().(*schema.Provider).Schema, map[string]interface{}{
+		"admin_urls": []interface{}{"localhost:8001"},
+	})
+	if _, err := providerConfigure(invalid); err == nil || !strings.Contains(err.Error(), "invalid admin_urls") {
+		t.Errorf("expected an admin url without a scheme to be rejected, got: %v", err)
+	}
+}
+
+func TestAdminUrlsAllDown(t *testing.T) {
+
+	rawUrls := []string{}
+	for i := 0; i < 2; i++ {
+		down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		rawUrls = append(rawUrls, down.URL)
+		down.Close()
+	}
+
+	adminUrls, err := readAdminUrls(rawUrls)
+	if err != nil {
+		t.Fatalf("could not read admin urls: %v", err)
+	}
+
+	transport := newAdminTransport(userAgent(""), false)
+	transport.useAdminUrls(adminUrls)
+
+	request, _ := http.NewRequest(http.MethodGet, rawUrls[0]+"/status", nil)
+	if _, err := transport.RoundTrip(request); err == nil || !isDialError(err) {
+		t.Errorf("expected the error connecting to the last admin url once every one is down, got: %v", err)
+	}
+	if active := transport.activeAdminUrl(); active != 0 {
+		t.Errorf("expected the active admin url to stay the same when none of them work, got: %d", active)
+	}
+}
+
+func TestAdminUrlsTrailingSlash(t *testing.T) {
+
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	downUrl := down.URL
+	down.Close()
+
+	var paths []string
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/" {
+			w.Write([]byte(`{"version":"3.6.1"}`))
+			return
+		}
+		w.Write([]byte(`{"id":"consumer-id","username":"alice"}`))
+	}))
+	defer up.Close()
+
+	// only one of the admin urls has a trailing slash, both join the request path the same way
+	for _, adminUrls := range [][]interface{}{{downUrl + "/", up.URL}, {downUrl, up.URL + "/"}} {
+		d := schema.TestResourceDataRaw(t, Provider().(*schema.Provider).Schema, map[string]interface{}{
+			"admin_urls": adminUrls,
+		})
+		meta, err := providerConfigure(d)
+		if err != nil {
+			t.Fatalf("could not configure provider: %v", err)
+		}
+
+		if found, err := meta.(*kongClient).get("/consumers/consumer-id", nil); err != nil || !found {
+			t.Fatalf("admin urls %v: expected the request to fail over, found: %t error: %v", adminUrls, found, err)
+		}
+		if expected := "/consumers/consumer-id"; paths[len(paths)-1] != expected {
+			t.Errorf("admin urls %v: expected %s to be sent, got: %v", adminUrls, expected, paths)
+		}
+	}
+
+	adminUrls, err := readAdminUrls([]string{"http://a:8001/", "http://b:8001", "http://c:8001/kong/"})
+	if err != nil {
+		t.Fatalf("could not read admin urls: %v", err)
+	}
+	for i, expected := range []string{"http://a:8001", "http://b:8001", "http://c:8001/kong"} {
+		if adminUrl := adminUrls[i].String(); adminUrl != expected {
+			t.Errorf("expected the admin url %s, got: %s", expected, adminUrl)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package kong
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// circuitBreaker stops sending requests to kong once threshold requests in a row have failed, the requests made during
+// the cooldown that follows fail straight away. After the cooldown one request is let through to see whether kong has
+// recovered, the breaker closes again when it succeeds and stays open for another cooldown when it fails.
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	lock      sync.Mutex
+	failures  int
+	openUntil time.Time
+	probing   bool
+	lastError string
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{threshold: threshold, cooldown: cooldown}
+}
+
+// circuitOpenError is returned for a request that was not sent because the circuit breaker is open
+type circuitOpenError struct {
+	failures  int
+	retryIn   time.Duration
+	lastError string
+}
+
+func (e *circuitOpenError) Error() string {
+	return fmt.Sprintf("not calling the kong admin api for another %s after %d failed requests in a row, the last one failed with: %s",
+		e.retryIn.Round(time.Second), e.failures, e.lastError)
+}
+
+// allow returns an error when the request must not be sent, a request that is let through has to be recorded
+func (b *circuitBreaker) allow() error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.failures < b.threshold {
+		return nil
+	}
+
+	now := time.Now()
+	if now.Before(b.openUntil) || b.probing {
+		retryIn := b.openUntil.Sub(now)
+		if retryIn < 0 {
+			retryIn = 0
+		}
+		return &circuitOpenError{failures: b.failures, retryIn: retryIn, lastError: b.lastError}
+	}
+
+	b.probing = true
+	return nil
+}
+
+// record counts the outcome of a request that was let through, err is nil for a request that did not fail
+func (b *circuitBreaker) record(err error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.probing = false
+	if err == nil {
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	b.lastError = err.Error()
+	if b.failures >= b.threshold {
+		b.openUntil = time.Now().Add(b.cooldown)
+	}
+}
+
+// release ends a request that was let through without recording it, a cancelled request says nothing about kong so it
+// neither counts as a failure nor closes the breaker. A cancelled check after the cooldown lets the next request check.
+func (b *circuitBreaker) release() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.probing = false
+}
+
+// requestCancelled is true for a request terraform cancelled before kong answered it
+func requestCancelled(r *http.Request, err error) bool {
+	return err != nil && r.Context().Err() == context.Canceled
+}
+
+// kongFailure tells whether a request failed in a way that shows kong is struggling: no response at all, a 5xx or a
+// 429. Requests that were cancelled by terraform are not recorded at all, see release.
+func kongFailure(r *http.Request, response *http.Response, err error) error {
+	if err != nil {
+		return err
+	}
+
+	if response.StatusCode >= 500 || response.StatusCode == http.StatusTooManyRequests {
+		return fmt.Errorf("kong responded to %s %s with status %d", r.Method, r.URL.Path, response.StatusCode)
+	}
+	return nil
+}
+
+// isCircuitOpenError is true for a request the circuit breaker did not send, gorequest returns the error of the
+// transport wrapped in a url.Error
+func isCircuitOpenError(err error) bool {
+	requestError, ok := err.(*requestError)
+	if !ok {
+		return false
+	}
+
+	for _, err := range requestError.errs {
+		if urlError, ok := err.(*url.Error); ok {
+			err = urlError.Err
+		}
+		if _, ok := err.(*circuitOpenError); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,199 @@
+package kong
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func TestAdminTransportCircuitBreaker(t *testing.T) {
+
+	var status, received int32 = http.StatusServiceUnavailable, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&received, 1)
+		w.WriteHeader(int(atomic.LoadInt32(&status)))
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	transport := newAdminTransport(userAgent(""), false)
+	transport.breakCircuit(3, 200*time.Millisecond)
+	client := &http.Client{Transport: transport}
+
+	get := func() error {
+		response, err := client.Get(server.URL + "/services")
+		if err != nil {
+			return err
+		}
+		ioutil.ReadAll(response.Body)
+		response.Body.Close()
+		return nil
+	}
+
+	// the kong responses are returned until the third failure in a row opens the breaker, then requests fail fast
+	for i := 0; i < 5; i++ {
+		err := get()
+		if i < 3 && err != nil {
+			t.Errorf("request %d: expected kong's response, got: %v", i, err)
+		}
+		if i >= 3 && (err == nil || !strings.Contains(err.Error(), "after 3 failed requests in a row, the last one failed with: kong responded to GET /services with status 503")) {
+			t.Errorf("request %d: expected the open breaker to fail the request, got: %v", i, err)
+		}
+	}
+	if n := atomic.LoadInt32(&received); n != 3 {
+		t.Errorf("expected kong to receive 3 requests while the breaker is open, it received %d", n)
+	}
+
+	// after the cooldown a request checks kong, it still fails so the breaker opens again straight away
+	time.Sleep(250 * time.Millisecond)
+	if err := get(); err != nil {
+		t.Errorf("expected the request after the cooldown to be sent, got: %v", err)
+	}
+	if err := get(); err == nil {
+		t.Errorf("expected the breaker to open again after the failed check")
+	}
+	if n := atomic.LoadInt32(&received); n != 4 {
+		t.Errorf("expected one request to be sent after the cooldown, kong received %d", n)
+	}
+
+	// kong recovered, the check after the next cooldown succeeds and closes the breaker
+	atomic.StoreInt32(&status, http.StatusOK)
+	time.Sleep(250 * time.Millisecond)
+	for i := 0; i < 3; i++ {
+		if err := get(); err != nil {
+			t.Errorf("request %d: expected the breaker to be closed once kong recovered, got: %v", i, err)
+		}
+	}
+	if n := atomic.LoadInt32(&received); n != 7 {
+		t.Errorf("expected every request to be sent once the breaker closed, kong received %d", n)
+	}
+}
+
+func TestAdminTransportCircuitBreakerIgnoresCancelledRequests(t *testing.T) {
+
+	var received int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&received, 1) > 2 {
+			// the check after the cooldown hangs until terraform cancels it
+			<-r.Context().Done()
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	transport := newAdminTransport(userAgent(""), false)
+	transport.breakCircuit(2, 100*time.Millisecond)
+	client := &http.Client{Transport: transport}
+
+	for i := 0; i < 2; i++ {
+		if response, err := client.Get(server.URL + "/services"); err == nil {
+			response.Body.Close()
+		}
+	}
+	if err := transport.breaker.allow(); err == nil {
+		t.Fatalf("expected two failures in a row to open the breaker")
+	}
+
+	time.Sleep(150 * time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	request, _ := http.NewRequest(http.MethodGet, server.URL+"/services", nil)
+	time.AfterFunc(50*time.Millisecond, cancel)
+	if _, err := client.Do(request.WithContext(ctx)); err == nil {
+		t.Fatalf("expected the cancelled check to fail")
+	}
+
+	// kong never answered, the breaker is still open: the next request checks kong again and others keep failing
+	if transport.breaker.failures != 2 {
+		t.Errorf("expected the cancelled check not to change the failures in a row, got: %d", transport.breaker.failures)
+	}
+	if err := transport.breaker.allow(); err != nil {
+		t.Errorf("expected the request after the cancelled check to check kong again, got: %v", err)
+	}
+	if err := transport.breaker.allow(); err == nil {
+		t.Errorf("expected the breaker to stay open after the cancelled check")
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+
+	breaker := newCircuitBreaker(2, time.Hour)
+	failure := errors.New("connection refused")
+
+	// a success ends the run of failures
+	breaker.record(failure)
+	breaker.record(nil)
+	breaker.record(failure)
+	if err := breaker.allow(); err != nil {
+		t.Errorf("expected failures that are not in a row not to open the breaker, got: %v", err)
+	}
+	breaker.record(failure)
+	if err := breaker.allow(); err == nil {
+		t.Errorf("expected two failures in a row to open the breaker")
+	}
+
+	// only one request checks kong after the cooldown, the others fail until it is recorded
+	breaker.openUntil = time.Now()
+	if err := breaker.allow(); err != nil {
+		t.Errorf("expected a request to check kong after the cooldown, got: %v", err)
+	}
+	if err := breaker.allow(); err == nil {
+		t.Errorf("expected a second request to fail while kong is being checked")
+	}
+	breaker.record(nil)
+	if err := breaker.allow(); err != nil {
+		t.Errorf("expected the breaker to close after a successful check, got: %v", err)
+	}
+
+	request := httptest.NewRequest(http.MethodGet, "/services", nil)
+	for status, failed := range map[int]bool{200: false, 404: false, 409: false, 429: true, 500: true, 502: true} {
+		if err := kongFailure(request, &http.Response{StatusCode: status}, nil); (err != nil) != failed {
+			t.Errorf("expected status %d to be a failure: %v, got: %v", status, failed, err)
+		}
+	}
+
+	open := &requestError{method: "GET", path: "/", errs: []error{&url.Error{Op: "Get", URL: "/", Err: &circuitOpenError{failures: 2}}}}
+	if !isCircuitOpenError(open) || isCircuitOpenError(&requestError{errs: []error{failure}}) {
+		t.Errorf("expected only the request the breaker did not send to be a circuit open error")
+	}
+}
+
+func TestProviderConfiguresCircuitBreaker(t *testing.T) {
+
+	for _, c := range []struct {
+		raw       map[string]interface{}
+		threshold int
+		cooldown  time.Duration
+	}{
+		{map[string]interface{}{}, 0, 0},
+		{map[string]interface{}{"circuit_breaker_threshold": 5}, 5, 30 * time.Second},
+		{map[string]interface{}{"circuit_breaker_threshold": 3, "circuit_breaker_cooldown_seconds": 10}, 3, 10 * time.Second},
+	} {
+		d := schema.TestResourceDataRaw(t, Provider().(*schema.Provider).Schema, c.raw)
+		meta, err := providerConfigure(d)
+		if err != nil {
+			t.Fatalf("%v: could not configure provider: %v", c.raw, err)
+		}
+
+		breaker := meta.(*kongClient).transport.(*adminTransport).breaker
+		if c.threshold == 0 && breaker != nil {
+			t.Errorf("%v: expected no circuit breaker", c.raw)
+		}
+		if c.threshold != 0 && (breaker == nil || breaker.threshold != c.threshold || breaker.cooldown != c.cooldown) {
+			t.Errorf("%v: expected a circuit breaker opening after %d failures for %s, got: %v", c.raw, c.threshold, c.cooldown, breaker)
+		}
+	}
+
+	if _, errors := validateCircuitBreakerCooldown(0, "circuit_breaker_cooldown_seconds"); len(errors) != 1 {
+		t.Errorf("expected a cooldown of 0 to be rejected, got: %v", errors)
+	}
+}
@@ -0,0 +1,484 @@
+package kong
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-querystring/query"
+	"github.com/hashicorp/go-version"
+	"github.com/kevholditch/gokong"
+	"github.com/parnurzeal/gorequest"
+)
+
+// kongClient is the provider meta. It embeds the gokong admin client and adds raw json requests, the provider only uses
+// the raw requests as they are the ones sent through the admin transport of the client (gokong builds a transport of
+// its own for every request).
+type kongClient struct {
+	*gokong.KongAdminClient
+	*kongClientState
+	config *gokong.Config
+	// ctx bounds the raw requests of a client returned by withTimeout, the gokong calls are not bounded by it
+	ctx context.Context
+}
+
+// kongClientState is shared by the provider's kongClient and the copies withTimeout makes of it
+type kongClientState struct {
+	// dbless is set when kong runs without a database, entities can then only be changed by loading a declarative config
+	dbless bool
+	// debug logs the fields that drifted from state when resources are read, see logDrift
+	debug bool
+	// offline skips every admin api call so plan can run without kong, see withOfflineResource
+	offline bool
+	// konnectControlPlaneId is set when the admin api is a konnect control plane, see useKonnect
+	konnectControlPlaneId string
+	// idempotentCreates creates services, routes and plugins with a PUT to an id derived from the entity, see create
+	idempotentCreates bool
+	// configJsonIndent indents the config_json stored in state, see formatConfigJson
+	configJsonIndent string
+	// transport sends every raw request of the client, each provider configuration has one of its own
+	transport http.RoundTripper
+
+	versionLock sync.Mutex
+	kongVersion *version.Version
+	// enterprise is set when the node is kong enterprise, it is read with the version
+	enterprise bool
+
+	// services looked up by name by the service data source, kept for the life of the provider (so one plan or apply)
+	serviceCacheLock sync.Mutex
+	serviceCache     map[string]*cachedService
+
+	// the schema of each plugin, see pluginSchema
+	pluginSchemaLock  sync.Mutex
+	pluginSchemaCache map[string]*pluginSchema
+}
+
+type cachedService struct {
+	lock    sync.Mutex
+	service *gokong.Service
+}
+
+func newKongClient(config *gokong.Config) *kongClient {
+	return &kongClient{
+		KongAdminClient: gokong.NewClient(config),
+		kongClientState: &kongClientState{transport: newAdminTransport(userAgent(""), config.InsecureSkipVerify)},
+		config:          config,
+	}
+}
+
+// withTimeout returns a client whose raw requests fail once timeout has passed, it is derived from the deadline of
+// client so a resource read at the end of a create is bounded by both. The cancel func has to be called when the
+// operation is done.
+func (client *kongClient) withTimeout(timeout time.Duration) (*kongClient, context.CancelFunc) {
+	parent := client.ctx
+	if parent == nil {
+		parent = context.Background()
+	}
+
+	ctx, cancel := context.WithTimeout(parent, timeout)
+
+	return &kongClient{
+		KongAdminClient: client.KongAdminClient,
+		kongClientState: client.kongClientState,
+		config:          client.config,
+		ctx:             ctx,
+	}, cancel
+}
+
+func (client *kongClient) newRequest(method string, path string) *gorequest.SuperAgent {
+	// tls_skip_verify is handled by the admin transport of the client, see end
+	r := gorequest.New().CustomMethod(method, client.config.HostAddress+path)
+
+	if client.config.Username != "" || client.config.Password != "" {
+		r.SetBasicAuth(client.config.Username, client.config.Password)
+	}
+
+	if client.config.ApiKey != "" {
+		r.Set("apikey", client.config.ApiKey)
+	}
+
+	if client.config.AdminToken != "" {
+		r.Set("kong-admin-token", client.config.AdminToken)
+	}
+
+	return r
+}
+
+// do sends the request and decodes the response into result (when result is not nil), it returns false if kong
+// responded with a 404.
+func (client *kongClient) do(method string, path string, request interface{}, result interface{}) (bool, error) {
+
+	if err := client.requireDatabase(method, path); err != nil {
+		return false, err
+	}
+
+	r := client.newRequest(method, path)
+	if request != nil {
+		r = r.Send(request)
+	}
+
+	response, body, errs := client.end(r)
+	if client.ctx != nil && client.ctx.Err() == context.DeadlineExceeded {
+		return false, fmt.Errorf("timed out calling %s %s, the operation did not finish within its timeout", method, path)
+	}
+	if errs != nil {
+		return false, &requestError{method: method, path: path, errs: errs}
+	}
+
+	if (response.StatusCode == 401 || response.StatusCode == 403) && client.konnectControlPlaneId != "" {
+		return false, konnectAuthError(client.konnectControlPlaneId, response.StatusCode, body)
+	}
+
+	if response.StatusCode == 401 || response.StatusCode == 403 {
+		return false, fmt.Errorf("not authorised, message from kong: %s", body)
+	}
+
+	if response.StatusCode == 404 {
+		return false, nil
+	}
+
+	if response.StatusCode >= 400 {
+		return false, &responseError{method: method, path: path, status: response.StatusCode, body: body}
+	}
+
+	if result != nil && body != "" {
+		if err := json.Unmarshal([]byte(body), result); err != nil {
+			return false, fmt.Errorf("could not parse response from %s %s, error: %v kong response: %s", method, path, err, body)
+		}
+	}
+
+	return true, nil
+}
+
+// requireDatabase fails the requests that change entities one by one when the provider is configured with dbless, kong
+// without a database only takes the whole config at once. Loading the declarative config and validating entities
+// against their schema are still sent.
+func (client *kongClient) requireDatabase(method string, path string) error {
+	if !client.dbless || method == gorequest.GET || path == declarativeConfigPath || strings.HasPrefix(path, "/schemas/") {
+		return nil
+	}
+
+	return fmt.Errorf("%s %s can not be sent when the provider is configured with dbless = true, kong without a database can only be changed with kong_declarative_config", method, path)
+}
+
+// end sends the request the way gorequest does but through the admin transport of the client, with the deadline of the
+// client when it has one. gorequest can only send a request with a transport it builds itself (or with the process
+// wide http.DefaultTransport), so it is only used to build the request.
+func (client *kongClient) end(r *gorequest.SuperAgent) (gorequest.Response, string, []error) {
+	if len(r.Errors) != 0 {
+		return nil, "", r.Errors
+	}
+
+	// the only part of gorequest's own preparation that applies, we never force a type or set the content type
+	if len(r.Data) != 0 && len(r.SliceData) != 0 {
+		r.BounceToRawString = true
+	}
+
+	request, err := r.MakeRequest()
+	if err != nil {
+		return nil, "", []error{err}
+	}
+
+	if client.ctx != nil {
+		request = request.WithContext(client.ctx)
+	}
+
+	response, err := (&http.Client{Transport: client.transport}).Do(request)
+	if err != nil {
+		return nil, "", []error{err}
+	}
+	defer response.Body.Close()
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, "", []error{err}
+	}
+
+	return response, string(body), nil
+}
+
+// requestError is returned when a request could not be sent or no response was received, it keeps the errors from
+// gorequest so callers can tell why (see isConnectionError).
+type requestError struct {
+	method string
+	path   string
+	errs   []error
+}
+
+func (e *requestError) Error() string {
+	return fmt.Sprintf("could not call %s %s, error: %v", e.method, e.path, e.errs)
+}
+
+// responseError is returned when kong responded with an error status, callers that expect one (e.g. a schema
+// violation) can read kong's response from it.
+type responseError struct {
+	method string
+	path   string
+	status int
+	body   string
+}
+
+func (e *responseError) Error() string {
+	return fmt.Sprintf("kong responded to %s %s with status %d: %s", e.method, e.path, e.status, e.body)
+}
+
+func (client *kongClient) get(path string, result interface{}) (bool, error) {
+	return client.do(gorequest.GET, path, nil, result)
+}
+
+func (client *kongClient) post(path string, request interface{}, result interface{}) error {
+	found, err := client.do(gorequest.POST, path, request, result)
+	if err == nil && !found {
+		return fmt.Errorf("kong responded to POST %s with status 404", path)
+	}
+	return err
+}
+
+func (client *kongClient) patch(path string, request interface{}, result interface{}) error {
+	found, err := client.do(gorequest.PATCH, path, request, result)
+	if err == nil && !found {
+		return fmt.Errorf("kong responded to PATCH %s with status 404", path)
+	}
+	return err
+}
+
+func (client *kongClient) put(path string, request interface{}, result interface{}) error {
+	found, err := client.do(gorequest.PUT, path, request, result)
+	if err == nil && !found {
+		return fmt.Errorf("kong responded to PUT %s with status 404", path)
+	}
+	return err
+}
+
+func (client *kongClient) delete(path string) error {
+	_, err := client.do(gorequest.DELETE, path, nil, nil)
+	return err
+}
+
+// deleteWithBody is for the endpoints that take what to delete in the body, e.g. the roles of an rbac user
+func (client *kongClient) deleteWithBody(path string, request interface{}) error {
+	_, err := client.do(gorequest.DELETE, path, request, nil)
+	return err
+}
+
+// listFiltered decodes the first page of the list endpoint at path into result, the fields of filter (one of gokong's
+// filters) are sent as the query string
+func (client *kongClient) listFiltered(path string, filter interface{}, result interface{}) error {
+	values, err := query.Values(filter)
+	if err != nil {
+		return fmt.Errorf("could not build query string for filter %v, error: %v", filter, err)
+	}
+
+	if encoded := values.Encode(); encoded != "" {
+		path = path + "?" + encoded
+	}
+
+	_, err = client.get(path, result)
+	return err
+}
+
+type listPage struct {
+	Data   []json.RawMessage `json:"data"`
+	Offset string            `json:"offset,omitempty"`
+}
+
+// listAll follows kong's offset based pagination for the list endpoint at path and returns the raw json of every
+// entity, callers decode each one into their own type.
+func (client *kongClient) listAll(path string) ([]json.RawMessage, error) {
+	results, found, err := client.listAllIfFound(path)
+	if err == nil && !found {
+		return nil, fmt.Errorf("kong responded to GET %s with status 404", path)
+	}
+	return results, err
+}
+
+// listAllIfFound is listAll for endpoints that may not exist, e.g. those of a plugin that is not installed. It returns
+// false when kong responds to the first page with a 404.
+func (client *kongClient) listAllIfFound(path string) ([]json.RawMessage, bool, error) {
+	var results []json.RawMessage
+	offset := ""
+
+	for {
+		pagePath := path
+		if offset != "" {
+			separator := "?"
+			if strings.Contains(path, "?") {
+				separator = "&"
+			}
+			pagePath = path + separator + "offset=" + url.QueryEscape(offset)
+		}
+
+		page := &listPage{}
+		found, err := client.get(pagePath, page)
+		if err != nil {
+			return nil, false, err
+		}
+
+		if !found && offset == "" {
+			return nil, false, nil
+		}
+
+		if !found {
+			return nil, false, fmt.Errorf("kong responded to GET %s with status 404", pagePath)
+		}
+
+		results = append(results, page.Data...)
+
+		if page.Offset == "" || len(page.Data) == 0 {
+			return results, true, nil
+		}
+		offset = page.Offset
+	}
+}
+
+type nodeInformation struct {
+	Version string `json:"version"`
+	Edition string `json:"edition"`
+}
+
+// version returns the version of the kong node, it is read from the admin api root the first time it is needed.
+func (client *kongClient) version() (*version.Version, error) {
+	client.versionLock.Lock()
+	defer client.versionLock.Unlock()
+
+	if client.kongVersion != nil {
+		return client.kongVersion, nil
+	}
+
+	node := &nodeInformation{}
+	found, err := client.get("/", node)
+	if err != nil {
+		return nil, err
+	}
+
+	if !found {
+		return nil, fmt.Errorf("kong responded to GET / with status 404")
+	}
+
+	kongVersion, err := parseKongVersion(node.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	client.kongVersion = kongVersion
+	client.enterprise = isEnterpriseNode(node)
+	return kongVersion, nil
+}
+
+// isEnterpriseNode tells kong enterprise from the open source edition. Newer nodes report their edition, older
+// enterprise versions have an -enterprise-edition suffix and the 3.x ones four version numbers (3.6.1.0) where the open
+// source edition has three.
+func isEnterpriseNode(node *nodeInformation) bool {
+	if node.Edition != "" {
+		return node.Edition == "enterprise"
+	}
+
+	if strings.Contains(node.Version, "enterprise") {
+		return true
+	}
+
+	numbers := node.Version
+	if i := strings.IndexAny(numbers, "-+ "); i >= 0 {
+		numbers = numbers[:i]
+	}
+
+	return strings.Count(numbers, ".") == 3
+}
+
+// parseKongVersion drops anything after the version numbers, enterprise versions look like 3.6.1.0-enterprise-edition
+// which would otherwise be treated as a pre-release and compare lower than 3.6.1.
+func parseKongVersion(raw string) (*version.Version, error) {
+	numbers := raw
+	if i := strings.IndexAny(numbers, "-+ "); i >= 0 {
+		numbers = numbers[:i]
+	}
+
+	kongVersion, err := version.NewVersion(numbers)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse kong version %q: %v", raw, err)
+	}
+
+	return kongVersion, nil
+}
+
+// requireVersion returns an error naming the feature when the kong node is older than minimumVersion
+func (client *kongClient) requireVersion(feature string, minimumVersion string) error {
+	kongVersion, err := client.version()
+	if err != nil {
+		return fmt.Errorf("could not check kong version for %s: %v", feature, err)
+	}
+
+	if kongVersion.LessThan(version.Must(version.NewVersion(minimumVersion))) {
+		return fmt.Errorf("%s requires kong %s or later, kong version is %s", feature, minimumVersion, kongVersion)
+	}
+
+	return nil
+}
+
+// requireEnterprise returns an error naming the feature when the kong node is the open source edition
+func (client *kongClient) requireEnterprise(feature string) error {
+	kongVersion, err := client.version()
+	if err != nil {
+		return fmt.Errorf("could not check kong edition for %s: %v", feature, err)
+	}
+
+	client.versionLock.Lock()
+	enterprise := client.enterprise
+	client.versionLock.Unlock()
+
+	if !enterprise {
+		return fmt.Errorf("%s requires kong enterprise, kong %s is the open source edition", feature, kongVersion)
+	}
+
+	return nil
+}
+
+// clearServiceCache drops every cached service so a service that was created, updated or deleted is looked up again
+func (client *kongClient) clearServiceCache() {
+	client.serviceCacheLock.Lock()
+	client.serviceCache = nil
+	client.serviceCacheLock.Unlock()
+}
+
+// getServiceByName returns the service with the name, only the first lookup of each name calls kong unless bypassCache
+// is set. Services that are not found are not cached so they are looked up again next time.
+func (client *kongClient) getServiceByName(name string, bypassCache bool) (*gokong.Service, error) {
+	client.serviceCacheLock.Lock()
+	if client.serviceCache == nil {
+		client.serviceCache = map[string]*cachedService{}
+	}
+	cached, ok := client.serviceCache[name]
+	if !ok {
+		cached = &cachedService{}
+		client.serviceCache[name] = cached
+	}
+	client.serviceCacheLock.Unlock()
+
+	// locking the entry rather than the whole cache lets reads of different services run in parallel while reads of
+	// the same service wait for the first one
+	cached.lock.Lock()
+	defer cached.lock.Unlock()
+
+	if cached.service != nil && !bypassCache {
+		return cached.service, nil
+	}
+
+	service := &gokong.Service{}
+	found, err := client.get(gokong.ServicesPath+name, service)
+	if err != nil {
+		return nil, err
+	}
+
+	if !found || service.Id == nil {
+		service = nil
+	}
+
+	cached.service = service
+	return service, nil
+}
@@ -0,0 +1,117 @@
+package kong
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kevholditch/gokong"
+)
+
+func TestParseKongVersion(t *testing.T) {
+
+	versions := map[string]string{
+		"0.13.1":                     "0.13.1",
+		"3.6.0":                      "3.6.0",
+		"3.6.1.0-enterprise-edition": "3.6.1.0",
+		"2.8.1-rc1":                  "2.8.1",
+	}
+
+	for raw, expected := range versions {
+		kongVersion, err := parseKongVersion(raw)
+		if err != nil {
+			t.Errorf("could not parse %s: %v", raw, err)
+			continue
+		}
+		if kongVersion.String() != expected {
+			t.Errorf("expected %s to be parsed as %s but was %s", raw, expected, kongVersion)
+		}
+	}
+
+	if _, err := parseKongVersion("not a version"); err == nil {
+		t.Errorf("expected an invalid version to be rejected")
+	}
+}
+
+func TestRequireVersion(t *testing.T) {
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"version":"3.6.1.0-enterprise-edition"}`))
+	}))
+	defer server.Close()
+
+	client := newKongClient(&gokong.Config{HostAddress: server.URL})
+
+	if err := client.requireVersion("kong_key", "3.6.0"); err != nil {
+		t.Errorf("expected kong 3.6.1.0 to satisfy 3.6.0, got: %v", err)
+	}
+
+	if err := client.requireVersion("something_newer", "3.7.0"); err == nil {
+		t.Errorf("expected kong 3.6.1.0 not to satisfy 3.7.0")
+	}
+
+	if requests != 1 {
+		t.Errorf("expected the kong version to be read once but it was read %d times", requests)
+	}
+}
+
+func TestIsEnterpriseNode(t *testing.T) {
+
+	nodes := map[nodeInformation]bool{
+		{Version: "0.13.1"}:                        false,
+		{Version: "3.6.0"}:                         false,
+		{Version: "2.8.1-rc1"}:                     false,
+		{Version: "2.8.4.1-enterprise-edition"}:    true,
+		{Version: "3.6.1.0"}:                       true,
+		{Version: "3.7.0", Edition: "enterprise"}:  true,
+		{Version: "3.7.0.0", Edition: "community"}: false,
+	}
+
+	for node, enterprise := range nodes {
+		node := node
+		if isEnterpriseNode(&node) != enterprise {
+			t.Errorf("expected %+v to be enterprise: %t", node, enterprise)
+		}
+	}
+}
+
+func TestRequireDatabase(t *testing.T) {
+
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.Method+" "+r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := newKongClient(&gokong.Config{HostAddress: server.URL})
+	client.dbless = true
+
+	if err := client.post(gokong.ConsumersPath, map[string]string{"username": "test"}, nil); err == nil {
+		t.Errorf("expected a POST to be rejected when dbless is set")
+	}
+	if err := client.put(gokong.ServicesPath+"test", map[string]string{"host": "test.org"}, nil); err == nil {
+		t.Errorf("expected a PUT to be rejected when dbless is set")
+	}
+	if err := client.patch(gokong.RoutesPath+"test", map[string]string{"name": "test"}, nil); err == nil {
+		t.Errorf("expected a PATCH to be rejected when dbless is set")
+	}
+	if err := client.delete(gokong.PluginsPath + "test"); err == nil {
+		t.Errorf("expected a DELETE to be rejected when dbless is set")
+	}
+
+	if _, err := client.get(gokong.ConsumersPath+"test", nil); err != nil {
+		t.Errorf("expected a GET to be sent when dbless is set, got: %v", err)
+	}
+	if err := loadKongDeclarativeConfig(client, "_format_version: \"1.1\""); err != nil {
+		t.Errorf("expected the declarative config to be loaded when dbless is set, got: %v", err)
+	}
+
+	if len(paths) != 2 || paths[0] != "GET /consumers/test" || paths[1] != "POST /config" {
+		t.Errorf("expected only the GET and the declarative config to reach kong but got %v", paths)
+	}
+}
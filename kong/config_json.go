@@ -0,0 +1,406 @@
+package kong
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+const sensitiveValueHashPrefix = "sha256:"
+
+const redactedValue = "<redacted>"
+
+// maxConfigJsonIndent is the most spaces config_json_indent can indent config_json by
+const maxConfigJsonIndent = 8
+
+// sensitiveConfigKeys are plugin config keys whose values are masked in error messages, a key also matches when it ends
+// with one of these after an underscore, e.g. api_key or hmac_secret.
+var sensitiveConfigKeys = []string{"password", "secret", "key", "client_secret"}
+
+// pluginUrlConfigKeys are the top level config keys of each plugin that hold a url, kong normalizes them (drops a
+// default port or a lone trailing slash) so the config read back can differ from the config that was sent.
+var pluginUrlConfigKeys = map[string][]string{
+	"http-log":             {"http_endpoint"},
+	"zipkin":               {"http_endpoint"},
+	"opentelemetry":        {"endpoint"},
+	"oauth2-introspection": {"introspection_url"},
+	"openid-connect":       {"issuer"},
+	"aws-lambda":           {"proxy_url"},
+}
+
+// hashSensitiveValues replaces every leaf of a decoded json object with a hash of its json encoding, the structure
+// (and so the path to each sensitive value) is kept so it can be reconciled against the upstream config.
+func hashSensitiveValues(data map[string]interface{}) map[string]interface{} {
+	hashed := map[string]interface{}{}
+	for key, val := range data {
+		if nested, ok := val.(map[string]interface{}); ok {
+			hashed[key] = hashSensitiveValues(nested)
+		} else {
+			hashed[key] = hashSensitiveValue(val)
+		}
+	}
+	return hashed
+}
+
+func hashSensitiveValue(val interface{}) string {
+	rawJson, _ := json.Marshal(val)
+	sum := sha256.Sum256(rawJson)
+	return sensitiveValueHashPrefix + hex.EncodeToString(sum[:])
+}
+
+// withoutHashedValues returns a copy of data without the leaves that are hashes of sensitive values. sensitive_config_json
+// reads back from state as hashes when it did not change, sending those would overwrite the secrets kong has with them.
+func withoutHashedValues(data map[string]interface{}) map[string]interface{} {
+	unhashed := map[string]interface{}{}
+	for key, val := range data {
+		if nested, ok := val.(map[string]interface{}); ok {
+			if nested = withoutHashedValues(nested); len(nested) > 0 {
+				unhashed[key] = nested
+			}
+		} else if hash, ok := val.(string); !ok || !strings.HasPrefix(hash, sensitiveValueHashPrefix) {
+			unhashed[key] = val
+		}
+	}
+	return unhashed
+}
+
+func normalizeSensitiveDataJSON(configI interface{}) string {
+	dataMap := map[string]interface{}{}
+	err := json.Unmarshal([]byte(configI.(string)), &dataMap)
+	if err != nil {
+		// The validate function should've taken care of this.
+		log.Printf("[ERROR] Invalid JSON data in sensitive_config_json: %s", err)
+		return ""
+	}
+
+	ret, _ := json.Marshal(hashSensitiveValues(dataMap))
+
+	return string(ret)
+}
+
+// mergeJSONObjects merges src into dst, nested objects are merged key by key and every other value in src replaces the
+// value in dst.
+func mergeJSONObjects(dst map[string]interface{}, src map[string]interface{}) map[string]interface{} {
+	if dst == nil {
+		dst = map[string]interface{}{}
+	}
+	for key, val := range src {
+		srcNested, srcIsObject := val.(map[string]interface{})
+		dstNested, dstIsObject := dst[key].(map[string]interface{})
+		if srcIsObject && dstIsObject {
+			dst[key] = mergeJSONObjects(dstNested, srcNested)
+		} else {
+			dst[key] = val
+		}
+	}
+	return dst
+}
+
+// copyJSONObject returns a copy of a decoded json object that shares nothing with it
+func copyJSONObject(data map[string]interface{}) map[string]interface{} {
+	rawJson, _ := json.Marshal(data)
+	copied := map[string]interface{}{}
+	json.Unmarshal(rawJson, &copied)
+	return copied
+}
+
+// extractJSONPaths removes every leaf path present in paths from data and returns the removed values with the same
+// structure as paths, the second return value is false if any of the paths was missing from data.
+func extractJSONPaths(data map[string]interface{}, paths map[string]interface{}) (map[string]interface{}, bool) {
+	extracted := map[string]interface{}{}
+	complete := true
+	for key, val := range paths {
+		upstream, ok := data[key]
+		if !ok {
+			complete = false
+			continue
+		}
+
+		nestedPaths, pathIsObject := val.(map[string]interface{})
+		nestedData, dataIsObject := upstream.(map[string]interface{})
+		if pathIsObject && dataIsObject {
+			nestedExtracted, nestedComplete := extractJSONPaths(nestedData, nestedPaths)
+			extracted[key] = nestedExtracted
+			complete = complete && nestedComplete
+			if len(nestedData) == 0 {
+				delete(data, key)
+			}
+		} else {
+			extracted[key] = upstream
+			delete(data, key)
+		}
+	}
+	return extracted, complete
+}
+
+// vaultReferencePattern matches a config value that references a secret in a kong vault, e.g. {vault://env/redis-password}
+var vaultReferencePattern = regexp.MustCompile(`^\{vault://[^}]+\}$`)
+
+// keepVaultReferences puts the vault references of the configured config back into the config read from kong. Kong
+// may return a referenced secret resolved, which would both store the secret in state and show as a change from the
+// reference on every plan. A value is only replaced when kong returned something that is not a reference, a different
+// reference is a real change and is kept.
+func keepVaultReferences(upstream interface{}, configured interface{}) interface{} {
+	return keepConfiguredStrings(upstream, configured, func(upstream string, configured string) bool {
+		return vaultReferencePattern.MatchString(configured) && !vaultReferencePattern.MatchString(upstream)
+	})
+}
+
+// encryptedPlaceholderPattern matches the placeholder kong enterprise can return in place of a config field it
+// stores encrypted, a run of asterisks such as ******
+var encryptedPlaceholderPattern = regexp.MustCompile(`^\*{3,}$`)
+
+// keepEncryptedConfigValues puts the configured value back into the config read from kong where kong returned the
+// placeholder of an encrypted field, the placeholder would otherwise replace the real value in state and show as a
+// change on every plan. A change made to an encrypted field outside of terraform can not be seen.
+func keepEncryptedConfigValues(upstream interface{}, configured interface{}) interface{} {
+	return keepConfiguredStrings(upstream, configured, func(upstream string, configured string) bool {
+		return encryptedPlaceholderPattern.MatchString(upstream) && !encryptedPlaceholderPattern.MatchString(configured)
+	})
+}
+
+// keepConfiguredStrings walks the configured config and the config read from kong together, replacing each string kong
+// returned with the configured one when keep says so. Objects are walked key by key and lists when both have the same
+// length, the config read from kong is changed in place.
+func keepConfiguredStrings(upstream interface{}, configured interface{}, keep func(upstream string, configured string) bool) interface{} {
+	switch configured := configured.(type) {
+	case map[string]interface{}:
+		if upstream, ok := upstream.(map[string]interface{}); ok {
+			for key, val := range configured {
+				if upstreamVal, ok := upstream[key]; ok {
+					upstream[key] = keepConfiguredStrings(upstreamVal, val, keep)
+				}
+			}
+		}
+	case []interface{}:
+		if upstream, ok := upstream.([]interface{}); ok && len(upstream) == len(configured) {
+			for i := range upstream {
+				upstream[i] = keepConfiguredStrings(upstream[i], configured[i], keep)
+			}
+		}
+	case string:
+		if upstream, ok := upstream.(string); ok && keep(upstream, configured) {
+			return configured
+		}
+	}
+	return upstream
+}
+
+// suppressEquivalentConfigJson suppresses config_json diffs that only differ in how numbers are represented, Kong may
+// return 5 where the config says "5" (or the other way around) depending on the field's type in the plugin schema.
+// The url config keys of the plugin (see pluginUrlConfigKeys) are compared after normalizing them the way kong does.
+// Keys kong added with their schema default are ignored when the config does not set them, which needs the schema of
+// the plugin to have been read by client (see pluginSchemaDefaults), the resources get the client of their provider
+// through withPluginSchemaDefaults. Fields kong renamed compare equal under either name (see pluginConfigAliases).
+func suppressEquivalentPluginConfigJson(client *kongClient, k, old, new string, d *schema.ResourceData) bool {
+	if new == "" {
+		return true
+	}
+
+	var oldData, newData interface{}
+	if err := json.Unmarshal([]byte(old), &oldData); err != nil {
+		return false
+	}
+	if err := json.Unmarshal([]byte(new), &newData); err != nil {
+		return false
+	}
+
+	urlKeys := pluginUrlConfigKeys[configPluginName(d)]
+	normalizeConfigUrls(oldData, urlKeys)
+	normalizeConfigUrls(newData, urlKeys)
+
+	oldConfig, oldIsObject := oldData.(map[string]interface{})
+	newConfig, newIsObject := newData.(map[string]interface{})
+	if oldIsObject && newIsObject {
+		normalizePluginConfigAliases(configPluginName(d), oldConfig)
+		normalizePluginConfigAliases(configPluginName(d), newConfig)
+
+		if defaults, _ := client.pluginSchemaDefaults(configPluginName(d)); defaults != nil {
+			removeUnsetConfigDefaults(oldConfig, newConfig, defaults)
+		}
+	}
+
+	return jsonEqualIgnoringNumericStrings(oldData, newData)
+}
+
+// suppressEquivalentConfigJson is suppressEquivalentPluginConfigJson without a client, so without the schema defaults
+func suppressEquivalentConfigJson(k, old, new string, d *schema.ResourceData) bool {
+	return suppressEquivalentPluginConfigJson(nil, k, old, new, d)
+}
+
+// configPluginName is the plugin name of the resources that have a config_json, kong_plugin calls it name and the
+// consumer resources plugin_name
+func configPluginName(d *schema.ResourceData) string {
+	if d == nil {
+		return ""
+	}
+
+	for _, key := range []string{"name", "plugin_name"} {
+		if name, ok := d.Get(key).(string); ok && name != "" {
+			return name
+		}
+	}
+
+	return ""
+}
+
+func normalizeConfigUrls(data interface{}, keys []string) {
+	config, ok := data.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	for _, key := range keys {
+		if value, ok := config[key].(string); ok {
+			config[key] = normalizeUrl(value)
+		}
+	}
+}
+
+var defaultUrlPorts = map[string]string{"http": "80", "https": "443"}
+
+// normalizeUrl lower cases the scheme and host and drops the default port of the scheme and a path that is only "/",
+// so http://Example.com:80/ and http://example.com are the same. Values that are not absolute urls are left as they are.
+func normalizeUrl(value string) string {
+	parsed, err := url.Parse(value)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return value
+	}
+
+	parsed.Scheme = strings.ToLower(parsed.Scheme)
+	host, port := parsed.Hostname(), parsed.Port()
+	if port == defaultUrlPorts[parsed.Scheme] {
+		port = ""
+	}
+	parsed.Host = strings.ToLower(host)
+	if strings.Contains(host, ":") {
+		parsed.Host = "[" + parsed.Host + "]"
+	}
+	if port != "" {
+		parsed.Host += ":" + port
+	}
+
+	if parsed.Path == "/" && parsed.RawQuery == "" && parsed.Fragment == "" {
+		parsed.Path = ""
+	}
+
+	return parsed.String()
+}
+
+func jsonEqualIgnoringNumericStrings(a interface{}, b interface{}) bool {
+	switch aVal := a.(type) {
+	case map[string]interface{}:
+		bVal, ok := b.(map[string]interface{})
+		if !ok || len(aVal) != len(bVal) {
+			return false
+		}
+		for key, val := range aVal {
+			other, ok := bVal[key]
+			if !ok || !jsonEqualIgnoringNumericStrings(val, other) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		bVal, ok := b.([]interface{})
+		if !ok || len(aVal) != len(bVal) {
+			return false
+		}
+		for i := range aVal {
+			if !jsonEqualIgnoringNumericStrings(aVal[i], bVal[i]) {
+				return false
+			}
+		}
+		return true
+	case float64:
+		return numericStringEquals(aVal, b)
+	case string:
+		if bNumber, ok := b.(float64); ok {
+			return numericStringEquals(bNumber, aVal)
+		}
+		return aVal == b
+	default:
+		return reflect.DeepEqual(a, b)
+	}
+}
+
+// numericStringEquals only treats a string as equal to a number when it is exactly a decimal representation of that
+// number, anything else (e.g. "5s" or "") is left as a real difference for Kong to validate.
+func numericStringEquals(number float64, other interface{}) bool {
+	switch otherVal := other.(type) {
+	case float64:
+		return number == otherVal
+	case string:
+		parsed, err := strconv.ParseFloat(otherVal, 64)
+		return err == nil && parsed == number
+	}
+	return false
+}
+
+func isSensitiveConfigKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitiveKey := range sensitiveConfigKeys {
+		if key == sensitiveKey || strings.HasSuffix(key, "_"+sensitiveKey) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactConfig returns a copy of config that is safe to include in errors and logs, values of sensitive keys and every
+// path present in sensitivePaths (the sensitive_config_json of the resource) are replaced. config itself is not changed.
+func redactConfig(config map[string]interface{}, sensitivePaths map[string]interface{}) map[string]interface{} {
+	if config == nil {
+		return nil
+	}
+
+	redacted := map[string]interface{}{}
+	for key, val := range config {
+		sensitivePath, isSensitivePath := sensitivePaths[key]
+		nestedPaths, _ := sensitivePath.(map[string]interface{})
+
+		if isSensitiveConfigKey(key) || (isSensitivePath && nestedPaths == nil) {
+			redacted[key] = redactedValue
+		} else if nested, ok := val.(map[string]interface{}); ok {
+			redacted[key] = redactConfig(nested, nestedPaths)
+		} else {
+			redacted[key] = val
+		}
+	}
+	return redacted
+}
+
+// redactedJson marshals a value that was redacted (see redactConfig) for an error or a log, without escaping the < and
+// > of redactedValue the way json.Marshal does
+func redactedJson(value interface{}) string {
+	var rawJson bytes.Buffer
+	encoder := json.NewEncoder(&rawJson)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(value)
+	return strings.TrimSpace(rawJson.String())
+}
+
+// formatConfigJson is the config_json read from kong as it is stored in state, indented by the config_json_indent
+// of the provider when it is set. The keys are already sorted as the json was marshalled from a map, so the output is
+// the same on every refresh. Only the stored string is changed, config_json is compared as json (see
+// suppressEquivalentConfigJson) so the indented state matches the config it was applied from.
+func (client *kongClient) formatConfigJson(configJson string) string {
+	if client.configJsonIndent == "" {
+		return configJson
+	}
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, []byte(configJson), "", client.configJsonIndent); err != nil {
+		return configJson
+	}
+	return indented.String()
+}
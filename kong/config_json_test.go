@@ -0,0 +1,173 @@
+package kong
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func TestHashSensitiveValuesKeepsStructure(t *testing.T) {
+
+	hashed := hashSensitiveValues(map[string]interface{}{
+		"password": "s3cr3t",
+		"redis": map[string]interface{}{
+			"port": float64(6379),
+		},
+	})
+
+	if hashed["password"] != hashSensitiveValue("s3cr3t") {
+		t.Errorf("expected password to be hashed, got: %v", hashed["password"])
+	}
+
+	redis, ok := hashed["redis"].(map[string]interface{})
+	if !ok || redis["port"] != hashSensitiveValue(float64(6379)) {
+		t.Errorf("expected nested redis.port to be hashed, got: %v", hashed["redis"])
+	}
+
+	if normalizeSensitiveDataJSON(`{"password": "s3cr3t"}`) != `{"password":"`+hashSensitiveValue("s3cr3t")+`"}` {
+		t.Errorf("expected normalized sensitive json to only contain the hash, got: %s", normalizeSensitiveDataJSON(`{"password": "s3cr3t"}`))
+	}
+}
+
+func TestMergeAndExtractJSONPaths(t *testing.T) {
+
+	sensitive := map[string]interface{}{
+		"redis": map[string]interface{}{
+			"password": "s3cr3t",
+		},
+	}
+
+	config := mergeJSONObjects(map[string]interface{}{
+		"minute": float64(10),
+		"redis": map[string]interface{}{
+			"host": "redis.example.com",
+		},
+	}, sensitive)
+
+	extracted, complete := extractJSONPaths(config, sensitive)
+
+	if !complete || !reflect.DeepEqual(extracted, sensitive) {
+		t.Errorf("expected to extract %v, got: %v", sensitive, extracted)
+	}
+
+	expected := map[string]interface{}{
+		"minute": float64(10),
+		"redis": map[string]interface{}{
+			"host": "redis.example.com",
+		},
+	}
+
+	if !reflect.DeepEqual(config, expected) {
+		t.Errorf("expected sensitive paths to be removed leaving %v, got: %v", expected, config)
+	}
+
+	if _, complete := extractJSONPaths(map[string]interface{}{}, sensitive); complete {
+		t.Errorf("expected extraction from config missing the sensitive path to be incomplete")
+	}
+}
+
+func TestSuppressEquivalentConfigJson(t *testing.T) {
+
+	cases := []struct {
+		old      string
+		new      string
+		suppress bool
+	}{
+		{`{"minute":10}`, ``, true},
+		{`{"minute":10}`, `{"minute":"10"}`, true},
+		{`{"minute":"10"}`, `{"minute":10}`, true},
+		{`{"limits":{"sms":{"minute":1.5}}}`, `{"limits":{"sms":{"minute":"1.5"}}}`, true},
+		{`{"ports":[80,443]}`, `{"ports":["80","443"]}`, true},
+		{`{"minute":10}`, `{"minute":"11"}`, false},
+		{`{"minute":10}`, `{"minute":"10s"}`, false},
+		{`{"minute":0}`, `{"minute":""}`, false},
+		{`{"enabled":true}`, `{"enabled":"true"}`, false},
+		{`{"minute":10}`, `{"minute":10,"hour":100}`, false},
+	}
+
+	for _, c := range cases {
+		if suppressEquivalentConfigJson("config_json", c.old, c.new, nil) != c.suppress {
+			t.Errorf("expected suppress to be %v for old: %s new: %s", c.suppress, c.old, c.new)
+		}
+	}
+}
+
+func TestSuppressEquivalentConfigJsonUrls(t *testing.T) {
+
+	httpLog := schema.TestResourceDataRaw(t, resourceKongPlugin().Schema, map[string]interface{}{"name": "http-log"})
+	consumerHttpLog := schema.TestResourceDataRaw(t, resourceKongConsumerPluginConfig().Schema, map[string]interface{}{"plugin_name": "http-log"})
+	cors := schema.TestResourceDataRaw(t, resourceKongPlugin().Schema, map[string]interface{}{"name": "cors"})
+
+	cases := []struct {
+		d        *schema.ResourceData
+		old      string
+		new      string
+		suppress bool
+	}{
+		{httpLog, `{"http_endpoint":"http://logs.example.com"}`, `{"http_endpoint":"http://logs.example.com:80/"}`, true},
+		{httpLog, `{"http_endpoint":"https://logs.example.com/"}`, `{"http_endpoint":"https://LOGS.example.com:443"}`, true},
+		{consumerHttpLog, `{"http_endpoint":"http://logs.example.com"}`, `{"http_endpoint":"http://logs.example.com:80"}`, true},
+		{httpLog, `{"http_endpoint":"http://logs.example.com:8080"}`, `{"http_endpoint":"http://logs.example.com"}`, false},
+		{httpLog, `{"http_endpoint":"https://logs.example.com"}`, `{"http_endpoint":"https://logs.example.com:80"}`, false},
+		{httpLog, `{"http_endpoint":"http://logs.example.com/bulk"}`, `{"http_endpoint":"http://logs.example.com/bulk/"}`, false},
+		{cors, `{"origins":"http://example.com"}`, `{"origins":"http://example.com:80/"}`, false},
+	}
+
+	for _, c := range cases {
+		if suppressEquivalentConfigJson("config_json", c.old, c.new, c.d) != c.suppress {
+			t.Errorf("expected suppress to be %v for %s old: %s new: %s", c.suppress, configPluginName(c.d), c.old, c.new)
+		}
+	}
+}
+
+func TestNormalizeUrl(t *testing.T) {
+
+	urls := map[string]string{
+		"http://example.com:80/":       "http://example.com",
+		"HTTPS://Example.com:443/path": "https://example.com/path",
+		"http://[::1]:80/":             "http://[::1]",
+		"http://example.com:8080/?a=1": "http://example.com:8080/?a=1",
+		"example.com:80":               "example.com:80",
+		"not a url":                    "not a url",
+	}
+
+	for value, expected := range urls {
+		if normalized := normalizeUrl(value); normalized != expected {
+			t.Errorf("expected %s to be normalized to %s but was %s", value, expected, normalized)
+		}
+	}
+}
+
+func TestRedactConfig(t *testing.T) {
+
+	config := map[string]interface{}{
+		"client_id":     "my-client",
+		"client_secret": "t0p-s3cr3t",
+		"Password":      "pw",
+		"api_key":       "k3y",
+		"key_names":     []interface{}{"apikey"},
+		"session":       map[string]interface{}{"redis_password": "r3d1s-pw", "redis_host": "redis"},
+		"issuer":        "https://h1dden.example.com",
+	}
+
+	redacted := redactConfig(config, map[string]interface{}{"issuer": "sha256:abc"})
+
+	expected := map[string]interface{}{
+		"client_id":     "my-client",
+		"client_secret": redactedValue,
+		"Password":      redactedValue,
+		"api_key":       redactedValue,
+		"key_names":     []interface{}{"apikey"},
+		"session":       map[string]interface{}{"redis_password": redactedValue, "redis_host": "redis"},
+		"issuer":        redactedValue,
+	}
+
+	if !reflect.DeepEqual(redacted, expected) {
+		t.Errorf("expected %v but was %v", expected, redacted)
+	}
+
+	if config["client_secret"] != "t0p-s3cr3t" {
+		t.Errorf("expected the original config not to be changed")
+	}
+}
@@ -108,7 +108,8 @@ func dataSourceKongApiRead(d *schema.ResourceData, meta interface{}) error {
 		}
 	}
 
-	results, err := meta.(*gokong.KongAdminClient).Apis().ListFiltered(filter)
+	results := &gokong.Apis{}
+	err := meta.(*kongClient).listFiltered(gokong.ApisPath, filter, results)
 
 	if err != nil {
 		return fmt.Errorf("could not find api, error: %v", err)
@@ -47,13 +47,14 @@ func dataSourceKongCertificateRead(d *schema.ResourceData, meta interface{}) err
 		}
 	}
 
-	result, err := meta.(*gokong.KongAdminClient).Certificates().GetById(filterId)
+	result := &gokong.Certificate{}
+	found, err := meta.(*kongClient).get(gokong.CertificatesPath+filterId, result)
 
 	if err != nil {
 		return fmt.Errorf("could not find certificate, error: %v", err)
 	}
 
-	if result == nil {
+	if !found || result.Id == nil {
 		return fmt.Errorf("could not find certificate by id: %v", filterId)
 	}
 
@@ -0,0 +1,188 @@
+package kong
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/parnurzeal/gorequest"
+)
+
+// configValidationMinimumKongVersion is the first kong with the /schemas/<entity>/validate endpoints
+const configValidationMinimumKongVersion = "1.1.0"
+
+// nilEntityId is sent as the parent of a nested entity that has no id, the validate endpoints only check the schema so
+// any id satisfies a required foreign key.
+const nilEntityId = "00000000-0000-0000-0000-000000000000"
+
+// declarativeNestedEntities are the collections a declarative config can nest under another entity, any other list of
+// objects (e.g. the sources of a route) is a field of the entity itself.
+var declarativeNestedEntities = []string{
+	"routes", "plugins", "targets", "snis", "acls", "keyauth_credentials", "basicauth_credentials",
+	"hmacauth_credentials", "jwt_secrets", "oauth2_credentials", "mtls_auth_credentials",
+}
+
+type schemaViolation struct {
+	Message string `json:"message"`
+}
+
+func dataSourceKongConfigValidation() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceKongConfigValidationRead,
+		Schema: map[string]*schema.Schema{
+			"config": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validateJsonDeclarativeConfig,
+				Description:  "The declarative config (JSON) to validate, nothing in it is applied",
+			},
+			"valid": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			// one error per invalid entity, prefixed with where it is in the config e.g. services[0].routes[1]
+			"errors": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+// kong can only check a config by loading it, so the entities are taken out of the config here and each one is
+// validated on its own, which needs the config to be parsed and so to be JSON.
+func validateJsonDeclarativeConfig(v interface{}, k string) ([]string, []error) {
+	if _, errors := validateDeclarativeConfig(v, k); len(errors) > 0 {
+		return nil, errors
+	}
+	if !strings.HasPrefix(strings.TrimSpace(v.(string)), "{") {
+		return nil, []error{fmt.Errorf("%s must be JSON, a YAML declarative config can not be validated (use jsonencode or yamldecode)", k)}
+	}
+	return nil, nil
+}
+
+func dataSourceKongConfigValidationRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*kongClient)
+
+	if err := client.requireVersion("kong_config_validation", configValidationMinimumKongVersion); err != nil {
+		return err
+	}
+
+	config := readStringFromResource(d, "config")
+
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(config), &data); err != nil {
+		return fmt.Errorf("could not parse declarative config: %v", err)
+	}
+
+	// sorted so the errors come back in the same order on every read
+	collections := []string{}
+	for collection := range data {
+		if !strings.HasPrefix(collection, "_") {
+			collections = append(collections, collection)
+		}
+	}
+	sort.Strings(collections)
+
+	errors := []string{}
+	for _, collection := range collections {
+		violations, err := validateDeclarativeEntities(client, collection, collection, data[collection], nil)
+		if err != nil {
+			return err
+		}
+		errors = append(errors, violations...)
+	}
+
+	d.SetId(declarativeConfigId(config))
+	d.Set("valid", len(errors) == 0)
+	d.Set("errors", errors)
+
+	return nil
+}
+
+// validateDeclarativeEntities validates each entity of a collection, and the entities nested under it, returning a
+// violation for every invalid one. parent is the foreign key the nested entities are given, e.g. the service of routes.
+func validateDeclarativeEntities(client *kongClient, collection string, location string, value interface{}, parent map[string]interface{}) ([]string, error) {
+	entities, ok := value.([]interface{})
+	if !ok {
+		return []string{fmt.Sprintf("%s: must be a list of entities", location)}, nil
+	}
+
+	violations := []string{}
+	for i, value := range entities {
+		entityLocation := fmt.Sprintf("%s[%d]", location, i)
+
+		entity, ok := value.(map[string]interface{})
+		if !ok {
+			violations = append(violations, fmt.Sprintf("%s: must be an entity", entityLocation))
+			continue
+		}
+
+		fields := map[string]interface{}{}
+		nested := []string{}
+		for field, fieldValue := range entity {
+			if contains(declarativeNestedEntities, field) {
+				nested = append(nested, field)
+				continue
+			}
+			fields[field] = fieldValue
+		}
+		for field, fieldValue := range parent {
+			if _, ok := fields[field]; !ok {
+				fields[field] = fieldValue
+			}
+		}
+
+		violation, err := validateKongEntity(client, collection, fields)
+		if err != nil {
+			return nil, err
+		}
+		if violation != "" {
+			violations = append(violations, fmt.Sprintf("%s: %s", entityLocation, violation))
+		}
+
+		id, ok := entity["id"].(string)
+		if !ok || id == "" {
+			id = nilEntityId
+		}
+		reference := map[string]interface{}{strings.TrimSuffix(collection, "s"): map[string]interface{}{"id": id}}
+
+		sort.Strings(nested)
+		for _, field := range nested {
+			nestedViolations, err := validateDeclarativeEntities(client, field, entityLocation+"."+field, entity[field], reference)
+			if err != nil {
+				return nil, err
+			}
+			violations = append(violations, nestedViolations...)
+		}
+	}
+
+	return violations, nil
+}
+
+// validateKongEntity checks entity against the schema kong has for collection without creating it, it returns kong's
+// message when the entity is invalid.
+func validateKongEntity(client *kongClient, collection string, entity map[string]interface{}) (string, error) {
+	found, err := client.do(gorequest.POST, "/schemas/"+collection+"/validate", entity, nil)
+
+	if err, ok := err.(*responseError); ok && err.status == 400 {
+		violation := &schemaViolation{}
+		if json.Unmarshal([]byte(err.body), violation) != nil || violation.Message == "" {
+			return err.body, nil
+		}
+		return violation.Message, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("could not validate kong %s: %v", collection, err)
+	}
+
+	// kong has no schema for entities it does not know about, e.g. those of a plugin that is not installed
+	if !found {
+		return fmt.Sprintf("kong has no entity %s", collection), nil
+	}
+
+	return "", nil
+}
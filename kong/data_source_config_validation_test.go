@@ -0,0 +1,125 @@
+package kong
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/kevholditch/gokong"
+)
+
+// newTestSchemaValidationServer mocks the validate endpoints of kong, services need a host, plugins a name and
+// credentials a consumer. Anything other than a validation fails the test as nothing may be applied.
+func newTestSchemaValidationServer(t *testing.T, kongVersion string, validated *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/" {
+			json.NewEncoder(w).Encode(map[string]string{"version": kongVersion})
+			return
+		}
+
+		if r.Method != http.MethodPost || !strings.HasPrefix(r.URL.Path, "/schemas/") || !strings.HasSuffix(r.URL.Path, "/validate") {
+			t.Errorf("expected only validate requests, got: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		collection := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/schemas/"), "/validate")
+		entity := map[string]interface{}{}
+		if err := json.NewDecoder(r.Body).Decode(&entity); err != nil {
+			t.Fatalf("could not decode entity: %v", err)
+		}
+		*validated = append(*validated, collection)
+
+		required := map[string]string{"services": "host", "plugins": "name", "keyauth_credentials": "consumer", "consumers": "username"}[collection]
+		if collection == "workspaces" {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]string{"message": "Not found"})
+			return
+		}
+		if _, ok := entity[required]; required != "" && !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"message": "schema violation (" + required + ": required field missing)",
+				"name":    "schema violation",
+				"fields":  map[string]string{required: "required field missing"},
+			})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"message": "schema validation successful"})
+	}))
+}
+
+func TestDataSourceKongConfigValidation(t *testing.T) {
+
+	var validated []string
+	server := newTestSchemaValidationServer(t, "3.6.1", &validated)
+	defer server.Close()
+
+	client := newKongClient(&gokong.Config{HostAddress: server.URL})
+
+	valid := `{
+		"_format_version": "3.0",
+		"services": [{"name": "api", "host": "api.internal", "routes": [{"name": "api", "paths": ["/api"],
+			"plugins": [{"name": "cors"}]}]}],
+		"consumers": [{"username": "ci", "keyauth_credentials": [{"key": "secret"}]}]
+	}`
+
+	d := schema.TestResourceDataRaw(t, dataSourceKongConfigValidation().Schema, map[string]interface{}{"config": valid})
+	if err := dataSourceKongConfigValidationRead(d, client); err != nil {
+		t.Fatalf("could not validate config: %v", err)
+	}
+
+	if !d.Get("valid").(bool) || len(d.Get("errors").([]interface{})) != 0 {
+		t.Errorf("expected the config to be valid, got errors: %v", d.Get("errors"))
+	}
+	if strings.Join(validated, ",") != "consumers,keyauth_credentials,services,routes,plugins" {
+		t.Errorf("expected every entity to be validated, nested ones with their parent, got: %v", validated)
+	}
+	if d.Id() != declarativeConfigId(valid) {
+		t.Errorf("expected the id to be the hash of the config, got: %s", d.Id())
+	}
+
+	invalid := `{
+		"_format_version": "3.0",
+		"services": [{"name": "api", "host": "api.internal"}, {"name": "broken", "plugins": [{"config": {}}]}],
+		"workspaces": [{"name": "team-a"}],
+		"upstreams": {"name": "not-a-list"}
+	}`
+
+	d = schema.TestResourceDataRaw(t, dataSourceKongConfigValidation().Schema, map[string]interface{}{"config": invalid})
+	if err := dataSourceKongConfigValidationRead(d, client); err != nil {
+		t.Fatalf("could not validate config: %v", err)
+	}
+
+	expected := []string{
+		"services[1]: schema violation (host: required field missing)",
+		"services[1].plugins[0]: schema violation (name: required field missing)",
+		"upstreams: must be a list of entities",
+		"workspaces[0]: kong has no entity workspaces",
+	}
+	if d.Get("valid").(bool) || strings.Join(configStrings(d.Get("errors")), "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected the config to be invalid with the errors %v, got: %v", expected, d.Get("errors"))
+	}
+
+	if _, errors := validateJsonDeclarativeConfig("_format_version: \"3.0\"", "config"); len(errors) != 1 {
+		t.Errorf("expected a YAML config to be rejected, got: %v", errors)
+	}
+}
+
+func TestDataSourceKongConfigValidationRequiresVersion(t *testing.T) {
+
+	var validated []string
+	server := newTestSchemaValidationServer(t, "1.0.3", &validated)
+	defer server.Close()
+
+	d := schema.TestResourceDataRaw(t, dataSourceKongConfigValidation().Schema, map[string]interface{}{"config": `{"_format_version": "1.1"}`})
+	err := dataSourceKongConfigValidationRead(d, newKongClient(&gokong.Config{HostAddress: server.URL}))
+
+	if err == nil || !strings.Contains(err.Error(), "kong_config_validation") || len(validated) != 0 {
+		t.Errorf("expected validating on kong 1.0 to fail before anything is sent, got: %v", err)
+	}
+}
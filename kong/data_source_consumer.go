@@ -61,7 +61,8 @@ func dataSourceKongConsumerRead(d *schema.ResourceData, meta interface{}) error
 		}
 	}
 
-	results, err := meta.(*gokong.KongAdminClient).Consumers().ListFiltered(filter)
+	results := &gokong.Consumers{}
+	err := meta.(*kongClient).listFiltered(gokong.ConsumersPath, filter, results)
 
 	if err != nil {
 		return fmt.Errorf("could not find consumer, error: %v", err)
@@ -0,0 +1,61 @@
+package kong
+
+import (
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func dataSourceKongConsumerCredentials() *schema.Resource {
+	credentialsSchema := map[string]*schema.Schema{
+		"consumer_id": {
+			Type:     schema.TypeString,
+			Required: true,
+		},
+	}
+
+	// one list of ids per plugin, e.g. key_auth_ids for key-auth
+	for _, pluginName := range consumerCredentialPlugins {
+		credentialsSchema[consumerCredentialsAttribute(pluginName)] = &schema.Schema{
+			Type:     schema.TypeList,
+			Computed: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		}
+	}
+
+	return &schema.Resource{
+		Read:   dataSourceKongConsumerCredentialsRead,
+		Schema: credentialsSchema,
+	}
+}
+
+func dataSourceKongConsumerCredentialsRead(d *schema.ResourceData, meta interface{}) error {
+	consumerId, configs, err := listConsumerPluginConfigs(meta.(*kongClient), readStringFromResource(d, "consumer_id"))
+	if err != nil {
+		return err
+	}
+
+	ids := map[string][]string{}
+	for _, config := range configs {
+		ids[config.pluginName] = append(ids[config.pluginName], config.id)
+	}
+
+	d.SetId(consumerId)
+	for _, pluginName := range consumerCredentialPlugins {
+		if ids[pluginName] == nil {
+			ids[pluginName] = []string{}
+		}
+		d.Set(consumerCredentialsAttribute(pluginName), ids[pluginName])
+	}
+
+	return nil
+}
+
+// consumerCredentialsAttribute is the attribute the ids of a plugin's configs are set in, acls are acl_ids as each
+// config is one acl group
+func consumerCredentialsAttribute(pluginName string) string {
+	if pluginName == "acls" {
+		return "acl_ids"
+	}
+	return strings.Replace(pluginName, "-", "_", -1) + "_ids"
+}
@@ -0,0 +1,70 @@
+package kong
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/kevholditch/gokong"
+)
+
+func TestDataSourceKongConsumerCredentials(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/consumers/billing-client":
+			// the consumer is looked up by its username, the ids are listed below its id
+			w.Write([]byte(`{"id":"consumer-id","username":"billing-client"}`))
+		case "/consumers/consumer-id/key-auth/":
+			if r.URL.Query().Get("offset") == "" {
+				w.Write([]byte(`{"data":[{"id":"key-1","key":"a"}],"offset":"page-2"}`))
+			} else {
+				w.Write([]byte(`{"data":[{"id":"key-2","key":"b"}]}`))
+			}
+		case "/consumers/consumer-id/basic-auth/":
+			w.Write([]byte(`{"data":[{"id":"basic-1","username":"billing"}]}`))
+		case "/consumers/consumer-id/jwt/":
+			w.Write([]byte(`{"data":[{"id":"jwt-1","key":"billing"}]}`))
+		case "/consumers/consumer-id/acls/":
+			w.Write([]byte(`{"data":[{"id":"acl-1","group":"billing"},{"id":"acl-2","group":"internal"}]}`))
+		case "/consumers/consumer-id/hmac-auth/", "/consumers/consumer-id/oauth2/":
+			w.Write([]byte(`{"data":[]}`))
+		default:
+			// mtls-auth is not installed
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"Not found"}`))
+		}
+	}))
+	defer server.Close()
+
+	d := schema.TestResourceDataRaw(t, dataSourceKongConsumerCredentials().Schema, map[string]interface{}{"consumer_id": "billing-client"})
+	if err := dataSourceKongConsumerCredentialsRead(d, newKongClient(&gokong.Config{HostAddress: server.URL})); err != nil {
+		t.Fatalf("could not read consumer credentials: %v", err)
+	}
+
+	expected := map[string]string{
+		"key_auth_ids":   "key-1,key-2",
+		"basic_auth_ids": "basic-1",
+		"jwt_ids":        "jwt-1",
+		"hmac_auth_ids":  "",
+		"oauth2_ids":     "",
+		"acl_ids":        "acl-1,acl-2",
+		"mtls_auth_ids":  "",
+	}
+	for attribute, ids := range expected {
+		var found []string
+		for _, id := range d.Get(attribute).([]interface{}) {
+			found = append(found, id.(string))
+		}
+		if strings.Join(found, ",") != ids {
+			t.Errorf("expected %s to be %q, got: %v", attribute, ids, found)
+		}
+	}
+
+	if d.Id() != "consumer-id" {
+		t.Errorf("expected the id to be the id of the consumer, got: %s", d.Id())
+	}
+}
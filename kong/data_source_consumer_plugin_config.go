@@ -0,0 +1,115 @@
+package kong
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/kevholditch/gokong"
+)
+
+// consumerCredentialPlugins are the plugins whose config kong stores per consumer, they are listed below the consumer
+// at /consumers/<consumer_id>/<plugin_name> which is also where kong_consumer_plugin_config manages them
+var consumerCredentialPlugins = []string{"acls", "basic-auth", "hmac-auth", "jwt", "key-auth", "mtls-auth", "oauth2"}
+
+func dataSourceKongConsumerPluginConfig() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceKongConsumerPluginConfigRead,
+		Schema: map[string]*schema.Schema{
+			"consumer_id": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"plugin_configs": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"plugin_name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						// the id to import the config as a kong_consumer_plugin_config with
+						"import_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceKongConsumerPluginConfigRead(d *schema.ResourceData, meta interface{}) error {
+	consumerId, configs, err := listConsumerPluginConfigs(meta.(*kongClient), readStringFromResource(d, "consumer_id"))
+	if err != nil {
+		return err
+	}
+
+	pluginConfigs := []map[string]interface{}{}
+	for _, config := range configs {
+		pluginConfigs = append(pluginConfigs, map[string]interface{}{
+			"plugin_name": config.pluginName,
+			"id":          config.id,
+			"import_id":   buildId(consumerId, config.pluginName, config.id),
+		})
+	}
+
+	d.SetId(consumerId)
+	d.Set("plugin_configs", pluginConfigs)
+
+	return nil
+}
+
+type consumerPluginConfigEntry struct {
+	pluginName string
+	id         string
+}
+
+// listConsumerPluginConfigs returns the kong id of the consumer and every config of consumerCredentialPlugins it has,
+// in the order of consumerCredentialPlugins, following every page of each. It fails when there is no consumer with
+// the id.
+func listConsumerPluginConfigs(client *kongClient, consumerId string) (string, []consumerPluginConfigEntry, error) {
+	consumer := &gokong.Consumer{}
+	found, err := client.get(gokong.ConsumersPath+consumerId, consumer)
+	if err != nil {
+		return "", nil, fmt.Errorf("could not find kong consumer with id: %s error: %v", consumerId, err)
+	}
+
+	if !found || consumer.Id == "" {
+		return "", nil, fmt.Errorf("could not find kong consumer with id: %s, it does not exist", consumerId)
+	}
+
+	configs := []consumerPluginConfigEntry{}
+	for _, pluginName := range consumerCredentialPlugins {
+		// kong responds with a 404 for the plugins that are not installed
+		results, found, err := client.listAllIfFound(consumerPluginConfigsPath(consumer.Id, pluginName))
+		if err != nil {
+			return "", nil, fmt.Errorf("could not list kong consumer %s plugin configs of %s: %v", consumer.Id, pluginName, err)
+		}
+
+		if !found {
+			continue
+		}
+
+		for _, result := range results {
+			config := &entityReference{}
+			if err := json.Unmarshal(result, config); err != nil {
+				return "", nil, fmt.Errorf("could not parse kong consumer %s plugin config of %s: %v", consumer.Id, pluginName, err)
+			}
+
+			configs = append(configs, consumerPluginConfigEntry{pluginName: pluginName, id: config.Id})
+		}
+	}
+
+	return consumer.Id, configs, nil
+}
+
+func consumerPluginConfigsPath(consumerId string, pluginName string) string {
+	return gokong.ConsumersPath + consumerId + "/" + pluginName + "/"
+}
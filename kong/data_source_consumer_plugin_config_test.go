@@ -0,0 +1,143 @@
+package kong
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/kevholditch/gokong"
+)
+
+func TestAccDataSourceKongConsumerPluginConfig(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testConsumerPluginConfigDataSourceConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.kong_consumer_plugin_config.configs", "plugin_configs.#", "2"),
+					resource.TestCheckResourceAttr("data.kong_consumer_plugin_config.configs", "plugin_configs.0.plugin_name", "basic-auth"),
+					resource.TestCheckResourceAttr("data.kong_consumer_plugin_config.configs", "plugin_configs.1.plugin_name", "jwt"),
+					resource.TestCheckResourceAttrPair("data.kong_consumer_plugin_config.configs", "plugin_configs.1.import_id", "kong_consumer_plugin_config.jwt", "id"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccDataSourceKongConsumerPluginConfigMissingConsumer(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      testConsumerPluginConfigDataSourceMissingConsumerConfig,
+				ExpectError: regexp.MustCompile("could not find kong consumer with id: 7b8e4f52-3c02-4c21-8f4a-5b0f2d6e9a10, it does not exist"),
+			},
+		},
+	})
+}
+
+func TestDataSourceKongConsumerPluginConfigListsEveryConfig(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/consumers/consumer-id":
+			w.Write([]byte(`{"id":"consumer-id","username":"consumer"}`))
+		case "/consumers/consumer-id/jwt/":
+			if r.URL.Query().Get("offset") == "" {
+				w.Write([]byte(`{"data":[{"id":"jwt-1","key":"a"}],"offset":"page-2"}`))
+			} else {
+				w.Write([]byte(`{"data":[{"id":"jwt-2","key":"b"}]}`))
+			}
+		case "/consumers/consumer-id/key-auth/":
+			w.Write([]byte(`{"data":[{"id":"key-1","key":"secret"}]}`))
+		case "/consumers/consumer-id/acls/":
+			w.Write([]byte(`{"data":[]}`))
+		default:
+			// the other credential plugins are not installed
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"Not found"}`))
+		}
+	}))
+	defer server.Close()
+
+	client := newKongClient(&gokong.Config{HostAddress: server.URL})
+
+	d := schema.TestResourceDataRaw(t, dataSourceKongConsumerPluginConfig().Schema, map[string]interface{}{"consumer_id": "consumer-id"})
+	if err := dataSourceKongConsumerPluginConfigRead(d, client); err != nil {
+		t.Fatalf("could not read consumer plugin configs: %v", err)
+	}
+
+	var importIds []string
+	for _, config := range d.Get("plugin_configs").([]interface{}) {
+		importIds = append(importIds, config.(map[string]interface{})["import_id"].(string))
+	}
+
+	expected := strings.Join([]string{
+		buildId("consumer-id", "jwt", "jwt-1"),
+		buildId("consumer-id", "jwt", "jwt-2"),
+		buildId("consumer-id", "key-auth", "key-1"),
+	}, ",")
+	if strings.Join(importIds, ",") != expected {
+		t.Errorf("expected the plugin configs %s but got %v", expected, importIds)
+	}
+
+	d = schema.TestResourceDataRaw(t, dataSourceKongConsumerPluginConfig().Schema, map[string]interface{}{"consumer_id": "missing-id"})
+	if err := dataSourceKongConsumerPluginConfigRead(d, client); err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("expected reading the plugin configs of a missing consumer to fail, got: %v", err)
+	}
+}
+
+const testConsumerPluginConfigDataSourceConfig = `
+resource "kong_consumer" "consumer" {
+	username = "plugin-config-audit"
+}
+
+resource "kong_plugin" "jwt" {
+	name = "jwt"
+}
+
+resource "kong_plugin" "basic_auth" {
+	name = "basic-auth"
+}
+
+resource "kong_consumer_plugin_config" "jwt" {
+	consumer_id = "${kong_consumer.consumer.id}"
+	plugin_name = "jwt"
+	config_json = <<EOT
+		{
+			"key": "audit_key",
+			"secret": "audit_secret"
+		}
+EOT
+}
+
+resource "kong_consumer_plugin_config" "basic_auth" {
+	consumer_id = "${kong_consumer.consumer.id}"
+	plugin_name = "basic-auth"
+	config_json = <<EOT
+		{
+			"username": "audit",
+			"password": "audit_password"
+		}
+EOT
+}
+
+data "kong_consumer_plugin_config" "configs" {
+	consumer_id = "${kong_consumer.consumer.id}"
+	depends_on  = ["kong_consumer_plugin_config.jwt", "kong_consumer_plugin_config.basic_auth"]
+}
+`
+
+const testConsumerPluginConfigDataSourceMissingConsumerConfig = `
+data "kong_consumer_plugin_config" "configs" {
+	consumer_id = "7b8e4f52-3c02-4c21-8f4a-5b0f2d6e9a10"
+}
+`
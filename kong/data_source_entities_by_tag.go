@@ -0,0 +1,97 @@
+package kong
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/kevholditch/gokong"
+)
+
+// taggedEntityTypes maps the resource type entities are imported as to the path kong lists them at, in the order they
+// are returned
+var taggedEntityTypes = []struct {
+	resourceType string
+	path         string
+}{
+	{"kong_service", gokong.ServicesPath},
+	{"kong_route", gokong.RoutesPath},
+	{"kong_plugin", gokong.PluginsPath},
+	{"kong_consumer", gokong.ConsumersPath},
+}
+
+func dataSourceKongEntitiesByTag() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceKongEntitiesByTagRead,
+		Schema: map[string]*schema.Schema{
+			"tag": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"entities": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						// the resource type to import the entity as, e.g. kong_service
+						"type": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						// the name of a service, route or plugin and the username of a consumer
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+type namedEntity struct {
+	Id       string `json:"id"`
+	Name     string `json:"name"`
+	Username string `json:"username"`
+}
+
+func dataSourceKongEntitiesByTagRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*kongClient)
+	tag := readStringFromResource(d, "tag")
+
+	if err := client.requireVersion("kong_entities_by_tag", tagsMinimumKongVersion); err != nil {
+		return err
+	}
+
+	entities := []map[string]interface{}{}
+	for _, entityType := range taggedEntityTypes {
+		results, err := client.listAll(entityType.path + "?tags=" + url.QueryEscape(tag))
+		if err != nil {
+			return fmt.Errorf("could not list kong entities of %s tagged %s: %v", entityType.resourceType, tag, err)
+		}
+
+		for _, result := range results {
+			entity := &namedEntity{}
+			if err := json.Unmarshal(result, entity); err != nil {
+				return fmt.Errorf("could not parse kong entity of %s tagged %s: %v", entityType.resourceType, tag, err)
+			}
+
+			entities = append(entities, map[string]interface{}{
+				"type": entityType.resourceType,
+				"id":   entity.Id,
+				"name": firstNonEmpty(entity.Name, entity.Username),
+			})
+		}
+	}
+
+	d.SetId(tag)
+	d.Set("entities", entities)
+
+	return nil
+}
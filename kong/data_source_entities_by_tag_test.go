@@ -0,0 +1,144 @@
+package kong
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/kevholditch/gokong"
+)
+
+func TestAccDataSourceKongEntitiesByTag(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheckKongVersion(t, tagsMinimumKongVersion) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testEntitiesByTagDataSourceConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.kong_entities_by_tag.team_a", "entities.#", "1"),
+					resource.TestCheckResourceAttr("data.kong_entities_by_tag.team_a", "entities.0.type", "kong_consumer"),
+					resource.TestCheckResourceAttr("data.kong_entities_by_tag.team_a", "entities.0.name", "tagged-team-a"),
+					resource.TestCheckResourceAttrPair("data.kong_entities_by_tag.team_a", "entities.0.id", "kong_consumer.team_a", "id"),
+				),
+			},
+		},
+	})
+}
+
+func TestDataSourceKongEntitiesByTag(t *testing.T) {
+
+	entities := map[string][]map[string]interface{}{
+		"/services/": {
+			{"id": "service-a", "name": "billing", "tags": []string{"team-a"}},
+			{"id": "service-b", "name": "search", "tags": []string{"team-b"}},
+		},
+		"/routes/": {
+			{"id": "route-a", "name": "billing-route", "tags": []string{"team-a", "public"}},
+			{"id": "route-b", "tags": []string{"team-a"}},
+			{"id": "route-c", "tags": []string{}},
+		},
+		"/plugins/": {
+			{"id": "plugin-b", "name": "cors", "tags": []string{"team-b"}},
+		},
+		"/consumers/": {
+			{"id": "consumer-a", "username": "billing-client", "tags": []string{"team-a"}},
+		},
+	}
+
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/" {
+			json.NewEncoder(w).Encode(map[string]string{"version": "2.8.0"})
+			return
+		}
+
+		tag := r.URL.Query().Get("tags")
+		queries = append(queries, tag)
+
+		var tagged []map[string]interface{}
+		for _, entity := range entities[r.URL.Path] {
+			if contains(entity["tags"].([]string), tag) {
+				tagged = append(tagged, entity)
+			}
+		}
+
+		// one entity per page so the pages are followed
+		page := map[string]interface{}{"data": tagged}
+		if offset := r.URL.Query().Get("offset"); offset == "" && len(tagged) > 1 {
+			page = map[string]interface{}{"data": tagged[:1], "offset": "next"}
+		} else if offset != "" {
+			page = map[string]interface{}{"data": tagged[1:]}
+		}
+		json.NewEncoder(w).Encode(page)
+	}))
+	defer server.Close()
+
+	d := schema.TestResourceDataRaw(t, dataSourceKongEntitiesByTag().Schema, map[string]interface{}{"tag": "team-a"})
+	if err := dataSourceKongEntitiesByTagRead(d, newKongClient(&gokong.Config{HostAddress: server.URL})); err != nil {
+		t.Fatalf("could not read entities by tag: %v", err)
+	}
+
+	var found []string
+	for _, entity := range d.Get("entities").([]interface{}) {
+		entity := entity.(map[string]interface{})
+		found = append(found, entity["type"].(string)+":"+entity["id"].(string)+":"+entity["name"].(string))
+	}
+
+	expected := "kong_service:service-a:billing,kong_route:route-a:billing-route,kong_route:route-b:,kong_consumer:consumer-a:billing-client"
+	if strings.Join(found, ",") != expected {
+		t.Errorf("expected only the entities tagged team-a %s but got %v", expected, found)
+	}
+
+	if len(queries) != 5 {
+		t.Errorf("expected the four types to be listed and the routes to have a second page, got %d lists", len(queries))
+	}
+
+	for _, query := range queries {
+		if query != "team-a" {
+			t.Errorf("expected every list to filter by the tag, got the tags %q", query)
+		}
+	}
+
+	if d.Id() != "team-a" {
+		t.Errorf("expected the id to be the tag, got: %s", d.Id())
+	}
+}
+
+func TestDataSourceKongEntitiesByTagOnOldKong(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"version": "1.0.3"})
+	}))
+	defer server.Close()
+
+	d := schema.TestResourceDataRaw(t, dataSourceKongEntitiesByTag().Schema, map[string]interface{}{"tag": "team-a"})
+	err := dataSourceKongEntitiesByTagRead(d, newKongClient(&gokong.Config{HostAddress: server.URL}))
+	if err == nil || !strings.Contains(err.Error(), "requires kong 1.1.0 or later") {
+		t.Errorf("expected reading entities by tag from kong 1.0 to fail, got: %v", err)
+	}
+}
+
+const testEntitiesByTagDataSourceConfig = `
+resource "kong_consumer" "team_a" {
+	username = "tagged-team-a"
+	tags     = ["team-a"]
+}
+
+resource "kong_consumer" "team_b" {
+	username = "tagged-team-b"
+	tags     = ["team-b"]
+}
+
+data "kong_entities_by_tag" "team_a" {
+	tag        = "team-a"
+	depends_on = ["kong_consumer.team_a", "kong_consumer.team_b"]
+}
+`
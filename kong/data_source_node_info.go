@@ -0,0 +1,129 @@
+package kong
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// dblessDatabase is the database of a kong node without one, its entities come from a declarative config
+const dblessDatabase = "off"
+
+// nodeInfo is the admin api root, the plugins are a list of names before kong 1.0, then a map of name to true and
+// since kong 3.0 a map of name to the version and priority
+type nodeInfo struct {
+	nodeInformation
+	Hostname      string                 `json:"hostname"`
+	NodeId        string                 `json:"node_id"`
+	Configuration map[string]interface{} `json:"configuration"`
+	Plugins       struct {
+		AvailableOnServer interface{} `json:"available_on_server"`
+		EnabledInCluster  []string    `json:"enabled_in_cluster"`
+	} `json:"plugins"`
+}
+
+func dataSourceKongNodeInfo() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceKongNodeInfoRead,
+		Schema: map[string]*schema.Schema{
+			"version": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"edition": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "enterprise or community",
+			},
+			"hostname": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"node_id": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"database": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The database of the node, off for a db-less node",
+			},
+			"dbless": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			"plugins": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The plugins the node is configured to load, sorted by name",
+			},
+			"enabled_plugins": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The plugins that have an instance in the cluster, sorted by name",
+			},
+		},
+	}
+}
+
+func dataSourceKongNodeInfoRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*kongClient)
+
+	node := &nodeInfo{}
+	found, err := client.get("/", node)
+	if err != nil {
+		return fmt.Errorf("could not read kong node information: %v", err)
+	}
+	if !found {
+		return fmt.Errorf("kong responded to GET / with status 404")
+	}
+
+	edition := "community"
+	if isEnterpriseNode(&node.nodeInformation) {
+		edition = "enterprise"
+	}
+
+	// kong enterprise leaves the configuration out for an admin without access to it, the database is then empty
+	database, _ := node.Configuration["database"].(string)
+
+	enabled := append([]string{}, node.Plugins.EnabledInCluster...)
+	sort.Strings(enabled)
+
+	id := node.NodeId
+	if id == "" {
+		id = firstNonEmpty(node.Hostname, "node")
+	}
+	d.SetId(id)
+	d.Set("version", node.Version)
+	d.Set("edition", edition)
+	d.Set("hostname", node.Hostname)
+	d.Set("node_id", node.NodeId)
+	d.Set("database", database)
+	d.Set("dbless", database == dblessDatabase)
+	d.Set("plugins", availablePluginNames(node.Plugins.AvailableOnServer))
+	d.Set("enabled_plugins", enabled)
+
+	return nil
+}
+
+// availablePluginNames reads the names of the plugins available on the node from the list or the map kong returns
+func availablePluginNames(available interface{}) []string {
+	names := []string{}
+	switch plugins := available.(type) {
+	case []interface{}:
+		for _, plugin := range plugins {
+			if name, ok := plugin.(string); ok {
+				names = append(names, name)
+			}
+		}
+	case map[string]interface{}:
+		for name := range plugins {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
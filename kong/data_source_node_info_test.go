@@ -0,0 +1,76 @@
+package kong
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/kevholditch/gokong"
+)
+
+func TestDataSourceKongNodeInfo(t *testing.T) {
+
+	cases := []struct {
+		description string
+		response    string
+		version     string
+		edition     string
+		database    string
+		dbless      bool
+		plugins     string
+		enabled     string
+	}{
+		{
+			"a db-less open source node",
+			`{"version":"3.6.1","hostname":"kong-0","node_id":"node-id","configuration":{"database":"off","plugins":["bundled"]},
+			"plugins":{"available_on_server":{"key-auth":{"version":"3.6.1","priority":1250},"acl":{"version":"3.6.1","priority":950}},"enabled_in_cluster":["key-auth","acl"]}}`,
+			"3.6.1", "community", "off", true, "acl,key-auth", "acl,key-auth",
+		},
+		{
+			"an enterprise node with a database",
+			`{"version":"2.8.4.1-enterprise-edition","hostname":"kong-1","node_id":"node-id","configuration":{"database":"postgres"},
+			"plugins":{"available_on_server":{"rate-limiting-advanced":true,"cors":true},"enabled_in_cluster":[]}}`,
+			"2.8.4.1-enterprise-edition", "enterprise", "postgres", false, "cors,rate-limiting-advanced", "",
+		},
+		{
+			"an enterprise node that leaves out its configuration",
+			`{"version":"3.6.1.0","edition":"enterprise","hostname":"kong-2","plugins":{"available_on_server":{"cors":true}}}`,
+			"3.6.1.0", "enterprise", "", false, "cors", "",
+		},
+	}
+
+	for _, c := range cases {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			if r.URL.Path != "/" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write([]byte(c.response))
+		}))
+		client := newKongClient(&gokong.Config{HostAddress: server.URL})
+
+		d := schema.TestResourceDataRaw(t, dataSourceKongNodeInfo().Schema, map[string]interface{}{})
+		err := dataSourceKongNodeInfoRead(d, client)
+		server.Close()
+		if err != nil {
+			t.Fatalf("%s: could not read node info: %v", c.description, err)
+		}
+
+		if d.Get("version") != c.version || d.Get("edition") != c.edition || d.Get("database") != c.database || d.Get("dbless") != c.dbless {
+			t.Errorf("%s: expected version %s, edition %s and database %q, got: %v %v %q", c.description, c.version, c.edition, c.database,
+				d.Get("version"), d.Get("edition"), d.Get("database"))
+		}
+		if plugins := strings.Join(readStringArrayFromResource(d, "plugins"), ","); plugins != c.plugins {
+			t.Errorf("%s: expected the plugins %s, got: %s", c.description, c.plugins, plugins)
+		}
+		if enabled := strings.Join(readStringArrayFromResource(d, "enabled_plugins"), ","); enabled != c.enabled {
+			t.Errorf("%s: expected the enabled plugins %s, got: %s", c.description, c.enabled, enabled)
+		}
+		if d.Id() == "" {
+			t.Errorf("%s: expected an id to be set", c.description)
+		}
+	}
+}
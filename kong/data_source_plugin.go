@@ -74,7 +74,8 @@ func dataSourceKongPluginRead(d *schema.ResourceData, meta interface{}) error {
 		}
 	}
 
-	results, err := meta.(*gokong.KongAdminClient).Plugins().ListFiltered(filter)
+	results := &gokong.Plugins{}
+	err := meta.(*kongClient).listFiltered(gokong.PluginsPath, filter, results)
 
 	if err != nil {
 		return fmt.Errorf("could not find plugin, error: %v", err)
@@ -0,0 +1,125 @@
+package kong
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// dataSourceKongPluginConfig builds the config_json of a plugin from terraform values, it does not call kong.
+func dataSourceKongPluginConfig() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceKongPluginConfigRead,
+		Schema: map[string]*schema.Schema{
+			// values that look like booleans or numbers are sent as them, keys can use dots to build nested objects
+			"config": {
+				Type:     schema.TypeMap,
+				Optional: true,
+				Elem:     schema.TypeString,
+			},
+			// values are always sent as strings, for the ones that only look like a boolean or a number
+			"string_config": {
+				Type:     schema.TypeMap,
+				Optional: true,
+				Elem:     schema.TypeString,
+			},
+			"list_config": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"key": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"values": {
+							Type:     schema.TypeList,
+							Optional: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						"string_values": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  false,
+						},
+					},
+				},
+			},
+			"config_json": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceKongPluginConfigRead(d *schema.ResourceData, meta interface{}) error {
+
+	config, err := buildPluginConfigJson(readMapFromResource(d, "config"), readMapFromResource(d, "string_config"), d.Get("list_config").([]interface{}))
+	if err != nil {
+		return fmt.Errorf("could not build plugin config, error: %v", err)
+	}
+
+	sum := sha256.Sum256([]byte(config))
+	d.SetId(hex.EncodeToString(sum[:]))
+	d.Set("config_json", config)
+
+	return nil
+}
+
+// buildPluginConfigJson encodes the values into the json object kong expects for a plugin config, keys are handled in
+// sorted order so a key set twice gives the same error on every run.
+func buildPluginConfigJson(configMap map[string]interface{}, stringConfigMap map[string]interface{}, listConfig []interface{}) (string, error) {
+	config := map[string]interface{}{}
+
+	for _, key := range sortedConfigKeys(configMap) {
+		if err := setNestedConfigValue(config, strings.Split(key, "."), inferConfigKeyValue(key, configMap[key].(string))); err != nil {
+			return "", fmt.Errorf("invalid config key %s: %v", key, err)
+		}
+	}
+
+	for _, key := range sortedConfigKeys(stringConfigMap) {
+		if err := setNestedConfigValue(config, strings.Split(key, "."), stringConfigMap[key].(string)); err != nil {
+			return "", fmt.Errorf("invalid string_config key %s: %v", key, err)
+		}
+	}
+
+	for _, item := range listConfig {
+		list := item.(map[string]interface{})
+		key := list["key"].(string)
+
+		values := []interface{}{}
+		for _, value := range list["values"].([]interface{}) {
+			if list["string_values"].(bool) {
+				values = append(values, value.(string))
+			} else {
+				values = append(values, inferConfigKeyValue(key, value.(string)))
+			}
+		}
+
+		if err := setNestedConfigValue(config, strings.Split(key, "."), values); err != nil {
+			return "", fmt.Errorf("invalid list_config key %s: %v", key, err)
+		}
+	}
+
+	rawJson, err := json.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+
+	return string(rawJson), nil
+}
+
+func sortedConfigKeys(configMap map[string]interface{}) []string {
+	keys := make([]string, 0, len(configMap))
+	for key := range configMap {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
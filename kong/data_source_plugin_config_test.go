@@ -0,0 +1,100 @@
+package kong
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccDataSourceKongPluginConfig(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testPluginConfigDataSourceConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.kong_plugin_config.rate_limiting", "config_json", `{"fault_tolerant":true,"hour":500,"minute":20,"policy":"redis","redis_host":"redis.example.com","redis_port":6379}`),
+					resource.TestCheckResourceAttr("data.kong_plugin_config.cors", "config_json", `{"credentials":true,"exposed_headers":["X-Auth-Token"],"headers":["Accept","Content-Type"],"max_age":3600,"methods":["GET","POST"],"origins":["https://example.com","https://*.example.org"],"preflight_continue":false}`),
+				),
+			},
+		},
+	})
+}
+
+func TestBuildPluginConfigJson(t *testing.T) {
+
+	config, err := buildPluginConfigJson(
+		map[string]interface{}{"minute": "20", "limits.sms.hour": "500", "policy": "local"},
+		map[string]interface{}{"version": "1.0"},
+		[]interface{}{
+			map[string]interface{}{"key": "status_codes", "values": []interface{}{"200", "201"}, "string_values": false},
+			map[string]interface{}{"key": "anonymous.ids", "values": []interface{}{"007", "true"}, "string_values": true},
+			map[string]interface{}{"key": "empty", "values": []interface{}{}, "string_values": false},
+		},
+	)
+
+	if err != nil {
+		t.Fatalf("could not build config: %v", err)
+	}
+
+	expected := `{"anonymous":{"ids":["007","true"]},"empty":[],"limits":{"sms":{"hour":500}},"minute":20,"policy":"local","status_codes":[200,201],"version":"1.0"}`
+	if config != expected {
+		t.Errorf("expected config %s but was %s", expected, config)
+	}
+
+	if config, _ := buildPluginConfigJson(nil, nil, nil); config != "{}" {
+		t.Errorf("expected an empty config to be {} but was %s", config)
+	}
+
+	if _, err := buildPluginConfigJson(map[string]interface{}{"minute": "20"}, map[string]interface{}{"minute": "20"}, nil); err == nil {
+		t.Errorf("expected a key set in both config and string_config to be rejected")
+	}
+
+	if _, err := buildPluginConfigJson(map[string]interface{}{"origins.first": "*"}, nil, []interface{}{
+		map[string]interface{}{"key": "origins", "values": []interface{}{"*"}, "string_values": false},
+	}); err == nil {
+		t.Errorf("expected a key used as both an object and a list to be rejected")
+	}
+}
+
+const testPluginConfigDataSourceConfig = `
+data "kong_plugin_config" "rate_limiting" {
+	config = {
+		minute         = "20"
+		hour           = "500"
+		policy         = "redis"
+		redis_host     = "redis.example.com"
+		redis_port     = "6379"
+		fault_tolerant = "true"
+	}
+}
+
+data "kong_plugin_config" "cors" {
+	config = {
+		credentials        = "true"
+		max_age            = "3600"
+		preflight_continue = "false"
+	}
+
+	list_config {
+		key    = "origins"
+		values = [ "https://example.com", "https://*.example.org" ]
+	}
+
+	list_config {
+		key    = "methods"
+		values = [ "GET", "POST" ]
+	}
+
+	list_config {
+		key    = "headers"
+		values = [ "Accept", "Content-Type" ]
+	}
+
+	list_config {
+		key    = "exposed_headers"
+		values = [ "X-Auth-Token" ]
+	}
+}
+`
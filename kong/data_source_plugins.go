@@ -0,0 +1,196 @@
+package kong
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/kevholditch/gokong"
+)
+
+// globalPluginScope is the scope filter of plugins that are not scoped to any entity
+const globalPluginScope = "global"
+
+func dataSourceKongPlugins() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceKongPluginsRead,
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			// a string as an unset bool can not be told from false
+			"enabled": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validatePluginsEnabledFilter,
+			},
+			"scope": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validatePluginsScopeFilter,
+				Description:  "Only plugins scoped to this type of entity: consumer, consumer_group, service or route, global for plugins without a scope",
+			},
+			"tags": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Only plugins that have all of these tags (kong 1.1)",
+			},
+			"plugins": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"enabled": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						// the types of entity the plugin is scoped to, empty for a global plugin
+						"scope": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						"api_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"service_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"route_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"consumer_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"consumer_group_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func validatePluginsEnabledFilter(v interface{}, k string) ([]string, []error) {
+	if value := v.(string); value != "true" && value != "false" {
+		return nil, []error{fmt.Errorf("%s must be one of %v, got: %s", k, []string{"true", "false"}, value)}
+	}
+	return nil, nil
+}
+
+func validatePluginsScopeFilter(v interface{}, k string) ([]string, []error) {
+	scopes := append([]string{globalPluginScope}, pluginScopes...)
+	if value := v.(string); !contains(scopes, value) {
+		return nil, []error{fmt.Errorf("%s must be one of %v, got: %s", k, scopes, value)}
+	}
+	return nil, nil
+}
+
+// pluginScopeIds returns the id of each entity the plugin is scoped to by scope type, in the order of pluginScopes
+func pluginScopeIds(plugin *scopedPlugin) ([]string, map[string]string) {
+	ids := map[string]string{
+		"consumer":       firstNonEmpty(plugin.ConsumerId, plugin.Consumer.id()),
+		"consumer_group": plugin.ConsumerGroup.id(),
+		"service":        firstNonEmpty(plugin.ServiceId, plugin.Service.id()),
+		"route":          firstNonEmpty(plugin.RouteId, plugin.Route.id()),
+	}
+
+	scopes := []string{}
+	for _, scope := range pluginScopes {
+		if ids[scope] != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+	if plugin.ApiId != "" {
+		scopes = append(scopes, "api")
+	}
+
+	return scopes, ids
+}
+
+func dataSourceKongPluginsRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*kongClient)
+
+	name := readStringFromResource(d, "name")
+	enabled := readStringFromResource(d, "enabled")
+	scope := readStringFromResource(d, "scope")
+	tags := readStringArrayFromResource(d, "tags")
+
+	// kong only filters lists by tags, the other filters are applied to every page it returns
+	filters := url.Values{}
+	path := gokong.PluginsPath
+	if len(tags) > 0 {
+		if err := client.requireVersion("kong_plugins tags", tagsMinimumKongVersion); err != nil {
+			return err
+		}
+		path = path + "?tags=" + url.QueryEscape(strings.Join(tags, ","))
+		filters.Set("tags", strings.Join(tags, ","))
+	}
+
+	results, err := client.listAll(path)
+	if err != nil {
+		return fmt.Errorf("could not list kong plugins: %v", err)
+	}
+
+	plugins := []map[string]interface{}{}
+	for _, result := range results {
+		plugin := &scopedPlugin{}
+		if err := json.Unmarshal(result, plugin); err != nil {
+			return fmt.Errorf("could not parse kong plugin: %v", err)
+		}
+
+		scopes, ids := pluginScopeIds(plugin)
+
+		if name != "" && plugin.Name != name {
+			continue
+		}
+		if enabled != "" && strconv.FormatBool(plugin.Enabled) != enabled {
+			continue
+		}
+		if (scope == globalPluginScope && len(scopes) > 0) || (scope != "" && scope != globalPluginScope && ids[scope] == "") {
+			continue
+		}
+
+		plugins = append(plugins, map[string]interface{}{
+			"id":                plugin.Id,
+			"name":              plugin.Name,
+			"enabled":           plugin.Enabled,
+			"scope":             scopes,
+			"api_id":            plugin.ApiId,
+			"service_id":        ids["service"],
+			"route_id":          ids["route"],
+			"consumer_id":       ids["consumer"],
+			"consumer_group_id": ids["consumer_group"],
+		})
+	}
+
+	for key, value := range map[string]string{"name": name, "enabled": enabled, "scope": scope} {
+		if value != "" {
+			filters.Set(key, value)
+		}
+	}
+
+	d.SetId("plugins?" + filters.Encode())
+	d.Set("plugins", plugins)
+
+	return nil
+}
@@ -0,0 +1,122 @@
+package kong
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/kevholditch/gokong"
+)
+
+func TestDataSourceKongPlugins(t *testing.T) {
+
+	plugins := []map[string]interface{}{
+		{"id": "global-cors", "name": "cors", "enabled": true, "tags": []string{"audit"}},
+		{"id": "service-cors", "name": "cors", "enabled": false, "service": map[string]string{"id": "service-id"}, "tags": []string{"audit", "team-a"}},
+		{"id": "route-acl", "name": "acl", "enabled": true, "route": map[string]string{"id": "route-id"}, "service": nil, "tags": []string{"team-a"}},
+		{"id": "consumer-rate-limiting", "name": "rate-limiting", "enabled": true, "consumer": map[string]string{"id": "consumer-id"},
+			"service": map[string]string{"id": "service-id"}, "tags": []string{"audit"}},
+		{"id": "group-rate-limiting", "name": "rate-limiting", "enabled": false, "consumer_group": map[string]string{"id": "group-id"}, "tags": []string{}},
+	}
+
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/" {
+			json.NewEncoder(w).Encode(map[string]string{"version": "3.6.1"})
+			return
+		}
+
+		tags := r.URL.Query().Get("tags")
+		if r.URL.Query().Get("offset") == "" {
+			queries = append(queries, tags)
+		}
+
+		var tagged []map[string]interface{}
+		for _, plugin := range plugins {
+			matches := true
+			for _, tag := range strings.Split(tags, ",") {
+				matches = matches && (tag == "" || contains(plugin["tags"].([]string), tag))
+			}
+			if matches {
+				tagged = append(tagged, plugin)
+			}
+		}
+
+		// two plugins per page so the pages are followed
+		page := map[string]interface{}{"data": tagged}
+		if offset := r.URL.Query().Get("offset"); offset == "" && len(tagged) > 2 {
+			page = map[string]interface{}{"data": tagged[:2], "offset": "next"}
+		} else if offset != "" {
+			page = map[string]interface{}{"data": tagged[2:]}
+		}
+		json.NewEncoder(w).Encode(page)
+	}))
+	defer server.Close()
+
+	client := newKongClient(&gokong.Config{HostAddress: server.URL})
+
+	cases := []struct {
+		filters  map[string]interface{}
+		expected string
+	}{
+		{map[string]interface{}{}, "global-cors,service-cors,route-acl,consumer-rate-limiting,group-rate-limiting"},
+		{map[string]interface{}{"name": "cors"}, "global-cors,service-cors"},
+		{map[string]interface{}{"enabled": "false"}, "service-cors,group-rate-limiting"},
+		{map[string]interface{}{"name": "cors", "enabled": "true"}, "global-cors"},
+		{map[string]interface{}{"scope": "global"}, "global-cors"},
+		{map[string]interface{}{"scope": "service"}, "service-cors,consumer-rate-limiting"},
+		{map[string]interface{}{"scope": "service", "enabled": "true"}, "consumer-rate-limiting"},
+		{map[string]interface{}{"scope": "consumer_group"}, "group-rate-limiting"},
+		{map[string]interface{}{"tags": []interface{}{"audit"}}, "global-cors,service-cors,consumer-rate-limiting"},
+		{map[string]interface{}{"tags": []interface{}{"audit", "team-a"}}, "service-cors"},
+		{map[string]interface{}{"tags": []interface{}{"audit"}, "scope": "service", "name": "rate-limiting"}, "consumer-rate-limiting"},
+		{map[string]interface{}{"name": "acl", "scope": "consumer"}, ""},
+	}
+
+	for _, c := range cases {
+		queries = nil
+		d := schema.TestResourceDataRaw(t, dataSourceKongPlugins().Schema, c.filters)
+		if err := dataSourceKongPluginsRead(d, client); err != nil {
+			t.Fatalf("%v: could not read plugins: %v", c.filters, err)
+		}
+
+		var found []string
+		for _, plugin := range d.Get("plugins").([]interface{}) {
+			found = append(found, plugin.(map[string]interface{})["id"].(string))
+		}
+
+		if strings.Join(found, ",") != c.expected {
+			t.Errorf("%v: expected the plugins %s but got %v", c.filters, c.expected, found)
+		}
+
+		if tags := strings.Join(configStrings(c.filters["tags"]), ","); len(queries) != 1 || queries[0] != tags {
+			t.Errorf("%v: expected the tags to be left to kong with a single list, got the tags %q", c.filters, queries)
+		}
+	}
+
+	d := schema.TestResourceDataRaw(t, dataSourceKongPlugins().Schema, map[string]interface{}{"scope": "service", "name": "rate-limiting"})
+	if err := dataSourceKongPluginsRead(d, client); err != nil {
+		t.Fatalf("could not read plugins: %v", err)
+	}
+
+	plugin := d.Get("plugins").([]interface{})[0].(map[string]interface{})
+	if scope := plugin["scope"].([]interface{}); len(scope) != 2 || scope[0] != "consumer" || scope[1] != "service" ||
+		plugin["service_id"] != "service-id" || plugin["consumer_id"] != "consumer-id" || plugin["enabled"] != true {
+		t.Errorf("expected the scope and enabled status of the plugin, got: %v", plugin)
+	}
+
+	if d.Id() != "plugins?name=rate-limiting&scope=service" {
+		t.Errorf("expected the id to be made of the filters, got: %s", d.Id())
+	}
+
+	if _, errors := validatePluginsScopeFilter("api", "scope"); len(errors) != 1 {
+		t.Errorf("expected an unknown scope to be rejected, got: %v", errors)
+	}
+	if _, errors := validatePluginsEnabledFilter("yes", "enabled"); len(errors) != 1 {
+		t.Errorf("expected enabled to be true or false, got: %v", errors)
+	}
+}
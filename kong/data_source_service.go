@@ -0,0 +1,138 @@
+package kong
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/kevholditch/gokong"
+)
+
+func dataSourceKongService() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceKongServiceRead,
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			// services are cached by name for the whole plan or apply, set this to always read the service from kong
+			"bypass_cache": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"id": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"protocol": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"host": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"port": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"path": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"retries": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"connect_timeout": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"write_timeout": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"read_timeout": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			// the routes and plugins are read on every refresh, only the service is cached
+			"route_ids": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"plugin_ids": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func dataSourceKongServiceRead(d *schema.ResourceData, meta interface{}) error {
+
+	name := readStringFromResource(d, "name")
+
+	service, err := meta.(*kongClient).getServiceByName(name, d.Get("bypass_cache").(bool))
+
+	if err != nil {
+		return fmt.Errorf("could not find service, error: %v", err)
+	}
+
+	if service == nil {
+		return fmt.Errorf("could not find service with name: %s", name)
+	}
+
+	d.SetId(*service.Id)
+	d.Set("id", service.Id)
+	d.Set("name", service.Name)
+	d.Set("protocol", service.Protocol)
+	d.Set("host", service.Host)
+	d.Set("port", service.Port)
+	if service.Path != nil {
+		d.Set("path", service.Path)
+	} else {
+		d.Set("path", "")
+	}
+	d.Set("retries", service.Retries)
+	d.Set("connect_timeout", service.ConnectTimeout)
+	d.Set("write_timeout", service.WriteTimeout)
+	d.Set("read_timeout", service.ReadTimeout)
+
+	routeIds, err := listEntityIds(meta.(*kongClient), gokong.ServicesPath+*service.Id+"/routes")
+	if err != nil {
+		return fmt.Errorf("could not find routes of service %s, error: %v", name, err)
+	}
+	d.Set("route_ids", routeIds)
+
+	pluginIds, err := listEntityIds(meta.(*kongClient), gokong.ServicesPath+*service.Id+"/plugins")
+	if err != nil {
+		return fmt.Errorf("could not find plugins of service %s, error: %v", name, err)
+	}
+	d.Set("plugin_ids", pluginIds)
+
+	return nil
+}
+
+// listEntityIds returns the id of every entity of the list endpoint at path
+func listEntityIds(client *kongClient, path string) ([]string, error) {
+	results, err := client.listAll(path)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(results))
+	for _, result := range results {
+		entity := &entityReference{}
+		if err := json.Unmarshal(result, entity); err != nil {
+			return nil, fmt.Errorf("could not parse kong response from %s: %v", path, err)
+		}
+		ids = append(ids, entity.Id)
+	}
+
+	return ids, nil
+}
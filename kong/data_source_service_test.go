@@ -0,0 +1,239 @@
+package kong
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/kevholditch/gokong"
+)
+
+func TestAccDataSourceKongService(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testServiceDataSourceConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair("data.kong_service.service_data_source", "id", "kong_service.service", "id"),
+					resource.TestCheckResourceAttr("data.kong_service.service_data_source", "protocol", "http"),
+					resource.TestCheckResourceAttr("data.kong_service.service_data_source", "host", "test.org"),
+					resource.TestCheckResourceAttr("data.kong_service.service_data_source", "port", "8080"),
+					resource.TestCheckResourceAttr("data.kong_service.service_data_source", "path", "/mypath"),
+					resource.TestCheckResourceAttrPair("data.kong_service.service_bypass_cache", "id", "kong_service.service", "id"),
+					resource.TestCheckResourceAttr("data.kong_service.service_data_source", "route_ids.#", "0"),
+					resource.TestCheckResourceAttr("data.kong_service.service_data_source", "plugin_ids.#", "0"),
+				),
+			},
+			{
+				Config: testServiceDataSourceWithDependentsConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.kong_service.service_data_source", "route_ids.#", "2"),
+					resource.TestCheckResourceAttr("data.kong_service.service_data_source", "plugin_ids.#", "2"),
+				),
+			},
+		},
+	})
+}
+
+func TestDataSourceKongServiceCachesByName(t *testing.T) {
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/routes") || strings.HasSuffix(r.URL.Path, "/plugins") {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"data":[]}`))
+			return
+		}
+		if r.URL.Path != "/services/test" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"a2b3c4d5-0000-4000-8000-000000000001","name":"test","protocol":"http","host":"test.org","port":8080}`))
+	}))
+	defer server.Close()
+
+	client := newKongClient(&gokong.Config{HostAddress: server.URL})
+
+	for i := 0; i < 3; i++ {
+		d := schema.TestResourceDataRaw(t, dataSourceKongService().Schema, map[string]interface{}{"name": "test"})
+		if err := dataSourceKongServiceRead(d, client); err != nil {
+			t.Fatalf("could not read service: %v", err)
+		}
+		if d.Id() != "a2b3c4d5-0000-4000-8000-000000000001" {
+			t.Errorf("expected the service id to be set but was %s", d.Id())
+		}
+	}
+
+	if calls != 1 {
+		t.Errorf("expected 1 call to kong for repeated reads of the same service but there were %d", calls)
+	}
+
+	d := schema.TestResourceDataRaw(t, dataSourceKongService().Schema, map[string]interface{}{"name": "test", "bypass_cache": true})
+	if err := dataSourceKongServiceRead(d, client); err != nil {
+		t.Fatalf("could not read service: %v", err)
+	}
+
+	if calls != 2 {
+		t.Errorf("expected bypass_cache to call kong again but there were %d calls", calls)
+	}
+
+	d = schema.TestResourceDataRaw(t, dataSourceKongService().Schema, map[string]interface{}{"name": "missing"})
+	if err := dataSourceKongServiceRead(d, client); err == nil {
+		t.Errorf("expected a missing service to be an error")
+	}
+}
+
+func TestDataSourceKongServiceCacheClearedByServiceChanges(t *testing.T) {
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"a2b3c4d5-0000-4000-8000-000000000001","name":"test","protocol":"http","host":"test.org","port":8080}`))
+	}))
+	defer server.Close()
+
+	client := newKongClient(&gokong.Config{HostAddress: server.URL})
+
+	if _, err := client.getServiceByName("test", false); err != nil {
+		t.Fatalf("could not get service: %v", err)
+	}
+
+	d := schema.TestResourceDataRaw(t, resourceKongService().Schema, map[string]interface{}{"name": "test"})
+	d.SetId("a2b3c4d5-0000-4000-8000-000000000001")
+	if err := resourceKongServiceDelete(d, client); err != nil {
+		t.Fatalf("could not delete service: %v", err)
+	}
+
+	if _, err := client.getServiceByName("test", false); err != nil {
+		t.Fatalf("could not get service: %v", err)
+	}
+
+	if calls != 2 {
+		t.Errorf("expected deleting a service to clear the cache so kong is called again but there were %d calls", calls)
+	}
+}
+
+func TestDataSourceKongServiceRoutesAndPlugins(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/services/with-dependents":
+			w.Write([]byte(`{"id":"service-id","name":"with-dependents","protocol":"http","host":"test.org","port":80}`))
+		case "/services/without-dependents":
+			w.Write([]byte(`{"id":"lonely-id","name":"without-dependents","protocol":"http","host":"test.org","port":80}`))
+		case "/services/service-id/routes":
+			if r.URL.Query().Get("offset") == "" {
+				w.Write([]byte(`{"data":[{"id":"route-1"},{"id":"route-2"}],"offset":"page-2"}`))
+			} else {
+				w.Write([]byte(`{"data":[{"id":"route-3"}]}`))
+			}
+		case "/services/service-id/plugins":
+			w.Write([]byte(`{"data":[{"id":"plugin-1","name":"cors"},{"id":"plugin-2","name":"acl"}]}`))
+		case "/services/lonely-id/routes", "/services/lonely-id/plugins":
+			w.Write([]byte(`{"data":[]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := newKongClient(&gokong.Config{HostAddress: server.URL})
+
+	cases := []struct {
+		name      string
+		routeIds  string
+		pluginIds string
+	}{
+		{"with-dependents", "route-1,route-2,route-3", "plugin-1,plugin-2"},
+		{"without-dependents", "", ""},
+	}
+
+	for _, c := range cases {
+		d := schema.TestResourceDataRaw(t, dataSourceKongService().Schema, map[string]interface{}{"name": c.name})
+		if err := dataSourceKongServiceRead(d, client); err != nil {
+			t.Fatalf("service %s: could not read service: %v", c.name, err)
+		}
+
+		if routeIds := strings.Join(readStringArrayFromResource(d, "route_ids"), ","); routeIds != c.routeIds {
+			t.Errorf("service %s: expected route_ids [%s] but was [%s]", c.name, c.routeIds, routeIds)
+		}
+
+		if pluginIds := strings.Join(readStringArrayFromResource(d, "plugin_ids"), ","); pluginIds != c.pluginIds {
+			t.Errorf("service %s: expected plugin_ids [%s] but was [%s]", c.name, c.pluginIds, pluginIds)
+		}
+	}
+}
+
+const testServiceDataSourceConfig = `
+resource "kong_service" "service" {
+	name     = "test"
+	protocol = "http"
+	host     = "test.org"
+	port     = 8080
+	path     = "/mypath"
+}
+
+data "kong_service" "service_data_source" {
+	name = "${kong_service.service.name}"
+}
+
+data "kong_service" "service_bypass_cache" {
+	name         = "${kong_service.service.name}"
+	bypass_cache = true
+}
+`
+
+const testServiceDataSourceWithDependentsConfig = `
+resource "kong_service" "service" {
+	name     = "test"
+	protocol = "http"
+	host     = "test.org"
+	port     = 8080
+	path     = "/mypath"
+}
+
+resource "kong_route" "first" {
+	protocols  = ["http"]
+	paths      = ["/first"]
+	service_id = "${kong_service.service.id}"
+}
+
+resource "kong_route" "second" {
+	protocols  = ["http"]
+	paths      = ["/second"]
+	service_id = "${kong_service.service.id}"
+}
+
+resource "kong_plugin" "rate_limit" {
+	name       = "rate-limiting"
+	service_id = "${kong_service.service.id}"
+	config     = {
+		minute = "10"
+	}
+}
+
+resource "kong_plugin" "cors" {
+	name       = "cors"
+	service_id = "${kong_service.service.id}"
+}
+
+data "kong_service" "service_data_source" {
+	name         = "${kong_service.service.name}"
+	bypass_cache = true
+	depends_on   = ["kong_route.first", "kong_route.second", "kong_plugin.rate_limit", "kong_plugin.cors"]
+}
+`
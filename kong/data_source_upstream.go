@@ -1,7 +1,9 @@
 package kong
 
 import (
+	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/kevholditch/gokong"
@@ -45,10 +47,132 @@ func dataSourceKongUpstream() *schema.Resource {
 				Elem:     &schema.Schema{Type: schema.TypeInt},
 				Computed: true,
 			},
+			// One entry per address kong resolved the targets to, read from the upstream's health endpoint
+			"effective_targets": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"target": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"ip": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"port": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"weight": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"health": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"dns_error": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
 		},
 	}
 }
 
+type upstreamTargetHealth struct {
+	Target string `json:"target"`
+	Weight int    `json:"weight"`
+	Health string `json:"health"`
+	Data   struct {
+		Dns       string `json:"dns"`
+		Addresses []struct {
+			Ip     string `json:"ip"`
+			Port   int    `json:"port"`
+			Weight int    `json:"weight"`
+			Health string `json:"health"`
+		} `json:"addresses"`
+	} `json:"data"`
+}
+
+// readUpstreamEffectiveTargets flattens the targets of the upstream's health into the addresses kong balances over, a
+// target with an SRV record becomes one entry per record. A target whose name could not be resolved has no addresses,
+// it is kept as a single entry without an ip that holds the dns error.
+func readUpstreamEffectiveTargets(client *kongClient, upstreamId string) ([]map[string]interface{}, error) {
+	health, err := listUpstreamTargetHealth(client, upstreamId)
+	if err != nil {
+		return nil, err
+	}
+
+	targets := []map[string]interface{}{}
+	for _, target := range health {
+		if dnsError := upstreamTargetDnsError(target); dnsError != "" || len(target.Data.Addresses) == 0 {
+			targets = append(targets, map[string]interface{}{
+				"target":    target.Target,
+				"ip":        "",
+				"port":      0,
+				"weight":    0,
+				"health":    target.Health,
+				"dns_error": dnsError,
+			})
+			continue
+		}
+
+		for _, address := range target.Data.Addresses {
+			targets = append(targets, map[string]interface{}{
+				"target":    target.Target,
+				"ip":        address.Ip,
+				"port":      address.Port,
+				"weight":    address.Weight,
+				"health":    address.Health,
+				"dns_error": "",
+			})
+		}
+	}
+
+	return targets, nil
+}
+
+// listUpstreamTargetHealth reads the health of every target of the upstream from kong
+func listUpstreamTargetHealth(client *kongClient, upstreamId string) ([]*upstreamTargetHealth, error) {
+	results, err := client.listAll(gokong.UpstreamsPath + upstreamId + "/health/")
+	if err != nil {
+		return nil, err
+	}
+
+	targets := make([]*upstreamTargetHealth, 0, len(results))
+	for _, result := range results {
+		target := &upstreamTargetHealth{}
+		if err := json.Unmarshal(result, target); err != nil {
+			return nil, fmt.Errorf("could not parse upstream target health: %v", err)
+		}
+		targets = append(targets, target)
+	}
+
+	return targets, nil
+}
+
+// upstreamTargetDnsError returns the error kong reports instead of the record type when it could not resolve the target
+// (e.g. "dns server error: 3 name error"), kong 3.x also reports the target's health as DNS_ERROR.
+func upstreamTargetDnsError(target *upstreamTargetHealth) string {
+	if strings.Contains(target.Data.Dns, "error") {
+		return target.Data.Dns
+	}
+
+	if target.Health == "DNS_ERROR" {
+		if target.Data.Dns != "" {
+			return target.Data.Dns
+		}
+		return "kong could not resolve " + target.Target
+	}
+
+	return ""
+}
+
 func dataSourceKongUpstreamRead(d *schema.ResourceData, meta interface{}) error {
 
 	filter := &gokong.UpstreamFilter{}
@@ -62,7 +186,8 @@ func dataSourceKongUpstreamRead(d *schema.ResourceData, meta interface{}) error
 		}
 	}
 
-	results, err := meta.(*gokong.KongAdminClient).Upstreams().ListFiltered(filter)
+	results := &gokong.Upstreams{}
+	err := meta.(*kongClient).listFiltered(gokong.UpstreamsPath, filter, results)
 
 	if err != nil {
 		return fmt.Errorf("could not find upstream, error: %v", err)
@@ -83,5 +208,11 @@ func dataSourceKongUpstreamRead(d *schema.ResourceData, meta interface{}) error
 	d.Set("name", upstream.Name)
 	d.Set("slots", upstream.Slots)
 
+	effectiveTargets, err := readUpstreamEffectiveTargets(meta.(*kongClient), upstream.Id)
+	if err != nil {
+		return fmt.Errorf("could not read health of kong upstream %s: %v", upstream.Id, err)
+	}
+	d.Set("effective_targets", effectiveTargets)
+
 	return nil
 }
@@ -0,0 +1,94 @@
+package kong
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func dataSourceKongUpstreamHealth() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceKongUpstreamHealthRead,
+		Schema: map[string]*schema.Schema{
+			"upstream_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The id or name of the upstream",
+			},
+			"all_healthy": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			"status_summary": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceKongUpstreamHealthRead(d *schema.ResourceData, meta interface{}) error {
+	upstreamId := readStringFromResource(d, "upstream_id")
+
+	targets, err := listUpstreamTargetHealth(meta.(*kongClient), upstreamId)
+	if err != nil {
+		return fmt.Errorf("could not read health of kong upstream %s: %v", upstreamId, err)
+	}
+
+	allHealthy, summary := summarizeUpstreamHealth(targets)
+
+	d.SetId(upstreamId)
+	d.Set("all_healthy", allHealthy)
+	d.Set("status_summary", summary)
+
+	return nil
+}
+
+// summarizeUpstreamHealth is true only when kong knows every target and each of its addresses to be healthy, an
+// upstream without targets or with health checks turned off is not known to be healthy. The summary gives the reason
+// for each target that is not healthy.
+func summarizeUpstreamHealth(targets []*upstreamTargetHealth) (bool, string) {
+	if len(targets) == 0 {
+		return false, "the upstream has no targets"
+	}
+
+	var reasons []string
+	for _, target := range targets {
+		if reason := upstreamTargetUnhealthyReason(target); reason != "" {
+			reasons = append(reasons, target.Target+" "+reason)
+		}
+	}
+
+	healthy := len(targets) - len(reasons)
+	summary := fmt.Sprintf("%d of %d targets healthy", healthy, len(targets))
+	if len(reasons) > 0 {
+		summary += ": " + strings.Join(reasons, ", ")
+	}
+
+	return len(reasons) == 0, summary
+}
+
+func upstreamTargetUnhealthyReason(target *upstreamTargetHealth) string {
+	if dnsError := upstreamTargetDnsError(target); dnsError != "" {
+		return "could not be resolved (" + dnsError + ")"
+	}
+
+	switch target.Health {
+	case "HEALTHY":
+	case "HEALTHCHECKS_OFF":
+		return "has health checks turned off so its health is unknown"
+	case "":
+		return "has no health reported by kong"
+	default:
+		return "is " + target.Health
+	}
+
+	for _, address := range target.Data.Addresses {
+		if address.Health != "" && address.Health != "HEALTHY" {
+			return fmt.Sprintf("has address %s:%d that is %s", address.Ip, address.Port, address.Health)
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,113 @@
+package kong
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/kevholditch/gokong"
+)
+
+func TestAccDataSourceKongUpstreamHealth(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheckKongVersion(t, "1.0.0") },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testUpstreamHealthDataSourceConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.kong_upstream_health.health", "all_healthy", "false"),
+					resource.TestCheckResourceAttr("data.kong_upstream_health.health", "status_summary",
+						"0 of 1 targets healthy: 10.0.0.1:8080 has health checks turned off so its health is unknown"),
+				),
+			},
+		},
+	})
+}
+
+func TestDataSourceKongUpstreamHealth(t *testing.T) {
+
+	responses := map[string]string{
+		"/upstreams/healthy/health/": `{"data":[
+			{"target":"10.0.0.1:80","health":"HEALTHY","data":{"addresses":[{"ip":"10.0.0.1","port":80,"health":"HEALTHY"}]}},
+			{"target":"service.consul:80","health":"HEALTHY","data":{"dns":"SRV","addresses":[{"ip":"10.0.0.2","port":8000,"health":"HEALTHY"},{"ip":"10.0.0.3","port":8001,"health":"HEALTHY"}]}}
+		]}`,
+		"/upstreams/mixed/health/": `{"data":[
+			{"target":"10.0.0.1:80","health":"HEALTHY","data":{"addresses":[{"ip":"10.0.0.1","port":80,"health":"HEALTHY"}]}},
+			{"target":"10.0.0.4:80","health":"UNHEALTHY","data":{"addresses":[{"ip":"10.0.0.4","port":80,"health":"UNHEALTHY"}]}},
+			{"target":"service.consul:80","health":"HEALTHY","data":{"dns":"SRV","addresses":[{"ip":"10.0.0.2","port":8000,"health":"HEALTHY"},{"ip":"10.0.0.3","port":8001,"health":"UNHEALTHY"}]}},
+			{"target":"missing.consul:80","health":"DNS_ERROR","data":{"dns":"dns server error: 3 name error","addresses":[]}}
+		]}`,
+		"/upstreams/unchecked/health/": `{"data":[
+			{"target":"10.0.0.1:80","health":"HEALTHCHECKS_OFF","data":{"addresses":[{"ip":"10.0.0.1","port":80,"health":"HEALTHCHECKS_OFF"}]}}
+		]}`,
+		"/upstreams/empty/health/": `{"data":[]}`,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response, ok := responses[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(response))
+	}))
+	defer server.Close()
+
+	client := newKongClient(&gokong.Config{HostAddress: server.URL})
+
+	cases := []struct {
+		upstream string
+		healthy  bool
+		summary  string
+	}{
+		{"healthy", true, "2 of 2 targets healthy"},
+		{"mixed", false, "1 of 4 targets healthy: 10.0.0.4:80 is UNHEALTHY, service.consul:80 has address 10.0.0.3:8001 that is UNHEALTHY, " +
+			"missing.consul:80 could not be resolved (dns server error: 3 name error)"},
+		{"unchecked", false, "0 of 1 targets healthy: 10.0.0.1:80 has health checks turned off so its health is unknown"},
+		{"empty", false, "the upstream has no targets"},
+	}
+
+	for _, c := range cases {
+		d := schema.TestResourceDataRaw(t, dataSourceKongUpstreamHealth().Schema, map[string]interface{}{"upstream_id": c.upstream})
+		if err := dataSourceKongUpstreamHealthRead(d, client); err != nil {
+			t.Fatalf("upstream %s: could not read health: %v", c.upstream, err)
+		}
+
+		if healthy := d.Get("all_healthy").(bool); healthy != c.healthy {
+			t.Errorf("upstream %s: expected all_healthy to be %t", c.upstream, c.healthy)
+		}
+
+		if summary := d.Get("status_summary").(string); summary != c.summary {
+			t.Errorf("upstream %s: expected the summary %q but was %q", c.upstream, c.summary, summary)
+		}
+	}
+
+	d := schema.TestResourceDataRaw(t, dataSourceKongUpstreamHealth().Schema, map[string]interface{}{"upstream_id": "missing"})
+	if err := dataSourceKongUpstreamHealthRead(d, client); err == nil || !strings.Contains(err.Error(), "could not read health of kong upstream missing") {
+		t.Errorf("expected reading the health of a missing upstream to fail, got: %v", err)
+	}
+}
+
+const testUpstreamHealthDataSourceConfig = `
+resource "kong_upstream" "upstream" {
+	name  = "HealthUpstream"
+	slots = 10
+}
+
+resource "kong_target" "target" {
+	upstream_id = "${kong_upstream.upstream.id}"
+	target      = "10.0.0.1:8080"
+	weight      = 100
+}
+
+data "kong_upstream_health" "health" {
+	upstream_id = "${kong_upstream.upstream.id}"
+	depends_on  = ["kong_target.target"]
+}
+`
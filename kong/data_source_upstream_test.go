@@ -1,9 +1,13 @@
 package kong
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/kevholditch/gokong"
 )
 
 func TestAccDataSourceKongUpstream(t *testing.T) {
@@ -16,12 +20,98 @@ func TestAccDataSourceKongUpstream(t *testing.T) {
 				Check: resource.ComposeTestCheckFunc(
 					resource.TestCheckResourceAttr("data.kong_upstream.upstream_data_source", "name", "TestUpstream"),
 					resource.TestCheckResourceAttr("data.kong_upstream.upstream_data_source", "slots", "10"),
+					resource.TestCheckResourceAttr("data.kong_upstream.upstream_data_source", "effective_targets.#", "0"),
 				),
 			},
 		},
 	})
 }
 
+func TestDataSourceKongUpstreamEffectiveTargets(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/upstreams/":
+			w.Write([]byte(`{"data":[{"id":"upstream-id","name":"srv-upstream","slots":10}]}`))
+		case "/upstreams/upstream-id/health/":
+			w.Write([]byte(testUpstreamHealthResponse))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	d := schema.TestResourceDataRaw(t, dataSourceKongUpstream().Schema, map[string]interface{}{
+		"filter": []interface{}{map[string]interface{}{"name": "srv-upstream"}},
+	})
+
+	if err := dataSourceKongUpstreamRead(d, newKongClient(&gokong.Config{HostAddress: server.URL})); err != nil {
+		t.Fatalf("could not read upstream: %v", err)
+	}
+
+	expected := []map[string]interface{}{
+		{"target": "service.consul:80", "ip": "10.0.0.1", "port": 8000, "weight": 10, "health": "HEALTHY", "dns_error": ""},
+		{"target": "service.consul:80", "ip": "10.0.0.2", "port": 8001, "weight": 20, "health": "UNHEALTHY", "dns_error": ""},
+		{"target": "10.0.0.3:80", "ip": "10.0.0.3", "port": 80, "weight": 100, "health": "HEALTHCHECKS_OFF", "dns_error": ""},
+		{"target": "missing.consul:80", "ip": "", "port": 0, "weight": 0, "health": "DNS_ERROR", "dns_error": "dns server error: 3 name error"},
+	}
+
+	targets := d.Get("effective_targets").([]interface{})
+	if len(targets) != len(expected) {
+		t.Fatalf("expected %d effective targets, got: %v", len(expected), targets)
+	}
+
+	for i, target := range targets {
+		for key, value := range expected[i] {
+			if target.(map[string]interface{})[key] != value {
+				t.Errorf("effective target %d: expected %s to be %v, got: %v", i, key, value, target)
+			}
+		}
+	}
+}
+
+const testUpstreamHealthResponse = `
+{
+	"total": 3,
+	"node_id": "node-id",
+	"data": [
+		{
+			"target": "service.consul:80",
+			"weight": 100,
+			"health": "HEALTHY",
+			"data": {
+				"dns": "SRV",
+				"addresses": [
+					{"ip": "10.0.0.1", "port": 8000, "weight": 10, "health": "HEALTHY"},
+					{"ip": "10.0.0.2", "port": 8001, "weight": 20, "health": "UNHEALTHY"}
+				]
+			}
+		},
+		{
+			"target": "10.0.0.3:80",
+			"weight": 100,
+			"health": "HEALTHCHECKS_OFF",
+			"data": {
+				"dns": "A",
+				"addresses": [
+					{"ip": "10.0.0.3", "port": 80, "weight": 100, "health": "HEALTHCHECKS_OFF"}
+				]
+			}
+		},
+		{
+			"target": "missing.consul:80",
+			"weight": 100,
+			"health": "DNS_ERROR",
+			"data": {
+				"dns": "dns server error: 3 name error",
+				"addresses": []
+			}
+		}
+	]
+}
+`
+
 const testUpstreamDataSourceConfig = `
 resource "kong_upstream" "upstream" {
 	name  		= "TestUpstream"
@@ -0,0 +1,56 @@
+package kong
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// logDrift logs every field where the value read from kong differs from the one in state, it has to be called before
+// Read sets the upstream values. Nothing is logged unless the provider debug flag is set, or straight after a create when
+// the state only holds the planned values. The values of sensitive keys are masked, see redactDriftValue.
+func logDrift(client *kongClient, resourceType string, d *schema.ResourceData, upstream map[string]interface{}) {
+	if !client.debug || d.IsNewResource() {
+		return
+	}
+
+	keys := make([]string, 0, len(upstream))
+	for key := range upstream {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		stateValue := driftValue(d.Get(key))
+		upstreamValue := driftValue(upstream[key])
+		if stateValue != upstreamValue {
+			log.Printf("[INFO] %s %s drifted: %s changed from %q to %q", resourceType, d.Id(), key, redactDriftValue(key, stateValue), redactDriftValue(key, upstreamValue))
+		}
+	}
+}
+
+func driftValue(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
+
+// redactDriftValue masks what must not end up in the logs: the whole value of a sensitive key and, in a json config
+// like config_json, the values of its sensitive keys (see redactConfig)
+func redactDriftValue(key string, value string) string {
+	if isSensitiveConfigKey(key) {
+		return redactedValue
+	}
+
+	config := map[string]interface{}{}
+	if strings.HasPrefix(strings.TrimSpace(value), "{") && json.Unmarshal([]byte(value), &config) == nil {
+		return redactedJson(redactConfig(config, nil))
+	}
+
+	return value
+}
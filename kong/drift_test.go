@@ -0,0 +1,97 @@
+package kong
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/kevholditch/gokong"
+)
+
+func TestKongPluginReadLogsDrift(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"plugin-id","name":"request-size-limiting","enabled":true,"config":{"allowed_payload_size":128}}`))
+	}))
+	defer server.Close()
+
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+
+	for _, debug := range []bool{false, true} {
+		logs.Reset()
+
+		d := resourceKongPlugin().Data(&terraform.InstanceState{
+			ID: "plugin-id",
+			Attributes: map[string]string{
+				"id":          "plugin-id",
+				"name":        "request-size-limiting",
+				"enabled":     "true",
+				"config_json": `{"allowed_payload_size":64}`,
+			},
+		})
+
+		client := newKongClient(&gokong.Config{HostAddress: server.URL})
+		client.debug = debug
+
+		if err := resourceKongPluginRead(d, client); err != nil {
+			t.Fatalf("could not read plugin: %v", err)
+		}
+
+		drifted := strings.Contains(logs.String(), `[INFO] kong_plugin plugin-id drifted: config_json changed from "{\"allowed_payload_size\":64}" to "{\"allowed_payload_size\":128}"`)
+
+		if debug && !drifted {
+			t.Errorf("expected the config_json drift to be logged, logs were: %s", logs.String())
+		}
+
+		if !debug && logs.Len() > 0 {
+			t.Errorf("expected nothing to be logged without the debug flag, logs were: %s", logs.String())
+		}
+
+		if strings.Contains(logs.String(), "name changed") || strings.Contains(logs.String(), "enabled changed") {
+			t.Errorf("expected only the fields that drifted to be logged, logs were: %s", logs.String())
+		}
+	}
+}
+
+func TestKongPluginReadDriftRedactsSecrets(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"plugin-id","name":"rate-limiting","enabled":true,"config":{"minute":20,"redis_password":"r0tated"}}`))
+	}))
+	defer server.Close()
+
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+
+	d := resourceKongPlugin().Data(&terraform.InstanceState{
+		ID: "plugin-id",
+		Attributes: map[string]string{
+			"id":          "plugin-id",
+			"name":        "rate-limiting",
+			"enabled":     "true",
+			"config_json": `{"minute":10,"redis_password":"s3cr3t"}`,
+		},
+	})
+
+	client := newKongClient(&gokong.Config{HostAddress: server.URL})
+	client.debug = true
+
+	if err := resourceKongPluginRead(d, client); err != nil {
+		t.Fatalf("could not read plugin: %v", err)
+	}
+
+	expected := `config_json changed from "{\"minute\":10,\"redis_password\":\"<redacted>\"}" to "{\"minute\":20,\"redis_password\":\"<redacted>\"}"`
+	if !strings.Contains(logs.String(), expected) || strings.Contains(logs.String(), "s3cr3t") || strings.Contains(logs.String(), "r0tated") {
+		t.Errorf("expected the drift to be logged with the redis password redacted, logs were: %s", logs.String())
+	}
+}
@@ -0,0 +1,49 @@
+package kong
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+)
+
+// idempotentCreatesMinimumKongVersion is the first kong version that creates an entity with a PUT to an id of our
+// choosing
+const idempotentCreatesMinimumKongVersion = "1.0.0"
+
+// idempotentEntityId derives a uuid from the admin api address, the path the entity is created at and the attributes
+// that identify the entity in kong (e.g. the name of a service). Terraform does not tell a provider the address of the
+// resource it is creating, so creating the same entity again gives it the same id.
+func (client *kongClient) idempotentEntityId(path string, identity []string) string {
+	hash := sha1.Sum([]byte(client.config.HostAddress + "\n" + path + "\n" + strings.Join(identity, "\n")))
+
+	// the version (5, name based with sha1) and variant bits of a uuid
+	hash[6] = (hash[6] & 0x0f) | 0x50
+	hash[8] = (hash[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", hash[0:4], hash[4:6], hash[6:8], hash[8:10], hash[10:16])
+}
+
+// create creates the entity at path. With use_idempotent_creates set it is created with a PUT to the id derived from
+// identity (see idempotentEntityId) instead of a POST, so a create that is tried again after kong committed the first
+// attempt, e.g. one that timed out waiting for the response, updates that entity rather than making a duplicate. It
+// falls back to the POST for kong versions older than 1.0.
+func (client *kongClient) create(path string, identity []string, request interface{}, result interface{}) error {
+	if !client.idempotentCreates {
+		return client.post(path, request, result)
+	}
+
+	kongVersion, err := client.version()
+	if err != nil {
+		return fmt.Errorf("could not check kong version for use_idempotent_creates: %v", err)
+	}
+
+	if kongVersion.LessThan(version.Must(version.NewVersion(idempotentCreatesMinimumKongVersion))) {
+		log.Printf("[WARN] use_idempotent_creates requires kong %s or later, kong %s: creating %s with a POST", idempotentCreatesMinimumKongVersion, kongVersion, path)
+		return client.post(path, request, result)
+	}
+
+	return client.put(path+client.idempotentEntityId(path, identity), request, result)
+}
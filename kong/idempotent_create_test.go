@@ -0,0 +1,180 @@
+package kong
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/kevholditch/gokong"
+)
+
+// kongEntityStore mocks the admin api of a kong node, entities are stored by path. POST creates an entity with a new
+// id and PUT creates or replaces the entity at its id. When hang is set the next create is committed but not answered
+// until the client gives up, like a kong that is too slow to respond.
+type kongEntityStore struct {
+	lock        sync.Mutex
+	kongVersion string
+	entities    map[string]map[string]interface{}
+	requests    []string
+	hang        bool
+}
+
+func newKongEntityStore(kongVersion string) (*kongEntityStore, *httptest.Server) {
+	store := &kongEntityStore{kongVersion: kongVersion, entities: map[string]map[string]interface{}{}}
+	return store, httptest.NewServer(http.HandlerFunc(store.serveHTTP))
+}
+
+func (store *kongEntityStore) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+
+	store.lock.Lock()
+	store.requests = append(store.requests, r.Method+" "+r.URL.Path)
+
+	path := r.URL.Path
+	if r.Method == http.MethodPost {
+		path += fmt.Sprintf("posted-%d", len(store.entities))
+	}
+
+	var response []byte
+	hang := false
+	switch {
+	case r.URL.Path == "/":
+		response, _ = json.Marshal(map[string]string{"version": store.kongVersion})
+	case r.Method == http.MethodPost || r.Method == http.MethodPut:
+		entity := map[string]interface{}{}
+		json.Unmarshal(body, &entity)
+		entity["id"] = path[strings.LastIndex(path, "/")+1:]
+		store.entities[path] = entity
+		response, _ = json.Marshal(entity)
+		hang, store.hang = store.hang, false
+	case r.Method == http.MethodGet && store.entities[path] != nil:
+		response, _ = json.Marshal(store.entities[path])
+	}
+	store.lock.Unlock()
+
+	if hang {
+		<-r.Context().Done()
+		return
+	}
+
+	if response == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(response)
+}
+
+func TestIdempotentCreateAfterTimeout(t *testing.T) {
+
+	for _, idempotent := range []bool{true, false} {
+		store, server := newKongEntityStore("2.8.0")
+
+		client := newKongClient(&gokong.Config{HostAddress: server.URL})
+		client.idempotentCreates = idempotent
+
+		plugin := map[string]interface{}{
+			"name":        "key-auth",
+			"config_json": `{"hide_credentials":true}`,
+		}
+
+		store.hang = true
+		if err := applyWithCreateTimeout(t, resourceKongPlugin(), plugin, client); err == nil || !strings.Contains(err.Error(), "timed out") {
+			t.Fatalf("use_idempotent_creates %t: expected the first create to time out, got: %v", idempotent, err)
+		}
+
+		// the create is tried again, like the next apply does as the plugin never made it into state
+		if err := applyWithCreateTimeout(t, resourceKongPlugin(), plugin, client); err != nil {
+			t.Fatalf("use_idempotent_creates %t: could not create plugin: %v", idempotent, err)
+		}
+		server.Close()
+
+		expected := 2
+		if idempotent {
+			expected = 1
+		}
+
+		if len(store.entities) != expected {
+			t.Errorf("use_idempotent_creates %t: expected kong to end up with %d plugins but it has %d: %v", idempotent, expected, len(store.entities), store.requests)
+		}
+	}
+}
+
+func TestIdempotentCreatesOfServicesAndRoutes(t *testing.T) {
+
+	for _, kongVersion := range []string{"2.8.0", "0.14.1"} {
+		store, server := newKongEntityStore(kongVersion)
+
+		client := newKongClient(&gokong.Config{HostAddress: server.URL})
+		client.idempotentCreates = true
+
+		service := schema.TestResourceDataRaw(t, resourceKongService().Schema, map[string]interface{}{
+			"name":     "service",
+			"protocol": "http",
+			"host":     "test.org",
+		})
+		if err := resourceKongServiceCreate(service, client); err != nil {
+			t.Fatalf("kong %s: could not create service: %v", kongVersion, err)
+		}
+
+		route := schema.TestResourceDataRaw(t, resourceKongRoute().Schema, map[string]interface{}{
+			"protocols":  []interface{}{"http"},
+			"paths":      []interface{}{"/"},
+			"service_id": service.Id(),
+		})
+		if err := resourceKongRouteCreate(route, client); err != nil {
+			t.Fatalf("kong %s: could not create route: %v", kongVersion, err)
+		}
+		server.Close()
+
+		var creates []string
+		for _, request := range store.requests {
+			if strings.HasPrefix(request, "PUT") || strings.HasPrefix(request, "POST") {
+				creates = append(creates, request)
+			}
+		}
+
+		expected := []string{"PUT /services/" + service.Id(), "PUT /routes/" + route.Id()}
+		if kongVersion == "0.14.1" {
+			expected = []string{"POST /services/", "POST /routes/"}
+		}
+
+		if strings.Join(creates, ",") != strings.Join(expected, ",") {
+			t.Errorf("kong %s: expected the creates %v but kong was sent %v", kongVersion, expected, creates)
+		}
+	}
+}
+
+func TestIdempotentEntityId(t *testing.T) {
+
+	client := newKongClient(&gokong.Config{HostAddress: "http://kong:8001"})
+	otherClient := newKongClient(&gokong.Config{HostAddress: "http://kong:8001/workspace"})
+
+	id := client.idempotentEntityId(gokong.ServicesPath, []string{"service"})
+
+	if !regexp.MustCompile("^[0-9a-f]{8}-[0-9a-f]{4}-5[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$").MatchString(id) {
+		t.Errorf("expected a name based uuid but was %s", id)
+	}
+
+	if again := client.idempotentEntityId(gokong.ServicesPath, []string{"service"}); again != id {
+		t.Errorf("expected the same entity to get the same id but got %s and %s", id, again)
+	}
+
+	for _, other := range []string{
+		client.idempotentEntityId(gokong.ServicesPath, []string{"other-service"}),
+		client.idempotentEntityId(gokong.RoutesPath, []string{"service"}),
+		otherClient.idempotentEntityId(gokong.ServicesPath, []string{"service"}),
+	} {
+		if other == id {
+			t.Errorf("expected a different entity to get a different id than %s", id)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package kong
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+)
+
+const defaultKonnectApiUrl = "https://us.api.konghq.com"
+
+// konnectKongVersion is the kong version a konnect control plane is treated as, the konnect admin api has no root
+// endpoint to read it from and konnect runs a recent kong enterprise.
+const konnectKongVersion = "3.6.0"
+
+// konnectProbePath is read to check a konnect control plane can be reached, konnect has no admin api root to read the
+// version from
+const konnectProbePath = "/services?size=1"
+
+// konnectAdminAddress is the admin api of a konnect control plane, the kong admin api paths are served below it
+func konnectAdminAddress(apiUrl string, controlPlaneId string) string {
+	return strings.TrimSuffix(apiUrl, "/") + "/v2/control-planes/" + controlPlaneId + "/core-entities"
+}
+
+// konnectAuthError explains a 401 or 403 from konnect, they mean the token is wrong rather than the admin api being
+// locked down like they do on a kong node.
+func konnectAuthError(controlPlaneId string, statusCode int, body string) error {
+	return fmt.Errorf("konnect responded with status %d, check konnect_token is a valid personal or system access token with access to control plane %s: %s", statusCode, controlPlaneId, body)
+}
+
+// useKonnect points the client at the konnect control plane, its version is fixed as konnect does not report one
+func (client *kongClient) useKonnect(controlPlaneId string) {
+	client.konnectControlPlaneId = controlPlaneId
+	client.kongVersion = version.Must(version.NewVersion(konnectKongVersion))
+	client.enterprise = true
+}
+
+// checkKonnect reads the services of the control plane, a wrong konnect_token fails with konnectAuthError and a control
+// plane konnect does not know of with a 404
+func (client *kongClient) checkKonnect() error {
+	found, err := client.get(konnectProbePath, nil)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("konnect has no control plane %s", client.konnectControlPlaneId)
+	}
+	return nil
+}
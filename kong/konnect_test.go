@@ -0,0 +1,160 @@
+package kong
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func TestKonnectSendsBearerTokenToControlPlane(t *testing.T) {
+
+	type request struct{ path, authorization string }
+	requests := make(chan request, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests <- request{r.URL.Path, r.Header.Get("Authorization")}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"service-id","name":"service"}`))
+	}))
+	defer server.Close()
+
+	d := schema.TestResourceDataRaw(t, Provider().(*schema.Provider).Schema, map[string]interface{}{
+		"konnect":          true,
+		"konnect_token":    "kpat_token",
+		"control_plane_id": "cp-id",
+		"konnect_api_url":  server.URL + "/",
+	})
+
+	meta, err := providerConfigure(d)
+	if err != nil {
+		t.Fatalf("could not configure the provider for konnect: %v", err)
+	}
+	client := meta.(*kongClient)
+
+	expected := request{"/v2/control-planes/cp-id/core-entities/services/service", "Bearer kpat_token"}
+
+	if _, err := client.get("/services/service", nil); err != nil {
+		t.Fatalf("raw request failed: %v", err)
+	}
+	if actual := <-requests; actual != expected {
+		t.Errorf("expected raw request %+v but was %+v", expected, actual)
+	}
+
+	if err := client.requireEnterprise("kong_license"); err != nil {
+		t.Errorf("expected konnect to be treated as kong enterprise: %v", err)
+	}
+}
+
+func TestKonnectTokenOnlySentToControlPlane(t *testing.T) {
+
+	authorizations := make(chan string, 1)
+	elsewhere := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorizations <- r.Header.Get("Authorization")
+		w.Write([]byte(`{}`))
+	}))
+	defer elsewhere.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, elsewhere.URL+"/services/service", http.StatusFound)
+	}))
+	defer server.Close()
+
+	d := schema.TestResourceDataRaw(t, Provider().(*schema.Provider).Schema, map[string]interface{}{
+		"konnect":          true,
+		"konnect_token":    "kpat_token",
+		"control_plane_id": "cp-id",
+		"konnect_api_url":  server.URL,
+	})
+
+	meta, err := providerConfigure(d)
+	if err != nil {
+		t.Fatalf("could not configure the provider for konnect: %v", err)
+	}
+
+	if _, err := meta.(*kongClient).get("/services/service", nil); err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if authorization := <-authorizations; authorization != "" {
+		t.Errorf("expected the konnect token not to be sent to another host, it was sent: %s", authorization)
+	}
+}
+
+func TestKonnectAuthError(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"message":"Unauthorized"}`))
+	}))
+	defer server.Close()
+
+	d := schema.TestResourceDataRaw(t, Provider().(*schema.Provider).Schema, map[string]interface{}{
+		"konnect":          true,
+		"konnect_token":    "expired",
+		"control_plane_id": "cp-id",
+		"konnect_api_url":  server.URL,
+	})
+
+	meta, err := providerConfigure(d)
+	if err != nil {
+		t.Fatalf("could not configure the provider for konnect: %v", err)
+	}
+
+	_, err = meta.(*kongClient).get("/services/service", nil)
+	if err == nil || !strings.Contains(err.Error(), "konnect responded with status 401, check konnect_token") {
+		t.Errorf("expected a konnect token error, got: %v", err)
+	}
+}
+
+func TestKonnectRequiresTokenAndControlPlane(t *testing.T) {
+
+	d := schema.TestResourceDataRaw(t, Provider().(*schema.Provider).Schema, map[string]interface{}{
+		"konnect":       true,
+		"konnect_token": "kpat_token",
+	})
+
+	if _, err := providerConfigure(d); err == nil || !strings.Contains(err.Error(), "konnect requires both konnect_token and control_plane_id") {
+		t.Errorf("expected konnect without a control plane to be rejected, got: %v", err)
+	}
+}
+
+func TestKonnectConfigureRetrySeconds(t *testing.T) {
+
+	var paths []string
+	controlPlaneId := "cp-id"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if !strings.HasPrefix(r.URL.Path, "/v2/control-planes/cp-id/") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":[]}`))
+	}))
+	defer server.Close()
+
+	configure := func() error {
+		d := schema.TestResourceDataRaw(t, Provider().(*schema.Provider).Schema, map[string]interface{}{
+			"konnect":                 true,
+			"konnect_token":           "kpat_token",
+			"control_plane_id":        controlPlaneId,
+			"konnect_api_url":         server.URL,
+			"configure_retry_seconds": 1,
+		})
+		_, err := providerConfigure(d)
+		return err
+	}
+
+	if err := configure(); err != nil {
+		t.Fatalf("expected configure to reach the konnect control plane, got: %v", err)
+	}
+	if len(paths) != 1 || paths[0] != "/v2/control-planes/cp-id/core-entities/services" {
+		t.Errorf("expected configure to read the services of the control plane, konnect was sent: %v", paths)
+	}
+
+	controlPlaneId = "missing"
+	if err := configure(); err == nil || !strings.Contains(err.Error(), "konnect has no control plane missing") {
+		t.Errorf("expected configure to fail for a control plane konnect does not know of, got: %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+package kong
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+var errOffline = fmt.Errorf("the kong provider is configured with offline = true, applying changes requires a connection to the kong admin api")
+
+// offlineTransport fails every request, it is installed in offline mode so a call that was missed by the wrappers
+// below fails rather than reaching the network.
+type offlineTransport struct{}
+
+func (offlineTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return nil, fmt.Errorf("not calling %s %s: %v", req.Method, req.URL, errOffline)
+}
+
+func isOffline(meta interface{}) bool {
+	client, ok := meta.(*kongClient)
+	return ok && client.offline
+}
+
+// withOfflineResource lets a plan run with the provider offline, refresh keeps the state as it is and creating,
+// updating, deleting or importing fails before calling kong.
+func withOfflineResource(r *schema.Resource) *schema.Resource {
+	read := r.Read
+	r.Read = func(d *schema.ResourceData, meta interface{}) error {
+		if isOffline(meta) {
+			return nil
+		}
+		return read(d, meta)
+	}
+
+	r.Create = failWhenOffline(r.Create)
+	r.Update = failWhenOffline(r.Update)
+	r.Delete = failWhenOffline(r.Delete)
+
+	if r.Importer != nil && r.Importer.State != nil {
+		importState := r.Importer.State
+		r.Importer.State = func(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+			if isOffline(meta) {
+				return nil, errOffline
+			}
+			return importState(d, meta)
+		}
+	}
+
+	return r
+}
+
+// withOfflineDataSource makes a data source read with the provider offline return empty values, its computed
+// attributes are left unset and the id is "offline".
+func withOfflineDataSource(r *schema.Resource) *schema.Resource {
+	read := r.Read
+	r.Read = func(d *schema.ResourceData, meta interface{}) error {
+		if isOffline(meta) {
+			d.SetId("offline")
+			return nil
+		}
+		return read(d, meta)
+	}
+
+	return r
+}
+
+func failWhenOffline(f func(*schema.ResourceData, interface{}) error) func(*schema.ResourceData, interface{}) error {
+	if f == nil {
+		return nil
+	}
+
+	return func(d *schema.ResourceData, meta interface{}) error {
+		if isOffline(meta) {
+			return errOffline
+		}
+		return f(d, meta)
+	}
+}
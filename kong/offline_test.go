@@ -0,0 +1,69 @@
+package kong
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestProviderOfflineMakesNoCalls(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	provider := Provider().(*schema.Provider)
+	meta, err := providerConfigure(schema.TestResourceDataRaw(t, provider.Schema, map[string]interface{}{
+		"kong_admin_uri":          server.URL,
+		"offline":                 true,
+		"configure_retry_seconds": 5,
+	}))
+	if err != nil {
+		t.Fatalf("could not configure offline provider: %v", err)
+	}
+
+	service := provider.ResourcesMap["kong_service"]
+	d := service.Data(&terraform.InstanceState{
+		ID:         "service-id",
+		Attributes: map[string]string{"id": "service-id", "name": "test", "host": "test.org", "protocol": "http"},
+	})
+
+	if err := service.Read(d, meta); err != nil {
+		t.Errorf("expected refresh to succeed offline, got: %v", err)
+	}
+
+	if d.Id() != "service-id" || d.Get("host") != "test.org" {
+		t.Errorf("expected refresh to keep the state offline, id was %s and host %s", d.Id(), d.Get("host"))
+	}
+
+	dataSource := provider.DataSourcesMap["kong_service"]
+	data := dataSource.TestResourceData()
+	data.Set("name", "test")
+
+	if err := dataSource.Read(data, meta); err != nil {
+		t.Errorf("expected the data source to be read offline, got: %v", err)
+	}
+
+	if data.Id() != "offline" || data.Get("host") != "" {
+		t.Errorf("expected the data source to be empty offline, id was %s and host %s", data.Id(), data.Get("host"))
+	}
+
+	if err := service.Create(service.TestResourceData(), meta); err != errOffline {
+		t.Errorf("expected create to fail offline, got: %v", err)
+	}
+
+	// anything that still calls kong fails in the transport rather than reaching it
+	if _, err := meta.(*kongClient).get("/services/service-id", nil); err == nil {
+		t.Errorf("expected a request made offline to fail")
+	}
+
+	if calls != 0 {
+		t.Errorf("expected no calls to kong offline but there were %d", calls)
+	}
+}
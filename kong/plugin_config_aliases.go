@@ -0,0 +1,82 @@
+package kong
+
+import (
+	"log"
+
+	"github.com/hashicorp/go-version"
+)
+
+// pluginConfigAlias is a config field kong renamed, alias is the name older kong versions use for field
+type pluginConfigAlias struct {
+	field string
+	alias string
+	// since is the first kong version that calls it field
+	since string
+}
+
+// pluginConfigAliases are the renamed config fields of each plugin, a config can use either name and is sent with the
+// name the kong node uses (see applyPluginConfigAliases), so upgrading kong does not require changing the config.
+var pluginConfigAliases = map[string][]pluginConfigAlias{
+	"acl": {
+		{field: "allow", alias: "whitelist", since: "2.1.0"},
+		{field: "deny", alias: "blacklist", since: "2.1.0"},
+	},
+	"ip-restriction": {
+		{field: "allow", alias: "whitelist", since: "2.1.0"},
+		{field: "deny", alias: "blacklist", since: "2.1.0"},
+	},
+	"bot-detection": {
+		{field: "allow", alias: "whitelist", since: "2.1.0"},
+		{field: "deny", alias: "blacklist", since: "2.1.0"},
+	},
+}
+
+// applyPluginConfigAliases renames the fields of config to the names the kong node knows them by, a field is left alone
+// when the config sets both names.
+func applyPluginConfigAliases(client *kongClient, pluginName string, config map[string]interface{}) error {
+	aliases := pluginConfigAliases[pluginName]
+	if len(aliases) == 0 || len(config) == 0 {
+		return nil
+	}
+
+	kongVersion, err := client.version()
+	if err != nil {
+		return err
+	}
+
+	for _, alias := range aliases {
+		from, to := alias.alias, alias.field
+		if kongVersion.LessThan(version.Must(version.NewVersion(alias.since))) {
+			from, to = alias.field, alias.alias
+		}
+
+		if renameConfigField(config, from, to) {
+			log.Printf("[INFO] kong plugin %s: config field %s is called %s in kong %s, it is sent as %s", pluginName, from, to, kongVersion, to)
+		}
+	}
+
+	return nil
+}
+
+// normalizePluginConfigAliases renames the fields of config that use an old name to the current one, so configs that
+// only differ in which name they use compare as equal whatever the kong version
+func normalizePluginConfigAliases(pluginName string, config map[string]interface{}) {
+	for _, alias := range pluginConfigAliases[pluginName] {
+		renameConfigField(config, alias.alias, alias.field)
+	}
+}
+
+func renameConfigField(config map[string]interface{}, from string, to string) bool {
+	value, ok := config[from]
+	if !ok {
+		return false
+	}
+
+	if _, exists := config[to]; exists {
+		return false
+	}
+
+	delete(config, from)
+	config[to] = value
+	return true
+}
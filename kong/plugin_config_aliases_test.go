@@ -0,0 +1,104 @@
+package kong
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/kevholditch/gokong"
+)
+
+func TestKongPluginConfigAliasesAcrossVersions(t *testing.T) {
+
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+
+	cases := []struct {
+		kongVersion string
+		configJson  string
+		expected    string
+	}{
+		{"2.8.0", `{"whitelist":["10.0.0.0/8"]}`, `{"allow":["10.0.0.0/8"]}`},
+		{"2.8.0", `{"allow":["10.0.0.0/8"]}`, `{"allow":["10.0.0.0/8"]}`},
+		{"1.5.0", `{"allow":["10.0.0.0/8"]}`, `{"whitelist":["10.0.0.0/8"]}`},
+		{"1.5.0", `{"whitelist":["10.0.0.0/8"]}`, `{"whitelist":["10.0.0.0/8"]}`},
+	}
+
+	for _, c := range cases {
+		var sent map[string]json.RawMessage
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			switch {
+			case r.URL.Path == "/":
+				json.NewEncoder(w).Encode(map[string]string{"version": c.kongVersion})
+			case r.URL.Path == "/plugins/" && r.Method == http.MethodPost:
+				json.NewDecoder(r.Body).Decode(&sent)
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte(`{"id":"plugin-id"}`))
+			case r.URL.Path == "/plugins/plugin-id":
+				w.Write([]byte(`{"id":"plugin-id","name":"ip-restriction","enabled":true,"config":` + string(sent["config"]) + `}`))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+
+		logs.Reset()
+		d := schema.TestResourceDataRaw(t, resourceKongPlugin().Schema, map[string]interface{}{
+			"name":        "ip-restriction",
+			"config_json": c.configJson,
+		})
+
+		err := resourceKongPluginCreate(d, newKongClient(&gokong.Config{HostAddress: server.URL}))
+		server.Close()
+		if err != nil {
+			t.Fatalf("kong %s: could not create plugin: %v", c.kongVersion, err)
+		}
+
+		if string(sent["config"]) != c.expected {
+			t.Errorf("kong %s: expected %s to be sent as %s but was %s", c.kongVersion, c.configJson, c.expected, sent["config"])
+		}
+
+		renamed := c.configJson != c.expected
+		if logged := strings.Contains(logs.String(), "it is sent as"); logged != renamed {
+			t.Errorf("kong %s: expected the rename of %s to be logged: %t, logs: %s", c.kongVersion, c.configJson, renamed, logs.String())
+		}
+
+		if !suppressEquivalentConfigJson("config_json", d.Get("config_json").(string), c.configJson, d) {
+			t.Errorf("kong %s: expected %s to match the config read back from kong %s", c.kongVersion, c.configJson, d.Get("config_json"))
+		}
+	}
+}
+
+func TestSuppressConfigJsonAliases(t *testing.T) {
+
+	d := schema.TestResourceDataRaw(t, resourceKongPlugin().Schema, map[string]interface{}{"name": "acl"})
+
+	cases := []struct {
+		old      string
+		new      string
+		suppress bool
+	}{
+		{`{"allow":["admins"],"hide_groups_header":false}`, `{"whitelist":["admins"],"hide_groups_header":false}`, true},
+		{`{"whitelist":["admins"]}`, `{"allow":["admins"]}`, true},
+		{`{"allow":["admins"]}`, `{"whitelist":["users"]}`, false},
+		{`{"allow":["admins"]}`, `{"blacklist":["admins"]}`, false},
+	}
+
+	for _, c := range cases {
+		if suppress := suppressEquivalentConfigJson("config_json", c.old, c.new, d); suppress != c.suppress {
+			t.Errorf("%s to %s: expected the diff to be suppressed: %t", c.old, c.new, c.suppress)
+		}
+	}
+
+	other := schema.TestResourceDataRaw(t, resourceKongPlugin().Schema, map[string]interface{}{"name": "cors"})
+	if suppressEquivalentConfigJson("config_json", `{"allow":["admins"]}`, `{"whitelist":["admins"]}`, other) {
+		t.Errorf("expected fields of plugins without aliases not to be treated as renamed")
+	}
+}
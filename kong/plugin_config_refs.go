@@ -0,0 +1,82 @@
+package kong
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// pluginConfigRefAttributes are the kong_plugin attributes that map a config path (dot separated for nested objects,
+// e.g. anonymous or config.consumer) to the id of an entity, the ids are put into the config before it is sent so they
+// do not have to be interpolated into config_json.
+var pluginConfigRefAttributes = []string{"config_service_ref", "config_consumer_ref"}
+
+// readPluginConfigRefs returns the entity id of every config path of the ref attributes
+func readPluginConfigRefs(d *schema.ResourceData) (map[string]string, error) {
+	refs := map[string]string{}
+	for _, attribute := range pluginConfigRefAttributes {
+		for path, id := range readMapFromResource(d, attribute) {
+			if _, ok := refs[path]; ok {
+				return nil, fmt.Errorf("config path %s is set by more than one of %v", path, pluginConfigRefAttributes)
+			}
+			refs[path] = id.(string)
+		}
+	}
+	return refs, nil
+}
+
+// injectPluginConfigRefs sets the id of each ref at its path in config, a path the config already has is an error as
+// the two would disagree on what it is.
+func injectPluginConfigRefs(config map[string]interface{}, refs map[string]string) error {
+	paths := make([]string, 0, len(refs))
+	for path := range refs {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		if err := setNestedConfigValue(config, strings.Split(path, "."), refs[path]); err != nil {
+			return fmt.Errorf("could not set config path %s of %v: %v", path, pluginConfigRefAttributes, err)
+		}
+	}
+	return nil
+}
+
+// extractPluginConfigRefs removes the ref paths from config read from kong and returns the ids kong has at them, a
+// path kong has no id at is left out.
+func extractPluginConfigRefs(config map[string]interface{}, paths []string) map[string]string {
+	ids := map[string]string{}
+	for _, path := range paths {
+		keys := strings.Split(path, ".")
+		parent := config
+		for _, key := range keys[:len(keys)-1] {
+			parent, _ = parent[key].(map[string]interface{})
+		}
+
+		if id, ok := parent[keys[len(keys)-1]].(string); ok && id != "" {
+			ids[path] = id
+		}
+		delete(parent, keys[len(keys)-1])
+	}
+	return ids
+}
+
+// setPluginConfigRefs sets each ref attribute from the ids kong has, a ref kong has a different id for (or none) then
+// shows as a change
+func setPluginConfigRefs(d *schema.ResourceData, config map[string]interface{}) {
+	for _, attribute := range pluginConfigRefAttributes {
+		configured := readMapFromResource(d, attribute)
+		if len(configured) == 0 {
+			continue
+		}
+
+		paths := make([]string, 0, len(configured))
+		for path := range configured {
+			paths = append(paths, path)
+		}
+
+		d.Set(attribute, extractPluginConfigRefs(config, paths))
+	}
+}
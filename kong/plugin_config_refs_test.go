@@ -0,0 +1,125 @@
+package kong
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/kevholditch/gokong"
+)
+
+func TestKongPluginConfigConsumerRef(t *testing.T) {
+
+	var sent []map[string]interface{}
+	anonymous := ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != gokong.PluginsPath && r.URL.Path != gokong.PluginsPath+"plugin-id" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if r.Method == http.MethodPost || r.Method == http.MethodPatch {
+			request := map[string]interface{}{}
+			json.NewDecoder(r.Body).Decode(&request)
+			sent = append(sent, request)
+			anonymous, _ = request["config"].(map[string]interface{})["anonymous"].(string)
+			w.WriteHeader(http.StatusCreated)
+		}
+
+		// kong fills in the defaults of the key-auth config
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":      "plugin-id",
+			"name":    "key-auth",
+			"enabled": true,
+			"config": map[string]interface{}{
+				"key_names":        []string{"x-api-key"},
+				"hide_credentials": false,
+				"anonymous":        anonymous,
+			},
+		})
+	}))
+	defer server.Close()
+
+	client := newKongClient(&gokong.Config{HostAddress: server.URL})
+	d := schema.TestResourceDataRaw(t, resourceKongPlugin().Schema, map[string]interface{}{
+		"name":                "key-auth",
+		"config_json":         `{"key_names": ["x-api-key"], "hide_credentials": false}`,
+		"config_consumer_ref": map[string]interface{}{"anonymous": "anonymous-consumer-id"},
+	})
+
+	if err := resourceKongPluginCreate(d, client); err != nil {
+		t.Fatalf("could not create plugin: %v", err)
+	}
+
+	if len(sent) != 1 || anonymous != "anonymous-consumer-id" {
+		t.Fatalf("expected the id of the anonymous consumer to be put into the config sent to kong, sent: %v", sent)
+	}
+
+	if config := d.Get("config_json").(string); strings.Contains(config, "anonymous") {
+		t.Errorf("expected the ref to be kept out of config_json, got: %s", config)
+	}
+	if d.Get("config_consumer_ref.anonymous") != "anonymous-consumer-id" {
+		t.Errorf("expected the ref to be read back from kong, got: %v", d.Get("config_consumer_ref"))
+	}
+
+	// a different consumer set outside of terraform shows as a change to the ref
+	anonymous = "other-consumer-id"
+	if err := resourceKongPluginRead(d, client); err != nil {
+		t.Fatalf("could not read plugin: %v", err)
+	}
+	if d.Get("config_consumer_ref.anonymous") != "other-consumer-id" {
+		t.Errorf("expected the changed anonymous consumer to be read into the ref, got: %v", d.Get("config_consumer_ref"))
+	}
+}
+
+func TestPluginConfigRefsInvalid(t *testing.T) {
+
+	cases := []struct {
+		raw      map[string]interface{}
+		expected string
+	}{
+		{map[string]interface{}{
+			"name":                "key-auth",
+			"config_json":         `{"anonymous": "consumer-id"}`,
+			"config_consumer_ref": map[string]interface{}{"anonymous": "anonymous-consumer-id"},
+		}, "could not set config path anonymous"},
+		{map[string]interface{}{
+			"name":                "custom",
+			"config_service_ref":  map[string]interface{}{"upstream.service": "service-id"},
+			"config_consumer_ref": map[string]interface{}{"upstream.service": "consumer-id"},
+		}, "config path upstream.service is set by more than one"},
+	}
+
+	for _, c := range cases {
+		d := schema.TestResourceDataRaw(t, resourceKongPlugin().Schema, c.raw)
+		if _, err := createKongPluginRequestFromResourceData(d); err == nil || !strings.Contains(err.Error(), c.expected) {
+			t.Errorf("%v: expected the error %q, got: %v", c.raw, c.expected, err)
+		}
+	}
+
+	// nested paths are created in the config
+	d := schema.TestResourceDataRaw(t, resourceKongPlugin().Schema, map[string]interface{}{
+		"name":               "custom",
+		"config_json":        `{"upstream": {"timeout": 10}}`,
+		"config_service_ref": map[string]interface{}{"upstream.service": "service-id"},
+	})
+	request, err := createKongPluginRequestFromResourceData(d)
+	if err != nil {
+		t.Fatalf("could not build plugin request: %v", err)
+	}
+	if config, _ := json.Marshal(request.Config); string(config) != `{"upstream":{"service":"service-id","timeout":10}}` {
+		t.Errorf("expected the service id to be set in the nested config, got: %s", config)
+	}
+
+	config := map[string]interface{}{"upstream": map[string]interface{}{"service": "service-id", "timeout": 10.0}}
+	if ids := extractPluginConfigRefs(config, []string{"upstream.service", "missing.path"}); len(ids) != 1 || ids["upstream.service"] != "service-id" {
+		t.Errorf("expected only the id kong has to be extracted, got: %v", ids)
+	}
+	if upstream := config["upstream"].(map[string]interface{}); len(upstream) != 1 {
+		t.Errorf("expected the ref to be removed from the config, got: %v", config)
+	}
+}
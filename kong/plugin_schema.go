@@ -0,0 +1,450 @@
+package kong
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/kevholditch/gokong"
+)
+
+const pluginSchemasPath = "/schemas/plugins/"
+
+type pluginSchema struct {
+	Fields []map[string]*pluginSchemaField `json:"fields"`
+}
+
+type pluginSchemaField struct {
+	Type     string      `json:"type"`
+	Auto     bool        `json:"auto"`
+	Computed bool        `json:"computed"`
+	ReadOnly bool        `json:"read_only"`
+	Default  interface{} `json:"default"`
+	Required bool        `json:"required"`
+	// Eq is the value the field is limited to, kept raw to tell "eq": null from no eq
+	Eq     json.RawMessage                 `json:"eq"`
+	Fields []map[string]*pluginSchemaField `json:"fields"`
+}
+
+func (field *pluginSchemaField) isComputed() bool {
+	return field.Auto || field.Computed || field.ReadOnly
+}
+
+// computedProperties lists the fields kong sets itself, of the plugin entity and of its config. It returns false when
+// the schema has no config record, e.g. when the response was not a plugin schema.
+func (schema *pluginSchema) computedProperties() ([]string, bool) {
+	computed := []string{}
+	hasConfig := false
+
+	for _, fields := range schema.Fields {
+		for name, field := range fields {
+			if field == nil {
+				continue
+			}
+
+			if name == "config" && field.Type == "record" {
+				hasConfig = true
+				for _, configFields := range field.Fields {
+					for configName, configField := range configFields {
+						if configField != nil && configField.isComputed() {
+							computed = append(computed, configName)
+						}
+					}
+				}
+			} else if field.isComputed() {
+				computed = append(computed, name)
+			}
+		}
+	}
+
+	return computed, hasConfig
+}
+
+// pluginConfigDefaults holds the default of each config field of a plugin schema, the default of a record field is the
+// pluginConfigDefaults of its own fields. A field without a default has a nil default, kong returns null for it.
+type pluginConfigDefaults map[string]interface{}
+
+func recordDefaults(fields []map[string]*pluginSchemaField) pluginConfigDefaults {
+	defaults := pluginConfigDefaults{}
+	for _, recordFields := range fields {
+		for name, field := range recordFields {
+			if field == nil {
+				continue
+			}
+
+			if field.Type == "record" && field.Default == nil {
+				defaults[name] = recordDefaults(field.Fields)
+			} else {
+				defaults[name] = field.Default
+			}
+		}
+	}
+	return defaults
+}
+
+// configDefaults returns the defaults of the config record, nil when the schema has no config record
+func (schema *pluginSchema) configDefaults() pluginConfigDefaults {
+	for _, fields := range schema.Fields {
+		if field, ok := fields["config"]; ok && field != nil && field.Type == "record" {
+			return recordDefaults(field.Fields)
+		}
+	}
+	return nil
+}
+
+// protocolsDefault returns the default protocols of the plugin entity, nil when the schema has none (kong before 1.0)
+func (schema *pluginSchema) protocolsDefault() []string {
+	for _, fields := range schema.Fields {
+		field, ok := fields["protocols"]
+		if !ok || field == nil {
+			continue
+		}
+
+		defaults, _ := field.Default.([]interface{})
+		protocols := []string{}
+		for _, protocol := range defaults {
+			if value, ok := protocol.(string); ok {
+				protocols = append(protocols, value)
+			}
+		}
+		if len(protocols) > 0 {
+			return protocols
+		}
+	}
+	return nil
+}
+
+// pluginSchemaDefaults returns the config defaults and the default protocols of the schema of the plugin once the
+// client has read it (see pluginSchema), both are nil before that and for a nil client. It does not call kong as it is
+// used while diffing, the schema of a plugin is read during refresh so the defaults are known by the time the plan is
+// diffed.
+func (client *kongClient) pluginSchemaDefaults(name string) (pluginConfigDefaults, []string) {
+	if client == nil {
+		return nil, nil
+	}
+
+	client.pluginSchemaLock.Lock()
+	schema := client.pluginSchemaCache[name]
+	client.pluginSchemaLock.Unlock()
+
+	if schema == nil {
+		return nil, nil
+	}
+	return schema.configDefaults(), schema.protocolsDefault()
+}
+
+// withPluginSchemaDefaults gives the config_json and protocols diffs of r the plugin schema defaults read by the client
+// meta returns. A DiffSuppressFunc is not given the provider meta, so each provider configuration (e.g. aliases for
+// different kong nodes) only uses the defaults of its own node.
+func withPluginSchemaDefaults(r *schema.Resource, meta func() interface{}) *schema.Resource {
+	client := func() *kongClient {
+		client, _ := meta().(*kongClient)
+		return client
+	}
+
+	for key, field := range r.Schema {
+		if field.DiffSuppressFunc == nil {
+			continue
+		}
+
+		switch key {
+		case "config_json", "config_json_in_place":
+			field.DiffSuppressFunc = func(k, old, new string, d *schema.ResourceData) bool {
+				return suppressEquivalentPluginConfigJson(client(), k, old, new, d)
+			}
+		case "protocols":
+			field.DiffSuppressFunc = func(k, old, new string, d *schema.ResourceData) bool {
+				return suppressSchemaDefaultPluginProtocols(client(), k, old, new, d)
+			}
+		}
+	}
+
+	return r
+}
+
+// suppressSchemaDefaultPluginProtocols suppresses the diff of protocols that are not configured while the plugin has the
+// default protocols of its schema in kong. It is called for every key of the set and the config can not be read from d
+// once it falls back to the state, so only the count going to 0 and the removed elements are suppressed: a configured
+// set still adds its elements and changes the count. The defaults are those read by client, see
+// withPluginSchemaDefaults.
+func suppressSchemaDefaultPluginProtocols(client *kongClient, k, old, new string, d *schema.ResourceData) bool {
+	if count := strings.HasSuffix(k, ".#"); (count && new != "0") || (!count && new != "") {
+		return false
+	}
+
+	_, defaults := client.pluginSchemaDefaults(configPluginName(d))
+	if defaults == nil {
+		return false
+	}
+
+	upstream, _ := d.GetChange("protocols")
+	protocols := []string{}
+	if set, ok := upstream.(*schema.Set); ok {
+		for _, protocol := range set.List() {
+			protocols = append(protocols, protocol.(string))
+		}
+	}
+
+	added, removed := tagsDiff(protocols, defaults)
+	return len(added) == 0 && len(removed) == 0
+}
+
+// suppressDefaultPluginProtocols is suppressSchemaDefaultPluginProtocols without a client, it suppresses nothing until
+// withPluginSchemaDefaults gives it the client of the provider
+func suppressDefaultPluginProtocols(k, old, new string, d *schema.ResourceData) bool {
+	return suppressSchemaDefaultPluginProtocols(nil, k, old, new, d)
+}
+
+// removeUnsetConfigDefaults removes the keys of upstream that are not in config and still hold their schema default, a
+// record that is in both is handled field by field. Keys that are not in the schema are always kept.
+func removeUnsetConfigDefaults(upstream map[string]interface{}, config map[string]interface{}, defaults pluginConfigDefaults) {
+	for key, value := range upstream {
+		fieldDefault, known := defaults[key]
+		if !known {
+			continue
+		}
+
+		nestedDefaults, isRecord := fieldDefault.(pluginConfigDefaults)
+		nestedUpstream, upstreamIsObject := value.(map[string]interface{})
+
+		if configValue, set := config[key]; set {
+			nestedConfig, configIsObject := configValue.(map[string]interface{})
+			if isRecord && upstreamIsObject && configIsObject {
+				removeUnsetConfigDefaults(nestedUpstream, nestedConfig, nestedDefaults)
+			}
+			continue
+		}
+
+		if isRecord && upstreamIsObject {
+			removeUnsetConfigDefaults(nestedUpstream, nil, nestedDefaults)
+			if len(nestedUpstream) == 0 {
+				delete(upstream, key)
+			}
+		} else if (isRecord && value == nil) || (!isRecord && jsonEqualIgnoringNumericStrings(value, fieldDefault)) {
+			delete(upstream, key)
+		}
+	}
+}
+
+// pluginSchema returns the schema of the plugin, nil without an error when kong has no plugin schema for it (kong
+// versions before 1.0 do not have the schema endpoint). Schemas are read once and kept for the life of the provider,
+// errors are not kept so the schema is tried again next time.
+func (client *kongClient) pluginSchema(name string) (*pluginSchema, error) {
+	client.pluginSchemaLock.Lock()
+	defer client.pluginSchemaLock.Unlock()
+
+	if schema, ok := client.pluginSchemaCache[name]; ok {
+		return schema, nil
+	}
+
+	schema := &pluginSchema{}
+	found, err := client.get(pluginSchemasPath+name, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := schema.computedProperties(); !found || !ok {
+		schema = nil
+	}
+
+	if client.pluginSchemaCache == nil {
+		client.pluginSchemaCache = map[string]*pluginSchema{}
+	}
+	client.pluginSchemaCache[name] = schema
+
+	return schema, nil
+}
+
+// pluginComputedProperties returns the properties to strip from the config read back for the plugin, derived from the
+// plugin's schema. The hardcoded computedPluginProperties are used when the schema can not be read.
+func (client *kongClient) pluginComputedProperties(name string) []string {
+	schema, err := client.pluginSchema(name)
+	if err != nil {
+		log.Printf("[WARN] could not read the schema of kong plugin %s, using the default computed properties: %v", name, err)
+		return computedPluginProperties
+	}
+
+	if schema == nil {
+		return computedPluginProperties
+	}
+
+	computed, _ := schema.computedProperties()
+	return computed
+}
+
+// pluginConfigBooleans marks the boolean fields of a config, a field is true when it is a boolean and the
+// pluginConfigBooleans of its own fields when it is a record
+type pluginConfigBooleans map[string]interface{}
+
+func recordBooleans(fields []map[string]*pluginSchemaField) pluginConfigBooleans {
+	booleans := pluginConfigBooleans{}
+	for _, recordFields := range fields {
+		for name, field := range recordFields {
+			if field == nil {
+				continue
+			}
+
+			if field.Type == "boolean" {
+				booleans[name] = true
+			} else if field.Type == "record" {
+				booleans[name] = recordBooleans(field.Fields)
+			}
+		}
+	}
+	return booleans
+}
+
+// configBooleans returns the boolean fields of the config record, nil when the schema has no config record
+func (schema *pluginSchema) configBooleans() pluginConfigBooleans {
+	for _, fields := range schema.Fields {
+		if field, ok := fields["config"]; ok && field != nil && field.Type == "record" {
+			return recordBooleans(field.Fields)
+		}
+	}
+	return nil
+}
+
+// configuredBooleans marks the fields config sets to a boolean, used in place of the schema when it can not be read
+func configuredBooleans(config map[string]interface{}) pluginConfigBooleans {
+	booleans := pluginConfigBooleans{}
+	for name, value := range config {
+		switch value := value.(type) {
+		case bool:
+			booleans[name] = true
+		case map[string]interface{}:
+			booleans[name] = configuredBooleans(value)
+		}
+	}
+	return booleans
+}
+
+// coerceConfigBooleans replaces the 1 and 0 of boolean fields with true and false, some kong and database combinations
+// return booleans as integers which would otherwise be a change from the true and false of the config. Other values
+// are left as they are.
+func coerceConfigBooleans(config map[string]interface{}, booleans pluginConfigBooleans) {
+	for name, value := range config {
+		switch field := booleans[name].(type) {
+		case bool:
+			if number, ok := value.(float64); ok && (number == 0 || number == 1) {
+				config[name] = number == 1
+			}
+		case pluginConfigBooleans:
+			if record, ok := value.(map[string]interface{}); ok {
+				coerceConfigBooleans(record, field)
+			}
+		}
+	}
+}
+
+// coercePluginConfigBooleans coerces the booleans kong returned as integers in the config of the plugin read back,
+// the boolean fields are those of the plugin's schema. When the schema can not be read they are the fields configured
+// sets to a boolean, configured is nil when nothing is configured.
+func (client *kongClient) coercePluginConfigBooleans(name string, config map[string]interface{}, configured map[string]interface{}) {
+	schema, err := client.pluginSchema(name)
+	if err != nil {
+		log.Printf("[WARN] could not read the schema of kong plugin %s, using the configured booleans: %v", name, err)
+	}
+
+	if err == nil && schema != nil {
+		coerceConfigBooleans(config, schema.configBooleans())
+	} else {
+		coerceConfigBooleans(config, configuredBooleans(configured))
+	}
+}
+
+// pluginScopes are the entities a plugin can be scoped to, by the name of their field in the plugin schema
+var pluginScopes = []string{"consumer", "consumer_group", "service", "route"}
+
+// scopeError checks the scopes a plugin is given against its schema, a scope field with "eq": null (no_consumer and
+// the like) can not be set and a required one has to be.
+func (schema *pluginSchema) scopeError(name string, scoped map[string]bool) error {
+	for _, fields := range schema.Fields {
+		for scope, field := range fields {
+			if field == nil || !contains(pluginScopes, scope) {
+				continue
+			}
+
+			if scoped[scope] && string(field.Eq) == "null" {
+				return fmt.Errorf("kong plugin %s can not be scoped to a %s", name, strings.Replace(scope, "_", " ", -1))
+			}
+
+			if !scoped[scope] && field.Required {
+				return fmt.Errorf("kong plugin %s has to be scoped to a %s", name, strings.Replace(scope, "_", " ", -1))
+			}
+		}
+	}
+	return nil
+}
+
+// validatePluginScopeSchema rejects scoping a plugin in a way its schema does not allow before the plugin is sent to
+// kong, kong is left to validate the scope when the schema can not be read.
+func validatePluginScopeSchema(client *kongClient, pluginRequest *gokong.PluginRequest, consumerGroupId string) error {
+	schema, err := client.pluginSchema(pluginRequest.Name)
+	if err != nil || schema == nil {
+		return nil
+	}
+
+	return schema.scopeError(pluginRequest.Name, map[string]bool{
+		"consumer":       pluginRequest.ConsumerId != "",
+		"consumer_group": consumerGroupId != "",
+		"service":        pluginRequest.ServiceId != "",
+		"route":          pluginRequest.RouteId != "",
+	})
+}
+
+// missingRequiredFields returns the fields of the record that are required, have no default and are not set in config,
+// a nested field by its path e.g. storage_config.host. The fields of a record are only checked when the record is set
+// or is itself required, kong does not check the fields of a record that is left null.
+func missingRequiredFields(fields []map[string]*pluginSchemaField, config map[string]interface{}, prefix string) []string {
+	missing := []string{}
+	for _, recordFields := range fields {
+		for name, field := range recordFields {
+			if field == nil || field.isComputed() {
+				continue
+			}
+
+			value, set := config[name]
+			if field.Type == "record" {
+				nested, isObject := value.(map[string]interface{})
+				if isObject || (field.Required && value == nil) {
+					missing = append(missing, missingRequiredFields(field.Fields, nested, prefix+name+".")...)
+				}
+				continue
+			}
+
+			if field.Required && field.Default == nil && (!set || value == nil) {
+				missing = append(missing, prefix+name)
+			}
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// missingConfigFields returns the required config fields without a default that config does not set
+func (schema *pluginSchema) missingConfigFields(config map[string]interface{}) []string {
+	for _, fields := range schema.Fields {
+		if field, ok := fields["config"]; ok && field != nil && field.Type == "record" {
+			return missingRequiredFields(field.Fields, config, "")
+		}
+	}
+	return nil
+}
+
+// validatePluginConfigSchema rejects a config that leaves out a required field kong has no default for before the
+// plugin is sent, kong's own error does not always name the field. Kong is left to validate the config when the schema
+// can not be read.
+func validatePluginConfigSchema(client *kongClient, name string, config map[string]interface{}) error {
+	schema, err := client.pluginSchema(name)
+	if err != nil || schema == nil {
+		return nil
+	}
+
+	if missing := schema.missingConfigFields(config); len(missing) > 0 {
+		return fmt.Errorf("the config of kong plugin %s is missing the required %s, kong has no default for them", name, strings.Join(missing, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,425 @@
+package kong
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/kevholditch/gokong"
+)
+
+const testPluginSchema = `{"fields":[
+	{"id":{"type":"string","uuid":true,"auto":true}},
+	{"name":{"type":"string","required":true}},
+	{"created_at":{"type":"integer","timestamp":true,"auto":true}},
+	{"config":{"type":"record","fields":[
+		{"minute":{"type":"number"}},
+		{"issued_secret":{"type":"string","auto":true}},
+		{"last_rotated_at":{"type":"number","read_only":true}},
+		{"storage_config":{"type":"record","fields":[{"id":{"type":"string"}}]}}
+	]}}
+]}`
+
+func TestPluginComputedProperties(t *testing.T) {
+
+	schemaRequests := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/schemas/plugins/rate-limiting":
+			schemaRequests["rate-limiting"]++
+			w.Write([]byte(testPluginSchema))
+		case "/schemas/plugins/key-auth":
+			schemaRequests["key-auth"]++
+			w.WriteHeader(http.StatusNotFound)
+		case "/plugins/plugin-id":
+			w.Write([]byte(`{"id":"plugin-id","name":"rate-limiting","enabled":true,"config":{"minute":10,"issued_secret":"abc","last_rotated_at":1700000000,"storage_config":{"id":"kept"}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := newKongClient(&gokong.Config{HostAddress: server.URL})
+
+	computed := client.pluginComputedProperties("rate-limiting")
+	sort.Strings(computed)
+	if expected := "created_at,id,issued_secret,last_rotated_at"; strings.Join(computed, ",") != expected {
+		t.Errorf("expected the computed properties from the schema to be %s but were %v", expected, computed)
+	}
+
+	if fallback := client.pluginComputedProperties("key-auth"); strings.Join(fallback, ",") != strings.Join(computedPluginProperties, ",") {
+		t.Errorf("expected the default computed properties without a schema but were %v", fallback)
+	}
+
+	d := resourceKongPlugin().Data(&terraform.InstanceState{
+		ID:         "plugin-id",
+		Attributes: map[string]string{"id": "plugin-id", "name": "rate-limiting"},
+	})
+	if err := resourceKongPluginRead(d, client); err != nil {
+		t.Fatalf("could not read plugin: %v", err)
+	}
+
+	if expected := `{"minute":10,"storage_config":{"id":"kept"}}`; d.Get("config_json") != expected {
+		t.Errorf("expected config_json %s without the fields the schema marks computed but was %s", expected, d.Get("config_json"))
+	}
+
+	client.pluginComputedProperties("key-auth")
+	if schemaRequests["rate-limiting"] != 1 || schemaRequests["key-auth"] != 1 {
+		t.Errorf("expected the schema of each plugin to be read once but it was read %v times", schemaRequests)
+	}
+}
+
+func TestKongPluginReadCoercesIntegerBooleans(t *testing.T) {
+
+	// kong returns the booleans of the config as 1 and 0, minute is a number that happens to be 1
+	body := `{"id":"%s","name":"%s","enabled":true,"config":{"minute":1,"hide_client_headers":1,"fault_tolerant":0,"redis":{"ssl":1,"port":6379}}}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/schemas/plugins/rate-limiting":
+			w.Write([]byte(`{"fields":[{"config":{"type":"record","fields":[
+				{"minute":{"type":"number"}},
+				{"hide_client_headers":{"type":"boolean","default":false}},
+				{"fault_tolerant":{"type":"boolean","default":true}},
+				{"redis":{"type":"record","fields":[{"ssl":{"type":"boolean"}},{"port":{"type":"integer"}}]}}
+			]}}]}`))
+		case "/plugins/plugin-id":
+			w.Write([]byte(fmt.Sprintf(body, "plugin-id", "rate-limiting")))
+		case "/plugins/custom-plugin-id":
+			w.Write([]byte(fmt.Sprintf(body, "custom-plugin-id", "custom-rate-limiting")))
+		default:
+			// the schema of custom-rate-limiting can not be read
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	client := newKongClient(&gokong.Config{HostAddress: server.URL})
+
+	configJson := `{"fault_tolerant":false,"hide_client_headers":true,"minute":1,"redis":{"port":6379,"ssl":true}}`
+	for _, c := range []struct {
+		id       string
+		name     string
+		expected string
+	}{
+		// the schema marks the booleans
+		{"plugin-id", "rate-limiting", configJson},
+		// without the schema the configured booleans are coerced, the number stays a number
+		{"custom-plugin-id", "custom-rate-limiting", configJson},
+	} {
+		d := resourceKongPlugin().Data(&terraform.InstanceState{
+			ID:         c.id,
+			Attributes: map[string]string{"id": c.id, "name": c.name, "config_json": configJson},
+		})
+		if err := resourceKongPluginRead(d, client); err != nil {
+			t.Fatalf("%s: could not read plugin: %v", c.name, err)
+		}
+
+		if d.Get("config_json") != c.expected {
+			t.Errorf("%s: expected the integer booleans to be read as booleans %s but was %s", c.name, c.expected, d.Get("config_json"))
+		}
+	}
+
+	// the typed plugins read them as booleans too
+	if !configBool(1.0) || configBool(0.0) || configBool(2.0) {
+		t.Errorf("expected 1 and 0 to be read as true and false")
+	}
+}
+
+const testRateLimitingSchema = `{"fields":[
+	{"id":{"type":"string","uuid":true,"auto":true}},
+	{"config":{"type":"record","fields":[
+		{"second":{"type":"number"}},
+		{"minute":{"type":"number"}},
+		{"policy":{"type":"string","default":"local"}},
+		{"fault_tolerant":{"type":"boolean","default":true}},
+		{"limit_by":{"type":"string","default":"consumer"}},
+		{"redis":{"type":"record","fields":[
+			{"host":{"type":"string"}},
+			{"port":{"type":"integer","default":6379}}
+		]}}
+	]}}
+]}`
+
+func TestSuppressConfigJsonSchemaDefaults(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(testRateLimitingSchema))
+	}))
+	defer server.Close()
+
+	client := newKongClient(&gokong.Config{HostAddress: server.URL})
+	client.pluginComputedProperties("rate-limiting")
+
+	d := schema.TestResourceDataRaw(t, resourceKongPlugin().Schema, map[string]interface{}{
+		"name":        "rate-limiting",
+		"config_json": `{"minute":10}`,
+	})
+
+	upstream := `{"second":null,"minute":10,"policy":"local","fault_tolerant":true,"limit_by":"consumer","redis":{"host":null,"port":6379}}`
+
+	cases := []struct {
+		old      string
+		new      string
+		suppress bool
+	}{
+		{upstream, `{"minute":10}`, true},
+		{upstream, `{"minute":"10","policy":"local"}`, true},
+		{upstream, `{"minute":10,"redis":{"port":6379}}`, true},
+		{upstream, `{"minute":20}`, false},
+		{strings.Replace(upstream, `"local"`, `"cluster"`, 1), `{"minute":10}`, false},
+		{strings.Replace(upstream, `"host":null`, `"host":"redis"`, 1), `{"minute":10}`, false},
+		{strings.Replace(upstream, `"second":null`, `"second":1`, 1), `{"minute":10}`, false},
+		{strings.Replace(upstream, `"minute":10`, `"minute":10,"unknown":null`, 1), `{"minute":10}`, false},
+	}
+
+	for _, c := range cases {
+		if suppress := suppressEquivalentPluginConfigJson(client, "config_json", c.old, c.new, d); suppress != c.suppress {
+			t.Errorf("expected suppressing %s against %s to be %t", c.old, c.new, c.suppress)
+		}
+	}
+
+	// the defaults belong to the client that read the schema, another provider configuration does not use them
+	other := newKongClient(&gokong.Config{HostAddress: server.URL})
+	for c, suppress := range map[*kongClient]bool{client: true, other: false} {
+		c := c
+		r := withPluginSchemaDefaults(resourceKongPlugin(), func() interface{} { return c })
+		if r.Schema["config_json"].DiffSuppressFunc("config_json", upstream, `{"minute":10}`, d) != suppress {
+			t.Errorf("expected the config_json diff to use the defaults of its own client only, suppressed: %t", !suppress)
+		}
+	}
+
+	keyAuth := schema.TestResourceDataRaw(t, resourceKongPlugin().Schema, map[string]interface{}{
+		"name":        "key-auth",
+		"config_json": `{"minute":10}`,
+	})
+	if suppressEquivalentPluginConfigJson(client, "config_json", upstream, `{"minute":10}`, keyAuth) {
+		t.Errorf("expected the rate-limiting defaults not to be used for another plugin")
+	}
+}
+
+const testNoConsumerPluginSchema = `{"fields":[
+	{"consumer":{"type":"foreign","reference":"consumers","eq":null}},
+	{"service":{"type":"foreign","reference":"services"}},
+	{"config":{"type":"record","fields":[{"allowed_payload_size":{"type":"integer","default":128}}]}}
+]}`
+
+func TestKongPluginScopeFromSchema(t *testing.T) {
+
+	posts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/schemas/plugins/request-size-limiting":
+			w.Write([]byte(testNoConsumerPluginSchema))
+		case r.URL.Path == "/plugins/" && r.Method == http.MethodPost:
+			posts++
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id":"plugin-id"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := newKongClient(&gokong.Config{HostAddress: server.URL})
+
+	d := schema.TestResourceDataRaw(t, resourceKongPlugin().Schema, map[string]interface{}{
+		"name":        "request-size-limiting",
+		"consumer_id": "consumer-id",
+		"config_json": `{"allowed_payload_size":64}`,
+	})
+
+	err := resourceKongPluginCreate(d, client)
+	if err == nil || !strings.Contains(err.Error(), "kong plugin request-size-limiting can not be scoped to a consumer") {
+		t.Errorf("expected consumer scoping to be rejected by the plugin schema, got: %v", err)
+	}
+
+	if posts != 0 {
+		t.Errorf("expected the plugin not to be sent to kong, it was sent %d times", posts)
+	}
+
+	d = schema.TestResourceDataRaw(t, resourceKongPlugin().Schema, map[string]interface{}{
+		"name":        "request-size-limiting",
+		"service_id":  "service-id",
+		"config_json": `{"allowed_payload_size":64}`,
+	})
+
+	if err := resourceKongPluginCreate(d, client); err != nil {
+		t.Errorf("expected a service scoped plugin to be created: %v", err)
+	}
+
+	// without a schema kong validates the scope
+	d = schema.TestResourceDataRaw(t, resourceKongPlugin().Schema, map[string]interface{}{
+		"name":        "key-auth",
+		"consumer_id": "consumer-id",
+	})
+
+	if err := resourceKongPluginCreate(d, client); err != nil {
+		t.Errorf("expected a plugin without a schema to be sent to kong: %v", err)
+	}
+
+	if posts != 2 {
+		t.Errorf("expected two plugins to be sent to kong but %d were", posts)
+	}
+}
+
+const testRequiredFieldsPluginSchema = `{"fields":[
+	{"id":{"type":"string","uuid":true,"auto":true}},
+	{"config":{"type":"record","required":true,"fields":[
+		{"http_endpoint":{"type":"string","required":true}},
+		{"method":{"type":"string","required":true,"default":"POST"}},
+		{"issued_secret":{"type":"string","required":true,"auto":true}},
+		{"timeout":{"type":"number"}},
+		{"queue":{"type":"record","required":true,"fields":[{"name":{"type":"string","required":true}},{"max_batch_size":{"type":"integer","required":true,"default":1}}]}},
+		{"redis":{"type":"record","fields":[{"host":{"type":"string","required":true}}]}}
+	]}}
+]}`
+
+func TestKongPluginConfigMissingRequiredFields(t *testing.T) {
+
+	posts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/schemas/plugins/http-log":
+			w.Write([]byte(testRequiredFieldsPluginSchema))
+		case r.URL.Path == "/plugins/" && r.Method == http.MethodPost:
+			posts++
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id":"plugin-id"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := newKongClient(&gokong.Config{HostAddress: server.URL})
+
+	d := schema.TestResourceDataRaw(t, resourceKongPlugin().Schema, map[string]interface{}{
+		"name":        "http-log",
+		"config_json": `{"timeout":1000,"queue":{"max_batch_size":10}}`,
+	})
+	err := resourceKongPluginCreate(d, client)
+	if err == nil || !strings.Contains(err.Error(), "the config of kong plugin http-log is missing the required http_endpoint, queue.name") {
+		t.Errorf("expected the required fields without a default to be named, got: %v", err)
+	}
+	if posts != 0 {
+		t.Errorf("expected the plugin not to be sent to kong, it was sent %d times", posts)
+	}
+
+	// a required field set to null is missing too, the fields of a record that is not required are checked once it is set
+	pluginSchema, _ := client.pluginSchema("http-log")
+	cases := []struct {
+		config  map[string]interface{}
+		missing string
+	}{
+		{map[string]interface{}{"http_endpoint": nil, "queue": map[string]interface{}{"name": "logs"}}, "http_endpoint"},
+		{map[string]interface{}{"http_endpoint": "http://logs", "queue": map[string]interface{}{"name": "logs"}, "redis": map[string]interface{}{}}, "redis.host"},
+		{map[string]interface{}{"http_endpoint": "http://logs", "queue": map[string]interface{}{"name": "logs"}, "redis": nil}, ""},
+	}
+	for _, c := range cases {
+		if missing := strings.Join(pluginSchema.missingConfigFields(c.config), ","); missing != c.missing {
+			t.Errorf("%v: expected the missing fields %q, got: %q", c.config, c.missing, missing)
+		}
+	}
+
+	d = schema.TestResourceDataRaw(t, resourceKongPlugin().Schema, map[string]interface{}{
+		"name":        "http-log",
+		"config_json": `{"http_endpoint":"http://logs","queue":{"name":"logs"}}`,
+	})
+	if err := resourceKongPluginCreate(d, client); err != nil {
+		t.Errorf("expected a config with the required fields to be created: %v", err)
+	}
+	if posts != 1 {
+		t.Errorf("expected the plugin to be sent to kong once, it was sent %d times", posts)
+	}
+}
+
+func TestKongPluginRenameMissingRequiredFields(t *testing.T) {
+
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/schemas/plugins/http-log":
+			w.Write([]byte(testRequiredFieldsPluginSchema))
+		case r.Method != http.MethodGet:
+			requests = append(requests, r.Method+" "+r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	state := &terraform.InstanceState{
+		ID: "old-id",
+		Attributes: map[string]string{"id": "old-id", "name": "file-log", "enabled": "true", "fail_on_missing": "false",
+			"create_before_rename": "false", "config_merge_strategy": "replace", "config_json": `{"queue":{"name":"logs"}}`},
+	}
+
+	// the replacing plugin is checked before the plugin it replaces is deleted
+	_, err := resourceKongPlugin().Apply(state, &terraform.InstanceDiff{Attributes: map[string]*terraform.ResourceAttrDiff{
+		"name": &terraform.ResourceAttrDiff{Old: "file-log", New: "http-log"},
+	}}, newKongClient(&gokong.Config{HostAddress: server.URL}))
+	if err == nil || !strings.Contains(err.Error(), "missing the required http_endpoint") {
+		t.Errorf("expected the rename to be rejected for the missing http_endpoint, got: %v", err)
+	}
+	if len(requests) != 0 {
+		t.Errorf("expected nothing to be changed in kong, kong was sent: %v", requests)
+	}
+}
+
+func TestKongPluginUpdateRequiredFieldInSensitiveConfig(t *testing.T) {
+
+	var patched map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/schemas/plugins/http-log":
+			w.Write([]byte(testRequiredFieldsPluginSchema))
+		case r.URL.Path == "/plugins/plugin-id" && r.Method == http.MethodPatch:
+			patched = map[string]interface{}{}
+			json.NewDecoder(r.Body).Decode(&patched)
+			w.Write([]byte(`{"id":"plugin-id"}`))
+		case r.URL.Path == "/plugins/plugin-id":
+			w.Write([]byte(`{"id":"plugin-id","name":"http-log","enabled":true,"config":{"http_endpoint":"http://logs","queue":{"name":"other"}}}`))
+		case r.URL.Path == "/":
+			w.Write([]byte(`{"version":"3.4.2"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	sensitiveConfig := fmt.Sprintf(`{"http_endpoint":"%s"}`, hashSensitiveValue("http://logs"))
+	state := &terraform.InstanceState{
+		ID: "plugin-id",
+		Attributes: map[string]string{"id": "plugin-id", "name": "http-log", "enabled": "true", "fail_on_missing": "false",
+			"create_before_rename": "false", "config_merge_strategy": "replace", "config_json": `{"queue":{"name":"logs"}}`,
+			"sensitive_config_json": sensitiveConfig},
+	}
+
+	// the unchanged http_endpoint is not sent, kong keeps the value it has
+	_, err := resourceKongPlugin().Apply(state, &terraform.InstanceDiff{Attributes: map[string]*terraform.ResourceAttrDiff{
+		"config_json": &terraform.ResourceAttrDiff{Old: `{"queue":{"name":"logs"}}`, New: `{"queue":{"name":"other"}}`},
+	}}, newKongClient(&gokong.Config{HostAddress: server.URL}))
+	if err != nil {
+		t.Fatalf("expected the update to keep the required http_endpoint of sensitive_config_json, got: %v", err)
+	}
+	if patched == nil {
+		t.Fatalf("expected the plugin to be patched")
+	}
+	if config, _ := patched["config"].(map[string]interface{}); config == nil || config["http_endpoint"] != nil {
+		t.Errorf("expected the hashed http_endpoint to be left out of the patch, got: %v", patched)
+	}
+}
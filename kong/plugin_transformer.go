@@ -0,0 +1,132 @@
+package kong
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// transformerActions are the blocks of the request-transformer and response-transformer plugins, kong applies them in
+// this order. remove lists names, rename lists old:new and the others list name:value.
+var transformerActions = []string{"remove", "rename", "replace", "add", "append"}
+
+// headerNamePattern matches the characters http allows in a header name
+var headerNamePattern = regexp.MustCompile("^[!#$%&'*+.^_`|~0-9A-Za-z-]+$")
+
+// queryNameSeparators cannot be in the name of a querystring argument
+const queryNameSeparators = "&=?# "
+
+// transformerPluginSchema has a block for every action with a list of each of fields (e.g. headers and json), the lists
+// keep the order kong applies them in
+func transformerPluginSchema(fields []string) map[string]*schema.Schema {
+	actions := map[string]*schema.Schema{}
+	for _, action := range transformerActions {
+		attributes := map[string]*schema.Schema{}
+		for _, field := range fields {
+			attributes[field] = &schema.Schema{
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validateTransformerEntry(action, field),
+				},
+			}
+		}
+		actions[action] = &schema.Schema{
+			Type:     schema.TypeList,
+			Optional: true,
+			MaxItems: 1,
+			Elem:     &schema.Resource{Schema: attributes},
+		}
+	}
+	return actions
+}
+
+// expandTransformerPluginConfig sends every list of every action, a list that is not set is sent as null so kong resets
+// it (kong merges the config of an update, a removed entry would otherwise still be applied)
+func expandTransformerPluginConfig(d *schema.ResourceData, fields []string) map[string]interface{} {
+	config := map[string]interface{}{}
+	for _, action := range transformerActions {
+		block := transformerActionBlock(d, action)
+		lists := map[string]interface{}{}
+		for _, field := range fields {
+			lists[field] = nil
+			if entries := configStrings(block[field]); len(entries) > 0 {
+				lists[field] = entries
+			}
+		}
+		config[action] = lists
+	}
+	return config
+}
+
+// flattenTransformerPluginConfig reads the lists of every action in the order kong has them. kong returns every action
+// with empty lists, an action is only in state when it has entries or its block was configured.
+func flattenTransformerPluginConfig(d *schema.ResourceData, config map[string]interface{}, fields []string) {
+	for _, action := range transformerActions {
+		kongAction, _ := config[action].(map[string]interface{})
+		lists := map[string]interface{}{}
+		hasEntries := false
+		for _, field := range fields {
+			entries := configStrings(kongAction[field])
+			hasEntries = hasEntries || len(entries) > 0
+			lists[field] = entries
+		}
+
+		if !hasEntries && transformerActionBlock(d, action) == nil {
+			d.Set(action, nil)
+			continue
+		}
+		d.Set(action, []map[string]interface{}{lists})
+	}
+}
+
+// transformerActionBlock returns the lists of the action's block, nil when it is not set
+func transformerActionBlock(d *schema.ResourceData, action string) map[string]interface{} {
+	blocks, _ := d.Get(action).([]interface{})
+	if len(blocks) == 0 {
+		return nil
+	}
+	block, _ := blocks[0].(map[string]interface{})
+	if block == nil {
+		// a block without lists, e.g. add {}
+		return map[string]interface{}{}
+	}
+	return block
+}
+
+// validateTransformerEntry checks the format of an entry of the action's list of field, a header has to be a valid
+// header name and a querystring name cannot have the characters that separate the query
+func validateTransformerEntry(action string, field string) schema.SchemaValidateFunc {
+	return func(v interface{}, k string) ([]string, []error) {
+		value := v.(string)
+		name, rest := value, ""
+		if action != "remove" {
+			separator := strings.Index(value, ":")
+			if separator < 0 {
+				format := "name:value"
+				if action == "rename" {
+					format = "old:new"
+				}
+				return nil, []error{fmt.Errorf("%s must be %s, got: %s", k, format, value)}
+			}
+			name, rest = value[:separator], value[separator+1:]
+		}
+
+		if name == "" {
+			return nil, []error{fmt.Errorf("%s must have a name, got: %s", k, value)}
+		}
+		if action == "rename" && rest == "" {
+			return nil, []error{fmt.Errorf("%s must have the new name, got: %s", k, value)}
+		}
+		if field == "headers" && (!headerNamePattern.MatchString(name) || (action == "rename" && !headerNamePattern.MatchString(rest))) {
+			return nil, []error{fmt.Errorf("%s must be a valid header name, got: %s", k, value)}
+		}
+		if field == "querystring" && (strings.ContainsAny(name, queryNameSeparators) || (action == "rename" && strings.ContainsAny(rest, queryNameSeparators))) {
+			return nil, []error{fmt.Errorf("%s must be a querystring name without any of %q, got: %s", k, queryNameSeparators, value)}
+		}
+		return nil, nil
+	}
+}
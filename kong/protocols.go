@@ -0,0 +1,91 @@
+package kong
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+)
+
+// protocolMinimumKongVersions is the first kong version that accepts each protocol on services and routes
+var protocolMinimumKongVersions = map[string]string{
+	"http":            "0.13.0",
+	"https":           "0.13.0",
+	"tcp":             "1.0.0",
+	"tls":             "1.0.0",
+	"grpc":            "1.3.0",
+	"grpcs":           "1.3.0",
+	"udp":             "2.0.0",
+	"tls_passthrough": "2.2.0",
+	"ws":              "3.0.0",
+	"wss":             "3.0.0",
+}
+
+// validateProtocols checks every protocol is one kong knows about and that the kong being configured supports it, the
+// kong version is only looked up when a protocol needs a newer kong than this provider supports.
+func validateProtocols(client *kongClient, protocols []string) error {
+	if err := validateProtocolNames(protocols); err != nil {
+		return err
+	}
+
+	needsVersion := false
+	for _, protocol := range protocols {
+		needsVersion = needsVersion || protocolMinimumKongVersions[protocol] != protocolMinimumKongVersions["http"]
+	}
+
+	if !needsVersion {
+		return nil
+	}
+
+	kongVersion, err := client.version()
+	if err != nil {
+		return fmt.Errorf("could not check kong version for protocols %v: %v", protocols, err)
+	}
+
+	return validateProtocolsForVersion(protocols, kongVersion)
+}
+
+func validateProtocolNames(protocols []string) error {
+	var unknown []string
+	for _, protocol := range protocols {
+		if _, ok := protocolMinimumKongVersions[protocol]; !ok {
+			unknown = append(unknown, protocol)
+		}
+	}
+
+	if len(unknown) > 0 {
+		return fmt.Errorf("unknown protocols %v, protocols must be one of: %s", unknown, strings.Join(knownProtocols(), ", "))
+	}
+
+	return nil
+}
+
+func validateProtocolsForVersion(protocols []string, kongVersion *version.Version) error {
+	if err := validateProtocolNames(protocols); err != nil {
+		return err
+	}
+
+	var unsupported []string
+	for _, protocol := range protocols {
+		minimumVersion := protocolMinimumKongVersions[protocol]
+		if kongVersion.LessThan(version.Must(version.NewVersion(minimumVersion))) {
+			unsupported = append(unsupported, fmt.Sprintf("%s (requires kong %s)", protocol, minimumVersion))
+		}
+	}
+
+	if len(unsupported) > 0 {
+		return fmt.Errorf("protocols not supported by kong %s: %s", kongVersion, strings.Join(unsupported, ", "))
+	}
+
+	return nil
+}
+
+func knownProtocols() []string {
+	protocols := make([]string, 0, len(protocolMinimumKongVersions))
+	for protocol := range protocolMinimumKongVersions {
+		protocols = append(protocols, protocol)
+	}
+	sort.Strings(protocols)
+	return protocols
+}
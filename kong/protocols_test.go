@@ -0,0 +1,85 @@
+package kong
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	"github.com/kevholditch/gokong"
+)
+
+func TestValidateProtocolsForVersion(t *testing.T) {
+
+	cases := []struct {
+		kongVersion string
+		protocols   []string
+		unsupported []string
+	}{
+		{"0.13.0", []string{"http", "https"}, nil},
+		{"0.13.0", []string{"http", "tcp"}, []string{"tcp"}},
+		{"1.0.0", []string{"tcp", "tls"}, nil},
+		{"1.2.0", []string{"grpc", "http"}, []string{"grpc"}},
+		{"1.3.0", []string{"grpc", "grpcs"}, nil},
+		{"1.5.0", []string{"udp", "tls_passthrough"}, []string{"udp", "tls_passthrough"}},
+		{"2.2.0", []string{"udp", "tls_passthrough"}, nil},
+		{"2.8.1", []string{"ws", "wss", "https"}, []string{"ws", "wss"}},
+		{"3.0.0", []string{"ws", "wss", "udp"}, nil},
+	}
+
+	for _, c := range cases {
+		err := validateProtocolsForVersion(c.protocols, version.Must(version.NewVersion(c.kongVersion)))
+
+		if len(c.unsupported) == 0 {
+			if err != nil {
+				t.Errorf("expected %v to be supported by kong %s, got: %v", c.protocols, c.kongVersion, err)
+			}
+			continue
+		}
+
+		if err == nil {
+			t.Errorf("expected %v to be rejected on kong %s", c.protocols, c.kongVersion)
+			continue
+		}
+
+		for _, protocol := range c.unsupported {
+			if !strings.Contains(err.Error(), protocol+" (requires kong") {
+				t.Errorf("expected the error to name %s as unsupported on kong %s: %v", protocol, c.kongVersion, err)
+			}
+		}
+	}
+}
+
+func TestValidateProtocols(t *testing.T) {
+
+	versionCalls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		versionCalls++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"version":"1.3.0"}`))
+	}))
+	defer server.Close()
+
+	client := newKongClient(&gokong.Config{HostAddress: server.URL})
+
+	if err := validateProtocols(client, []string{"http", "https"}); err != nil {
+		t.Errorf("expected http and https to be valid, got: %v", err)
+	}
+
+	if versionCalls != 0 {
+		t.Errorf("expected the kong version not to be looked up for protocols every version supports")
+	}
+
+	if err := validateProtocols(client, []string{"grpcs"}); err != nil {
+		t.Errorf("expected grpcs to be valid on kong 1.3.0, got: %v", err)
+	}
+
+	if err := validateProtocols(client, []string{"wss"}); err == nil || !strings.Contains(err.Error(), "wss (requires kong 3.0.0)") {
+		t.Errorf("expected wss to be rejected on kong 1.3.0, got: %v", err)
+	}
+
+	if err := validateProtocols(client, []string{"htp"}); err == nil || !strings.Contains(err.Error(), "unknown protocols [htp]") {
+		t.Errorf("expected an unknown protocol to be rejected, got: %v", err)
+	}
+}
@@ -1,7 +1,10 @@
 package kong
 
 import (
+	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/terraform"
@@ -9,7 +12,7 @@ import (
 )
 
 func Provider() terraform.ResourceProvider {
-	return &schema.Provider{
+	provider := &schema.Provider{
 		Schema: map[string]*schema.Schema{
 			"kong_admin_uri": &schema.Schema{
 				Type:        schema.TypeString,
@@ -17,6 +20,12 @@ func Provider() terraform.ResourceProvider {
 				DefaultFunc: envDefaultFuncWithDefault("KONG_ADMIN_ADDR", "http://localhost:8001"),
 				Description: "The address of the kong admin url e.g. http://localhost:8001",
 			},
+			"admin_urls": &schema.Schema{
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The addresses of several nodes of the kong admin api, used instead of kong_admin_uri. Requests go to the first one and fail over to the next when it can not be connected to",
+			},
 			"kong_admin_username": &schema.Schema{
 				Type:        schema.TypeString,
 				Optional:    true,
@@ -47,29 +56,214 @@ func Provider() terraform.ResourceProvider {
 				DefaultFunc: envDefaultFuncWithDefault("KONG_ADMIN_TOKEN", ""),
 				Description: "API key for the kong api (Enterprise Edition)",
 			},
+			"dbless": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: envDefaultFuncWithDefault("KONG_DBLESS", "false"),
+				Description: "Whether kong is running without a database, only kong_declarative_config can be used in this mode",
+			},
+			"user_agent_suffix": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: envDefaultFuncWithDefault("KONG_USER_AGENT_SUFFIX", ""),
+				Description: "Appended to the terraform-provider-kong/<version> User-Agent sent with every kong admin api request",
+			},
+			"configure_retry_seconds": &schema.Schema{
+				Type:        schema.TypeInt,
+				Optional:    true,
+				DefaultFunc: envDefaultFuncWithDefault("KONG_CONFIGURE_RETRY_SECONDS", "0"),
+				Description: "When set the admin api is checked when the provider is configured, retrying connection refused and dns errors for up to this many seconds",
+			},
+			"debug": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: envDefaultFuncWithDefault("KONG_DEBUG", "false"),
+				Description: "Log the fields that drifted from state when resources are read, shown at the INFO log level",
+			},
+			"offline": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: envDefaultFuncWithDefault("KONG_OFFLINE", "false"),
+				Description: "Run plan without calling the kong admin api, refresh keeps the state and data sources are empty, apply fails",
+			},
+			"konnect": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: envDefaultFuncWithDefault("KONG_KONNECT", "false"),
+				Description: "Manage a Konnect control plane instead of a kong node, kong_admin_uri is then ignored",
+			},
+			"konnect_token": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				DefaultFunc: envDefaultFuncWithDefault("KONNECT_TOKEN", ""),
+				Description: "Personal or system access token sent as a bearer token to Konnect",
+			},
+			"control_plane_id": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: envDefaultFuncWithDefault("KONNECT_CONTROL_PLANE_ID", ""),
+				Description: "The id of the Konnect control plane to manage",
+			},
+			"konnect_api_url": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: envDefaultFuncWithDefault("KONNECT_API_URL", defaultKonnectApiUrl),
+				Description: "The Konnect api of the region the control plane is in e.g. https://eu.api.konghq.com",
+			},
+			"admin_api_version": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  envDefaultFuncWithDefault("KONG_ADMIN_API_VERSION", ""),
+				ValidateFunc: validateAdminApiVersion,
+				Description:  "Build requests for this kong version (e.g. 2.8.1, or 3.6.1.0 for kong enterprise) instead of reading the version from the admin api",
+			},
+			"max_idle_conns": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  envDefaultFuncWithDefault("KONG_MAX_IDLE_CONNS", "0"),
+				ValidateFunc: validateConnectionLimit,
+				Description:  "Keep up to this many connections to the kong admin api open for reuse, by default every request opens a new connection",
+			},
+			"max_conns_per_host": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  envDefaultFuncWithDefault("KONG_MAX_CONNS_PER_HOST", "0"),
+				ValidateFunc: validateConnectionLimit,
+				Description:  "Send at most this many requests to the kong admin api at once so at most this many connections are open, by default there is no limit",
+			},
+			"requests_per_second": &schema.Schema{
+				Type:         schema.TypeFloat,
+				Optional:     true,
+				DefaultFunc:  envDefaultFuncWithDefault("KONG_REQUESTS_PER_SECOND", "0"),
+				ValidateFunc: validateRequestsPerSecond,
+				Description:  "Send at most this many requests a second to the kong admin api, retries included, by default there is no limit",
+			},
+			"circuit_breaker_threshold": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  envDefaultFuncWithDefault("KONG_CIRCUIT_BREAKER_THRESHOLD", "0"),
+				ValidateFunc: validateConnectionLimit,
+				Description:  "Stop calling the kong admin api after this many requests in a row failed (no response, a 5xx or a 429), the requests fail straight away until circuit_breaker_cooldown_seconds have passed. By default requests are always sent",
+			},
+			"circuit_breaker_cooldown_seconds": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  envDefaultFuncWithDefault("KONG_CIRCUIT_BREAKER_COOLDOWN_SECONDS", "30"),
+				ValidateFunc: validateCircuitBreakerCooldown,
+				Description:  "How long requests fail straight away once the circuit breaker has opened, then one request is sent to check kong has recovered",
+			},
+			"use_idempotent_creates": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: envDefaultFuncWithDefault("KONG_USE_IDEMPOTENT_CREATES", "false"),
+				Description: "Create services, routes and plugins with a PUT to an id derived from them, so a create retried after kong committed it does not make a duplicate (kong 1.0 and later)",
+			},
+			"config_json_indent": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  envDefaultFuncWithDefault("KONG_CONFIG_JSON_INDENT", "0"),
+				ValidateFunc: validateConfigJsonIndent,
+				Description:  "Store the config_json read from kong indented by this many spaces (with sorted keys) instead of on one line, what is sent to kong is not changed",
+			},
 		},
 
 		ResourcesMap: map[string]*schema.Resource{
-			"kong_api":                    resourceKongApi(),
-			"kong_certificate":            resourceKongCertificate(),
-			"kong_consumer":               resourceKongConsumer(),
-			"kong_consumer_plugin_config": resourceKongConsumerPluginConfig(),
-			"kong_plugin":                 resourceKongPlugin(),
-			"kong_sni":                    resourceKongSni(),
-			"kong_upstream":               resourceKongUpstream(),
-			"kong_service":                resourceKongService(),
-			"kong_route":                  resourceKongRoute(),
+			"kong_api":                            resourceKongApi(),
+			"kong_ca_certificate":                 resourceKongCaCertificate(),
+			"kong_certificate":                    resourceKongCertificate(),
+			"kong_consumer":                       resourceKongConsumer(),
+			"kong_consumer_group_plugin_override": resourceKongConsumerGroupPluginOverride(),
+			"kong_consumer_plugin_config":         resourceKongConsumerPluginConfig(),
+			"kong_declarative_config":             resourceKongDeclarativeConfig(),
+			"kong_global_plugin":                  resourceKongGlobalPlugin(),
+			"kong_key":                            resourceKongKey(),
+			"kong_key_set":                        resourceKongKeySet(),
+			"kong_license":                        resourceKongLicense(),
+			"kong_plugin":                         resourceKongPlugin(),
+			"kong_plugin_acl":                     resourceKongPluginAcl(),
+			"kong_plugin_acme":                    resourceKongPluginAcme(),
+			"kong_plugin_bot_detection":           resourceKongPluginBotDetection(),
+			"kong_plugin_cors":                    resourceKongPluginCors(),
+			"kong_plugin_http_log":                resourceKongPluginHttpLog(),
+			"kong_plugin_ip_restriction":          resourceKongPluginIpRestriction(),
+			"kong_plugin_jwt":                     resourceKongPluginJwt(),
+			"kong_plugin_key_auth":                resourceKongPluginKeyAuth(),
+			"kong_plugin_oauth2":                  resourceKongPluginOauth2(),
+			"kong_plugin_post_function":           resourceKongPluginPostFunction(),
+			"kong_plugin_pre_function":            resourceKongPluginPreFunction(),
+			"kong_plugin_prometheus":              resourceKongPluginPrometheus(),
+			"kong_plugin_rate_limiting_advanced":  resourceKongPluginRateLimitingAdvanced(),
+			"kong_plugin_request_size_limiting":   resourceKongPluginRequestSizeLimiting(),
+			"kong_plugin_request_transformer":     resourceKongPluginRequestTransformer(),
+			"kong_plugin_response_transformer":    resourceKongPluginResponseTransformer(),
+			"kong_rbac_user":                      resourceKongRbacUser(),
+			"kong_rbac_user_role":                 resourceKongRbacUserRole(),
+			"kong_sni":                            resourceKongSni(),
+			"kong_upstream":                       resourceKongUpstream(),
+			"kong_upstream_traffic_split":         resourceKongUpstreamTrafficSplit(),
+			"kong_target":                         resourceKongTarget(),
+			"kong_service":                        resourceKongService(),
+			"kong_route":                          resourceKongRoute(),
 		},
 
 		DataSourcesMap: map[string]*schema.Resource{
-			"kong_api":         dataSourceKongApi(),
-			"kong_certificate": dataSourceKongCertificate(),
-			"kong_consumer":    dataSourceKongConsumer(),
-			"kong_plugin":      dataSourceKongPlugin(),
-			"kong_upstream":    dataSourceKongUpstream(),
+			"kong_api":                    dataSourceKongApi(),
+			"kong_certificate":            dataSourceKongCertificate(),
+			"kong_config_validation":      dataSourceKongConfigValidation(),
+			"kong_consumer":               dataSourceKongConsumer(),
+			"kong_consumer_credentials":   dataSourceKongConsumerCredentials(),
+			"kong_consumer_plugin_config": dataSourceKongConsumerPluginConfig(),
+			"kong_entities_by_tag":        dataSourceKongEntitiesByTag(),
+			"kong_node_info":              dataSourceKongNodeInfo(),
+			"kong_plugin":                 dataSourceKongPlugin(),
+			"kong_plugin_config":          dataSourceKongPluginConfig(),
+			"kong_plugins":                dataSourceKongPlugins(),
+			"kong_service":                dataSourceKongService(),
+			"kong_upstream":               dataSourceKongUpstream(),
+			"kong_upstream_health":        dataSourceKongUpstreamHealth(),
 		},
 		ConfigureFunc: providerConfigure,
 	}
+
+	for _, r := range provider.ResourcesMap {
+		withOfflineResource(r)
+		withPluginSchemaDefaults(r, provider.Meta)
+	}
+
+	for _, r := range provider.DataSourcesMap {
+		withOfflineDataSource(r)
+	}
+
+	return provider
+}
+
+func validateConnectionLimit(v interface{}, k string) ([]string, []error) {
+	if limit := v.(int); limit < 0 {
+		return nil, []error{fmt.Errorf("%s must not be negative, got: %d", k, limit)}
+	}
+	return nil, nil
+}
+
+func validateRequestsPerSecond(v interface{}, k string) ([]string, []error) {
+	if limit := v.(float64); limit < 0 {
+		return nil, []error{fmt.Errorf("%s must not be negative, got: %v", k, limit)}
+	}
+	return nil, nil
+}
+
+func validateCircuitBreakerCooldown(v interface{}, k string) ([]string, []error) {
+	if cooldown := v.(int); cooldown < 1 {
+		return nil, []error{fmt.Errorf("%s must be at least 1, got: %d", k, cooldown)}
+	}
+	return nil, nil
+}
+
+func validateConfigJsonIndent(v interface{}, k string) ([]string, []error) {
+	if indent := v.(int); indent < 0 || indent > maxConfigJsonIndent {
+		return nil, []error{fmt.Errorf("%s must be between 0 and %d, got: %d", k, maxConfigJsonIndent, indent)}
+	}
+	return nil, nil
 }
 
 func envDefaultFuncWithDefault(key string, defaultValue string) schema.SchemaDefaultFunc {
@@ -97,5 +291,62 @@ func providerConfigure(d *schema.ResourceData) (interface{}, error) {
 		AdminToken:         d.Get("kong_admin_token").(string),
 	}
 
-	return gokong.NewClient(config), nil
+	adminUrls, err := readAdminUrls(readStringArrayFromResource(d, "admin_urls"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid admin_urls: %v", err)
+	}
+	if len(adminUrls) > 0 {
+		config.HostAddress = adminUrls[0].String()
+	}
+
+	konnect := d.Get("konnect").(bool)
+	controlPlaneId := d.Get("control_plane_id").(string)
+	if konnect {
+		if d.Get("konnect_token").(string) == "" || controlPlaneId == "" {
+			return nil, fmt.Errorf("konnect requires both konnect_token and control_plane_id to be set")
+		}
+		config.HostAddress = konnectAdminAddress(d.Get("konnect_api_url").(string), controlPlaneId)
+	}
+
+	client := newKongClient(config)
+	if konnect {
+		client.useKonnect(controlPlaneId)
+	}
+	if adminApiVersion := d.Get("admin_api_version").(string); adminApiVersion != "" {
+		if err := client.useAdminApiVersion(adminApiVersion); err != nil {
+			return nil, fmt.Errorf("invalid admin_api_version: %v", err)
+		}
+	}
+	client.dbless = d.Get("dbless").(bool)
+	client.debug = d.Get("debug").(bool)
+	client.offline = d.Get("offline").(bool)
+	client.idempotentCreates = d.Get("use_idempotent_creates").(bool)
+	client.configJsonIndent = strings.Repeat(" ", d.Get("config_json_indent").(int))
+
+	if client.offline {
+		client.transport = offlineTransport{}
+		return client, nil
+	}
+
+	transport := newAdminTransport(userAgent(d.Get("user_agent_suffix").(string)), config.InsecureSkipVerify)
+	if konnect {
+		if err := transport.useBearerToken(d.Get("konnect_token").(string), config.HostAddress); err != nil {
+			return nil, fmt.Errorf("invalid konnect_api_url: %v", err)
+		}
+	}
+	transport.limitConnections(d.Get("max_idle_conns").(int), d.Get("max_conns_per_host").(int))
+	transport.throttleRequests(d.Get("requests_per_second").(float64))
+	transport.breakCircuit(d.Get("circuit_breaker_threshold").(int), time.Duration(d.Get("circuit_breaker_cooldown_seconds").(int))*time.Second)
+	if !konnect {
+		transport.useAdminUrls(adminUrls)
+	}
+	client.transport = transport
+
+	if retrySeconds := d.Get("configure_retry_seconds").(int); retrySeconds > 0 {
+		if err := waitForKong(client, time.Duration(retrySeconds)*time.Second); err != nil {
+			return nil, fmt.Errorf("could not reach kong admin api at %s: %v", config.HostAddress, err)
+		}
+	}
+
+	return client, nil
 }
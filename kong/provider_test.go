@@ -5,6 +5,7 @@ import (
 	"os"
 	"testing"
 
+	"github.com/hashicorp/go-version"
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/terraform"
 	"github.com/kevholditch/gokong"
@@ -33,6 +34,28 @@ func TestProvider_impl(t *testing.T) {
 	var _ terraform.ResourceProvider = Provider()
 }
 
+// testAccPreCheckKongVersion skips tests for features the kong version under test does not have
+func testAccPreCheckKongVersion(t *testing.T, minimumVersion string) {
+	kongVersion, err := version.NewVersion(GetEnvVarOrDefault("KONG_VERSION", defaultKongVersion))
+	if err != nil {
+		t.Fatalf("could not parse kong version: %v", err)
+	}
+
+	if kongVersion.LessThan(version.Must(version.NewVersion(minimumVersion))) {
+		t.Skipf("kong %s does not support this feature, requires at least %s", kongVersion, minimumVersion)
+	}
+}
+
+// testAccPreCheckKongDbless skips tests that need kong running without a database, the kong started for the test run
+// uses one unless KONG_DBLESS is set
+func testAccPreCheckKongDbless(t *testing.T) {
+	testAccPreCheckKongVersion(t, "1.1.0")
+
+	if GetEnvVarOrDefault("KONG_DBLESS", "false") != "true" {
+		t.Skip("set KONG_DBLESS=true to run tests against a kong node without a database")
+	}
+}
+
 func TestMain(m *testing.M) {
 
 	testContext := containers.StartKong(GetEnvVarOrDefault("KONG_VERSION", defaultKongVersion))
@@ -100,10 +100,11 @@ func resourceKongApiCreate(d *schema.ResourceData, meta interface{}) error {
 
 	apiRequest := createKongApiRequestFromResourceData(d)
 
-	api, err := meta.(*gokong.KongAdminClient).Apis().Create(apiRequest)
+	api := &gokong.Api{}
+	err := meta.(*kongClient).post(gokong.ApisPath, apiRequest, api)
 
-	if err != nil || api == nil {
-		return fmt.Errorf("failed to create kong api: %v error: %v", apiRequest, err)
+	if err != nil || api.Id == nil {
+		return fmt.Errorf("failed to create kong api: %v error: %v", requestString(apiRequest), err)
 	}
 
 	d.SetId(*api.Id)
@@ -116,7 +117,7 @@ func resourceKongApiUpdate(d *schema.ResourceData, meta interface{}) error {
 
 	apiRequest := createKongApiRequestFromResourceData(d)
 
-	_, err := meta.(*gokong.KongAdminClient).Apis().UpdateById(d.Id(), apiRequest)
+	err := meta.(*kongClient).patch(gokong.ApisPath+d.Id(), apiRequest, nil)
 
 	if err != nil {
 		return fmt.Errorf("error updating kong api: %s", err)
@@ -127,13 +128,14 @@ func resourceKongApiUpdate(d *schema.ResourceData, meta interface{}) error {
 
 func resourceKongApiRead(d *schema.ResourceData, meta interface{}) error {
 
-	api, err := meta.(*gokong.KongAdminClient).Apis().GetById(d.Id())
+	api := &gokong.Api{}
+	found, err := meta.(*kongClient).get(gokong.ApisPath+d.Id(), api)
 
 	if err != nil {
 		return fmt.Errorf("could not find kong api: %v", err)
 	}
 
-	if api == nil {
+	if !found || api.Id == nil {
 		d.SetId("")
 	} else {
 		if api.Name != nil {
@@ -194,7 +196,7 @@ func resourceKongApiRead(d *schema.ResourceData, meta interface{}) error {
 
 func resourceKongApiDelete(d *schema.ResourceData, meta interface{}) error {
 
-	err := meta.(*gokong.KongAdminClient).Apis().DeleteById(d.Id())
+	err := meta.(*kongClient).delete(gokong.ApisPath + d.Id())
 
 	if err != nil {
 		return fmt.Errorf("could not delete kong api: %v", err)
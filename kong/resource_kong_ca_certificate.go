@@ -0,0 +1,159 @@
+package kong
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+const caCertificatesPath = "/ca_certificates/"
+
+type caCertificateRequest struct {
+	Cert string `json:"cert,omitempty"`
+}
+
+type caCertificate struct {
+	Id         string `json:"id,omitempty"`
+	Cert       string `json:"cert,omitempty"`
+	CertDigest string `json:"cert_digest,omitempty"`
+}
+
+func resourceKongCaCertificate() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceKongCaCertificateCreate,
+		Read:   resourceKongCaCertificateRead,
+		Delete: resourceKongCaCertificateDelete,
+		Update: resourceKongCaCertificateUpdate,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"cert": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     false,
+				ValidateFunc: validateCaCertificatePEM,
+				Description:  "PEM encoded CA certificate used to verify client and upstream certificates",
+			},
+			"cert_digest": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func resourceKongCaCertificateCreate(d *schema.ResourceData, meta interface{}) error {
+
+	caCertificateRequest := createKongCaCertificateRequestFromResourceData(d)
+
+	caCertificate := &caCertificate{}
+	err := meta.(*kongClient).post(caCertificatesPath, caCertificateRequest, caCertificate)
+
+	if err != nil {
+		return fmt.Errorf("failed to create kong ca certificate, error: %v", err)
+	}
+
+	d.SetId(caCertificate.Id)
+
+	return resourceKongCaCertificateRead(d, meta)
+}
+
+func resourceKongCaCertificateUpdate(d *schema.ResourceData, meta interface{}) error {
+	d.Partial(false)
+
+	caCertificateRequest := createKongCaCertificateRequestFromResourceData(d)
+
+	err := meta.(*kongClient).patch(caCertificatesPath+d.Id(), caCertificateRequest, nil)
+
+	if err != nil {
+		return fmt.Errorf("error updating kong ca certificate: %s", err)
+	}
+
+	return resourceKongCaCertificateRead(d, meta)
+}
+
+func resourceKongCaCertificateRead(d *schema.ResourceData, meta interface{}) error {
+
+	caCertificate := &caCertificate{}
+	found, err := meta.(*kongClient).get(caCertificatesPath+d.Id(), caCertificate)
+
+	if err != nil {
+		return fmt.Errorf("could not find kong ca certificate: %v", err)
+	}
+
+	if !found {
+		d.SetId("")
+	} else {
+		d.Set("cert", caCertificate.Cert)
+		d.Set("cert_digest", caCertificate.CertDigest)
+	}
+
+	return nil
+}
+
+func resourceKongCaCertificateDelete(d *schema.ResourceData, meta interface{}) error {
+
+	err := meta.(*kongClient).delete(caCertificatesPath + d.Id())
+
+	if err != nil {
+		return fmt.Errorf("could not delete kong ca certificate: %v", err)
+	}
+
+	return nil
+}
+
+func createKongCaCertificateRequestFromResourceData(d *schema.ResourceData) *caCertificateRequest {
+
+	caCertificateRequest := &caCertificateRequest{}
+
+	caCertificateRequest.Cert = readStringFromResource(d, "cert")
+
+	return caCertificateRequest
+}
+
+// validateCaCertificateIds checks kong supports the ca_certificates of a route or service (entity) and that each of them
+// is the id of a ca certificate
+func validateCaCertificateIds(client *kongClient, entity string, minimumKongVersion string, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	if err := client.requireVersion(fmt.Sprintf("kong_%s ca_certificates", entity), minimumKongVersion); err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		found, err := client.get(caCertificatesPath+id, nil)
+		if err != nil {
+			return fmt.Errorf("could not check kong %s ca certificate %s exists: %v", entity, id, err)
+		}
+		if !found {
+			return fmt.Errorf("kong %s ca_certificates has %s, there is no kong ca certificate with that id", entity, id)
+		}
+	}
+
+	return nil
+}
+
+// Kong rejects certificates without the CA basic constraint on this endpoint, so catch that at plan time
+func validateCaCertificatePEM(v interface{}, k string) ([]string, []error) {
+	block, _ := pem.Decode([]byte(v.(string)))
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, []error{fmt.Errorf("%s must be a PEM encoded certificate", k)}
+	}
+
+	certificate, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, []error{fmt.Errorf("%s could not be parsed as a certificate: %v", k, err)}
+	}
+
+	if !certificate.BasicConstraintsValid || !certificate.IsCA {
+		return nil, []error{fmt.Errorf("%s is not a CA certificate (basic constraints CA:TRUE is required)", k)}
+	}
+
+	return nil, nil
+}
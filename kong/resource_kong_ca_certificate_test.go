@@ -0,0 +1,234 @@
+package kong
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccKongCaCertificate(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheckKongVersion(t, "1.3.0") },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckKongCaCertificateDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testCreateCaCertificateConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckKongCaCertificateExists("kong_ca_certificate.ca"),
+					resource.TestCheckResourceAttr("kong_ca_certificate.ca", "cert", testCaCert1+"\n"),
+					resource.TestCheckResourceAttrSet("kong_ca_certificate.ca", "cert_digest"),
+				),
+			},
+			{
+				Config: testUpdateCaCertificateConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckKongCaCertificateExists("kong_ca_certificate.ca"),
+					resource.TestCheckResourceAttr("kong_ca_certificate.ca", "cert", testCaCert2+"\n"),
+					resource.TestCheckResourceAttrSet("kong_ca_certificate.ca", "cert_digest"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccKongCaCertificateImport(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheckKongVersion(t, "1.3.0") },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckKongCaCertificateDestroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testCreateCaCertificateConfig,
+			},
+
+			resource.TestStep{
+				ResourceName:      "kong_ca_certificate.ca",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestValidateCaCertificatePEM(t *testing.T) {
+
+	if _, errors := validateCaCertificatePEM(testCaCert1, "cert"); len(errors) != 0 {
+		t.Errorf("expected CA certificate to be valid, got: %v", errors)
+	}
+
+	if _, errors := validateCaCertificatePEM(testLeafCert, "cert"); len(errors) != 1 {
+		t.Errorf("expected non CA certificate to be rejected, got: %v", errors)
+	}
+
+	if _, errors := validateCaCertificatePEM("not a certificate", "cert"); len(errors) != 1 {
+		t.Errorf("expected invalid PEM to be rejected, got: %v", errors)
+	}
+}
+
+func testAccCheckKongCaCertificateDestroy(state *terraform.State) error {
+
+	client := testAccProvider.Meta().(*kongClient)
+
+	caCertificates := getResourcesByType("kong_ca_certificate", state)
+
+	if len(caCertificates) != 1 {
+		return fmt.Errorf("expecting only 1 ca certificate resource found %v", len(caCertificates))
+	}
+
+	found, err := client.get(caCertificatesPath+caCertificates[0].Primary.ID, nil)
+
+	if err != nil {
+		return fmt.Errorf("error calling get ca certificate by id: %v", err)
+	}
+
+	if found {
+		return fmt.Errorf("ca certificate %s still exists", caCertificates[0].Primary.ID)
+	}
+
+	return nil
+}
+
+func testAccCheckKongCaCertificateExists(resourceKey string) resource.TestCheckFunc {
+
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resourceKey]
+
+		if !ok {
+			return fmt.Errorf("not found: %s", resourceKey)
+		}
+
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("no ID is set")
+		}
+
+		found, err := testAccProvider.Meta().(*kongClient).get(caCertificatesPath+rs.Primary.ID, nil)
+
+		if err != nil {
+			return err
+		}
+
+		if !found {
+			return fmt.Errorf("ca certificate with id %v not found", rs.Primary.ID)
+		}
+
+		return nil
+	}
+}
+
+const testCaCert1 = `-----BEGIN CERTIFICATE-----
+MIIDKzCCAhOgAwIBAgIUBBOwEmpHmgmCT6rmhR3OxUMDXFkwDQYJKoZIhvcNAQEL
+BQAwHDEaMBgGA1UEAwwRVGVycmFmb3JtIFRlc3QgQ0EwIBcNMjYxMDE0MDQzMDI1
+WhgPMjEyNjA5MjAwNDMwMjVaMBwxGjAYBgNVBAMMEVRlcnJhZm9ybSBUZXN0IENB
+MIIBIjANBgkqhkiG9w0BAQEFAAOCAQ8AMIIBCgKCAQEAi1ZkShr+BsKtdSxaTBLL
+fum3A5zI6u8Q8nbFbTCkGPEhHibAvjL7YKiJzSTrTg17oadw3szO+FRgu5AlUr0T
++YCOcuIN860UjiFHY9j8teqrEMDpI0qS3zNBDzArdNhB3a2KTxtBuu7/lv/Coa5h
+0+magCtgLIlZoRfUFGDGIb66VWNDf6JTJY/hujOB/TkSk9mXTjo4d0X1RZoNpohk
+1qoO7agoQDdcZ1j6XgBtanHsSxY7a2iVjUFrRHipZxlUxH4MAv2dvuKnVy1IMWpT
+osZHH5HcvNgu30kIlxCDtiVqwrieYoCsBUNd/UtwNbiXt1JwaeIde0jFiTFeKrzH
+ZwIDAQABo2MwYTAdBgNVHQ4EFgQUKMYvpsc43hu0SaSqo1Ygp0zYvk8wHwYDVR0j
+BBgwFoAUKMYvpsc43hu0SaSqo1Ygp0zYvk8wDwYDVR0TAQH/BAUwAwEB/zAOBgNV
+HQ8BAf8EBAMCAQYwDQYJKoZIhvcNAQELBQADggEBAFml9s5ntSckKMPAAYiwpRUj
+bOSqMczyfasm25V7NEP6PZM77FhEGfCOR8X7ys5IJPFIkBda5piUTE2yrkQbp1Bk
+of/LpkE2SE7M3L+81t4prye43L6eaVXdosx6G+HiESW0Yk5eIfPA3taBqnpDY5Xm
+VK6nf/hCWFLeayNmptIxfPFiotva1xrf8Vx797RXjVa8FUPzWSTt0sGzNwgI7KSs
+FveaoK+pa5kU/QbnH9H6+Byhx0bBZmD8nXxZOo9jp6rKn5WTDuI9yP2QDtUzDYZN
++2ki2gj7u0zIYEyOZxJp0f6TAOhBfB7/wjUf5Pdaiwy5Ppqk8YsyAanbp3Sd1Lo=
+-----END CERTIFICATE-----`
+
+const testCaCert2 = `-----BEGIN CERTIFICATE-----
+MIIDLzCCAhegAwIBAgIUAoQEzuA1FrWgKlNaHH6G+B/AGBIwDQYJKoZIhvcNAQEL
+BQAwHjEcMBoGA1UEAwwTVGVycmFmb3JtIFRlc3QgQ0EgMjAgFw0yNjEwMTQwNDMw
+MjVaGA8yMTI2MDkyMDA0MzAyNVowHjEcMBoGA1UEAwwTVGVycmFmb3JtIFRlc3Qg
+Q0EgMjCCASIwDQYJKoZIhvcNAQEBBQADggEPADCCAQoCggEBANXYaonG35F+W6pO
+YiKVcZNSUTMkbIgTttYFG4kJV3WGhCoWxNIzu1Sy2K+lfVE/+YUl+KpIEArzXpf+
+ERkjxgkyA7fhdcn1MhnZervxE1ZOhUIaOYCL+qMQJUxoEPdIz20JAwPwlQ/binb1
+4pnB5G7bhMn1NYFUh50/7xrN/5xIPXjzyY3PscHz+4sgwjwwNsLPopV1nIIuDVx7
+DTngMH3ySIj3OOwpw6F0rWECWJIxJhM9BtRJ3VjcLWHDV5D8Pk4KWrV6EMdt4Gmm
+Kacbk7mD7luPtUJ7INUEe4MfEQStdk9aFnNhI2Ij/7XPFB7XQ3THww54Q0CmKTqp
+mEN0YkECAwEAAaNjMGEwHQYDVR0OBBYEFKL0blHVj8nL9xZhlhNXY58cxnwKMB8G
+A1UdIwQYMBaAFKL0blHVj8nL9xZhlhNXY58cxnwKMA8GA1UdEwEB/wQFMAMBAf8w
+DgYDVR0PAQH/BAQDAgEGMA0GCSqGSIb3DQEBCwUAA4IBAQChF6eQDMU2cjF1UXEM
+Ayg/ndIij60WMP1S002wBI22gyu6JeL6OsSYG4UKmbl4PZgTDoWLDXbpb827n4a+
+I9RZ4D23HusxlLBUdGVA3pjS0AZxDVCXPzTiyZvu7rI5jJ9BZ+TYF6Avi5dEVIaf
+8whOi2fQFJbF5VlSdNDLOuCnwYmtSmBFnAYxrLcp81AXevwpO8y9ewACjHe78Ps4
+TXhJCj07Q+LxygWAbA2EBrKjPtQxFVtIHxjcohAtCzTCFsRGC+wDKe2ReaT1NZx+
+Sb5Lp/Ai1tcR7Gt4+u0ewEM+QOkPNrBjrJK1sN/O8vw0+tGog7471aVMfXmNT+Co
+CM1p
+-----END CERTIFICATE-----`
+
+const testLeafCert = `-----BEGIN CERTIFICATE-----
+MIIDFDCCAfygAwIBAgIUb4idsDHSBJ4/FjTXhmlMnvMw++wwDQYJKoZIhvcNAQEL
+BQAwGjEYMBYGA1UEAwwPd3d3LmV4YW1wbGUuY29tMCAXDTI2MTAxNDA0MzAyNloY
+DzIxMjYwOTIwMDQzMDI2WjAaMRgwFgYDVQQDDA93d3cuZXhhbXBsZS5jb20wggEi
+MA0GCSqGSIb3DQEBAQUAA4IBDwAwggEKAoIBAQDak8FbKRF1KzmfE6Yn8D5f1ZUQ
+dlbntCWDdqUtRrid6iFi3DUX308VAgWJ9NI7qEiInRHfhuw3sU6/c4MIiCQqbjjN
+nODyJ16vLBL3ZXP+tDc2VmzackJdTk50nA9d2lg6pls82D2iWak22WG/Pai64e0Y
+fJQCU9s63aLvNS594NipFM0noBOrYqg6jaxVPhjYWUMUOyV+xld7c5Fs+BbukrIB
+KAdYMYUk26YMIm/rTc9uXkfekSPDztUnOT4ZA/5qkEDPjxsDUFrOHDL4tnOKIz4J
+OqZ/aNYbZP4uu6CWKJ5gc7EBWLoJ9Gm9JmaLfN+bHlv4iu4uvgwZ9rd4uoRJAgMB
+AAGjUDBOMB0GA1UdDgQWBBTAHY5G/Ygg6m53vfsQZGJfUMLrVDAfBgNVHSMEGDAW
+gBTAHY5G/Ygg6m53vfsQZGJfUMLrVDAMBgNVHRMBAf8EAjAAMA0GCSqGSIb3DQEB
+CwUAA4IBAQCkFoUlfafpv856FDrFHzUu5K3vFUCd9QSowEICkVEY8BQUSv30oB9g
+IS97RiTSTag80bsBRP8Tl5OMa3CsNa7gWqPlLRrjk6s9FZdYBxR9hxNpTBq5WcZq
+bKyl5KnA8llFbIpDIN6LZjPL9/mmPahmXUla71xZLMRBKUbIH6bbvfNzpkX0NbaA
+JTgjBIkQXISQjbeLgJWMBZoMqxiwzQIEyQih62k1WhdKTIObVF4yXs6cGXDx2rBU
+5Ry5uE4AfM3HwpC3Qkg3k3IH1+lSkfPdY13KZMXlLqwmsTr0kiI78TAxrI/DPUxO
+jo+3zftVBWV9TBR+9Quvl7O0+/+0LUau
+-----END CERTIFICATE-----`
+
+const testCreateCaCertificateConfig = `
+resource "kong_ca_certificate" "ca" {
+	cert = <<EOF
+-----BEGIN CERTIFICATE-----
+MIIDKzCCAhOgAwIBAgIUBBOwEmpHmgmCT6rmhR3OxUMDXFkwDQYJKoZIhvcNAQEL
+BQAwHDEaMBgGA1UEAwwRVGVycmFmb3JtIFRlc3QgQ0EwIBcNMjYxMDE0MDQzMDI1
+WhgPMjEyNjA5MjAwNDMwMjVaMBwxGjAYBgNVBAMMEVRlcnJhZm9ybSBUZXN0IENB
+MIIBIjANBgkqhkiG9w0BAQEFAAOCAQ8AMIIBCgKCAQEAi1ZkShr+BsKtdSxaTBLL
+fum3A5zI6u8Q8nbFbTCkGPEhHibAvjL7YKiJzSTrTg17oadw3szO+FRgu5AlUr0T
++YCOcuIN860UjiFHY9j8teqrEMDpI0qS3zNBDzArdNhB3a2KTxtBuu7/lv/Coa5h
+0+magCtgLIlZoRfUFGDGIb66VWNDf6JTJY/hujOB/TkSk9mXTjo4d0X1RZoNpohk
+1qoO7agoQDdcZ1j6XgBtanHsSxY7a2iVjUFrRHipZxlUxH4MAv2dvuKnVy1IMWpT
+osZHH5HcvNgu30kIlxCDtiVqwrieYoCsBUNd/UtwNbiXt1JwaeIde0jFiTFeKrzH
+ZwIDAQABo2MwYTAdBgNVHQ4EFgQUKMYvpsc43hu0SaSqo1Ygp0zYvk8wHwYDVR0j
+BBgwFoAUKMYvpsc43hu0SaSqo1Ygp0zYvk8wDwYDVR0TAQH/BAUwAwEB/zAOBgNV
+HQ8BAf8EBAMCAQYwDQYJKoZIhvcNAQELBQADggEBAFml9s5ntSckKMPAAYiwpRUj
+bOSqMczyfasm25V7NEP6PZM77FhEGfCOR8X7ys5IJPFIkBda5piUTE2yrkQbp1Bk
+of/LpkE2SE7M3L+81t4prye43L6eaVXdosx6G+HiESW0Yk5eIfPA3taBqnpDY5Xm
+VK6nf/hCWFLeayNmptIxfPFiotva1xrf8Vx797RXjVa8FUPzWSTt0sGzNwgI7KSs
+FveaoK+pa5kU/QbnH9H6+Byhx0bBZmD8nXxZOo9jp6rKn5WTDuI9yP2QDtUzDYZN
++2ki2gj7u0zIYEyOZxJp0f6TAOhBfB7/wjUf5Pdaiwy5Ppqk8YsyAanbp3Sd1Lo=
+-----END CERTIFICATE-----
+EOF
+}
+`
+const testUpdateCaCertificateConfig = `
+resource "kong_ca_certificate" "ca" {
+	cert = <<EOF
+-----BEGIN CERTIFICATE-----
+MIIDLzCCAhegAwIBAgIUAoQEzuA1FrWgKlNaHH6G+B/AGBIwDQYJKoZIhvcNAQEL
+BQAwHjEcMBoGA1UEAwwTVGVycmFmb3JtIFRlc3QgQ0EgMjAgFw0yNjEwMTQwNDMw
+MjVaGA8yMTI2MDkyMDA0MzAyNVowHjEcMBoGA1UEAwwTVGVycmFmb3JtIFRlc3Qg
+Q0EgMjCCASIwDQYJKoZIhvcNAQEBBQADggEPADCCAQoCggEBANXYaonG35F+W6pO
+YiKVcZNSUTMkbIgTttYFG4kJV3WGhCoWxNIzu1Sy2K+lfVE/+YUl+KpIEArzXpf+
+ERkjxgkyA7fhdcn1MhnZervxE1ZOhUIaOYCL+qMQJUxoEPdIz20JAwPwlQ/binb1
+4pnB5G7bhMn1NYFUh50/7xrN/5xIPXjzyY3PscHz+4sgwjwwNsLPopV1nIIuDVx7
+DTngMH3ySIj3OOwpw6F0rWECWJIxJhM9BtRJ3VjcLWHDV5D8Pk4KWrV6EMdt4Gmm
+Kacbk7mD7luPtUJ7INUEe4MfEQStdk9aFnNhI2Ij/7XPFB7XQ3THww54Q0CmKTqp
+mEN0YkECAwEAAaNjMGEwHQYDVR0OBBYEFKL0blHVj8nL9xZhlhNXY58cxnwKMB8G
+A1UdIwQYMBaAFKL0blHVj8nL9xZhlhNXY58cxnwKMA8GA1UdEwEB/wQFMAMBAf8w
+DgYDVR0PAQH/BAQDAgEGMA0GCSqGSIb3DQEBCwUAA4IBAQChF6eQDMU2cjF1UXEM
+Ayg/ndIij60WMP1S002wBI22gyu6JeL6OsSYG4UKmbl4PZgTDoWLDXbpb827n4a+
+I9RZ4D23HusxlLBUdGVA3pjS0AZxDVCXPzTiyZvu7rI5jJ9BZ+TYF6Avi5dEVIaf
+8whOi2fQFJbF5VlSdNDLOuCnwYmtSmBFnAYxrLcp81AXevwpO8y9ewACjHe78Ps4
+TXhJCj07Q+LxygWAbA2EBrKjPtQxFVtIHxjcohAtCzTCFsRGC+wDKe2ReaT1NZx+
+Sb5Lp/Ai1tcR7Gt4+u0ewEM+QOkPNrBjrJK1sN/O8vw0+tGog7471aVMfXmNT+Co
+CM1p
+-----END CERTIFICATE-----
+EOF
+}
+`
@@ -1,7 +1,15 @@
 package kong
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
 	"fmt"
+	"sort"
+	"time"
 
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/kevholditch/gokong"
@@ -28,57 +36,138 @@ func resourceKongCertificate() *schema.Resource {
 				Optional: true,
 				ForceNew: false,
 			},
+			"fingerprint": &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "SHA-256 of the DER encoding of the first certificate, empty when the certificate is not PEM encoded",
+			},
+			"not_before": &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "When the leaf certificate becomes valid (RFC 3339, UTC), empty when the certificate can not be parsed",
+			},
+			"expires_at": &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "When the leaf certificate expires (RFC 3339, UTC), empty when the certificate can not be parsed",
+			},
+			"snis": &schema.Schema{
+				Type:        schema.TypeSet,
+				Optional:    true,
+				ForceNew:    false,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Every SNI of the certificate, SNIs of the certificate that are not in the set are deleted",
+			},
+			"tags": &schema.Schema{
+				Type:     schema.TypeSet,
+				Optional: true,
+				ForceNew: false,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
 		},
 	}
 }
 
-func resourceKongCertificateCreate(d *schema.ResourceData, meta interface{}) error {
+type certificateRequest struct {
+	*gokong.CertificateRequest
+	Tags *[]string `json:"tags,omitempty"`
+}
 
-	certificateRequest := createKongCertificateRequestFromResourceData(d)
+type certificate struct {
+	gokong.Certificate
+	Tags []string `json:"tags"`
+}
 
-	certificate, err := meta.(*gokong.KongAdminClient).Certificates().Create(certificateRequest)
+func resourceKongCertificateCreate(d *schema.ResourceData, meta interface{}) error {
 
+	certificateRequest, err := createKongCertificateRequestFromResourceData(meta.(*kongClient), d)
 	if err != nil {
-		return fmt.Errorf("failed to create kong certificate: %v error: %v", certificateRequest, err)
+		return err
+	}
+
+	certificate := &gokong.Certificate{}
+	err = meta.(*kongClient).post(gokong.CertificatesPath, certificateRequest, certificate)
+
+	if err != nil || certificate.Id == nil {
+		return fmt.Errorf("failed to create kong certificate: %v error: %v", redactCertificateRequest(certificateRequest), err)
 	}
 
 	d.SetId(*certificate.Id)
 
+	if err := reconcileKongCertificateSnis(meta.(*kongClient), d.Id(), nil, readStringSetFromResource(d, "snis")); err != nil {
+		return err
+	}
+
 	return resourceKongCertificateRead(d, meta)
 }
 
 func resourceKongCertificateUpdate(d *schema.ResourceData, meta interface{}) error {
 	d.Partial(false)
 
-	certificateRequest := createKongCertificateRequestFromResourceData(d)
+	certificateRequest, err := createKongCertificateRequestFromResourceData(meta.(*kongClient), d)
+	if err != nil {
+		return err
+	}
 
-	_, err := meta.(*gokong.KongAdminClient).Certificates().UpdateById(d.Id(), certificateRequest)
+	err = meta.(*kongClient).patch(gokong.CertificatesPath+d.Id(), certificateRequest.CertificateRequest, nil)
 
 	if err != nil {
 		return fmt.Errorf("error updating kong certificate: %s", err)
 	}
 
+	if tags := certificateRequest.Tags; tags != nil && d.HasChange("tags") {
+		if err := syncTags(meta.(*kongClient), "certificates", d.Id(), *tags); err != nil {
+			return fmt.Errorf("error updating kong certificate: %s", err)
+		}
+	}
+
+	if d.HasChange("snis") {
+		oldSnis, newSnis := d.GetChange("snis")
+		if err := reconcileKongCertificateSnis(meta.(*kongClient), d.Id(), stringSet(oldSnis), stringSet(newSnis)); err != nil {
+			return err
+		}
+	}
+
 	return resourceKongCertificateRead(d, meta)
 }
 
 func resourceKongCertificateRead(d *schema.ResourceData, meta interface{}) error {
 
-	certificate, err := meta.(*gokong.KongAdminClient).Certificates().GetById(d.Id())
+	client := meta.(*kongClient)
+	certificate := &certificate{}
+	found, err := client.get(gokong.CertificatesPath+d.Id(), certificate)
 
 	if err != nil {
 		return fmt.Errorf("could not find kong certificate: %v", err)
 	}
 
-	if certificate == nil {
+	if !found || certificate.Id == nil {
 		d.SetId("")
 	} else {
 		if certificate.Cert != nil {
 			d.Set("certificate", certificate.Cert)
+			d.Set("fingerprint", certificateFingerprint(*certificate.Cert))
+
+			notBefore, expiresAt := certificateValidity(*certificate.Cert)
+			d.Set("not_before", notBefore)
+			d.Set("expires_at", expiresAt)
 		}
 
 		if certificate.Key != nil {
 			d.Set("private_key", certificate.Key)
 		}
+
+		setTagsFromKong(client, d, certificate.Tags)
+
+		// the snis are only read when they are managed here, certificates whose snis are kong_sni resources would
+		// otherwise show them all as changes
+		if len(readStringSetFromResource(d, "snis")) > 0 {
+			snis, err := getKongCertificateSnis(client, d.Id())
+			if err != nil {
+				return fmt.Errorf("could not find snis of kong certificate: %v", err)
+			}
+			d.Set("snis", snis)
+		}
 	}
 
 	return nil
@@ -86,7 +175,7 @@ func resourceKongCertificateRead(d *schema.ResourceData, meta interface{}) error
 
 func resourceKongCertificateDelete(d *schema.ResourceData, meta interface{}) error {
 
-	err := meta.(*gokong.KongAdminClient).Certificates().DeleteById(d.Id())
+	err := meta.(*kongClient).delete(gokong.CertificatesPath + d.Id())
 
 	if err != nil {
 		return fmt.Errorf("could not delete kong certificate: %v", err)
@@ -95,12 +184,156 @@ func resourceKongCertificateDelete(d *schema.ResourceData, meta interface{}) err
 	return nil
 }
 
-func createKongCertificateRequestFromResourceData(d *schema.ResourceData) *gokong.CertificateRequest {
+// redactCertificateRequest is the request without its private key, for errors
+func redactCertificateRequest(request *certificateRequest) string {
+	redacted := *request.CertificateRequest
+	redacted.Key = nil
+	return requestString(&certificateRequest{CertificateRequest: &redacted, Tags: request.Tags})
+}
+
+func createKongCertificateRequestFromResourceData(client *kongClient, d *schema.ResourceData) (*certificateRequest, error) {
 
-	certificateRequest := &gokong.CertificateRequest{}
+	certificateRequest := &certificateRequest{CertificateRequest: &gokong.CertificateRequest{}}
 
 	certificateRequest.Cert = readStringPtrFromResource(d, "certificate")
 	certificateRequest.Key = readStringPtrFromResource(d, "private_key")
 
-	return certificateRequest
+	tags, err := readTagsFromResource(client, "kong_certificate", d)
+	if err != nil {
+		return nil, fmt.Errorf("could not check kong version for tags: %v", err)
+	}
+	certificateRequest.Tags = tags
+
+	return certificateRequest, nil
+}
+
+// certificateFingerprint hashes the DER bytes rather than the PEM text, so whitespace or line ending changes in the PEM
+// do not change the fingerprint. Only the first certificate of a chain is used.
+func certificateFingerprint(certificate string) string {
+	block, _ := pem.Decode([]byte(certificate))
+	if block == nil || block.Type != "CERTIFICATE" {
+		return ""
+	}
+
+	sum := sha256.Sum256(block.Bytes)
+	return hex.EncodeToString(sum[:])
+}
+
+// certificateValidity returns when the leaf certificate of the PEM is valid from and until, formatted as RFC 3339 in
+// UTC. The leaf is the certificate of a chain that did not issue any of the others, so it is found whatever the order of
+// the chain. Both are empty when there is no certificate that can be parsed.
+func certificateValidity(certificate string) (string, string) {
+	var certificates []*x509.Certificate
+	rest := []byte(certificate)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		if parsed, err := x509.ParseCertificate(block.Bytes); err == nil {
+			certificates = append(certificates, parsed)
+		}
+	}
+
+	if len(certificates) == 0 {
+		return "", ""
+	}
+
+	leaf := certificates[0]
+	for _, candidate := range certificates {
+		issuer := false
+		for _, other := range certificates {
+			if other != candidate && bytes.Equal(other.RawIssuer, candidate.RawSubject) {
+				issuer = true
+				break
+			}
+		}
+		if !issuer {
+			leaf = candidate
+			break
+		}
+	}
+
+	return leaf.NotBefore.UTC().Format(time.RFC3339), leaf.NotAfter.UTC().Format(time.RFC3339)
+}
+
+type certificateSni struct {
+	Name             string           `json:"name"`
+	SslCertificateId string           `json:"ssl_certificate_id"`
+	Certificate      *entityReference `json:"certificate"`
+}
+
+// getKongCertificateSnis lists every sni and keeps the ones of the certificate, kong versions before 1.0 can not list
+// the snis of a single certificate
+func getKongCertificateSnis(client *kongClient, certificateId string) ([]string, error) {
+	results, err := client.listAll(gokong.SnisPath)
+	if err != nil {
+		return nil, err
+	}
+
+	snis := []string{}
+	for _, result := range results {
+		sni := &certificateSni{}
+		if err := json.Unmarshal(result, sni); err != nil {
+			return nil, fmt.Errorf("could not parse sni %s: %v", result, err)
+		}
+
+		if firstNonEmpty(sni.SslCertificateId, sni.Certificate.id()) == certificateId {
+			snis = append(snis, sni.Name)
+		}
+	}
+
+	sort.Strings(snis)
+	return snis, nil
+}
+
+// getKongCertificateIdBySni returns the id of the certificate the sni belongs to, kong 1.0 and later return it as a
+// reference and older versions as ssl_certificate_id
+func getKongCertificateIdBySni(client *kongClient, name string) (string, error) {
+	sni := &certificateSni{}
+	found, err := client.get(gokong.SnisPath+name, sni)
+	if err != nil {
+		return "", fmt.Errorf("could not find kong sni %s: %v", name, err)
+	}
+
+	certificateId := firstNonEmpty(sni.SslCertificateId, sni.Certificate.id())
+	if !found || certificateId == "" {
+		return "", fmt.Errorf("there is no kong sni %s to find the certificate of", name)
+	}
+	return certificateId, nil
+}
+
+// reconcileKongCertificateSnis deletes the snis that were removed from the set before creating the added ones, so an
+// sni can be moved between certificates in one apply
+func reconcileKongCertificateSnis(client *kongClient, certificateId string, oldSnis []string, newSnis []string) error {
+	for _, name := range oldSnis {
+		if !contains(newSnis, name) {
+			if err := client.delete(gokong.SnisPath + name); err != nil {
+				return fmt.Errorf("could not delete kong sni %s of certificate %s: %v", name, certificateId, err)
+			}
+		}
+	}
+
+	for _, name := range newSnis {
+		if !contains(oldSnis, name) {
+			sniRequest := &gokong.SnisRequest{Name: name, SslCertificateId: certificateId}
+			if err := client.post(gokong.SnisPath, sniRequest, nil); err != nil {
+				return fmt.Errorf("failed to create kong sni: %v error: %v", requestString(sniRequest), err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func stringSet(value interface{}) []string {
+	var strings []string
+	for _, item := range value.(*schema.Set).List() {
+		strings = append(strings, item.(string))
+	}
+	return strings
 }
@@ -1,10 +1,25 @@
 package kong
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
 	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/hashicorp/terraform/config"
 	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/terraform"
 	"github.com/kevholditch/gokong"
 )
@@ -21,6 +36,7 @@ func TestAccKongCertificate(t *testing.T) {
 					testAccCheckKongCertificateExists("kong_certificate.certificate"),
 					resource.TestCheckResourceAttr("kong_certificate.certificate", "certificate", "public key --- 123 ----"),
 					resource.TestCheckResourceAttr("kong_certificate.certificate", "private_key", "private key --- 456 ----"),
+					resource.TestCheckResourceAttr("kong_certificate.certificate", "fingerprint", ""),
 				),
 			},
 			{
@@ -54,9 +70,301 @@ func TestAccKongCertificateImport(t *testing.T) {
 	})
 }
 
+func TestAccKongCertificateSnis(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckKongCertificateDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testCreateCertificateWithSnisConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckKongCertificateExists("kong_certificate.certificate"),
+					resource.TestCheckResourceAttr("kong_certificate.certificate", "snis.#", "2"),
+					testAccCheckKongCertificateSnis("kong_certificate.certificate", "a.example.com", "b.example.com"),
+				),
+			},
+			{
+				Config: testUpdateCertificateWithSnisConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckKongCertificateExists("kong_certificate.certificate"),
+					resource.TestCheckResourceAttr("kong_certificate.certificate", "snis.#", "2"),
+					testAccCheckKongCertificateSnis("kong_certificate.certificate", "b.example.com", "c.example.com"),
+				),
+			},
+			{
+				Config: testCreateCertificateConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("kong_certificate.certificate", "snis.#", "0"),
+					testAccCheckKongCertificateSnis("kong_certificate.certificate"),
+				),
+			},
+		},
+	})
+}
+
+func TestKongCertificateCreateErrorRedactsPrivateKey(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodGet {
+			w.Write([]byte(`{"version":"3.4.2"}`))
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"message":"invalid certificate"}`))
+	}))
+	defer server.Close()
+
+	client := newKongClient(&gokong.Config{HostAddress: server.URL})
+	d := schema.TestResourceDataRaw(t, resourceKongCertificate().Schema, map[string]interface{}{
+		"certificate": "public key --- 123 ----",
+		"private_key": "private key --- 456 ----",
+	})
+
+	err := resourceKongCertificateCreate(d, client)
+
+	if err == nil || !strings.Contains(err.Error(), "public key --- 123 ----") {
+		t.Errorf("expected the error to show the certificate that could not be created, got: %v", err)
+	}
+	if err != nil && strings.Contains(err.Error(), "private key --- 456 ----") {
+		t.Errorf("expected the private key to be left out of the error, got: %v", err)
+	}
+}
+
+func TestKongCertificateReconcileSnis(t *testing.T) {
+
+	snis := map[string]string{"a.example.com": "cert-id", "b.example.com": "cert-id", "other.example.com": "other-cert-id"}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		name := strings.TrimPrefix(r.URL.Path, gokong.SnisPath)
+		switch {
+		case r.Method == http.MethodGet && name == "":
+			data := []*gokong.Sni{}
+			for name, certificateId := range snis {
+				data = append(data, &gokong.Sni{Name: name, SslCertificateId: certificateId})
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+		case r.Method == http.MethodPost:
+			sni := &gokong.Sni{}
+			json.NewDecoder(r.Body).Decode(sni)
+			snis[sni.Name] = sni.SslCertificateId
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(sni)
+		case r.Method == http.MethodDelete:
+			delete(snis, name)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := newKongClient(&gokong.Config{HostAddress: server.URL})
+
+	if err := reconcileKongCertificateSnis(client, "cert-id", []string{"a.example.com", "b.example.com"}, []string{"b.example.com", "c.example.com"}); err != nil {
+		t.Fatalf("could not reconcile snis: %v", err)
+	}
+
+	certificateSnis, err := getKongCertificateSnis(client, "cert-id")
+	if err != nil {
+		t.Fatalf("could not list snis: %v", err)
+	}
+
+	if expected := "b.example.com,c.example.com"; strings.Join(certificateSnis, ",") != expected {
+		t.Errorf("expected the certificate to have snis %s but had %v", expected, certificateSnis)
+	}
+
+	if snis["other.example.com"] != "other-cert-id" {
+		t.Errorf("expected the sni of another certificate to be left alone, snis were %v", snis)
+	}
+}
+
+// taggedEntityServer mocks a kong node holding a single entity below collection, the fields of every create and update
+// are merged into it. The tags sent with each request are kept in sentTags.
+type taggedEntityServer struct {
+	*httptest.Server
+	entity   map[string]interface{}
+	sentTags [][]interface{}
+}
+
+func newTaggedEntityServer(collection string, id string) *taggedEntityServer {
+	server := &taggedEntityServer{}
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/" {
+			json.NewEncoder(w).Encode(map[string]string{"version": "2.8.0"})
+			return
+		}
+
+		if r.Method == http.MethodPost {
+			server.entity = map[string]interface{}{"id": id}
+			w.WriteHeader(http.StatusCreated)
+		} else if server.entity == nil || r.URL.Path != collection+id {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if r.Method == http.MethodPost || r.Method == http.MethodPatch {
+			request := map[string]interface{}{}
+			json.NewDecoder(r.Body).Decode(&request)
+			for key, value := range request {
+				server.entity[key] = value
+			}
+			if tags, ok := request["tags"]; ok {
+				server.sentTags = append(server.sentTags, tags.([]interface{}))
+			}
+		}
+
+		json.NewEncoder(w).Encode(server.entity)
+	}))
+	return server
+}
+
+func applyTaggedResource(t *testing.T, r *schema.Resource, client *kongClient, state *terraform.InstanceState, raw map[string]interface{}) *terraform.InstanceState {
+	rawConfig, err := config.NewRawConfig(raw)
+	if err != nil {
+		t.Fatalf("could not build config: %v", err)
+	}
+	diff, err := r.Diff(state, terraform.NewResourceConfig(rawConfig))
+	if err != nil {
+		t.Fatalf("could not diff %v: %v", raw, err)
+	}
+	if diff == nil || diff.Empty() {
+		return state
+	}
+	state, err = r.Apply(state, diff, client)
+	if err != nil {
+		t.Fatalf("could not apply %v: %v", raw, err)
+	}
+	return state
+}
+
+func TestKongCertificateTags(t *testing.T) {
+
+	server := newTaggedEntityServer(gokong.CertificatesPath, "certificate-id")
+	defer server.Close()
+
+	client := newKongClient(&gokong.Config{HostAddress: server.URL})
+	r := resourceKongCertificate()
+
+	state := applyTaggedResource(t, r, client, nil, map[string]interface{}{
+		"certificate": testCaCert1,
+		"private_key": "private key --- 456 ----",
+		"tags":        []interface{}{"team-a", "billing"},
+	})
+
+	if len(server.sentTags) != 1 || len(server.sentTags[0]) != 2 {
+		t.Fatalf("expected the tags to be sent with the certificate, kong was sent: %v", server.sentTags)
+	}
+	if state.ID != "certificate-id" || state.Attributes["tags.#"] != "2" {
+		t.Errorf("expected the tags to be read back, got: %v", state.Attributes)
+	}
+
+	// the order of the tags is not a change
+	server.entity["tags"] = []interface{}{"billing", "team-a"}
+	state = applyTaggedResource(t, r, client, state, map[string]interface{}{
+		"certificate": testCaCert1,
+		"private_key": "private key --- 456 ----",
+		"tags":        []interface{}{"billing", "team-a"},
+	})
+	if len(server.sentTags) != 1 {
+		t.Errorf("expected reordered tags not to be sent, kong was sent: %v", server.sentTags[1:])
+	}
+
+	state = applyTaggedResource(t, r, client, state, map[string]interface{}{
+		"certificate": testCaCert1,
+		"private_key": "private key --- 456 ----",
+		"tags":        []interface{}{"billing", "team-b"},
+	})
+
+	// the certificate is patched without tags, they are then replaced keeping kong's order
+	if len(server.sentTags) != 2 || fmt.Sprint(server.sentTags[1]) != "[billing team-b]" {
+		t.Errorf("expected the changed tags to be sent, kong was sent: %v", server.sentTags)
+	}
+	if state.Attributes["tags.#"] != "2" || fmt.Sprint(server.entity["tags"]) != "[billing team-b]" {
+		t.Errorf("expected the updated tags to be read back, got: %v", state.Attributes)
+	}
+}
+
+func TestCertificateFingerprint(t *testing.T) {
+
+	fingerprint := certificateFingerprint(testCaCert1)
+	if fingerprint != "2880e48e0dd02d54ce5d632321fb56f94e6bcfcfafdcd8c5d81e606d152f7155" {
+		t.Errorf("expected the sha-256 of the certificate der but was %s", fingerprint)
+	}
+
+	if rotated := certificateFingerprint(testCaCert2); rotated == fingerprint || rotated == "" {
+		t.Errorf("expected the fingerprint to change when the certificate changes, was %s for both", fingerprint)
+	}
+
+	if reformatted := certificateFingerprint("\n" + strings.Replace(testCaCert1, "\n", "\r\n", -1)); reformatted != fingerprint {
+		t.Errorf("expected the fingerprint not to depend on the pem formatting but was %s", reformatted)
+	}
+
+	if chain := certificateFingerprint(testCaCert1 + "\n" + testCaCert2); chain != fingerprint {
+		t.Errorf("expected the fingerprint of a chain to be the one of its first certificate but was %s", chain)
+	}
+
+	if notPem := certificateFingerprint("public key --- 123 ----"); notPem != "" {
+		t.Errorf("expected no fingerprint for a certificate that is not pem but was %s", notPem)
+	}
+}
+
+func TestCertificateValidity(t *testing.T) {
+
+	notBefore, expiresAt := certificateValidity(testCaCert1)
+	if notBefore != "2026-10-14T04:30:25Z" || expiresAt != "2126-09-20T04:30:25Z" {
+		t.Errorf("expected the validity of the certificate, got not_before: %s expires_at: %s", notBefore, expiresAt)
+	}
+
+	// a chain with the issuer first still reports the leaf
+	caKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ca := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Terraform Test Intermediate"},
+		NotBefore:             time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		NotAfter:              time.Date(2036, 1, 1, 0, 0, 0, 0, time.UTC),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDer, err := x509.CreateCertificate(rand.Reader, ca, ca, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("could not create ca certificate: %v", err)
+	}
+
+	leafKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	leaf := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "api.example.com"},
+		NotBefore:    time.Date(2026, 10, 1, 12, 0, 0, 0, time.FixedZone("CEST", 2*60*60)),
+		NotAfter:     time.Date(2027, 1, 1, 12, 0, 0, 0, time.UTC),
+	}
+	leafDer, err := x509.CreateCertificate(rand.Reader, leaf, ca, &leafKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("could not create leaf certificate: %v", err)
+	}
+
+	chain := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDer})) +
+		string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: leafDer}))
+
+	for _, pemChain := range []string{chain, string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: leafDer})) +
+		string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDer}))} {
+		notBefore, expiresAt = certificateValidity(pemChain)
+		if notBefore != "2026-10-01T10:00:00Z" || expiresAt != "2027-01-01T12:00:00Z" {
+			t.Errorf("expected the validity of the leaf certificate in UTC, got not_before: %s expires_at: %s", notBefore, expiresAt)
+		}
+	}
+
+	if notBefore, expiresAt = certificateValidity("public key --- 123 ----"); notBefore != "" || expiresAt != "" {
+		t.Errorf("expected no validity for a certificate that is not pem, got not_before: %s expires_at: %s", notBefore, expiresAt)
+	}
+}
+
 func testAccCheckKongCertificateDestroy(state *terraform.State) error {
 
-	client := testAccProvider.Meta().(*gokong.KongAdminClient)
+	client := testAccProvider.Meta().(*kongClient)
 
 	certificates := getResourcesByType("kong_certificate", state)
 
@@ -77,6 +385,30 @@ func testAccCheckKongCertificateDestroy(state *terraform.State) error {
 	return nil
 }
 
+func testAccCheckKongCertificateSnis(resourceKey string, expected ...string) resource.TestCheckFunc {
+
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resourceKey]
+
+		if !ok {
+			return fmt.Errorf("not found: %s", resourceKey)
+		}
+
+		snis, err := getKongCertificateSnis(testAccProvider.Meta().(*kongClient), rs.Primary.ID)
+
+		if err != nil {
+			return err
+		}
+
+		sort.Strings(expected)
+		if strings.Join(snis, ",") != strings.Join(expected, ",") {
+			return fmt.Errorf("expected certificate %s to have snis %v but had %v", rs.Primary.ID, expected, snis)
+		}
+
+		return nil
+	}
+}
+
 func testAccCheckKongCertificateExists(resourceKey string) resource.TestCheckFunc {
 
 	return func(s *terraform.State) error {
@@ -90,7 +422,7 @@ func testAccCheckKongCertificateExists(resourceKey string) resource.TestCheckFun
 			return fmt.Errorf("no ID is set")
 		}
 
-		api, err := testAccProvider.Meta().(*gokong.KongAdminClient).Certificates().GetById(rs.Primary.ID)
+		api, err := testAccProvider.Meta().(*kongClient).Certificates().GetById(rs.Primary.ID)
 
 		if err != nil {
 			return err
@@ -116,3 +448,19 @@ resource "kong_certificate" "certificate" {
 	private_key = "private key --- 321 ----"
 }
 `
+
+const testCreateCertificateWithSnisConfig = `
+resource "kong_certificate" "certificate" {
+	certificate = "public key --- 123 ----"
+	private_key = "private key --- 456 ----"
+	snis        = ["a.example.com", "b.example.com"]
+}
+`
+
+const testUpdateCertificateWithSnisConfig = `
+resource "kong_certificate" "certificate" {
+	certificate = "public key --- 123 ----"
+	private_key = "private key --- 456 ----"
+	snis        = ["b.example.com", "c.example.com"]
+}
+`
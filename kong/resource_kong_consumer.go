@@ -28,18 +28,84 @@ func resourceKongConsumer() *schema.Resource {
 				Optional: true,
 				ForceNew: false,
 			},
+			"tags": &schema.Schema{
+				Type:     schema.TypeSet,
+				Optional: true,
+				ForceNew: false,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"meta": &schema.Schema{
+				Type:        schema.TypeMap,
+				Optional:    true,
+				ForceNew:    false,
+				Elem:        schema.TypeString,
+				Description: "Custom metadata of the consumer, only sent to kong enterprise",
+			},
+			"key_auth": &schema.Schema{
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Description: "A key-auth credential managed as part of the consumer's lifecycle",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"key": &schema.Schema{
+							Type:      schema.TypeString,
+							Optional:  true,
+							Computed:  true,
+							Sensitive: true,
+						},
+					},
+				},
+			},
 		},
 	}
 }
 
+type consumerRequest struct {
+	*gokong.ConsumerRequest
+	Tags *[]string         `json:"tags,omitempty"`
+	Meta map[string]string `json:"meta,omitempty"`
+}
+
+type consumer struct {
+	gokong.Consumer
+	Tags []string          `json:"tags"`
+	Meta map[string]string `json:"meta"`
+}
+
+type keyAuthCredential struct {
+	Id  string `json:"id,omitempty"`
+	Key string `json:"key,omitempty"`
+}
+
 func resourceKongConsumerCreate(d *schema.ResourceData, meta interface{}) error {
 
-	consumerRequest := createKongConsumerRequestFromResourceData(d)
+	consumerRequest, err := createKongConsumerRequestFromResourceData(meta.(*kongClient), d)
+	if err != nil {
+		return err
+	}
 
-	consumer, err := meta.(*gokong.KongAdminClient).Consumers().Create(consumerRequest)
+	consumer := &gokong.Consumer{}
+	err = meta.(*kongClient).post(gokong.ConsumersPath, consumerRequest, consumer)
 
 	if err != nil {
-		return fmt.Errorf("failed to create kong consumer: %v error: %v", consumerRequest, err)
+		return fmt.Errorf("failed to create kong consumer: %v error: %v", requestString(consumerRequest), err)
+	}
+
+	if keyAuth := readKeyAuthFromResource(d); keyAuth != nil {
+		credential, err := createKongConsumerKeyAuth(meta.(*kongClient), consumer.Id, keyAuth)
+		if err != nil {
+			// Roll back so we are not left with a consumer that is missing its credential
+			if deleteErr := meta.(*kongClient).delete(gokong.ConsumersPath + consumer.Id); deleteErr != nil {
+				return fmt.Errorf("failed to create kong consumer key auth: %v, and could not remove consumer %s: %v", err, consumer.Id, deleteErr)
+			}
+			return fmt.Errorf("failed to create kong consumer key auth: %v", err)
+		}
+		d.Set("key_auth", flattenKeyAuth(credential))
 	}
 
 	d.SetId(consumer.Id)
@@ -50,31 +116,69 @@ func resourceKongConsumerCreate(d *schema.ResourceData, meta interface{}) error
 func resourceKongConsumerUpdate(d *schema.ResourceData, meta interface{}) error {
 	d.Partial(false)
 
-	consumerRequest := createKongConsumerRequestFromResourceData(d)
+	consumerRequest, err := createKongConsumerRequestFromResourceData(meta.(*kongClient), d)
+	if err != nil {
+		return err
+	}
 
-	_, err := meta.(*gokong.KongAdminClient).Consumers().UpdateById(d.Id(), consumerRequest)
+	tags := consumerRequest.Tags
+	consumerRequest.Tags = nil
+
+	err = meta.(*kongClient).patch(gokong.ConsumersPath+d.Id(), consumerRequest, nil)
 
 	if err != nil {
 		return fmt.Errorf("error updating kong consumer: %s", err)
 	}
 
+	if tags != nil && d.HasChange("tags") {
+		if err := syncTags(meta.(*kongClient), "consumers", d.Id(), *tags); err != nil {
+			return fmt.Errorf("error updating kong consumer: %s", err)
+		}
+	}
+
+	if d.HasChange("key_auth") {
+		if err := updateKongConsumerKeyAuth(d, meta.(*kongClient)); err != nil {
+			return fmt.Errorf("error updating kong consumer key auth: %s", err)
+		}
+	}
+
 	return resourceKongConsumerRead(d, meta)
 }
 
 func resourceKongConsumerRead(d *schema.ResourceData, meta interface{}) error {
 
 	id := d.Id()
-	consumer, err := meta.(*gokong.KongAdminClient).Consumers().GetById(id)
+	consumer := &consumer{}
+	found, err := meta.(*kongClient).get(gokong.ConsumersPath+id, consumer)
 
 	if err != nil {
 		return fmt.Errorf("could not find kong consumer with id: %s error: %v", id, err)
 	}
 
-	if consumer == nil {
+	if !found || consumer.Id == "" {
 		d.SetId("")
 	} else {
 		d.Set("username", consumer.Username)
 		d.Set("custom_id", consumer.CustomId)
+		setTagsFromKong(meta.(*kongClient), d, consumer.Tags)
+		// only kong enterprise returns meta, the configured meta is kept otherwise
+		if consumer.Meta != nil {
+			d.Set("meta", consumer.Meta)
+		}
+
+		if keyAuth := readKeyAuthFromResource(d); keyAuth != nil && keyAuth.Id != "" {
+			credential := &keyAuthCredential{}
+			found, err := meta.(*kongClient).get(consumerKeyAuthPath(id)+keyAuth.Id, credential)
+			if err != nil {
+				return fmt.Errorf("could not find kong consumer key auth with id: %s error: %v", keyAuth.Id, err)
+			}
+
+			if found {
+				d.Set("key_auth", flattenKeyAuth(credential))
+			} else {
+				d.Set("key_auth", nil)
+			}
+		}
 	}
 
 	return nil
@@ -82,7 +186,8 @@ func resourceKongConsumerRead(d *schema.ResourceData, meta interface{}) error {
 
 func resourceKongConsumerDelete(d *schema.ResourceData, meta interface{}) error {
 
-	err := meta.(*gokong.KongAdminClient).Consumers().DeleteById(d.Id())
+	// Kong deletes the consumer's credentials (including key_auth) along with the consumer
+	err := meta.(*kongClient).delete(gokong.ConsumersPath + d.Id())
 
 	if err != nil {
 		return fmt.Errorf("could not delete kong consumer: %v", err)
@@ -91,12 +196,105 @@ func resourceKongConsumerDelete(d *schema.ResourceData, meta interface{}) error
 	return nil
 }
 
-func createKongConsumerRequestFromResourceData(d *schema.ResourceData) *gokong.ConsumerRequest {
+func createKongConsumerRequestFromResourceData(client *kongClient, d *schema.ResourceData) (*consumerRequest, error) {
 
-	consumerRequest := &gokong.ConsumerRequest{}
+	consumerRequest := &consumerRequest{ConsumerRequest: &gokong.ConsumerRequest{}}
 
 	consumerRequest.Username = readStringFromResource(d, "username")
 	consumerRequest.CustomId = readStringFromResource(d, "custom_id")
 
-	return consumerRequest
+	tags, err := readTagsFromResource(client, "kong_consumer", d)
+	if err != nil {
+		return nil, fmt.Errorf("could not check kong version for tags: %v", err)
+	}
+	consumerRequest.Tags = tags
+
+	// an empty meta is sent when it was removed so kong clears it
+	if meta := readConsumerMetaFromResource(d); len(meta) > 0 || d.HasChange("meta") {
+		if err := client.requireEnterprise("kong_consumer meta"); err != nil {
+			return nil, err
+		}
+		consumerRequest.Meta = meta
+	}
+
+	return consumerRequest, nil
+}
+
+func readConsumerMetaFromResource(d *schema.ResourceData) map[string]string {
+	meta := map[string]string{}
+	for key, value := range readMapFromResource(d, "meta") {
+		meta[key] = value.(string)
+	}
+	return meta
+}
+
+func consumerKeyAuthPath(consumerId string) string {
+	return "/consumers/" + consumerId + "/key-auth/"
+}
+
+func readKeyAuthFromResource(d *schema.ResourceData) *keyAuthCredential {
+	if attr, ok := d.GetOk("key_auth"); ok {
+		items := attr.([]interface{})
+		if len(items) == 1 && items[0] != nil {
+			keyAuth := items[0].(map[string]interface{})
+			return &keyAuthCredential{
+				Id:  keyAuth["id"].(string),
+				Key: keyAuth["key"].(string),
+			}
+		}
+		return &keyAuthCredential{}
+	}
+	return nil
+}
+
+func flattenKeyAuth(credential *keyAuthCredential) []interface{} {
+	return []interface{}{
+		map[string]interface{}{
+			"id":  credential.Id,
+			"key": credential.Key,
+		},
+	}
+}
+
+func createKongConsumerKeyAuth(client *kongClient, consumerId string, keyAuth *keyAuthCredential) (*keyAuthCredential, error) {
+	credential := &keyAuthCredential{}
+	err := client.post(consumerKeyAuthPath(consumerId), &keyAuthCredential{Key: keyAuth.Key}, credential)
+	if err != nil {
+		return nil, err
+	}
+
+	if credential.Id == "" {
+		return nil, fmt.Errorf("kong did not return an id for the key auth credential")
+	}
+
+	return credential, nil
+}
+
+func updateKongConsumerKeyAuth(d *schema.ResourceData, client *kongClient) error {
+	old, _ := d.GetChange("key_auth")
+	var oldId string
+	if items := old.([]interface{}); len(items) == 1 && items[0] != nil {
+		oldId = items[0].(map[string]interface{})["id"].(string)
+	}
+
+	keyAuth := readKeyAuthFromResource(d)
+
+	switch {
+	case keyAuth == nil && oldId != "":
+		return client.delete(consumerKeyAuthPath(d.Id()) + oldId)
+	case keyAuth != nil && oldId == "":
+		credential, err := createKongConsumerKeyAuth(client, d.Id(), keyAuth)
+		if err != nil {
+			return err
+		}
+		d.Set("key_auth", flattenKeyAuth(credential))
+	case keyAuth != nil:
+		credential := &keyAuthCredential{}
+		if err := client.patch(consumerKeyAuthPath(d.Id())+oldId, &keyAuthCredential{Key: keyAuth.Key}, credential); err != nil {
+			return err
+		}
+		d.Set("key_auth", flattenKeyAuth(credential))
+	}
+
+	return nil
 }
@@ -0,0 +1,196 @@
+package kong
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// consumer group overrides were added to the admin api of kong enterprise in 3.0
+const consumerGroupsMinimumKongVersion = "3.0.0"
+
+const rateLimitingAdvancedPluginName = "rate-limiting-advanced"
+
+type consumerGroupPluginOverride struct {
+	Config map[string]interface{} `json:"config"`
+}
+
+func resourceKongConsumerGroupPluginOverride() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceKongConsumerGroupPluginOverrideCreate,
+		Read:   resourceKongConsumerGroupPluginOverrideRead,
+		Delete: resourceKongConsumerGroupPluginOverrideDelete,
+		Update: resourceKongConsumerGroupPluginOverrideUpdate,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"consumer_group_id": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"plugin_name": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+				Default:  rateLimitingAdvancedPluginName,
+			},
+			"config_json": &schema.Schema{
+				Type:             schema.TypeString,
+				Required:         true,
+				StateFunc:        normalizeDataJSON,
+				ValidateFunc:     validateDataJSON,
+				Description:      "JSON format of the plugin config used for consumers in the group",
+				DiffSuppressFunc: suppressEquivalentConfigJson,
+			},
+		},
+	}
+}
+
+func consumerGroupPluginOverrideId(consumerGroupId string, pluginName string) string {
+	return consumerGroupId + "|" + pluginName
+}
+
+func consumerGroupPluginOverridePath(id string) (string, error) {
+	idSplit := strings.Split(id, "|")
+
+	if len(idSplit) != 2 || idSplit[0] == "" || idSplit[1] == "" {
+		return "", fmt.Errorf("kong consumer group plugin override id should be pipe separated as consumerGroupId|pluginName found: %v", id)
+	}
+
+	return "/consumer_groups/" + idSplit[0] + "/overrides/plugins/" + idSplit[1], nil
+}
+
+func resourceKongConsumerGroupPluginOverrideCreate(d *schema.ResourceData, meta interface{}) error {
+
+	overrideRequest, err := createKongConsumerGroupPluginOverrideRequestFromResourceData(d)
+	if err != nil {
+		return err
+	}
+
+	client := meta.(*kongClient)
+	if err := client.requireVersion("kong_consumer_group_plugin_override", consumerGroupsMinimumKongVersion); err != nil {
+		return err
+	}
+
+	id := consumerGroupPluginOverrideId(readStringFromResource(d, "consumer_group_id"), readStringFromResource(d, "plugin_name"))
+	path, err := consumerGroupPluginOverridePath(id)
+	if err != nil {
+		return err
+	}
+
+	if err := client.put(path, overrideRequest, nil); err != nil {
+		return fmt.Errorf("failed to create kong consumer group plugin override: %v error: %v", id, err)
+	}
+
+	d.SetId(id)
+
+	return resourceKongConsumerGroupPluginOverrideRead(d, meta)
+}
+
+func resourceKongConsumerGroupPluginOverrideUpdate(d *schema.ResourceData, meta interface{}) error {
+	d.Partial(false)
+
+	overrideRequest, err := createKongConsumerGroupPluginOverrideRequestFromResourceData(d)
+	if err != nil {
+		return err
+	}
+
+	path, err := consumerGroupPluginOverridePath(d.Id())
+	if err != nil {
+		return err
+	}
+
+	if err := meta.(*kongClient).put(path, overrideRequest, nil); err != nil {
+		return fmt.Errorf("error updating kong consumer group plugin override: %s", err)
+	}
+
+	return resourceKongConsumerGroupPluginOverrideRead(d, meta)
+}
+
+func resourceKongConsumerGroupPluginOverrideRead(d *schema.ResourceData, meta interface{}) error {
+
+	path, err := consumerGroupPluginOverridePath(d.Id())
+	if err != nil {
+		return err
+	}
+
+	client := meta.(*kongClient)
+	override := &consumerGroupPluginOverride{}
+	found, err := client.get(path, override)
+
+	if err != nil {
+		return fmt.Errorf("could not find kong consumer group plugin override: %v", err)
+	}
+
+	if !found || override.Config == nil {
+		d.SetId("")
+		return nil
+	}
+
+	idSplit := strings.Split(d.Id(), "|")
+	configured := map[string]interface{}{}
+	json.Unmarshal([]byte(readStringFromResource(d, "config_json")), &configured)
+	client.coercePluginConfigBooleans(idSplit[1], override.Config, configured)
+	keepEncryptedConfigValues(override.Config, configured)
+
+	d.Set("consumer_group_id", idSplit[0])
+	d.Set("plugin_name", idSplit[1])
+	d.Set("config_json", client.formatConfigJson(pluginConfigJsonToString(override.Config, client.pluginComputedProperties(idSplit[1]))))
+
+	return nil
+}
+
+func resourceKongConsumerGroupPluginOverrideDelete(d *schema.ResourceData, meta interface{}) error {
+
+	path, err := consumerGroupPluginOverridePath(d.Id())
+	if err != nil {
+		return err
+	}
+
+	if err := meta.(*kongClient).delete(path); err != nil {
+		return fmt.Errorf("could not delete kong consumer group plugin override: %v", err)
+	}
+
+	return nil
+}
+
+func createKongConsumerGroupPluginOverrideRequestFromResourceData(d *schema.ResourceData) (*consumerGroupPluginOverride, error) {
+
+	config := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(readStringFromResource(d, "config_json")), &config); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config_json, err: %v", err)
+	}
+
+	if readStringFromResource(d, "plugin_name") == rateLimitingAdvancedPluginName {
+		if err := validateRateLimitingAdvancedWindows(config); err != nil {
+			return nil, err
+		}
+	}
+
+	return &consumerGroupPluginOverride{Config: config}, nil
+}
+
+// kong pairs each limit with the window_size at the same index, so both have to be arrays of the same length
+func validateRateLimitingAdvancedWindows(config map[string]interface{}) error {
+	limits, limitsOk := config["limit"].([]interface{})
+	windowSizes, windowSizesOk := config["window_size"].([]interface{})
+
+	if !limitsOk || !windowSizesOk {
+		return fmt.Errorf("rate-limiting-advanced override config must have limit and window_size arrays")
+	}
+
+	if len(limits) != len(windowSizes) {
+		return fmt.Errorf("rate-limiting-advanced override config limit and window_size must be the same length, limit has %d entries and window_size has %d", len(limits), len(windowSizes))
+	}
+
+	if len(limits) == 0 {
+		return fmt.Errorf("rate-limiting-advanced override config limit and window_size must not be empty")
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package kong
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/kevholditch/gokong"
+)
+
+func TestAccKongConsumerGroupPluginOverrideMismatchedWindows(t *testing.T) {
+
+	// the override is rejected before anything is sent to kong so there is nothing for a destroy check to look up
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      testCreateConsumerGroupPluginOverrideMismatchedConfig,
+				ExpectError: regexp.MustCompile("limit and window_size must be the same length, limit has 2 entries and window_size has 1"),
+			},
+		},
+	})
+}
+
+func TestKongConsumerGroupPluginOverrideCreate(t *testing.T) {
+
+	var putBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/":
+			w.Write([]byte(`{"version":"3.4.3.1-enterprise-edition"}`))
+		case r.URL.Path == "/consumer_groups/gold/overrides/plugins/rate-limiting-advanced" && r.Method == http.MethodPut:
+			body, _ := ioutil.ReadAll(r.Body)
+			putBody = string(body)
+			w.Write([]byte(`{}`))
+		case r.URL.Path == "/consumer_groups/gold/overrides/plugins/rate-limiting-advanced":
+			w.Write([]byte(`{"consumer_group":"gold","plugin":"rate-limiting-advanced","config":{"id":"override-id","created_at":1700000000,"limit":[10,100],"window_size":[60,3600],"window_type":"sliding"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	d := schema.TestResourceDataRaw(t, resourceKongConsumerGroupPluginOverride().Schema, map[string]interface{}{
+		"consumer_group_id": "gold",
+		"config_json":       `{"limit":[10,100],"window_size":[60,3600],"window_type":"sliding"}`,
+	})
+
+	if err := resourceKongConsumerGroupPluginOverrideCreate(d, newKongClient(&gokong.Config{HostAddress: server.URL})); err != nil {
+		t.Fatalf("could not create override: %v", err)
+	}
+
+	if expected := `{"config":{"limit":[10,100],"window_size":[60,3600],"window_type":"sliding"}}`; putBody != expected {
+		t.Errorf("expected override %s to be sent but was %s", expected, putBody)
+	}
+
+	if d.Id() != "gold|rate-limiting-advanced" {
+		t.Errorf("expected id gold|rate-limiting-advanced but was %s", d.Id())
+	}
+
+	if expected := `{"limit":[10,100],"window_size":[60,3600],"window_type":"sliding"}`; d.Get("config_json") != expected {
+		t.Errorf("expected config_json %s without the computed properties but was %s", expected, d.Get("config_json"))
+	}
+}
+
+func TestValidateRateLimitingAdvancedWindows(t *testing.T) {
+
+	valid := []map[string]interface{}{
+		{"limit": []interface{}{10.0}, "window_size": []interface{}{60.0}},
+		{"limit": []interface{}{10.0, 100.0}, "window_size": []interface{}{60.0, 3600.0}, "window_type": "fixed"},
+	}
+
+	for _, config := range valid {
+		if err := validateRateLimitingAdvancedWindows(config); err != nil {
+			t.Errorf("expected config %v to be valid, got: %v", config, err)
+		}
+	}
+
+	invalid := []map[string]interface{}{
+		{"limit": []interface{}{10.0, 100.0}, "window_size": []interface{}{60.0}},
+		{"limit": []interface{}{}, "window_size": []interface{}{}},
+		{"limit": 10.0, "window_size": []interface{}{60.0}},
+		{"window_size": []interface{}{60.0}},
+	}
+
+	for _, config := range invalid {
+		if err := validateRateLimitingAdvancedWindows(config); err == nil {
+			t.Errorf("expected config %v to be rejected", config)
+		}
+	}
+}
+
+const testCreateConsumerGroupPluginOverrideMismatchedConfig = `
+resource "kong_consumer_group_plugin_override" "gold" {
+	consumer_group_id = "00000000-0000-0000-0000-000000000000"
+	config_json       = <<EOT
+	{
+	  "limit": [ 10, 100 ],
+	  "window_size": [ 60 ]
+	}
+	EOT
+}
+`
@@ -1,10 +1,11 @@
 package kong
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
 	"log"
+	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/hashicorp/terraform/helper/schema"
@@ -13,14 +14,20 @@ import (
 
 func resourceKongConsumerPluginConfig() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceKongConsumerPluginConfigCreate,
-		Read:   resourceKongConsumerPluginConfigRead,
-		Delete: resourceKongConsumerPluginConfigDelete,
+		Create: withOperationTimeout(schema.TimeoutCreate, resourceKongConsumerPluginConfigCreate),
+		Read:   withOperationTimeout(schema.TimeoutRead, resourceKongConsumerPluginConfigRead),
+		Delete: withOperationTimeout(schema.TimeoutDelete, resourceKongConsumerPluginConfigDelete),
+		Update: withOperationTimeout(schema.TimeoutUpdate, resourceKongConsumerPluginConfigUpdate),
 
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			State: resourceKongConsumerPluginConfigImport,
 		},
 
+		Timeouts: resourceKongTimeouts(),
+
+		SchemaVersion: 1,
+		MigrateState:  resourceKongConsumerPluginConfigMigrateState,
+
 		Schema: map[string]*schema.Schema{
 			"consumer_id": &schema.Schema{
 				Type:     schema.TypeString,
@@ -38,21 +45,35 @@ func resourceKongConsumerPluginConfig() *schema.Resource {
 				Optional:      true,
 				Elem:          schema.TypeString,
 				Default:       nil,
-				ConflictsWith: []string{"config_json"},
+				ConflictsWith: []string{"config_json", "config_json_in_place"},
 			},
 			// Suppress diff when config is empty so we can sync with upstream always
 			// The ForceNew property is what makes this work.
 			"config_json": &schema.Schema{
-				Type:          schema.TypeString,
-				ForceNew:      true,
-				Optional:      true,
-				StateFunc:     normalizeDataJSON,
-				ValidateFunc:  validateDataJSON,
-				ConflictsWith: []string{"config"},
-				Description:   "JSON format of plugin config",
-				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
-					return new == ""
-				},
+				Type:             schema.TypeString,
+				ForceNew:         true,
+				Optional:         true,
+				StateFunc:        normalizeDataJSON,
+				ValidateFunc:     validateDataJSON,
+				ConflictsWith:    []string{"config", "config_json_in_place"},
+				Description:      "JSON format of plugin config",
+				DiffSuppressFunc: suppressEquivalentConfigJson,
+			},
+			// The same as config_json except that changes patch the existing config in place (keeping its id) rather
+			// than replacing it, it can only be used together with allow_config_update.
+			"config_json_in_place": &schema.Schema{
+				Type:             schema.TypeString,
+				Optional:         true,
+				StateFunc:        normalizeDataJSON,
+				ValidateFunc:     validateDataJSON,
+				ConflictsWith:    []string{"config", "config_json"},
+				Description:      "JSON format of plugin config that is updated in place, needs allow_config_update",
+				DiffSuppressFunc: suppressEquivalentConfigJson,
+			},
+			"allow_config_update": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
 			},
 		},
 	}
@@ -95,11 +116,44 @@ func normalizeDataJSON(configI interface{}) string {
 	return string(ret)
 }
 
+// consumerPluginConfigId is the id of a kong_consumer_plugin_config in state, a json object so every part is named and
+// there is nothing a part could contain that would split it wrongly. Ids used to be pipe separated as
+// consumerId|pluginName|id, that is still the format they are imported with and older state is migrated on refresh
+// (see resourceKongConsumerPluginConfigMigrateState).
+type consumerPluginConfigId struct {
+	ConsumerId string `json:"consumer_id"`
+	PluginName string `json:"plugin_name"`
+	Id         string `json:"id"`
+}
+
 func buildId(consumerId, pluginName, configId string) string {
-	return consumerId + "|" + pluginName + "|" + configId
+	id, _ := json.Marshal(&consumerPluginConfigId{ConsumerId: consumerId, PluginName: pluginName, Id: configId})
+	return string(id)
+}
+
+func consumerPluginConfigPath(idFields *idFields) string {
+	return "/consumers/" + idFields.consumerId + "/" + idFields.pluginName + "/" + idFields.id
 }
 
+// splitIdIntoFields reads an id of either format, the json one of state or the pipe separated one of an import
 func splitIdIntoFields(id string) (*idFields, error) {
+	if strings.HasPrefix(id, "{") {
+		configId := &consumerPluginConfigId{}
+		if err := json.Unmarshal([]byte(id), configId); err != nil || configId.ConsumerId == "" || configId.PluginName == "" || configId.Id == "" {
+			return nil, fmt.Errorf("failed to calculate consumer plugin config id, should be a json object with consumer_id, plugin_name and id found: %v", id)
+		}
+
+		return &idFields{
+			consumerId: configId.ConsumerId,
+			pluginName: configId.PluginName,
+			id:         configId.Id,
+		}, nil
+	}
+
+	return splitPipeIdIntoFields(id)
+}
+
+func splitPipeIdIntoFields(id string) (*idFields, error) {
 	idSplit := strings.Split(id, "|")
 
 	if len(idSplit) != 3 {
@@ -113,52 +167,152 @@ func splitIdIntoFields(id string) (*idFields, error) {
 	}, nil
 }
 
-//Create either a key=value based list of parameters or json
+// Create the json body for the plugin config, either from config_json or from the config map. Keys in the map can use
+// dots to build nested objects (e.g. config.minute) and the values, which terraform always gives us as strings, are sent
+// as booleans or numbers when they look like one, except for sensitive keys.
 func generatePluginConfig(configMap map[string]interface{}, configJSON string) (string, error) {
 	if configMap != nil && configJSON != "" {
 		return "", fmt.Errorf("Cannot declare both config and config_json")
 	}
 	if configMap != nil {
-		var buffer bytes.Buffer
-		mapSize := len(configMap)
-		position := 1
+		config := map[string]interface{}{}
 		for key, value := range configMap {
-			buffer.WriteString(key)
-			buffer.WriteString("=")
-			buffer.WriteString(value.(string))
-			if mapSize > 1 && position != mapSize {
-				buffer.WriteString("&")
+			if err := setNestedConfigValue(config, strings.Split(key, "."), inferConfigKeyValue(key, value.(string))); err != nil {
+				return "", fmt.Errorf("invalid config key %s: %v", key, err)
 			}
-			position = position + 1
 		}
-		return buffer.String(), nil
+		rawJson, err := json.Marshal(config)
+		if err != nil {
+			return "", err
+		}
+		return string(rawJson), nil
 	}
 	return configJSON, nil
 }
 
+func setNestedConfigValue(config map[string]interface{}, path []string, value interface{}) error {
+	if len(path) == 1 {
+		if _, isObject := config[path[0]].(map[string]interface{}); isObject {
+			return fmt.Errorf("%s is also used as an object", path[0])
+		}
+		if _, exists := config[path[0]]; exists {
+			return fmt.Errorf("%s is set more than once", path[0])
+		}
+		config[path[0]] = value
+		return nil
+	}
+
+	nested, ok := config[path[0]].(map[string]interface{})
+	if !ok {
+		if _, exists := config[path[0]]; exists {
+			return fmt.Errorf("%s is also used as a value", path[0])
+		}
+		nested = map[string]interface{}{}
+		config[path[0]] = nested
+	}
+
+	return setNestedConfigValue(nested, path[1:], value)
+}
+
+// inferConfigValue turns "true" and "false" into booleans and decimal numbers into numbers, numbers with leading zeros
+// (e.g. "007") stay strings as they are more likely to be an identifier than a number.
+func inferConfigValue(value string) interface{} {
+	switch value {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+
+	if !configNumberPattern.MatchString(value) {
+		return value
+	}
+
+	if number, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return number
+	}
+
+	if number, err := strconv.ParseFloat(value, 64); err == nil {
+		return number
+	}
+
+	return value
+}
+
+// inferConfigKeyValue is inferConfigValue for the value of a dotted config key, the value of a sensitive key (e.g.
+// config.password) is always kept as a string so a numeric password or secret is not sent as a number.
+func inferConfigKeyValue(key string, value string) interface{} {
+	path := strings.Split(key, ".")
+	if isSensitiveConfigKey(path[len(path)-1]) {
+		return value
+	}
+	return inferConfigValue(value)
+}
+
+var configNumberPattern = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?$`)
+
 func resourceKongConsumerPluginConfigCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*kongClient)
 
 	consumerId := readStringFromResource(d, "consumer_id")
 	pluginName := readStringFromResource(d, "plugin_name")
-	config, err := generatePluginConfig(readMapFromResource(d, "config"), readStringFromResource(d, "config_json"))
+	if err := validateConsumerPluginConfigInPlace(d); err != nil {
+		return err
+	}
+	config, err := generatePluginConfig(readMapFromResource(d, "config"), readStringFromResource(d, consumerPluginConfigJsonKey(d)))
 	if err != nil {
 		return fmt.Errorf("error configuring plugin: %v", err)
 	}
-	consumerPluginConfig, err := meta.(*gokong.KongAdminClient).Consumers().CreatePluginConfig(consumerId, pluginName, config)
+	consumerPluginConfig := &gokong.ConsumerPluginConfig{}
+	err = client.post(gokong.ConsumersPath+consumerId+"/"+pluginName, config, consumerPluginConfig)
 	if err != nil {
 		return fmt.Errorf("failed to create kong consumer plugin config, error: %v", err)
 	}
 
-	if consumerPluginConfig == nil {
-		d.SetId("")
-	} else {
-		d.SetId(buildId(consumerId, pluginName, consumerPluginConfig.Id))
+	if consumerPluginConfig.Id == "" {
+		return fmt.Errorf("failed to create kong consumer plugin config, kong did not return an id")
 	}
 
-	return resourceKongConsumerPluginConfigRead(d, meta)
+	d.SetId(buildId(consumerId, pluginName, consumerPluginConfig.Id))
+
+	return resourceKongConsumerPluginConfigRead(d, client)
+}
+
+func resourceKongConsumerPluginConfigUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*kongClient)
+
+	d.Partial(false)
+
+	// config and config_json force a new config, only config_json_in_place is patched
+	if !d.HasChange("config_json_in_place") {
+		return resourceKongConsumerPluginConfigRead(d, client)
+	}
+
+	if err := validateConsumerPluginConfigInPlace(d); err != nil {
+		return err
+	}
+
+	idFields, err := splitIdIntoFields(d.Id())
+
+	if err != nil {
+		return err
+	}
+
+	config, err := generatePluginConfig(nil, readStringFromResource(d, "config_json_in_place"))
+	if err != nil {
+		return fmt.Errorf("error configuring plugin: %v", err)
+	}
+
+	err = client.patch(consumerPluginConfigPath(idFields), config, nil)
+	if err != nil {
+		return fmt.Errorf("error updating kong consumer plugin config: %v", err)
+	}
+
+	return resourceKongConsumerPluginConfigRead(d, client)
 }
 
 func resourceKongConsumerPluginConfigRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*kongClient)
 
 	idFields, err := splitIdIntoFields(d.Id())
 
@@ -166,14 +320,17 @@ func resourceKongConsumerPluginConfigRead(d *schema.ResourceData, meta interface
 		return err
 	}
 
-	consumerPluginConfig, err := meta.(*gokong.KongAdminClient).Consumers().GetPluginConfig(idFields.consumerId, idFields.pluginName, idFields.id)
+	var body json.RawMessage
+	found, err := client.get(consumerPluginConfigPath(idFields), &body)
 
 	if err != nil {
 		return fmt.Errorf("could not find kong consumer plugin config with id: %s error: %v", d.Id(), err)
 	}
 
-	if consumerPluginConfig == nil {
-		return fmt.Errorf("could not configure plugin for kong consumer")
+	if !found {
+		log.Printf("[WARN] kong consumer plugin config %s no longer exists, removing it from state", d.Id())
+		d.SetId("")
+		return nil
 	}
 
 	d.Set("consumer_id", idFields.consumerId)
@@ -182,17 +339,60 @@ func resourceKongConsumerPluginConfigRead(d *schema.ResourceData, meta interface
 	// We sync this property from upstream as a method to allow you to import a resource with the config tracked in
 	// terraform state. We do not track `config` as it will be a source of a perpetual diff.
 	// https://www.terraform.io/docs/extend/best-practices/detecting-drift.html#capture-all-state-in-read
-	upstreamJson, err := consumerPluginConfigJsonToString(consumerPluginConfig.Body)
+	// The keys kong filled in with their defaults are left out when config_json sets the config, only those in state
+	// (the config that was last applied) are kept. The config map and an import keep the whole body.
+	configJsonKey := consumerPluginConfigJsonKey(d)
+	configured := ""
+	if len(readMapFromResource(d, "config")) == 0 {
+		configured = readStringFromResource(d, configJsonKey)
+	}
+	upstreamJson, err := consumerPluginConfigJsonToString(string(body), configured)
 	if err != nil {
 		return fmt.Errorf("could not read in consumer plugin config body: %s error: %v", d.Id(), err)
 	}
 
-	d.Set("config_json", upstreamJson)
+	d.Set(configJsonKey, client.formatConfigJson(upstreamJson))
 
 	return nil
 }
 
+// consumerPluginConfigJsonKey is the attribute that has the json config, config_json_in_place when it is set and
+// config_json otherwise (which is also where an import puts the config).
+func consumerPluginConfigJsonKey(d *schema.ResourceData) string {
+	if readStringFromResource(d, "config_json_in_place") != "" {
+		return "config_json_in_place"
+	}
+	return "config_json"
+}
+
+func validateConsumerPluginConfigInPlace(d *schema.ResourceData) error {
+	if readStringFromResource(d, "config_json_in_place") != "" && !d.Get("allow_config_update").(bool) {
+		return fmt.Errorf("config_json_in_place of kong consumer plugin config can only be used with allow_config_update = true, use config_json otherwise")
+	}
+	return nil
+}
+
+// The import reads the config with resourceKongConsumerPluginConfigRead, so config_json has the computed properties
+// removed the same way as on every later refresh and the first plan after an import is clean.
+func resourceKongConsumerPluginConfigImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+
+	idFields, err := splitIdIntoFields(d.Id())
+	if err != nil {
+		return nil, err
+	}
+
+	d.SetId(buildId(idFields.consumerId, idFields.pluginName, idFields.id))
+	d.Set("allow_config_update", false)
+
+	if err := resourceKongConsumerPluginConfigRead(d, meta); err != nil {
+		return nil, fmt.Errorf("could not import kong consumer plugin config: %v", err)
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
 func resourceKongConsumerPluginConfigDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*kongClient)
 
 	idFields, err := splitIdIntoFields(d.Id())
 
@@ -200,7 +400,7 @@ func resourceKongConsumerPluginConfigDelete(d *schema.ResourceData, meta interfa
 		return err
 	}
 
-	err = meta.(*gokong.KongAdminClient).Consumers().DeletePluginConfig(idFields.consumerId, idFields.pluginName, idFields.id)
+	err = client.delete(consumerPluginConfigPath(idFields))
 
 	if err != nil {
 		return fmt.Errorf("could not delete kong consumer plugin config: %v", err)
@@ -209,8 +409,14 @@ func resourceKongConsumerPluginConfigDelete(d *schema.ResourceData, meta interfa
 	return nil
 }
 
-// Since this config is a schemaless "blob" we have to remove computed properties
-func consumerPluginConfigJsonToString(body string) (string, error) {
+// computedConsumerPluginConfigProperties adds the nested consumer reference kong 1.0 and later return instead of
+// consumer_id
+var computedConsumerPluginConfigProperties = append([]string{"consumer"}, computedPluginProperties...)
+
+// Since this config is a schemaless "blob" we have to remove computed properties. When configured is not empty only
+// the keys it has are kept (see extractJSONPaths), a configured key kong did not return is left out so it shows as a
+// change.
+func consumerPluginConfigJsonToString(body string, configured string) (string, error) {
 	data := map[string]interface{}{}
 	marshalledData := map[string]interface{}{}
 	err := json.Unmarshal([]byte(body), &data)
@@ -218,8 +424,16 @@ func consumerPluginConfigJsonToString(body string) (string, error) {
 		return "", err
 	}
 
+	if configured != "" {
+		configuredData := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(configured), &configuredData); err != nil {
+			return "", err
+		}
+		data, _ = extractJSONPaths(data, configuredData)
+	}
+
 	for key, val := range data {
-		if !contains(computedPluginProperties, key) {
+		if !contains(computedConsumerPluginConfigProperties, key) {
 			marshalledData[key] = val
 		}
 	}
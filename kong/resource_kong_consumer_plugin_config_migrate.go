@@ -0,0 +1,39 @@
+package kong
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform/terraform"
+)
+
+// resourceKongConsumerPluginConfigMigrateState migrates the state of a kong_consumer_plugin_config to the current
+// schema version. Version 0 had pipe separated ids, version 1 has the json ids of consumerPluginConfigId.
+func resourceKongConsumerPluginConfigMigrateState(version int, state *terraform.InstanceState, meta interface{}) (*terraform.InstanceState, error) {
+	switch version {
+	case 0:
+		log.Println("[INFO] found kong_consumer_plugin_config state v0, migrating to v1")
+		return migrateKongConsumerPluginConfigStateV0toV1(state)
+	default:
+		return state, fmt.Errorf("unexpected kong_consumer_plugin_config schema version: %d", version)
+	}
+}
+
+func migrateKongConsumerPluginConfigStateV0toV1(state *terraform.InstanceState) (*terraform.InstanceState, error) {
+	if state.Empty() {
+		return state, nil
+	}
+
+	idFields, err := splitPipeIdIntoFields(state.ID)
+	if err != nil {
+		return state, fmt.Errorf("could not migrate kong consumer plugin config: %v", err)
+	}
+
+	state.ID = buildId(idFields.consumerId, idFields.pluginName, idFields.id)
+	if state.Attributes == nil {
+		state.Attributes = map[string]string{}
+	}
+	state.Attributes["id"] = state.ID
+
+	return state, nil
+}
@@ -0,0 +1,85 @@
+package kong
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/kevholditch/gokong"
+)
+
+func TestKongConsumerPluginConfigMigrateStateV0toV1(t *testing.T) {
+
+	state := &terraform.InstanceState{
+		ID: "consumer-id|jwt|config-id",
+		Attributes: map[string]string{
+			"id":          "consumer-id|jwt|config-id",
+			"consumer_id": "consumer-id",
+			"plugin_name": "jwt",
+			"config_json": `{"key":"my_key"}`,
+		},
+	}
+
+	migrated, err := resourceKongConsumerPluginConfig().MigrateState(0, state, nil)
+	if err != nil {
+		t.Fatalf("could not migrate state: %v", err)
+	}
+
+	expected := `{"consumer_id":"consumer-id","plugin_name":"jwt","id":"config-id"}`
+	if migrated.ID != expected || migrated.Attributes["id"] != expected {
+		t.Errorf("expected the id to be migrated to %s but was %s (attribute %s)", expected, migrated.ID, migrated.Attributes["id"])
+	}
+
+	if migrated.Attributes["config_json"] != `{"key":"my_key"}` || migrated.Attributes["consumer_id"] != "consumer-id" {
+		t.Errorf("expected the other attributes to be kept, got: %v", migrated.Attributes)
+	}
+
+	fields, err := splitIdIntoFields(migrated.ID)
+	if err != nil || *fields != (idFields{consumerId: "consumer-id", pluginName: "jwt", id: "config-id"}) {
+		t.Errorf("expected the migrated id to split into its parts, got: %+v %v", fields, err)
+	}
+
+	if _, err := resourceKongConsumerPluginConfig().MigrateState(0, &terraform.InstanceState{ID: "not-an-id"}, nil); err == nil {
+		t.Errorf("expected an id that is not consumerId|pluginName|id to fail the migration")
+	}
+
+	if empty, err := resourceKongConsumerPluginConfig().MigrateState(0, &terraform.InstanceState{}, nil); err != nil || empty.ID != "" {
+		t.Errorf("expected an empty state to be left as it is, got: %v %v", empty, err)
+	}
+}
+
+func TestKongConsumerPluginConfigMigrateStateOnRefresh(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/consumers/consumer-id/jwt/config-id" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"config-id","created_at":1700000000,"consumer":{"id":"consumer-id"},"key":"my_key"}`))
+	}))
+	defer server.Close()
+
+	state := &terraform.InstanceState{
+		ID:         "consumer-id|jwt|config-id",
+		Attributes: map[string]string{"id": "consumer-id|jwt|config-id", "consumer_id": "consumer-id", "plugin_name": "jwt"},
+	}
+
+	refreshed, err := resourceKongConsumerPluginConfig().Refresh(state, newKongClient(&gokong.Config{HostAddress: server.URL}))
+	if err != nil {
+		t.Fatalf("could not refresh consumer plugin config: %v", err)
+	}
+
+	if expected := buildId("consumer-id", "jwt", "config-id"); refreshed.ID != expected {
+		t.Errorf("expected the refresh to migrate the id to %s but was %s", expected, refreshed.ID)
+	}
+
+	if refreshed.Meta["schema_version"] != "1" {
+		t.Errorf("expected the refreshed state to be schema version 1, got: %v", refreshed.Meta)
+	}
+
+	if refreshed.Attributes["config_json"] != `{"key":"my_key"}` {
+		t.Errorf("expected the config to be read with the migrated id, got: %v", refreshed.Attributes)
+	}
+}
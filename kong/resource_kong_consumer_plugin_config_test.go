@@ -1,10 +1,16 @@
 package kong
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/hashicorp/terraform/config"
 	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/terraform"
 	"github.com/kevholditch/gokong"
 )
@@ -74,15 +80,349 @@ func TestAccKongConsumerPluginConfigImport(t *testing.T) {
 			resource.TestStep{
 				ResourceName:      "kong_consumer_plugin_config.consumer_acl_config",
 				ImportState:       true,
-				ImportStateVerify: false,
+				ImportStateVerify: true,
+				// config is never read back, the imported config is in config_json
+				ImportStateVerifyIgnore: []string{"config"},
 			},
 		},
 	})
 }
 
+func TestKongConsumerPluginConfigImportHasNoDiff(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/consumers/consumer-id/jwt/config-id" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"config-id","created_at":1700000000,"consumer":{"id":"consumer-id"},"algorithm":"HS256","key":"my_key","secret":"my_secret"}`))
+	}))
+	defer server.Close()
+
+	client := newKongClient(&gokong.Config{HostAddress: server.URL})
+	r := resourceKongConsumerPluginConfig()
+
+	if _, err := r.Importer.State(r.Data(&terraform.InstanceState{ID: "not-an-id"}), client); err == nil {
+		t.Errorf("expected an id that is not consumerId|pluginName|id to be rejected")
+	}
+
+	imported, err := r.Importer.State(r.Data(&terraform.InstanceState{ID: "consumer-id|jwt|config-id"}), client)
+	if err != nil {
+		t.Fatalf("could not import consumer plugin config: %v", err)
+	}
+
+	if expected := `{"consumer_id":"consumer-id","plugin_name":"jwt","id":"config-id"}`; imported[0].Id() != expected {
+		t.Errorf("expected the import to set the id %s but was %s", expected, imported[0].Id())
+	}
+
+	if expected := `{"algorithm":"HS256","key":"my_key","secret":"my_secret"}`; imported[0].Get("config_json") != expected {
+		t.Errorf("expected config_json %s without the computed properties but was %s", expected, imported[0].Get("config_json"))
+	}
+
+	rawConfig, err := config.NewRawConfig(map[string]interface{}{
+		"consumer_id": "consumer-id",
+		"plugin_name": "jwt",
+		"config_json": `{"key": "my_key", "secret": "my_secret", "algorithm": "HS256"}`,
+	})
+	if err != nil {
+		t.Fatalf("could not build config: %v", err)
+	}
+
+	diff, err := r.Diff(imported[0].State(), terraform.NewResourceConfig(rawConfig))
+	if err != nil {
+		t.Fatalf("could not diff consumer plugin config: %v", err)
+	}
+
+	if diff != nil && !diff.Empty() {
+		t.Errorf("expected no diff after importing a consumer plugin config, got: %v", diff.Attributes)
+	}
+}
+
+func TestKongConsumerPluginConfigIgnoresKongDefaults(t *testing.T) {
+
+	key := "my_key"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusCreated)
+		}
+		// kong fills in the algorithm and generates a secret for a jwt credential that only sets the key
+		fmt.Fprintf(w, `{"id":"config-id","created_at":1700000000,"consumer":{"id":"consumer-id"},"key":"%s","algorithm":"HS256",`+
+			`"secret":"generated-secret","rsa_public_key":null,"tags":null}`, key)
+	}))
+	defer server.Close()
+
+	client := newKongClient(&gokong.Config{HostAddress: server.URL})
+	r := resourceKongConsumerPluginConfig()
+	raw := map[string]interface{}{
+		"consumer_id": "consumer-id",
+		"plugin_name": "jwt",
+		"config_json": `{"key": "my_key"}`,
+	}
+
+	d := schema.TestResourceDataRaw(t, r.Schema, raw)
+	if err := resourceKongConsumerPluginConfigCreate(d, client); err != nil {
+		t.Fatalf("could not create consumer plugin config: %v", err)
+	}
+
+	if expected := `{"key":"my_key"}`; d.Get("config_json") != expected {
+		t.Errorf("expected config_json %s without the keys kong defaulted but was %s", expected, d.Get("config_json"))
+	}
+
+	rawConfig, err := config.NewRawConfig(raw)
+	if err != nil {
+		t.Fatalf("could not build config: %v", err)
+	}
+
+	diff, err := r.Diff(d.State(), terraform.NewResourceConfig(rawConfig))
+	if err != nil {
+		t.Fatalf("could not diff consumer plugin config: %v", err)
+	}
+	if diff != nil && !diff.Empty() {
+		t.Errorf("expected no diff for the keys kong defaulted, got: %v", diff.Attributes)
+	}
+
+	// a change to a configured key outside of terraform is still read
+	key = "changed_key"
+	refreshed := r.Data(d.State())
+	if err := resourceKongConsumerPluginConfigRead(refreshed, client); err != nil {
+		t.Fatalf("could not read consumer plugin config: %v", err)
+	}
+	if expected := `{"key":"changed_key"}`; refreshed.Get("config_json") != expected {
+		t.Errorf("expected config_json %s to show the changed key but was %s", expected, refreshed.Get("config_json"))
+	}
+}
+
+func TestAccKongConsumerPluginConfigUpdateModes(t *testing.T) {
+
+	var recreatedId, patchedId string
+
+	resource.Test(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckKongConsumerPluginConfig,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testConsumerPluginConfigUpdateMode, "false", "config_json", "my_key"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckKongConsumerPluginConfigExists("kong_consumer_plugin_config.consumer_jwt_config"),
+					testAccCheckKongConsumerPluginConfigId("kong_consumer_plugin_config.consumer_jwt_config", &recreatedId, false),
+				),
+			},
+			{
+				// Without allow_config_update the config is deleted and created again so it gets a new id
+				Config: fmt.Sprintf(testConsumerPluginConfigUpdateMode, "false", "config_json", "updated_key"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckKongConsumerPluginConfigExists("kong_consumer_plugin_config.consumer_jwt_config"),
+					resource.TestCheckResourceAttr("kong_consumer_plugin_config.consumer_jwt_config", "config_json", `{"algorithm":"HS256","key":"updated_key","secret":"my_secret"}`),
+					testAccCheckKongConsumerPluginConfigId("kong_consumer_plugin_config.consumer_jwt_config", &recreatedId, false),
+				),
+			},
+			{
+				Config: fmt.Sprintf(testConsumerPluginConfigUpdateMode, "true", "config_json_in_place", "updated_key"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckKongConsumerPluginConfigExists("kong_consumer_plugin_config.consumer_jwt_config"),
+					testAccCheckKongConsumerPluginConfigId("kong_consumer_plugin_config.consumer_jwt_config", &patchedId, false),
+				),
+			},
+			{
+				// With allow_config_update config_json_in_place is patched in place and keeps its id
+				Config: fmt.Sprintf(testConsumerPluginConfigUpdateMode, "true", "config_json_in_place", "patched_key"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckKongConsumerPluginConfigExists("kong_consumer_plugin_config.consumer_jwt_config"),
+					resource.TestCheckResourceAttr("kong_consumer_plugin_config.consumer_jwt_config", "config_json_in_place", `{"algorithm":"HS256","key":"patched_key","secret":"my_secret"}`),
+					testAccCheckKongConsumerPluginConfigId("kong_consumer_plugin_config.consumer_jwt_config", &patchedId, true),
+				),
+			},
+		},
+	})
+}
+
+func TestKongConsumerPluginConfigUpdateModes(t *testing.T) {
+
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		if r.URL.Path != "/consumers/consumer-id/jwt/config-id" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"config-id","consumer":{"id":"consumer-id"},"algorithm":"HS256","key":"patched_key"}`))
+	}))
+	defer server.Close()
+
+	client := newKongClient(&gokong.Config{HostAddress: server.URL})
+	r := resourceKongConsumerPluginConfig()
+	state := &terraform.InstanceState{
+		ID: buildId("consumer-id", "jwt", "config-id"),
+		Attributes: map[string]string{
+			"consumer_id":          "consumer-id",
+			"plugin_name":          "jwt",
+			"allow_config_update":  "true",
+			"config_json_in_place": `{"key":"my_key"}`,
+		},
+	}
+
+	for _, c := range []struct {
+		attributes  map[string]interface{}
+		requiresNew bool
+	}{
+		// without allow_config_update the config is replaced, create_before_destroy still applies to it
+		{map[string]interface{}{"allow_config_update": false, "config_json": `{"key":"patched_key"}`}, true},
+		{map[string]interface{}{"allow_config_update": true, "config_json_in_place": `{"key":"patched_key"}`}, false},
+	} {
+		c.attributes["consumer_id"] = "consumer-id"
+		c.attributes["plugin_name"] = "jwt"
+		rawConfig, err := config.NewRawConfig(c.attributes)
+		if err != nil {
+			t.Fatalf("could not build config: %v", err)
+		}
+		diff, err := r.Diff(state, terraform.NewResourceConfig(rawConfig))
+		if err != nil {
+			t.Fatalf("could not diff consumer plugin config: %v", err)
+		}
+		if diff.RequiresNew() != c.requiresNew {
+			t.Errorf("expected the change of %v to require a new config: %t, got: %v", c.attributes, c.requiresNew, diff.Attributes)
+		}
+	}
+
+	diff := &terraform.InstanceDiff{Attributes: map[string]*terraform.ResourceAttrDiff{
+		"config_json_in_place": {Old: `{"key":"my_key"}`, New: `{"key":"patched_key"}`},
+	}}
+	newState, err := r.Apply(state, diff, client)
+	if err != nil {
+		t.Fatalf("could not update consumer plugin config in place: %v", err)
+	}
+	if expected := []string{"PATCH /consumers/consumer-id/jwt/config-id", "GET /consumers/consumer-id/jwt/config-id"}; strings.Join(requests, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected the config to be patched with %v, kong was sent: %v", expected, requests)
+	}
+	if newState.ID != state.ID || newState.Attributes["config_json_in_place"] != `{"key":"patched_key"}` {
+		t.Errorf("expected the config to keep its id and have the patched config_json_in_place: %v", newState)
+	}
+
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"consumer_id":          "consumer-id",
+		"plugin_name":          "jwt",
+		"config_json_in_place": `{"key":"my_key"}`,
+	})
+	if err := resourceKongConsumerPluginConfigCreate(d, client); err == nil || !strings.Contains(err.Error(), "allow_config_update") {
+		t.Errorf("expected config_json_in_place without allow_config_update to be rejected, got: %v", err)
+	}
+
+	// a config that was deleted outside of terraform is removed from state so it is created again
+	missing := r.Data(&terraform.InstanceState{ID: buildId("consumer-id", "jwt", "deleted-id")})
+	if err := resourceKongConsumerPluginConfigRead(missing, client); err != nil || missing.Id() != "" {
+		t.Errorf("expected a missing config to be removed from state, got id %q and error: %v", missing.Id(), err)
+	}
+}
+
+func TestAccKongConsumerPluginConfigKVTypes(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckKongConsumerPluginConfig,
+		Steps: []resource.TestStep{
+			{
+				Config: testCreateConsumerPluginConfigKVTypes,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckKongConsumerPluginConfigExists("kong_consumer_plugin_config.consumer_rate_limit"),
+					testAccCheckKongConsumerPluginConfigJsonValue("kong_consumer_plugin_config.consumer_rate_limit", "config.limits.sms.minute", float64(20)),
+					testAccCheckKongConsumerPluginConfigJsonValue("kong_consumer_plugin_config.consumer_rate_limit", "config.fault_tolerant", false),
+				),
+			},
+		},
+	})
+}
+
+func TestGeneratePluginConfig(t *testing.T) {
+
+	config, err := generatePluginConfig(map[string]interface{}{
+		"name":                            "response-ratelimiting",
+		"config.limits.sms.minute":        "20",
+		"config.fault_tolerant":           "false",
+		"config.block_on_first_violation": "true",
+		"config.ratio":                    "0.5",
+		"config.redis_database":           "-1",
+		"config.redis_host":               "redis.example.com",
+		"config.redis_password":           "123456",
+		"config.zip":                      "007",
+	}, "")
+
+	if err != nil {
+		t.Fatalf("could not generate config: %v", err)
+	}
+
+	expected := `{"config":{"block_on_first_violation":true,"fault_tolerant":false,"limits":{"sms":{"minute":20}},"ratio":0.5,"redis_database":-1,"redis_host":"redis.example.com","redis_password":"123456","zip":"007"},"name":"response-ratelimiting"}`
+	if config != expected {
+		t.Errorf("expected config %s but was %s", expected, config)
+	}
+
+	if _, err := generatePluginConfig(map[string]interface{}{"config": "x", "config.minute": "1"}, ""); err == nil {
+		t.Errorf("expected a key used as both a value and an object to be rejected")
+	}
+
+	if config, _ := generatePluginConfig(nil, `{"group":"nginx"}`); config != `{"group":"nginx"}` {
+		t.Errorf("expected config_json to be passed through but was %s", config)
+	}
+}
+
+// testAccCheckKongConsumerPluginConfigJsonValue checks the value (and so its json type) at a dot separated path in
+// config_json
+func testAccCheckKongConsumerPluginConfigJsonValue(resourceKey string, path string, expected interface{}) resource.TestCheckFunc {
+
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resourceKey]
+
+		if !ok {
+			return fmt.Errorf("not found: %s", resourceKey)
+		}
+
+		var value interface{}
+		if err := json.Unmarshal([]byte(rs.Primary.Attributes["config_json"]), &value); err != nil {
+			return fmt.Errorf("could not parse config_json: %v", err)
+		}
+
+		for _, key := range strings.Split(path, ".") {
+			object, ok := value.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("%s not found in config_json %s", path, rs.Primary.Attributes["config_json"])
+			}
+			value = object[key]
+		}
+
+		if value != expected {
+			return fmt.Errorf("expected %s to be %#v but was %#v", path, expected, value)
+		}
+
+		return nil
+	}
+}
+
+// testAccCheckKongConsumerPluginConfigId records the id of the resource in previousId, if previousId was already set
+// it also checks whether the id is still the same as expected
+func testAccCheckKongConsumerPluginConfigId(resourceKey string, previousId *string, expectSame bool) resource.TestCheckFunc {
+
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resourceKey]
+
+		if !ok {
+			return fmt.Errorf("not found: %s", resourceKey)
+		}
+
+		if *previousId != "" && (*previousId == rs.Primary.ID) != expectSame {
+			return fmt.Errorf("expected id equal to previous id %s to be %v, id is %s", *previousId, expectSame, rs.Primary.ID)
+		}
+
+		*previousId = rs.Primary.ID
+
+		return nil
+	}
+}
+
 func testAccCheckKongConsumerPluginConfig(state *terraform.State) error {
 
-	client := testAccProvider.Meta().(*gokong.KongAdminClient)
+	client := testAccProvider.Meta().(*kongClient)
 
 	consumerPluginConfigs := getResourcesByType("kong_consumer_plugin_config", state)
 
@@ -122,7 +462,7 @@ func testAccCheckKongConsumerPluginConfigExists(resourceKey string) resource.Tes
 			return fmt.Errorf("no ID is set")
 		}
 
-		client := testAccProvider.Meta().(*gokong.KongAdminClient)
+		client := testAccProvider.Meta().(*kongClient)
 
 		idFields, err := splitIdIntoFields(rs.Primary.ID)
 
@@ -312,3 +652,47 @@ resource "kong_consumer_plugin_config" "consumer_acl_config" {
 	}
 }
 `
+
+const testConsumerPluginConfigUpdateMode = `
+resource "kong_consumer" "my_consumer" {
+	username  = "User1"
+	custom_id = "123"
+}
+
+resource "kong_plugin" "jwt_plugin" {
+	name        = "jwt"
+	config 		= {
+		claims_to_verify = "exp"
+	}
+}
+
+resource "kong_consumer_plugin_config" "consumer_jwt_config" {
+	consumer_id         = "${kong_consumer.my_consumer.id}"
+	plugin_name         = "jwt"
+	allow_config_update = %s
+	%s = <<EOT
+		{
+			"algorithm": "HS256",
+			"key": "%s",
+			"secret": "my_secret"
+		}
+EOT
+}
+`
+
+const testCreateConsumerPluginConfigKVTypes = `
+resource "kong_consumer" "rate_limited_consumer" {
+	username  = "RateLimitedUser"
+	custom_id = "123"
+}
+
+resource "kong_consumer_plugin_config" "consumer_rate_limit" {
+	consumer_id = "${kong_consumer.rate_limited_consumer.id}"
+	plugin_name = "plugins"
+	config      = {
+		name                     = "response-ratelimiting"
+		config.limits.sms.minute = "20"
+		config.fault_tolerant    = "false"
+	}
+}
+`
@@ -1,10 +1,15 @@
 package kong
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/terraform"
 	"github.com/kevholditch/gokong"
 )
@@ -54,9 +59,220 @@ func TestAccKongConsumerImport(t *testing.T) {
 	})
 }
 
+func TestAccKongConsumerWithKeyAuth(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckKongConsumerDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testCreateConsumerWithKeyAuthConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckKongConsumerExists("kong_consumer.consumer"),
+					testAccCheckKongConsumerKeyAuthExists("kong_consumer.consumer"),
+					resource.TestCheckResourceAttr("kong_consumer.consumer", "key_auth.#", "1"),
+					resource.TestCheckResourceAttr("kong_consumer.consumer", "key_auth.0.key", "my-secret-key"),
+					resource.TestCheckResourceAttrSet("kong_consumer.consumer", "key_auth.0.id"),
+				),
+			},
+			{
+				Config: testUpdateConsumerWithKeyAuthConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckKongConsumerExists("kong_consumer.consumer"),
+					testAccCheckKongConsumerKeyAuthExists("kong_consumer.consumer"),
+					resource.TestCheckResourceAttr("kong_consumer.consumer", "key_auth.0.key", "my-rotated-key"),
+				),
+			},
+			{
+				Config: testCreateConsumerConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckKongConsumerExists("kong_consumer.consumer"),
+					resource.TestCheckResourceAttr("kong_consumer.consumer", "key_auth.#", "0"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccKongConsumerWithTags(t *testing.T) {
+
+	var consumerId string
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheckKongVersion(t, "1.1.0") },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckKongConsumerDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testCreateConsumerWithTagsConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckKongConsumerExists("kong_consumer.consumer"),
+					testAccCheckKongPluginIdUnchanged("kong_consumer.consumer", &consumerId),
+					resource.TestCheckResourceAttr("kong_consumer.consumer", "tags.#", "2"),
+				),
+			},
+			{
+				Config: testUpdateConsumerWithTagsConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckKongConsumerExists("kong_consumer.consumer"),
+					testAccCheckKongPluginIdUnchanged("kong_consumer.consumer", &consumerId),
+					resource.TestCheckResourceAttr("kong_consumer.consumer", "tags.#", "1"),
+				),
+			},
+		},
+	})
+}
+
+// newTestConsumerServer mocks the /consumers endpoints of a kong node reporting version, the last request body is
+// kept in sent
+func newTestConsumerServer(kongVersion string, sent map[string]interface{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.URL.Path == "/" {
+			json.NewEncoder(w).Encode(map[string]string{"version": kongVersion})
+			return
+		}
+
+		if r.Method == http.MethodPost || r.Method == http.MethodPatch {
+			for key := range sent {
+				delete(sent, key)
+			}
+			json.NewDecoder(r.Body).Decode(&sent)
+		}
+
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusCreated)
+		}
+
+		consumer := map[string]interface{}{"id": "consumer-id", "username": sent["username"], "tags": sent["tags"]}
+		if isEnterpriseNode(&nodeInformation{Version: kongVersion}) {
+			consumer["meta"] = sent["meta"]
+		}
+		json.NewEncoder(w).Encode(consumer)
+	}))
+}
+
+func TestKongConsumerTagsAndMeta(t *testing.T) {
+
+	sent := map[string]interface{}{}
+	server := newTestConsumerServer("3.4.3.1", sent)
+	defer server.Close()
+
+	client := newKongClient(&gokong.Config{HostAddress: server.URL})
+	d := schema.TestResourceDataRaw(t, resourceKongConsumer().Schema, map[string]interface{}{
+		"username": "User1",
+		"tags":     []interface{}{"team-a", "team-b"},
+		"meta":     map[string]interface{}{"owner": "team-a"},
+	})
+
+	if err := resourceKongConsumerCreate(d, client); err != nil {
+		t.Fatalf("could not create consumer: %v", err)
+	}
+
+	if tags, ok := sent["tags"].([]interface{}); !ok || len(tags) != 2 {
+		t.Errorf("expected tags to be sent as a json array, got: %v", sent["tags"])
+	}
+
+	if meta, ok := sent["meta"].(map[string]interface{}); !ok || meta["owner"] != "team-a" {
+		t.Errorf("expected meta to be sent to kong enterprise, got: %v", sent["meta"])
+	}
+
+	if d.Get("tags").(*schema.Set).Len() != 2 || d.Get("meta.owner") != "team-a" {
+		t.Errorf("expected tags and meta to be read back, got tags: %v meta: %v", d.Get("tags"), d.Get("meta"))
+	}
+}
+
+func TestKongConsumerMetaRequiresEnterprise(t *testing.T) {
+
+	sent := map[string]interface{}{}
+	server := newTestConsumerServer("3.4.2", sent)
+	defer server.Close()
+
+	client := newKongClient(&gokong.Config{HostAddress: server.URL})
+	d := schema.TestResourceDataRaw(t, resourceKongConsumer().Schema, map[string]interface{}{
+		"username": "User1",
+		"meta":     map[string]interface{}{"owner": "team-a"},
+	})
+
+	err := resourceKongConsumerCreate(d, client)
+
+	if err == nil || !strings.Contains(err.Error(), "kong_consumer meta requires kong enterprise") {
+		t.Errorf("expected consumer meta on open source kong to fail, got: %v", err)
+	}
+
+	if len(sent) != 0 {
+		t.Errorf("expected nothing to be sent to open source kong, got: %v", sent)
+	}
+
+	d = schema.TestResourceDataRaw(t, resourceKongConsumer().Schema, map[string]interface{}{
+		"username": "User1",
+		"tags":     []interface{}{"team-a"},
+	})
+
+	if err := resourceKongConsumerCreate(d, client); err != nil {
+		t.Fatalf("expected a consumer without meta to be created on open source kong: %v", err)
+	}
+
+	if _, ok := sent["meta"]; ok {
+		t.Errorf("expected meta to be left out of the request, got: %v", sent)
+	}
+}
+
+func TestKongConsumerCreateErrorShowsRequest(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodGet {
+			w.Write([]byte(`{"version":"3.4.2"}`))
+			return
+		}
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(`{"message":"UNIQUE violation detected on '{username=\"User1\"}'"}`))
+	}))
+	defer server.Close()
+
+	client := newKongClient(&gokong.Config{HostAddress: server.URL})
+	d := schema.TestResourceDataRaw(t, resourceKongConsumer().Schema, map[string]interface{}{
+		"username":  "User1",
+		"custom_id": "123",
+	})
+
+	err := resourceKongConsumerCreate(d, client)
+
+	if err == nil || !strings.Contains(err.Error(), `"username":"User1","custom_id":"123"`) {
+		t.Errorf("expected the error to show the consumer that could not be created, got: %v", err)
+	}
+}
+
+func testAccCheckKongConsumerKeyAuthExists(resourceKey string) resource.TestCheckFunc {
+
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resourceKey]
+
+		if !ok {
+			return fmt.Errorf("not found: %s", resourceKey)
+		}
+
+		credentialId := rs.Primary.Attributes["key_auth.0.id"]
+
+		found, err := testAccProvider.Meta().(*kongClient).get(consumerKeyAuthPath(rs.Primary.ID)+credentialId, nil)
+
+		if err != nil {
+			return err
+		}
+
+		if !found {
+			return fmt.Errorf("key auth credential with id %v not found for consumer %v", credentialId, rs.Primary.ID)
+		}
+
+		return nil
+	}
+}
+
 func testAccCheckKongConsumerDestroy(state *terraform.State) error {
 
-	client := testAccProvider.Meta().(*gokong.KongAdminClient)
+	client := testAccProvider.Meta().(*kongClient)
 
 	consumers := getResourcesByType("kong_consumer", state)
 
@@ -90,7 +306,7 @@ func testAccCheckKongConsumerExists(resourceKey string) resource.TestCheckFunc {
 			return fmt.Errorf("no ID is set")
 		}
 
-		client := testAccProvider.Meta().(*gokong.KongAdminClient)
+		client := testAccProvider.Meta().(*kongClient)
 
 		api, err := client.Consumers().GetById(rs.Primary.ID)
 
@@ -118,3 +334,35 @@ resource "kong_consumer" "consumer" {
 	custom_id = "456"
 }
 `
+const testCreateConsumerWithKeyAuthConfig = `
+resource "kong_consumer" "consumer" {
+	username  = "User1"
+	custom_id = "123"
+	key_auth {
+		key = "my-secret-key"
+	}
+}
+`
+const testUpdateConsumerWithKeyAuthConfig = `
+resource "kong_consumer" "consumer" {
+	username  = "User1"
+	custom_id = "123"
+	key_auth {
+		key = "my-rotated-key"
+	}
+}
+`
+const testCreateConsumerWithTagsConfig = `
+resource "kong_consumer" "consumer" {
+	username  = "User1"
+	custom_id = "123"
+	tags      = ["team-a", "billing"]
+}
+`
+const testUpdateConsumerWithTagsConfig = `
+resource "kong_consumer" "consumer" {
+	username  = "User1"
+	custom_id = "123"
+	tags      = ["team-a"]
+}
+`
@@ -0,0 +1,119 @@
+package kong
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+const declarativeConfigPath = "/config"
+
+// emptyDeclarativeConfig is loaded on destroy, a declarative config replaces everything kong has loaded so posting one
+// without any entities is how the config is removed.
+const emptyDeclarativeConfig = `{"_format_version":"1.1"}`
+
+type declarativeConfigRequest struct {
+	Config string `json:"config"`
+}
+
+func resourceKongDeclarativeConfig() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceKongDeclarativeConfigCreate,
+		Read:   resourceKongDeclarativeConfigRead,
+		Delete: resourceKongDeclarativeConfigDelete,
+		Update: resourceKongDeclarativeConfigUpdate,
+
+		Schema: map[string]*schema.Schema{
+			"config": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     false,
+				ValidateFunc: validateDeclarativeConfig,
+				Description:  "The full declarative config (YAML or JSON) to load into a kong node running without a database",
+			},
+		},
+	}
+}
+
+func resourceKongDeclarativeConfigCreate(d *schema.ResourceData, meta interface{}) error {
+
+	config := readStringFromResource(d, "config")
+
+	err := loadKongDeclarativeConfig(meta.(*kongClient), config)
+
+	if err != nil {
+		return fmt.Errorf("failed to load kong declarative config, error: %v", err)
+	}
+
+	d.SetId(declarativeConfigId(config))
+
+	return resourceKongDeclarativeConfigRead(d, meta)
+}
+
+func resourceKongDeclarativeConfigUpdate(d *schema.ResourceData, meta interface{}) error {
+	d.Partial(false)
+
+	config := readStringFromResource(d, "config")
+
+	err := loadKongDeclarativeConfig(meta.(*kongClient), config)
+
+	if err != nil {
+		return fmt.Errorf("error updating kong declarative config: %s", err)
+	}
+
+	d.SetId(declarativeConfigId(config))
+
+	return resourceKongDeclarativeConfigRead(d, meta)
+}
+
+// kong does not return the config it was loaded with (entities come back with generated ids and defaults filled in) so
+// there is nothing to reconcile against, the config in state is always the last one the provider loaded.
+func resourceKongDeclarativeConfigRead(d *schema.ResourceData, meta interface{}) error {
+	return nil
+}
+
+func resourceKongDeclarativeConfigDelete(d *schema.ResourceData, meta interface{}) error {
+
+	err := loadKongDeclarativeConfig(meta.(*kongClient), emptyDeclarativeConfig)
+
+	if err != nil {
+		return fmt.Errorf("could not clear kong declarative config: %v", err)
+	}
+
+	return nil
+}
+
+func loadKongDeclarativeConfig(client *kongClient, config string) error {
+	if !client.dbless {
+		return fmt.Errorf("kong_declarative_config can only be used when the provider is configured with dbless = true")
+	}
+
+	return client.post(declarativeConfigPath, &declarativeConfigRequest{Config: config}, nil)
+}
+
+func declarativeConfigId(config string) string {
+	sum := sha256.Sum256([]byte(config))
+	return hex.EncodeToString(sum[:])
+}
+
+// YAML is passed through for kong to validate, only JSON configs (and empty ones) can be checked at plan time
+func validateDeclarativeConfig(v interface{}, k string) ([]string, []error) {
+	config := strings.TrimSpace(v.(string))
+
+	if config == "" {
+		return nil, []error{fmt.Errorf("%s must not be empty", k)}
+	}
+
+	if strings.HasPrefix(config, "{") {
+		var data map[string]interface{}
+		if err := json.Unmarshal([]byte(config), &data); err != nil {
+			return nil, []error{fmt.Errorf("%s looks like JSON but could not be parsed: %v", k, err)}
+		}
+	}
+
+	return nil, nil
+}
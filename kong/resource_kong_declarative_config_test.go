@@ -0,0 +1,121 @@
+package kong
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccKongDeclarativeConfig(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheckKongDbless(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckKongDeclarativeConfigDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testCreateDeclarativeConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckKongDeclarativeServiceExists("declarative-service", "example.com"),
+					testAccCheckKongDeclarativeServiceNotExists("declarative-service-2"),
+				),
+			},
+			{
+				Config: testUpdateDeclarativeConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckKongDeclarativeServiceExists("declarative-service", "example.org"),
+					testAccCheckKongDeclarativeServiceExists("declarative-service-2", "example.net"),
+				),
+			},
+		},
+	})
+}
+
+func TestValidateDeclarativeConfig(t *testing.T) {
+
+	for _, config := range []string{"_format_version: \"1.1\"\n", `{"_format_version":"1.1"}`} {
+		if _, errors := validateDeclarativeConfig(config, "config"); len(errors) != 0 {
+			t.Errorf("expected config %q to be valid, got: %v", config, errors)
+		}
+	}
+
+	for _, config := range []string{"", "  \n", `{"_format_version":`} {
+		if _, errors := validateDeclarativeConfig(config, "config"); len(errors) != 1 {
+			t.Errorf("expected config %q to be rejected, got: %v", config, errors)
+		}
+	}
+}
+
+func testAccCheckKongDeclarativeConfigDestroy(state *terraform.State) error {
+
+	configs := getResourcesByType("kong_declarative_config", state)
+
+	if len(configs) != 1 {
+		return fmt.Errorf("expecting only 1 declarative config resource found %v", len(configs))
+	}
+
+	return testAccCheckKongDeclarativeServiceNotExists("declarative-service")(state)
+}
+
+func testAccCheckKongDeclarativeServiceExists(name string, host string) resource.TestCheckFunc {
+
+	return func(s *terraform.State) error {
+		service, err := testAccProvider.Meta().(*kongClient).Services().GetServiceByName(name)
+
+		if err != nil {
+			return err
+		}
+
+		if service == nil {
+			return fmt.Errorf("service %v from the declarative config not found", name)
+		}
+
+		if service.Host == nil || *service.Host != host {
+			return fmt.Errorf("expected service %v to have host %v but was %v", name, host, service.Host)
+		}
+
+		return nil
+	}
+}
+
+func testAccCheckKongDeclarativeServiceNotExists(name string) resource.TestCheckFunc {
+
+	return func(s *terraform.State) error {
+		service, err := testAccProvider.Meta().(*kongClient).Services().GetServiceByName(name)
+
+		if err != nil {
+			return err
+		}
+
+		if service != nil {
+			return fmt.Errorf("service %v still exists, %+v", name, service)
+		}
+
+		return nil
+	}
+}
+
+const testCreateDeclarativeConfig = `
+resource "kong_declarative_config" "config" {
+	config = <<EOF
+_format_version: "1.1"
+services:
+- name: declarative-service
+  url: http://example.com/
+EOF
+}
+`
+const testUpdateDeclarativeConfig = `
+resource "kong_declarative_config" "config" {
+	config = <<EOF
+_format_version: "1.1"
+services:
+- name: declarative-service
+  url: http://example.org/
+- name: declarative-service-2
+  url: http://example.net/
+EOF
+}
+`
@@ -0,0 +1,215 @@
+package kong
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/kevholditch/gokong"
+)
+
+// resourceKongGlobalPlugin is a plugin without a scope, so it runs for every service and route including the ones
+// created later. Kong allows one global plugin of each name, creating one that already exists adopts it and replaces
+// its config. An adopted plugin was not created by terraform, destroying the resource leaves it in kong.
+func resourceKongGlobalPlugin() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceKongGlobalPluginCreate,
+		Read:   resourceKongGlobalPluginRead,
+		Delete: resourceKongGlobalPluginDelete,
+		Update: resourceKongGlobalPluginUpdate,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"config_json": &schema.Schema{
+				Type:             schema.TypeString,
+				Optional:         true,
+				StateFunc:        normalizeDataJSON,
+				ValidateFunc:     validateDataJSON,
+				Description:      "plugin configuration in JSON format, configuration must be a valid JSON object.",
+				DiffSuppressFunc: suppressEquivalentConfigJson,
+			},
+			"enabled": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+			// true when kong already had a global plugin of the name when it was created, that plugin was adopted
+			// and its config replaced with this one
+			"adopted": &schema.Schema{
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+		},
+	}
+}
+
+type globalPluginRequest struct {
+	Name    string                 `json:"name,omitempty"`
+	Config  map[string]interface{} `json:"config,omitempty"`
+	Enabled bool                   `json:"enabled"`
+}
+
+func resourceKongGlobalPluginCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*kongClient)
+
+	pluginRequest, err := createKongGlobalPluginRequestFromResourceData(d)
+	if err != nil {
+		return err
+	}
+
+	if err := validatePluginConfigSchema(meta.(*kongClient), pluginRequest.Name, pluginRequest.Config); err != nil {
+		return err
+	}
+
+	plugin := &gokong.Plugin{}
+	err = client.post(gokong.PluginsPath, pluginRequest, plugin)
+
+	// kong responds with a 409 when there is a global plugin of the name already
+	if err, ok := err.(*responseError); ok && err.status == http.StatusConflict {
+		existing, findErr := findKongGlobalPlugin(client, pluginRequest.Name)
+		if findErr != nil {
+			return fmt.Errorf("failed to create kong global plugin %s: %v, and could not find the existing one: %v", pluginRequest.Name, err, findErr)
+		}
+		if existing == nil {
+			return fmt.Errorf("failed to create kong global plugin %s: %v", pluginRequest.Name, err)
+		}
+
+		log.Printf("[INFO] kong global plugin %s already exists, adopting %s", pluginRequest.Name, existing.Id)
+		d.SetId(existing.Id)
+		d.Set("adopted", true)
+
+		if err := updateKongGlobalPlugin(client, d.Id(), pluginRequest); err != nil {
+			return err
+		}
+		return resourceKongGlobalPluginRead(d, meta)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to create kong global plugin: %s error: %v", pluginRequest.Name, err)
+	}
+
+	d.SetId(plugin.Id)
+	d.Set("adopted", false)
+
+	return resourceKongGlobalPluginRead(d, meta)
+}
+
+func resourceKongGlobalPluginUpdate(d *schema.ResourceData, meta interface{}) error {
+	d.Partial(false)
+
+	pluginRequest, err := createKongGlobalPluginRequestFromResourceData(d)
+	if err != nil {
+		return err
+	}
+
+	if err := validatePluginConfigSchema(meta.(*kongClient), pluginRequest.Name, pluginRequest.Config); err != nil {
+		return err
+	}
+
+	if err := updateKongGlobalPlugin(meta.(*kongClient), d.Id(), pluginRequest); err != nil {
+		return err
+	}
+
+	return resourceKongGlobalPluginRead(d, meta)
+}
+
+func resourceKongGlobalPluginDelete(d *schema.ResourceData, meta interface{}) error {
+	if d.Get("adopted").(bool) {
+		log.Printf("[INFO] kong global plugin %s was adopted, it is left in kong and only removed from state", d.Id())
+		return nil
+	}
+
+	return resourceKongPluginDelete(d, meta)
+}
+
+// updateKongGlobalPlugin sends the whole config, the name can not change
+func updateKongGlobalPlugin(client *kongClient, id string, pluginRequest *globalPluginRequest) error {
+	update := &globalPluginRequest{Config: pluginRequest.Config, Enabled: pluginRequest.Enabled}
+	if err := client.patch(gokong.PluginsPath+id, update, nil); err != nil {
+		return fmt.Errorf("error updating kong global plugin %s: %v", id, err)
+	}
+	return nil
+}
+
+func resourceKongGlobalPluginRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*kongClient)
+
+	plugin, err := getKongScopedPlugin(client, d.Id())
+	if err != nil {
+		return fmt.Errorf("could not find kong global plugin: %v", err)
+	}
+
+	if plugin == nil {
+		d.SetId("")
+		return nil
+	}
+
+	// only the configured keys are kept, the rest of the config are kong's defaults. An import keeps the whole config.
+	config := plugin.Config
+	configured := map[string]interface{}{}
+	json.Unmarshal([]byte(readStringFromResource(d, "config_json")), &configured)
+	client.coercePluginConfigBooleans(plugin.Name, config, configured)
+	keepEncryptedConfigValues(config, configured)
+	if len(configured) > 0 {
+		config, _ = extractJSONPaths(copyJSONObject(config), configured)
+	}
+
+	d.Set("name", plugin.Name)
+	d.Set("enabled", plugin.Enabled)
+	d.Set("config_json", client.formatConfigJson(pluginConfigJsonToString(config, client.pluginComputedProperties(plugin.Name))))
+
+	return nil
+}
+
+// findKongGlobalPlugin returns the plugin of the name that has no scope, nil when there is none. Every plugin is
+// listed as not every kong version can filter the list by name.
+func findKongGlobalPlugin(client *kongClient, name string) (*scopedPlugin, error) {
+	results, err := client.listAll(gokong.PluginsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, result := range results {
+		plugin := &scopedPlugin{}
+		if err := json.Unmarshal(result, plugin); err != nil {
+			return nil, fmt.Errorf("could not parse kong plugin %s: %v", result, err)
+		}
+
+		if plugin.Name == name && isGlobalPlugin(plugin) {
+			return plugin, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func isGlobalPlugin(plugin *scopedPlugin) bool {
+	return firstNonEmpty(plugin.ApiId, plugin.ServiceId, plugin.Service.id(), plugin.RouteId, plugin.Route.id(),
+		plugin.ConsumerId, plugin.Consumer.id(), plugin.ConsumerGroup.id()) == ""
+}
+
+func createKongGlobalPluginRequestFromResourceData(d *schema.ResourceData) (*globalPluginRequest, error) {
+	pluginRequest := &globalPluginRequest{
+		Name:    readStringFromResource(d, "name"),
+		Enabled: d.Get("enabled").(bool),
+	}
+
+	if data := readStringFromResource(d, "config_json"); data != "" {
+		config := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(data), &config); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal config_json, err: %v", err)
+		}
+		pluginRequest.Config = config
+	}
+
+	return pluginRequest, nil
+}
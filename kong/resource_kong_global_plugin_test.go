@@ -0,0 +1,159 @@
+package kong
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/kevholditch/gokong"
+)
+
+// globalPluginServer mocks the plugins of a kong node, a plugin of a name that already has a global plugin is rejected
+// with a 409 the way kong does
+type globalPluginServer struct {
+	*httptest.Server
+	plugins  []map[string]interface{}
+	requests []string
+}
+
+func newGlobalPluginServer(plugins ...map[string]interface{}) *globalPluginServer {
+	server := &globalPluginServer{plugins: plugins}
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		server.requests = append(server.requests, r.Method+" "+r.URL.Path)
+
+		if r.URL.Path == gokong.PluginsPath {
+			if r.Method == http.MethodGet {
+				json.NewEncoder(w).Encode(map[string]interface{}{"data": server.plugins})
+				return
+			}
+
+			request := map[string]interface{}{}
+			json.NewDecoder(r.Body).Decode(&request)
+			for _, plugin := range server.plugins {
+				if plugin["name"] == request["name"] && plugin["service"] == nil && plugin["route"] == nil && plugin["consumer"] == nil {
+					w.WriteHeader(http.StatusConflict)
+					w.Write([]byte(`{"message":"UNIQUE violation detected on '{consumer=null,name=\"` + plugin["name"].(string) + `\",route=null,service=null}'"}`))
+					return
+				}
+			}
+
+			request["id"] = "created-id"
+			server.plugins = append(server.plugins, request)
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(request)
+			return
+		}
+
+		for _, plugin := range server.plugins {
+			if r.URL.Path != gokong.PluginsPath+plugin["id"].(string) {
+				continue
+			}
+			if r.Method == http.MethodPatch {
+				request := map[string]interface{}{}
+				json.NewDecoder(r.Body).Decode(&request)
+				for key, value := range request {
+					plugin[key] = value
+				}
+			}
+			json.NewEncoder(w).Encode(plugin)
+			return
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	return server
+}
+
+func TestKongGlobalPluginCreate(t *testing.T) {
+
+	// a plugin of the name scoped to a service is not the global one
+	server := newGlobalPluginServer(map[string]interface{}{
+		"id": "scoped-id", "name": "correlation-id", "service": map[string]interface{}{"id": "service-id"}, "enabled": true,
+		"config": map[string]interface{}{"header_name": "X-Service-Id"},
+	})
+	defer server.Close()
+
+	d := schema.TestResourceDataRaw(t, resourceKongGlobalPlugin().Schema, map[string]interface{}{
+		"name":        "correlation-id",
+		"config_json": `{"header_name": "X-Request-Id"}`,
+	})
+	if err := resourceKongGlobalPluginCreate(d, newKongClient(&gokong.Config{HostAddress: server.URL})); err != nil {
+		t.Fatalf("could not create global plugin: %v", err)
+	}
+
+	if d.Id() != "created-id" || d.Get("adopted").(bool) {
+		t.Errorf("expected a new global plugin to be created, got id: %s adopted: %v", d.Id(), d.Get("adopted"))
+	}
+	if created := server.plugins[1]; created["service"] != nil || created["route"] != nil || created["consumer"] != nil || created["enabled"] != true {
+		t.Errorf("expected the plugin to be created without a scope, kong was sent: %v", created)
+	}
+	if d.Get("config_json") != `{"header_name":"X-Request-Id"}` {
+		t.Errorf("expected the config to be read back, got: %s", d.Get("config_json"))
+	}
+}
+
+func TestKongGlobalPluginAdoptsExisting(t *testing.T) {
+
+	server := newGlobalPluginServer(map[string]interface{}{
+		"id": "existing-id", "name": "prometheus", "enabled": false,
+		"config": map[string]interface{}{"per_consumer": false, "status_code_metrics": false},
+	})
+	defer server.Close()
+
+	d := schema.TestResourceDataRaw(t, resourceKongGlobalPlugin().Schema, map[string]interface{}{
+		"name":        "prometheus",
+		"config_json": `{"status_code_metrics": true}`,
+	})
+	if err := resourceKongGlobalPluginCreate(d, newKongClient(&gokong.Config{HostAddress: server.URL})); err != nil {
+		t.Fatalf("could not create global plugin: %v", err)
+	}
+
+	if d.Id() != "existing-id" || !d.Get("adopted").(bool) {
+		t.Errorf("expected the existing global plugin to be adopted, got id: %s adopted: %v", d.Id(), d.Get("adopted"))
+	}
+	if len(server.plugins) != 1 {
+		t.Errorf("expected no second global plugin to be created, kong has: %v", server.plugins)
+	}
+
+	// the schema is read to check the config, the 409 of the create is followed by finding the global plugin and
+	// updating it, then it is read
+	expected := "GET /schemas/plugins/prometheus,POST /plugins/,GET /plugins/,PATCH /plugins/existing-id,GET /plugins/existing-id"
+	if requests := strings.Join(server.requests, ","); !strings.HasPrefix(requests, expected) {
+		t.Errorf("expected the requests %s, got: %s", expected, requests)
+	}
+
+	// the adopted plugin gets the config and is enabled
+	if d.Get("config_json") != `{"status_code_metrics":true}` || !d.Get("enabled").(bool) {
+		t.Errorf("expected the config to be set on the adopted plugin, got config_json: %s enabled: %v", d.Get("config_json"), d.Get("enabled"))
+	}
+}
+
+func TestKongGlobalPluginDeleteLeavesAdopted(t *testing.T) {
+
+	server := newGlobalPluginServer(map[string]interface{}{
+		"id": "existing-id", "name": "prometheus", "enabled": true, "config": map[string]interface{}{},
+	})
+	defer server.Close()
+
+	client := newKongClient(&gokong.Config{HostAddress: server.URL})
+	for _, name := range []string{"prometheus", "correlation-id"} {
+		d := schema.TestResourceDataRaw(t, resourceKongGlobalPlugin().Schema, map[string]interface{}{"name": name})
+		if err := resourceKongGlobalPluginCreate(d, client); err != nil {
+			t.Fatalf("could not create global plugin %s: %v", name, err)
+		}
+
+		server.requests = nil
+		if err := resourceKongGlobalPlugin().Delete(d, client); err != nil {
+			t.Fatalf("could not delete global plugin %s: %v", name, err)
+		}
+
+		deleted := strings.Join(server.requests, ",") == "DELETE /plugins/"+d.Id()
+		if adopted := d.Get("adopted").(bool); deleted == adopted {
+			t.Errorf("global plugin %s adopted: %t, expected it to be deleted from kong: %t, kong was sent: %v", name, adopted, !adopted, server.requests)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package kong
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+const keysPath = "/keys/"
+
+type keyPem struct {
+	PrivateKey string `json:"private_key,omitempty"`
+	PublicKey  string `json:"public_key,omitempty"`
+}
+
+// keyRequest sends null for whichever of jwk and pem is not used, so switching between them on update clears the other
+type keyRequest struct {
+	Name string           `json:"name,omitempty"`
+	Kid  string           `json:"kid"`
+	Set  *entityReference `json:"set"`
+	Jwk  *string          `json:"jwk"`
+	Pem  *keyPem          `json:"pem"`
+}
+
+type key struct {
+	Id   string           `json:"id,omitempty"`
+	Name string           `json:"name,omitempty"`
+	Kid  string           `json:"kid,omitempty"`
+	Set  *entityReference `json:"set"`
+	Jwk  string           `json:"jwk,omitempty"`
+	Pem  *keyPem          `json:"pem"`
+}
+
+func resourceKongKey() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceKongKeyCreate,
+		Read:   resourceKongKeyRead,
+		Delete: resourceKongKeyDelete,
+		Update: resourceKongKeyUpdate,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: false,
+			},
+			"kid": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: false,
+			},
+			"set_id": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: false,
+			},
+			"jwk": &schema.Schema{
+				Type:          schema.TypeString,
+				Optional:      true,
+				Sensitive:     true,
+				StateFunc:     normalizeDataJSON,
+				ValidateFunc:  validateDataJSON,
+				ConflictsWith: []string{"pem"},
+				Description:   "The key as a JSON Web Key, it may hold private key material so it is not shown in plans",
+			},
+			"pem": &schema.Schema{
+				Type:          schema.TypeList,
+				Optional:      true,
+				MaxItems:      1,
+				ConflictsWith: []string{"jwk"},
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"private_key": &schema.Schema{
+							Type:      schema.TypeString,
+							Optional:  true,
+							Sensitive: true,
+						},
+						"public_key": &schema.Schema{
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func resourceKongKeyCreate(d *schema.ResourceData, meta interface{}) error {
+
+	client := meta.(*kongClient)
+	if err := client.requireVersion("kong_key", keysMinimumKongVersion); err != nil {
+		return err
+	}
+
+	keyRequest, err := createKongKeyRequestFromResourceData(d)
+	if err != nil {
+		return err
+	}
+
+	key := &key{}
+	err = client.post(keysPath, keyRequest, key)
+
+	// the request is not included in the error as it holds the key material
+	if err != nil {
+		return fmt.Errorf("failed to create kong key %s, error: %v", keyRequest.Kid, err)
+	}
+
+	d.SetId(key.Id)
+
+	return resourceKongKeyRead(d, meta)
+}
+
+func resourceKongKeyUpdate(d *schema.ResourceData, meta interface{}) error {
+	d.Partial(false)
+
+	keyRequest, err := createKongKeyRequestFromResourceData(d)
+	if err != nil {
+		return err
+	}
+
+	err = meta.(*kongClient).patch(keysPath+d.Id(), keyRequest, nil)
+
+	if err != nil {
+		return fmt.Errorf("error updating kong key: %s", err)
+	}
+
+	return resourceKongKeyRead(d, meta)
+}
+
+func resourceKongKeyRead(d *schema.ResourceData, meta interface{}) error {
+
+	key := &key{}
+	found, err := meta.(*kongClient).get(keysPath+d.Id(), key)
+
+	if err != nil {
+		return fmt.Errorf("could not find kong key: %v", err)
+	}
+
+	if !found {
+		d.SetId("")
+	} else {
+		d.Set("name", key.Name)
+		d.Set("kid", key.Kid)
+		d.Set("set_id", key.Set.id())
+
+		if key.Jwk != "" {
+			d.Set("jwk", normalizeDataJSON(key.Jwk))
+		} else {
+			d.Set("jwk", "")
+		}
+
+		if key.Pem != nil {
+			d.Set("pem", []map[string]interface{}{{
+				"private_key": key.Pem.PrivateKey,
+				"public_key":  key.Pem.PublicKey,
+			}})
+		} else {
+			d.Set("pem", nil)
+		}
+	}
+
+	return nil
+}
+
+func resourceKongKeyDelete(d *schema.ResourceData, meta interface{}) error {
+
+	err := meta.(*kongClient).delete(keysPath + d.Id())
+
+	if err != nil {
+		return fmt.Errorf("could not delete kong key: %v", err)
+	}
+
+	return nil
+}
+
+func createKongKeyRequestFromResourceData(d *schema.ResourceData) (*keyRequest, error) {
+
+	keyRequest := &keyRequest{}
+
+	keyRequest.Name = readStringFromResource(d, "name")
+	keyRequest.Kid = readStringFromResource(d, "kid")
+	keyRequest.Set = newEntityReference(readStringFromResource(d, "set_id"))
+	keyRequest.Jwk = readStringPtrFromResource(d, "jwk")
+
+	if pems := d.Get("pem").([]interface{}); len(pems) == 1 && pems[0] != nil {
+		pem := pems[0].(map[string]interface{})
+		keyRequest.Pem = &keyPem{
+			PrivateKey: pem["private_key"].(string),
+			PublicKey:  pem["public_key"].(string),
+		}
+	}
+
+	if keyRequest.Jwk == nil && keyRequest.Pem == nil {
+		return keyRequest, fmt.Errorf("kong key %s needs one of jwk or pem", keyRequest.Kid)
+	}
+
+	return keyRequest, nil
+}
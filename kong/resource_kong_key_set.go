@@ -0,0 +1,146 @@
+package kong
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+const keySetsPath = "/key-sets/"
+
+// key sets and keys were added to the admin api in kong 3.6
+const keysMinimumKongVersion = "3.6.0"
+
+type keySetRequest struct {
+	Name string    `json:"name,omitempty"`
+	Tags *[]string `json:"tags,omitempty"`
+}
+
+type keySet struct {
+	Id   string   `json:"id,omitempty"`
+	Name string   `json:"name,omitempty"`
+	Tags []string `json:"tags"`
+}
+
+func resourceKongKeySet() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceKongKeySetCreate,
+		Read:   resourceKongKeySetRead,
+		Delete: resourceKongKeySetDelete,
+		Update: resourceKongKeySetUpdate,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: false,
+			},
+			"tags": &schema.Schema{
+				Type:     schema.TypeSet,
+				Optional: true,
+				ForceNew: false,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func resourceKongKeySetCreate(d *schema.ResourceData, meta interface{}) error {
+
+	client := meta.(*kongClient)
+	if err := client.requireVersion("kong_key_set", keysMinimumKongVersion); err != nil {
+		return err
+	}
+
+	keySetRequest, err := createKongKeySetRequestFromResourceData(client, d)
+	if err != nil {
+		return err
+	}
+
+	keySet := &keySet{}
+	err = client.post(keySetsPath, keySetRequest, keySet)
+
+	if err != nil {
+		return fmt.Errorf("failed to create kong key set: %v error: %v", requestString(keySetRequest), err)
+	}
+
+	d.SetId(keySet.Id)
+
+	return resourceKongKeySetRead(d, meta)
+}
+
+func resourceKongKeySetUpdate(d *schema.ResourceData, meta interface{}) error {
+	d.Partial(false)
+
+	client := meta.(*kongClient)
+	keySetRequest, err := createKongKeySetRequestFromResourceData(client, d)
+	if err != nil {
+		return err
+	}
+
+	tags := keySetRequest.Tags
+	keySetRequest.Tags = nil
+
+	err = client.patch(keySetsPath+d.Id(), keySetRequest, nil)
+
+	if err != nil {
+		return fmt.Errorf("error updating kong key set: %s", err)
+	}
+
+	if tags != nil && d.HasChange("tags") {
+		if err := syncTags(client, "key-sets", d.Id(), *tags); err != nil {
+			return fmt.Errorf("error updating kong key set: %s", err)
+		}
+	}
+
+	return resourceKongKeySetRead(d, meta)
+}
+
+func resourceKongKeySetRead(d *schema.ResourceData, meta interface{}) error {
+
+	client := meta.(*kongClient)
+	keySet := &keySet{}
+	found, err := client.get(keySetsPath+d.Id(), keySet)
+
+	if err != nil {
+		return fmt.Errorf("could not find kong key set: %v", err)
+	}
+
+	if !found {
+		d.SetId("")
+	} else {
+		d.Set("name", keySet.Name)
+		setTagsFromKong(client, d, keySet.Tags)
+	}
+
+	return nil
+}
+
+func resourceKongKeySetDelete(d *schema.ResourceData, meta interface{}) error {
+
+	err := meta.(*kongClient).delete(keySetsPath + d.Id())
+
+	if err != nil {
+		return fmt.Errorf("could not delete kong key set: %v", err)
+	}
+
+	return nil
+}
+
+func createKongKeySetRequestFromResourceData(client *kongClient, d *schema.ResourceData) (*keySetRequest, error) {
+
+	tags, err := readTagsFromResource(client, "kong_key_set", d)
+	if err != nil {
+		return nil, fmt.Errorf("could not check kong version for tags: %v", err)
+	}
+
+	keySetRequest := &keySetRequest{}
+
+	keySetRequest.Name = readStringFromResource(d, "name")
+	keySetRequest.Tags = tags
+
+	return keySetRequest, nil
+}
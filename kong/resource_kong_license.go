@@ -0,0 +1,100 @@
+package kong
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+const licensesPath = "/licenses/"
+
+type license struct {
+	Id      string `json:"id,omitempty"`
+	Payload string `json:"payload"`
+}
+
+func resourceKongLicense() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceKongLicenseCreate,
+		Read:   resourceKongLicenseRead,
+		Delete: resourceKongLicenseDelete,
+		Update: resourceKongLicenseUpdate,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"payload": &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				Sensitive:   true,
+				Description: "The kong enterprise license json",
+			},
+		},
+	}
+}
+
+func resourceKongLicenseCreate(d *schema.ResourceData, meta interface{}) error {
+
+	client := meta.(*kongClient)
+	if err := client.requireEnterprise("kong_license"); err != nil {
+		return err
+	}
+
+	createdLicense := &license{}
+	err := client.post(licensesPath, &license{Payload: readStringFromResource(d, "payload")}, createdLicense)
+
+	// the payload is left out of the error, it is the license itself
+	if err != nil {
+		return fmt.Errorf("failed to create kong license error: %v", err)
+	}
+
+	d.SetId(createdLicense.Id)
+
+	return resourceKongLicenseRead(d, meta)
+}
+
+func resourceKongLicenseUpdate(d *schema.ResourceData, meta interface{}) error {
+	d.Partial(false)
+
+	client := meta.(*kongClient)
+	if err := client.requireEnterprise("kong_license"); err != nil {
+		return err
+	}
+
+	err := client.patch(licensesPath+d.Id(), &license{Payload: readStringFromResource(d, "payload")}, nil)
+
+	if err != nil {
+		return fmt.Errorf("error updating kong license: %s", err)
+	}
+
+	return resourceKongLicenseRead(d, meta)
+}
+
+func resourceKongLicenseRead(d *schema.ResourceData, meta interface{}) error {
+
+	existingLicense := &license{}
+	found, err := meta.(*kongClient).get(licensesPath+d.Id(), existingLicense)
+
+	if err != nil {
+		return fmt.Errorf("could not find kong license: %v", err)
+	}
+
+	if !found {
+		d.SetId("")
+		return nil
+	}
+
+	d.Set("payload", existingLicense.Payload)
+
+	return nil
+}
+
+func resourceKongLicenseDelete(d *schema.ResourceData, meta interface{}) error {
+
+	if err := meta.(*kongClient).delete(licensesPath + d.Id()); err != nil {
+		return fmt.Errorf("could not delete kong license: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package kong
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/kevholditch/gokong"
+)
+
+const testLicensePayload = `{"license":{"payload":{"customer":"example","license_expiration_date":"2030-01-01"},"signature":"abc","version":"1"}}`
+
+// newTestLicenseServer mocks the /licenses endpoints of a kong node reporting version
+func newTestLicenseServer(t *testing.T, kongVersion string, licenses map[string]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.URL.Path == "/" {
+			json.NewEncoder(w).Encode(map[string]string{"version": kongVersion})
+			return
+		}
+
+		id := strings.TrimPrefix(r.URL.Path, licensesPath)
+		request := &license{}
+		if r.Method == http.MethodPost || r.Method == http.MethodPatch {
+			if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+				t.Fatalf("could not decode license request: %v", err)
+			}
+		}
+
+		switch {
+		case r.Method == http.MethodPost && id == "":
+			id = "license-id"
+			licenses[id] = request.Payload
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodPatch && licenses[id] != "":
+			licenses[id] = request.Payload
+		case r.Method == http.MethodDelete:
+			delete(licenses, id)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		case r.Method == http.MethodGet && licenses[id] != "":
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		json.NewEncoder(w).Encode(&license{Id: id, Payload: licenses[id]})
+	}))
+}
+
+func TestKongLicenseLifecycle(t *testing.T) {
+
+	licenses := map[string]string{}
+	server := newTestLicenseServer(t, "3.4.3.1", licenses)
+	defer server.Close()
+
+	client := newKongClient(&gokong.Config{HostAddress: server.URL})
+	d := schema.TestResourceDataRaw(t, resourceKongLicense().Schema, map[string]interface{}{
+		"payload": testLicensePayload,
+	})
+
+	if err := resourceKongLicenseCreate(d, client); err != nil {
+		t.Fatalf("could not create license: %v", err)
+	}
+
+	if d.Id() != "license-id" || licenses["license-id"] != testLicensePayload {
+		t.Fatalf("expected license-id to be created with the payload, id was %s and licenses %v", d.Id(), licenses)
+	}
+
+	renewed := strings.Replace(testLicensePayload, "2030", "2031", 1)
+	d.Set("payload", renewed)
+	if err := resourceKongLicenseUpdate(d, client); err != nil {
+		t.Fatalf("could not update license: %v", err)
+	}
+
+	if licenses["license-id"] != renewed || d.Get("payload") != renewed {
+		t.Errorf("expected the license to be updated in place, got %v and payload %s", licenses, d.Get("payload"))
+	}
+
+	if err := resourceKongLicenseDelete(d, client); err != nil {
+		t.Fatalf("could not delete license: %v", err)
+	}
+
+	if err := resourceKongLicenseRead(d, client); err != nil {
+		t.Fatalf("could not read deleted license: %v", err)
+	}
+
+	if d.Id() != "" {
+		t.Errorf("expected a deleted license to be removed from state but id was %s", d.Id())
+	}
+}
+
+func TestKongLicenseRequiresEnterprise(t *testing.T) {
+
+	licenses := map[string]string{}
+	server := newTestLicenseServer(t, "3.4.2", licenses)
+	defer server.Close()
+
+	d := schema.TestResourceDataRaw(t, resourceKongLicense().Schema, map[string]interface{}{
+		"payload": testLicensePayload,
+	})
+
+	err := resourceKongLicenseCreate(d, newKongClient(&gokong.Config{HostAddress: server.URL}))
+
+	if err == nil || !strings.Contains(err.Error(), "kong_license requires kong enterprise, kong 3.4.2 is the open source edition") {
+		t.Errorf("expected creating a license on open source kong to fail, got: %v", err)
+	}
+
+	if len(licenses) != 0 {
+		t.Errorf("expected no license to be sent to open source kong, got: %v", licenses)
+	}
+}
@@ -1,8 +1,13 @@
 package kong
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"log"
+	"strings"
 
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/kevholditch/gokong"
@@ -10,20 +15,40 @@ import (
 
 func resourceKongPlugin() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceKongPluginCreate,
-		Read:   resourceKongPluginRead,
-		Delete: resourceKongPluginDelete,
-		Update: resourceKongPluginUpdate,
+		Create: withOperationTimeout(schema.TimeoutCreate, resourceKongPluginCreate),
+		Read:   withOperationTimeout(schema.TimeoutRead, resourceKongPluginRead),
+		Delete: withOperationTimeout(schema.TimeoutDelete, resourceKongPluginDelete),
+		Update: withOperationTimeout(schema.TimeoutUpdate, resourceKongPluginUpdate),
 
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			State: resourceKongPluginImport,
 		},
 
+		Timeouts: resourceKongTimeouts(),
+
 		Schema: map[string]*schema.Schema{
+			// Renaming a plugin replaces it with a new one (with a new id) in update, see resourceKongPluginRename
+			// not ForceNew so a rename can create the new plugin before deleting the old one (see create_before_rename), the
+			// sdk has no way to force a new resource only when that flag is off
 			"name": &schema.Schema{
-				Type:     schema.TypeString,
-				Required: true,
-				ForceNew: true,
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    false,
+				Description: "Changing the name replaces the plugin, the plan shows an update in place but the old plugin is deleted and a new one is created. prevent_destroy does not stop a rename.",
+			},
+			"create_before_rename": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Create the renamed plugin before deleting the old one when the scope is unchanged, so the scope is never left without it.",
+			},
+			// Only used by the provider on update and read, it is not sent to kong
+			"config_merge_strategy": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "replace",
+				ValidateFunc: validateConfigMergeStrategy,
+				Description:  "replace to manage the whole plugin config, deep to only manage the configured keys and merge them into the config in kong on update.",
 			},
 			"api_id": &schema.Schema{
 				Type:     schema.TypeString,
@@ -36,101 +61,492 @@ func resourceKongPlugin() *schema.Resource {
 				ForceNew: false,
 			},
 			"service_id": &schema.Schema{
-				Type:     schema.TypeString,
-				Optional: true,
-				ForceNew: false,
+				Type:          schema.TypeString,
+				Optional:      true,
+				ForceNew:      false,
+				ConflictsWith: []string{"service_name"},
+			},
+			"service_name": &schema.Schema{
+				Type:          schema.TypeString,
+				Optional:      true,
+				ForceNew:      false,
+				ConflictsWith: []string{"service_id"},
+				Description:   "Scopes the plugin to the service with this name, the name is resolved to the service id when the plugin is created or updated",
 			},
 			"route_id": &schema.Schema{
 				Type:     schema.TypeString,
 				Optional: true,
 				ForceNew: false,
 			},
+			"consumer_group_id": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    false,
+				Description: "Scopes the plugin to a consumer group (Kong 3.x), can be combined with service_id and route_id",
+			},
+			"protocols": &schema.Schema{
+				Type:             schema.TypeSet,
+				Optional:         true,
+				Elem:             &schema.Schema{Type: schema.TypeString},
+				DiffSuppressFunc: suppressDefaultPluginProtocols,
+				Description:      "The protocols of the requests the plugin runs on, kong uses the default of the plugin's schema when they are not set.",
+			},
 			"config": &schema.Schema{
 				Type:          schema.TypeMap,
 				Optional:      true,
 				Elem:          schema.TypeString,
-				ConflictsWith: []string{"config_json"},
+				ConflictsWith: []string{"config_json", "config_json_file"},
 			},
 			"config_json": &schema.Schema{
+				Type:             schema.TypeString,
+				Optional:         true,
+				StateFunc:        normalizeDataJSON,
+				ValidateFunc:     validateDataJSON,
+				Description:      "plugin configuration in JSON format, configuration must be a valid JSON object.",
+				ConflictsWith:    []string{"config", "config_json_file"},
+				DiffSuppressFunc: suppressEquivalentConfigJson,
+			},
+			// The state holds the path with a hash of the file's normalized contents, so changing the file shows up as
+			// a change even though the path stays the same.
+			"config_json_file": &schema.Schema{
 				Type:          schema.TypeString,
 				Optional:      true,
-				StateFunc:     normalizeDataJSON,
-				ValidateFunc:  validateDataJSON,
-				Description:   "plugin configuration in JSON format, configuration must be a valid JSON object.",
-				ConflictsWith: []string{"config"},
-				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
-					return new == ""
-				},
+				StateFunc:     pluginConfigFileState,
+				ValidateFunc:  validatePluginConfigFile,
+				Description:   "path of a file holding the plugin configuration in JSON format, used instead of config or config_json.",
+				ConflictsWith: []string{"config", "config_json"},
+			},
+			"enabled": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				ForceNew:    false,
+				Default:     true,
+				Description: "Turns the plugin off without removing it, changing only this does not resend the config.",
+			},
+			// Only used by the provider on read, it is not sent to kong
+			"fail_on_missing": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Fail the refresh when the plugin no longer exists in kong instead of planning to create it again.",
+			},
+			// the config path (dot separated) to the id of a service or consumer, e.g. anonymous, set in the config sent
+			// to kong instead of being interpolated into config_json
+			"config_service_ref": &schema.Schema{
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Elem:        schema.TypeString,
+				Description: "config paths (dot separated) mapped to the id of the service kong gets at them",
+			},
+			"config_consumer_ref": &schema.Schema{
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Elem:        schema.TypeString,
+				Description: "config paths (dot separated) mapped to the id of the consumer kong gets at them",
+			},
+			"effective_config_json": &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "the complete plugin configuration in JSON format with the defaults kong filled in, without the values of sensitive_config_json.",
+			},
+			"sensitive_config_json": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Sensitive:    true,
+				StateFunc:    normalizeSensitiveDataJSON,
+				ValidateFunc: validateDataJSON,
+				Description:  "plugin configuration in JSON format merged into config_json, the values are hashed in state and are not shown in plans.",
 			},
 		},
 	}
 }
 
 func resourceKongPluginCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*kongClient)
 
-	pluginRequest, err := createKongPluginRequestFromResourceData(d)
+	pluginRequest, err := createValidatedKongPluginRequest(client, d)
 	if err != nil {
 		return err
 	}
 
-	plugin, err := meta.(*gokong.KongAdminClient).Plugins().Create(pluginRequest)
+	consumerGroupId := readStringFromResource(d, "consumer_group_id")
+	protocols := readStringSetFromResource(d, "protocols")
+	enabled := d.Get("enabled").(bool)
+
+	// kong allows one plugin of each name per scope
+	identity := []string{pluginRequest.Name, pluginRequest.ApiId, pluginRequest.ConsumerId, pluginRequest.ServiceId, pluginRequest.RouteId, consumerGroupId}
+
+	var pluginId string
+	if consumerGroupId != "" || len(protocols) > 0 {
+		// gokong has no protocols, they are sent with the nested entity references kong 1.0 added along with them
+		plugin := &gokong.Plugin{}
+		scopedPluginRequest := createScopedPluginRequest(pluginRequest, consumerGroupId)
+		scopedPluginRequest.Enabled = &enabled
+		scopedPluginRequest.Protocols = protocols
+		err = client.create(gokong.PluginsPath, identity, scopedPluginRequest, plugin)
+		pluginId = plugin.Id
+	} else if !enabled {
+		// gokong can not create a disabled plugin, send it ourselves so the plugin never runs enabled
+		plugin := &gokong.Plugin{}
+		err = client.create(gokong.PluginsPath, identity, &disabledPluginRequest{PluginRequest: pluginRequest, Enabled: false}, plugin)
+		pluginId = plugin.Id
+	} else {
+		plugin := &gokong.Plugin{}
+		err = client.create(gokong.PluginsPath, identity, pluginRequest, plugin)
+		pluginId = plugin.Id
+	}
 
 	if err != nil {
-		return fmt.Errorf("failed to create kong plugin: %v error: %v", pluginRequest, err)
+		return fmt.Errorf("failed to create kong plugin: %v error: %v", redactPluginRequest(d, pluginRequest), err)
 	}
 
-	d.SetId(plugin.Id)
+	d.SetId(pluginId)
 
-	return resourceKongPluginRead(d, meta)
+	return resourceKongPluginRead(d, client)
+}
+
+// createValidatedKongPluginRequest builds the request for a new plugin and checks it against kong's schemas and version,
+// nothing is changed in kong so it can run before a rename deletes the plugin being replaced.
+func createValidatedKongPluginRequest(client *kongClient, d *schema.ResourceData) (*gokong.PluginRequest, error) {
+	pluginRequest, err := createKongPluginRequestFromResourceData(d)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := resolveKongPluginServiceName(client, d, pluginRequest); err != nil {
+		return nil, err
+	}
+
+	if err := applyPluginConfigAliases(client, pluginRequest.Name, pluginRequest.Config); err != nil {
+		return nil, fmt.Errorf("could not check kong version for the config of kong plugin %s: %v", pluginRequest.Name, err)
+	}
+
+	if err := validatePluginScopeSchema(client, pluginRequest, readStringFromResource(d, "consumer_group_id")); err != nil {
+		return nil, fmt.Errorf("invalid kong plugin scope: %v", err)
+	}
+
+	if err := validatePluginConfigSchema(client, pluginRequest.Name, configWithSensitiveKeys(d, pluginRequest.Config)); err != nil {
+		return nil, err
+	}
+
+	if err := validateProtocols(client, readStringSetFromResource(d, "protocols")); err != nil {
+		return nil, err
+	}
+
+	return pluginRequest, nil
 }
 
 func resourceKongPluginUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*kongClient)
+
 	d.Partial(false)
 
+	if d.HasChange("name") {
+		return resourceKongPluginRename(d, client)
+	}
+
+	enabled := d.Get("enabled").(bool)
+
+	if pluginConfigOrScopeChanged(d) && !pluginConfigOnlyMoved(client, d) {
+		if err := updateKongPlugin(d, client); err != nil {
+			return err
+		}
+	}
+
+	if d.HasChange("enabled") {
+		err := client.patch(gokong.PluginsPath+d.Id(), &pluginEnabledRequest{Enabled: enabled}, nil)
+		if err != nil {
+			return fmt.Errorf("error updating kong plugin enabled to %t: %s", enabled, err)
+		}
+	}
+
+	return resourceKongPluginRead(d, client)
+}
+
+// resourceKongPluginRename replaces the plugin with one of the new name. Kong can not rename a plugin so the old plugin
+// is deleted and the new one created, unless create_before_rename is set and the scope is unchanged in which case the
+// new plugin is created first. Kong allows both to exist as their names differ, so the scope always has one of them.
+func resourceKongPluginRename(d *schema.ResourceData, client *kongClient) error {
+	oldId := d.Id()
+	oldName, newName := d.GetChange("name")
+	log.Printf("[WARN] renaming kong plugin %s from %s to %s replaces it, the plugin %s is deleted", oldId, oldName, newName, oldId)
+
+	// a replacement kong would reject must not cost the old plugin
+	if _, err := createValidatedKongPluginRequest(client, d); err != nil {
+		return err
+	}
+
+	if !d.Get("create_before_rename").(bool) || pluginScopeChanged(d) {
+		if err := resourceKongPluginDelete(d, client); err != nil {
+			return err
+		}
+		return resourceKongPluginCreate(d, client)
+	}
+
+	// the id only changes once the new plugin exists, if creating it fails state keeps pointing at the old one
+	if err := resourceKongPluginCreate(d, client); err != nil {
+		return err
+	}
+
+	if err := client.delete(gokong.PluginsPath + oldId); err != nil {
+		return fmt.Errorf("created kong plugin %s but could not delete the plugin %s it replaces, delete it by hand: %v", d.Id(), oldId, err)
+	}
+
+	return nil
+}
+
+func pluginScopeChanged(d *schema.ResourceData) bool {
+	for _, key := range pluginScopeAttributes {
+		if d.HasChange(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// pluginConfigOrScopeChanged is false when only enabled, fail_on_missing, create_before_rename or config_merge_strategy
+// changed, toggling a plugin is then a single patch of enabled that leaves the config alone and the others only live in
+// state
+func pluginConfigOrScopeChanged(d *schema.ResourceData) bool {
+	for _, key := range append(pluginScopeAttributes, append(pluginConfigRefAttributes, "config", "config_json", "config_json_file", "sensitive_config_json", "protocols")...) {
+		if d.HasChange(key) {
+			return true
+		}
+	}
+	return false
+}
+
+var pluginScopeAttributes = []string{"api_id", "consumer_id", "service_id", "service_name", "route_id", "consumer_group_id"}
+
+// pluginConfigOnlyMoved is true when the config moved between config, config_json and config_json_file without its
+// content changing, e.g. when migrating from the config map to config_json. The config is compared with the config_json
+// last read from kong the same way plans compare it, so the update has nothing to send.
+func pluginConfigOnlyMoved(client *kongClient, d *schema.ResourceData) bool {
+	if pluginScopeChanged(d) || d.HasChange("sensitive_config_json") || d.HasChange("protocols") {
+		return false
+	}
+
+	for _, key := range pluginConfigRefAttributes {
+		if d.HasChange(key) {
+			return false
+		}
+	}
+
+	upstream, _ := d.GetChange("config_json")
+	if upstream.(string) == "" {
+		return false
+	}
+
+	pluginRequest, err := createKongPluginRequestFromResourceData(d)
+	if err != nil || pluginRequest.Config == nil {
+		return false
+	}
+
+	config, err := json.Marshal(pluginRequest.Config)
+	if err != nil {
+		return false
+	}
+
+	return suppressEquivalentPluginConfigJson(client, "config_json", upstream.(string), string(config), d)
+}
+
+// mergeKongPluginConfig merges the config of the request into the config the plugin has in kong, see mergeJSONObjects:
+// objects are merged key by key and every other value, arrays included, replaces the one in kong. Keys that are not
+// configured keep the value kong has, so a module can manage part of a large config and leave the rest to others.
+func mergeKongPluginConfig(client *kongClient, id string, pluginRequest *gokong.PluginRequest) error {
+	plugin, err := getKongScopedPlugin(client, id)
+	if err != nil {
+		return fmt.Errorf("could not read the config of kong plugin %s to merge into: %v", id, err)
+	}
+
+	if plugin == nil {
+		return fmt.Errorf("could not read the config of kong plugin %s to merge into, it does not exist", id)
+	}
+
+	upstream := map[string]interface{}{}
+	for key, val := range plugin.Config {
+		if !contains(client.pluginComputedProperties(plugin.Name), key) {
+			upstream[key] = val
+		}
+	}
+
+	pluginRequest.Config = mergeJSONObjects(upstream, pluginRequest.Config)
+
+	return nil
+}
+
+func validateConfigMergeStrategy(value interface{}, k string) ([]string, []error) {
+	if strategies := []string{"replace", "deep"}; !contains(strategies, value.(string)) {
+		return nil, []error{fmt.Errorf("%s must be one of %v, got: %s", k, strategies, value)}
+	}
+	return nil, nil
+}
+
+func updateKongPlugin(d *schema.ResourceData, meta interface{}) error {
 	pluginRequest, err := createKongPluginRequestFromResourceData(d)
 	if err != nil {
 		return err
 	}
 
-	_, err = meta.(*gokong.KongAdminClient).Plugins().UpdateById(d.Id(), pluginRequest)
+	if err := resolveKongPluginServiceName(meta.(*kongClient), d, pluginRequest); err != nil {
+		return err
+	}
+
+	if err := applyPluginConfigAliases(meta.(*kongClient), pluginRequest.Name, pluginRequest.Config); err != nil {
+		return fmt.Errorf("could not check kong version for the config of kong plugin %s: %v", pluginRequest.Name, err)
+	}
+
+	if err := validatePluginScopeSchema(meta.(*kongClient), pluginRequest, readStringFromResource(d, "consumer_group_id")); err != nil {
+		return fmt.Errorf("invalid kong plugin scope: %v", err)
+	}
+
+	if d.Get("config_merge_strategy").(string) == "deep" {
+		if err := mergeKongPluginConfig(meta.(*kongClient), d.Id(), pluginRequest); err != nil {
+			return err
+		}
+	}
+
+	if err := validatePluginConfigSchema(meta.(*kongClient), pluginRequest.Name, configWithSensitiveKeys(d, pluginRequest.Config)); err != nil {
+		return err
+	}
+
+	protocols := readStringSetFromResource(d, "protocols")
+	if err := validateProtocols(meta.(*kongClient), protocols); err != nil {
+		return err
+	}
+
+	// Removing protocols sets them back to the schema default, kong would keep the ones it has otherwise
+	if len(protocols) == 0 && d.HasChange("protocols") {
+		_, protocols = meta.(*kongClient).pluginSchemaDefaults(pluginRequest.Name)
+	}
+
+	// once a plugin has been scoped to a consumer group it has to keep using the nested entity references, the flat
+	// fields gokong sends would not clear the consumer group.
+	if oldConsumerGroupId, consumerGroupId := d.GetChange("consumer_group_id"); oldConsumerGroupId.(string) != "" || consumerGroupId.(string) != "" || len(protocols) > 0 {
+		scopedPluginRequest := createScopedPluginRequest(pluginRequest, consumerGroupId.(string))
+		scopedPluginRequest.Protocols = protocols
+		err = meta.(*kongClient).patch(gokong.PluginsPath+d.Id(), scopedPluginRequest, nil)
+	} else {
+		err = meta.(*kongClient).patch(gokong.PluginsPath+d.Id(), pluginRequest, nil)
+	}
 
 	if err != nil {
-		return fmt.Errorf("error updating kong plugin: %s", err)
+		return fmt.Errorf("error updating kong plugin: %v error: %s", redactPluginRequest(d, pluginRequest), err)
 	}
 
-	return resourceKongPluginRead(d, meta)
+	return nil
 }
 
 func resourceKongPluginRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*kongClient)
 
-	plugin, err := meta.(*gokong.KongAdminClient).Plugins().GetById(d.Id())
+	plugin, err := getKongScopedPlugin(client, d.Id())
 
 	if err != nil {
 		return fmt.Errorf("could not find kong plugin: %v", err)
 	}
 
 	if plugin == nil {
+		if d.Get("fail_on_missing").(bool) {
+			return fmt.Errorf("kong plugin %s no longer exists and fail_on_missing is set, remove it from state with terraform state rm to create it again", d.Id())
+		}
 		d.SetId("")
 	} else {
-		d.Set("name", plugin.Name)
-		d.Set("api_id", plugin.ApiId)
-		d.Set("service_id", plugin.ServiceId)
-		d.Set("route_id", plugin.RouteId)
-		d.Set("consumer_id", plugin.ConsumerId)
-
 		// We sync this property from upstream as a method to allow you to import a resource with the config tracked in
 		// terraform state. We do not track `config` as it will be a source of a perpetual diff.
 		// https://www.terraform.io/docs/extend/best-practices/detecting-drift.html#capture-all-state-in-read
-		upstreamJson := pluginConfigJsonToString(plugin.Config)
+		config := plugin.Config
+		pluginRequest, err := createKongPluginRequestFromResourceData(d)
+		configured := err == nil && pluginRequest.Config != nil
+		if configured {
+			client.coercePluginConfigBooleans(plugin.Name, config, pluginRequest.Config)
+			keepVaultReferences(config, pluginRequest.Config)
+			keepEncryptedConfigValues(config, pluginRequest.Config)
+		} else {
+			client.coercePluginConfigBooleans(plugin.Name, config, nil)
+		}
+
+		// The complete config with the defaults kong filled in is only an output, plans compare config_json. The values
+		// of sensitive_config_json are left out of it.
+		sensitiveConfig := readSensitiveConfigFromResource(d)
+		effectiveConfig := copyJSONObject(config)
+		if sensitiveConfig != nil {
+			extractJSONPaths(effectiveConfig, sensitiveConfig)
+		}
+
+		// the ids of the config refs are only kept in their attributes
+		config = copyJSONObject(config)
+		setPluginConfigRefs(d, config)
+
+		// the keys kong has that are not configured are managed elsewhere, they are left out of config_json
+		if configured && d.Get("config_merge_strategy").(string) == "deep" {
+			config, _ = extractJSONPaths(copyJSONObject(config), pluginRequest.Config)
+		}
+
+		var sensitiveJson []byte
+		if sensitiveConfig != nil {
+			// Keep the sensitive values out of config_json, only their hash is stored in state.
+			if extracted, complete := extractJSONPaths(config, sensitiveConfig); complete {
+				sensitiveJson, _ = json.Marshal(hashSensitiveValues(extracted))
+			}
+		}
+
+		upstreamJson := client.formatConfigJson(pluginConfigJsonToString(config, client.pluginComputedProperties(plugin.Name)))
+
+		logDrift(client, "kong_plugin", d, map[string]interface{}{
+			"name":        plugin.Name,
+			"enabled":     plugin.Enabled,
+			"config_json": upstreamJson,
+		})
+
+		d.Set("name", plugin.Name)
+		d.Set("enabled", plugin.Enabled)
+		d.Set("protocols", plugin.Protocols)
+		setKongPluginScope(d, plugin)
+
+		if readStringFromResource(d, "service_name") != "" {
+			if err := setKongPluginServiceName(client, d, plugin); err != nil {
+				return fmt.Errorf("could not find kong service of plugin: %v", err)
+			}
+		}
+
+		if sensitiveJson != nil {
+			d.Set("sensitive_config_json", string(sensitiveJson))
+		}
 		d.Set("config_json", upstreamJson)
+		d.Set("effective_config_json", client.formatConfigJson(pluginConfigJsonToString(effectiveConfig, client.pluginComputedProperties(plugin.Name))))
 	}
 
 	return nil
 }
 
+// The scope is resolved when importing rather than left to the read that follows, so a plugin that no longer exists is
+// reported instead of being imported as an empty resource, and every scope id is in state before the first plan.
+func resourceKongPluginImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+
+	plugin, err := getKongScopedPlugin(meta.(*kongClient), d.Id())
+
+	if err != nil {
+		return nil, fmt.Errorf("could not import kong plugin: %v", err)
+	}
+
+	if plugin == nil {
+		return nil, fmt.Errorf("could not import kong plugin, no plugin with id %s", d.Id())
+	}
+
+	d.Set("name", plugin.Name)
+	d.Set("fail_on_missing", false)
+	d.Set("create_before_rename", false)
+	d.Set("config_merge_strategy", "replace")
+	setKongPluginScope(d, plugin)
+
+	return []*schema.ResourceData{d}, nil
+}
+
 func resourceKongPluginDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*kongClient)
 
-	err := meta.(*gokong.KongAdminClient).Plugins().DeleteById(d.Id())
+	err := client.delete(gokong.PluginsPath + d.Id())
 
 	if err != nil {
 		return fmt.Errorf("could not delete kong plugin: %v", err)
@@ -160,17 +576,274 @@ func createKongPluginRequestFromResourceData(d *schema.ResourceData) (*gokong.Pl
 			}
 
 			pluginRequest.Config = configJson
+		} else if path := readStringFromResource(d, "config_json_file"); path != "" {
+			configJson, err := readPluginConfigFile(pluginConfigFilePath(path))
+			if err != nil {
+				return pluginRequest, err
+			}
+
+			pluginRequest.Config = configJson
+		}
+	}
+
+	// an unchanged sensitive_config_json only has the hashes in state, kong keeps the values it has for those keys
+	if sensitiveConfig := withoutHashedValues(readSensitiveConfigFromResource(d)); len(sensitiveConfig) > 0 {
+		pluginRequest.Config = mergeJSONObjects(pluginRequest.Config, sensitiveConfig)
+	}
+
+	refs, err := readPluginConfigRefs(d)
+	if err != nil {
+		return pluginRequest, err
+	}
+	if len(refs) > 0 {
+		if pluginRequest.Config == nil {
+			pluginRequest.Config = map[string]interface{}{}
+		}
+		if err := injectPluginConfigRefs(pluginRequest.Config, refs); err != nil {
+			return pluginRequest, err
 		}
 	}
 
+	if err := validatePluginScope(pluginRequest, readStringFromResource(d, "consumer_group_id")); err != nil {
+		return pluginRequest, err
+	}
+
 	return pluginRequest, nil
 }
 
-// Since this config is a schemaless "blob" we have to remove computed properties
-func pluginConfigJsonToString(data map[string]interface{}) string {
+// redactPluginRequest returns a copy of the request for error messages with the secrets in its config masked
+func redactPluginRequest(d *schema.ResourceData, pluginRequest *gokong.PluginRequest) *gokong.PluginRequest {
+	redacted := *pluginRequest
+	redacted.Config = redactConfig(pluginRequest.Config, readSensitiveConfigFromResource(d))
+	return &redacted
+}
+
+// validatePluginScope checks the combination of entities the plugin is scoped to is one kong accepts, a plugin can be
+// scoped to any mix of service, route and one of consumer or consumer group. The legacy api scope can only be combined
+// with a consumer.
+func validatePluginScope(pluginRequest *gokong.PluginRequest, consumerGroupId string) error {
+	if pluginRequest.ConsumerId != "" && consumerGroupId != "" {
+		return fmt.Errorf("kong plugin %s can not be scoped to both consumer_id and consumer_group_id", pluginRequest.Name)
+	}
+
+	if pluginRequest.ApiId != "" && (pluginRequest.ServiceId != "" || pluginRequest.RouteId != "" || consumerGroupId != "") {
+		return fmt.Errorf("kong plugin %s scoped to api_id can only also be scoped to consumer_id, service_id, route_id and consumer_group_id are not supported with apis", pluginRequest.Name)
+	}
+
+	return nil
+}
+
+// entityReference is how kong 1.0 and later refer to related entities, e.g. "service": {"id": "..."}
+type entityReference struct {
+	Id string `json:"id"`
+}
+
+func (reference *entityReference) id() string {
+	if reference == nil {
+		return ""
+	}
+	return reference.Id
+}
+
+func newEntityReference(id string) *entityReference {
+	if id == "" {
+		return nil
+	}
+	return &entityReference{Id: id}
+}
+
+// scopedPlugin adds the nested entity references gokong does not decode to the plugin, older kong versions return the
+// flat *_id fields instead so both are read.
+type scopedPlugin struct {
+	gokong.Plugin
+	Service       *entityReference `json:"service"`
+	Route         *entityReference `json:"route"`
+	Consumer      *entityReference `json:"consumer"`
+	ConsumerGroup *entityReference `json:"consumer_group"`
+	Protocols     []string         `json:"protocols"`
+}
+
+// scopedPluginRequest is used for plugins scoped to a consumer group, consumer groups only exist in kong versions that
+// use nested entity references. The references are not omitted when empty so an update sends null and clears them.
+type scopedPluginRequest struct {
+	Name          string                 `json:"name"`
+	Service       *entityReference       `json:"service"`
+	Route         *entityReference       `json:"route"`
+	Consumer      *entityReference       `json:"consumer"`
+	ConsumerGroup *entityReference       `json:"consumer_group"`
+	Config        map[string]interface{} `json:"config,omitempty"`
+	Enabled       *bool                  `json:"enabled,omitempty"`
+	Protocols     []string               `json:"protocols,omitempty"`
+}
+
+type disabledPluginRequest struct {
+	*gokong.PluginRequest
+	Enabled bool `json:"enabled"`
+}
+
+type pluginEnabledRequest struct {
+	Enabled bool `json:"enabled"`
+}
+
+func getKongScopedPlugin(client *kongClient, id string) (*scopedPlugin, error) {
+	plugin := &scopedPlugin{}
+	found, err := client.get(gokong.PluginsPath+id, plugin)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !found || plugin.Id == "" {
+		return nil, nil
+	}
+
+	return plugin, nil
+}
+
+// setKongPluginScope sets every scope id from the plugin including the ones that are not set, so removing a scope in
+// kong shows up as a change.
+func setKongPluginScope(d *schema.ResourceData, plugin *scopedPlugin) {
+	d.Set("api_id", plugin.ApiId)
+	d.Set("service_id", firstNonEmpty(plugin.ServiceId, plugin.Service.id()))
+	d.Set("route_id", firstNonEmpty(plugin.RouteId, plugin.Route.id()))
+	d.Set("consumer_id", firstNonEmpty(plugin.ConsumerId, plugin.Consumer.id()))
+	d.Set("consumer_group_id", plugin.ConsumerGroup.id())
+}
+
+// resolveKongPluginServiceName scopes the request to the service named by service_name, the scope is checked again now
+// the service id is known.
+func resolveKongPluginServiceName(client *kongClient, d *schema.ResourceData, pluginRequest *gokong.PluginRequest) error {
+	serviceName := readStringFromResource(d, "service_name")
+	if serviceName == "" {
+		return nil
+	}
+
+	service, err := client.getServiceByName(serviceName, false)
+	if err != nil {
+		return fmt.Errorf("could not find kong service with name: %s error: %v", serviceName, err)
+	}
+
+	if service == nil || service.Id == nil {
+		return fmt.Errorf("could not find kong service with name: %s", serviceName)
+	}
+
+	pluginRequest.ServiceId = *service.Id
+
+	return validatePluginScope(pluginRequest, readStringFromResource(d, "consumer_group_id"))
+}
+
+// setKongPluginServiceName reconciles service_name from the id of the service the plugin is scoped to in kong, so
+// rescoping the plugin or renaming the service shows up as a change. service_id is cleared as it is not configured.
+func setKongPluginServiceName(client *kongClient, d *schema.ResourceData, plugin *scopedPlugin) error {
+	d.Set("service_id", "")
+
+	serviceId := firstNonEmpty(plugin.ServiceId, plugin.Service.id())
+	if serviceId == "" {
+		d.Set("service_name", "")
+		return nil
+	}
+
+	service := &gokong.Service{}
+	found, err := client.get(gokong.ServicesPath+serviceId, service)
+	if err != nil {
+		return err
+	}
+
+	if !found || service.Name == nil {
+		d.Set("service_name", "")
+	} else {
+		d.Set("service_name", *service.Name)
+	}
+
+	return nil
+}
+
+func createScopedPluginRequest(pluginRequest *gokong.PluginRequest, consumerGroupId string) *scopedPluginRequest {
+	return &scopedPluginRequest{
+		Name:          pluginRequest.Name,
+		Service:       newEntityReference(pluginRequest.ServiceId),
+		Route:         newEntityReference(pluginRequest.RouteId),
+		Consumer:      newEntityReference(pluginRequest.ConsumerId),
+		ConsumerGroup: newEntityReference(consumerGroupId),
+		Config:        pluginRequest.Config,
+	}
+}
+
+const pluginConfigFileHashSeparator = "#sha256:"
+
+func readPluginConfigFile(path string) (map[string]interface{}, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read config_json_file: %v", err)
+	}
+
+	config := map[string]interface{}{}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("config_json_file %s is not a valid JSON object: %v", path, err)
+	}
+
+	return config, nil
+}
+
+func validatePluginConfigFile(value interface{}, k string) ([]string, []error) {
+	if _, err := readPluginConfigFile(value.(string)); err != nil {
+		return nil, []error{err}
+	}
+	return nil, nil
+}
+
+// pluginConfigFileState appends the hash of the normalized config to the path, whitespace or key order changes in the
+// file are not a change
+func pluginConfigFileState(value interface{}) string {
+	path := value.(string)
+
+	config, err := readPluginConfigFile(path)
+	if err != nil {
+		// The validate function should've taken care of this.
+		return path
+	}
+
+	normalized, _ := json.Marshal(config)
+	sum := sha256.Sum256(normalized)
+
+	return path + pluginConfigFileHashSeparator + hex.EncodeToString(sum[:])
+}
+
+// pluginConfigFilePath is the path of config_json_file, the state value also has the hash of the contents
+func pluginConfigFilePath(value string) string {
+	if i := strings.LastIndex(value, pluginConfigFileHashSeparator); i >= 0 {
+		return value[:i]
+	}
+	return value
+}
+
+func readSensitiveConfigFromResource(d *schema.ResourceData) map[string]interface{} {
+	if data, ok := d.GetOk("sensitive_config_json"); ok {
+		sensitiveConfig := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(data.(string)), &sensitiveConfig); err == nil {
+			return sensitiveConfig
+		}
+	}
+	return nil
+}
+
+// configWithSensitiveKeys is the config with every key of sensitive_config_json set, for the required field check. The
+// request leaves out the keys that read back from state as hashes, kong still holds their values.
+func configWithSensitiveKeys(d *schema.ResourceData, config map[string]interface{}) map[string]interface{} {
+	sensitiveConfig := readSensitiveConfigFromResource(d)
+	if len(sensitiveConfig) == 0 {
+		return config
+	}
+	return mergeJSONObjects(copyJSONObject(config), sensitiveConfig)
+}
+
+// Since this config is a schemaless "blob" we have to remove computed properties, see pluginComputedProperties. Only
+// the top level is stripped, nested objects (e.g. the storage_config of the acme plugin) are user config and are kept as
+// they are even when they have keys like id.
+func pluginConfigJsonToString(data map[string]interface{}, computed []string) string {
 	marshalledData := map[string]interface{}{}
 	for key, val := range data {
-		if !contains(computedPluginProperties, key) {
+		if !contains(computed, key) {
 			marshalledData[key] = val
 		}
 	}
@@ -0,0 +1,177 @@
+package kong
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func resourceKongPluginAcl() *schema.Resource {
+	return resourceKongTypedPlugin(&typedPlugin{
+		name: "acl",
+		schema: map[string]*schema.Schema{
+			"allow": &schema.Schema{
+				Type:          schema.TypeList,
+				Optional:      true,
+				Elem:          &schema.Schema{Type: schema.TypeString},
+				ConflictsWith: []string{"deny", "deny_consumer_groups"},
+			},
+			"deny": &schema.Schema{
+				Type:          schema.TypeList,
+				Optional:      true,
+				Elem:          &schema.Schema{Type: schema.TypeString},
+				ConflictsWith: []string{"allow", "allow_consumer_groups"},
+			},
+			// consumer groups are looked up by name when the plugin is sent, their names are added to allow or deny
+			"allow_consumer_groups": &schema.Schema{
+				Type:          schema.TypeList,
+				Optional:      true,
+				Elem:          &schema.Schema{Type: schema.TypeString},
+				ConflictsWith: []string{"deny", "deny_consumer_groups"},
+				Description:   "Names of consumer groups whose consumers are allowed (kong 3.4), sent in allow with the groups of allow",
+			},
+			"deny_consumer_groups": &schema.Schema{
+				Type:          schema.TypeList,
+				Optional:      true,
+				Elem:          &schema.Schema{Type: schema.TypeString},
+				ConflictsWith: []string{"allow", "allow_consumer_groups"},
+				Description:   "Names of consumer groups whose consumers are denied (kong 3.4), sent in deny with the groups of deny",
+			},
+			"hide_groups_header": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"always_use_authenticated_groups": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+		},
+		expandConfig:  expandAclPluginConfig,
+		flattenConfig: flattenAclPluginConfig,
+		resolveConfig: resolveAclPluginConsumerGroups,
+	})
+}
+
+// the acl plugin matches the names of consumer groups from kong 3.4 on, when include_consumer_groups is set
+const aclConsumerGroupsMinimumKongVersion = "3.4.0"
+
+func expandAclPluginConfig(d *schema.ResourceData) (map[string]interface{}, error) {
+	allow := readStringArrayFromResource(d, "allow")
+	deny := readStringArrayFromResource(d, "deny")
+
+	if len(allow) == 0 && len(deny) == 0 && len(readStringArrayFromResource(d, "allow_consumer_groups")) == 0 &&
+		len(readStringArrayFromResource(d, "deny_consumer_groups")) == 0 {
+		return nil, fmt.Errorf("one of allow or deny must be set, or one of allow_consumer_groups or deny_consumer_groups")
+	}
+
+	// the list that is not used is sent as null, kong merges the config of an update so switching from allow to deny
+	// would otherwise keep the allow list
+	config := map[string]interface{}{
+		"allow":                           nil,
+		"deny":                            nil,
+		"hide_groups_header":              d.Get("hide_groups_header").(bool),
+		"always_use_authenticated_groups": d.Get("always_use_authenticated_groups").(bool),
+	}
+
+	if len(allow) > 0 {
+		config["allow"] = allow
+	}
+
+	if len(deny) > 0 {
+		config["deny"] = deny
+	}
+
+	return config, nil
+}
+
+// resolveAclPluginConsumerGroups adds the names of the consumer groups to allow or deny, each group is read from kong
+// first so a group that does not exist is reported instead of being sent as a group no consumer is in.
+func resolveAclPluginConsumerGroups(client *kongClient, d *schema.ResourceData, config map[string]interface{}) error {
+	allowGroups := readStringArrayFromResource(d, "allow_consumer_groups")
+	denyGroups := readStringArrayFromResource(d, "deny_consumer_groups")
+
+	if len(allowGroups) == 0 && len(denyGroups) == 0 {
+		// the consumer groups were removed, kong merges the config of an update so it would keep matching them
+		if d.HasChange("allow_consumer_groups") || d.HasChange("deny_consumer_groups") {
+			config["include_consumer_groups"] = false
+		}
+		return nil
+	}
+
+	if err := client.requireVersion("kong_plugin_acl consumer groups", aclConsumerGroupsMinimumKongVersion); err != nil {
+		return err
+	}
+
+	for key, consumerGroups := range map[string][]string{"allow": allowGroups, "deny": denyGroups} {
+		if len(consumerGroups) == 0 {
+			continue
+		}
+
+		groups, _ := config[key].([]string)
+		groups = append([]string{}, groups...)
+		for _, consumerGroup := range consumerGroups {
+			name, err := getKongConsumerGroupName(client, consumerGroup)
+			if err != nil {
+				return err
+			}
+			if !contains(groups, name) {
+				groups = append(groups, name)
+			}
+		}
+		config[key] = groups
+	}
+
+	config["include_consumer_groups"] = true
+
+	return nil
+}
+
+// consumerGroupResponse is a consumer group as kong returns it, kong enterprise nests it with its consumers
+type consumerGroupResponse struct {
+	namedEntity
+	ConsumerGroup *namedEntity `json:"consumer_group"`
+}
+
+func getKongConsumerGroupName(client *kongClient, nameOrId string) (string, error) {
+	consumerGroup := &consumerGroupResponse{}
+	found, err := client.get("/consumer_groups/"+nameOrId, consumerGroup)
+	if err != nil {
+		return "", fmt.Errorf("could not find kong consumer group %s: %v", nameOrId, err)
+	}
+
+	if consumerGroup.ConsumerGroup != nil {
+		consumerGroup.namedEntity = *consumerGroup.ConsumerGroup
+	}
+
+	if !found || consumerGroup.Name == "" {
+		return "", fmt.Errorf("could not find kong consumer group %s", nameOrId)
+	}
+
+	return consumerGroup.Name, nil
+}
+
+// splitAclConsumerGroups splits the groups read from kong into the configured consumer groups and the other groups
+func splitAclConsumerGroups(groups []string, consumerGroups []string) ([]string, []string) {
+	other, matched := []string{}, []string{}
+	for _, group := range groups {
+		if contains(consumerGroups, group) {
+			matched = append(matched, group)
+		} else {
+			other = append(other, group)
+		}
+	}
+	return other, matched
+}
+
+func flattenAclPluginConfig(d *schema.ResourceData, config map[string]interface{}) {
+	allow, allowGroups := splitAclConsumerGroups(configStrings(config["allow"]), readStringArrayFromResource(d, "allow_consumer_groups"))
+	deny, denyGroups := splitAclConsumerGroups(configStrings(config["deny"]), readStringArrayFromResource(d, "deny_consumer_groups"))
+	d.Set("allow", allow)
+	d.Set("deny", deny)
+	d.Set("allow_consumer_groups", allowGroups)
+	d.Set("deny_consumer_groups", denyGroups)
+	d.Set("hide_groups_header", configBool(config["hide_groups_header"]))
+	d.Set("always_use_authenticated_groups", configBool(config["always_use_authenticated_groups"]))
+}
@@ -0,0 +1,239 @@
+package kong
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/kevholditch/gokong"
+)
+
+func TestAccKongPluginAcl(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheckKongVersion(t, "2.1.0") },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckKongTypedPluginDestroy("kong_plugin_acl"),
+		Steps: []resource.TestStep{
+			{
+				Config: testCreateAclPluginConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckKongPluginExists("kong_plugin_acl.acl"),
+					testAccCheckForChildIdCorrect("kong_service.service", "kong_plugin_acl.acl", "service_id"),
+					resource.TestCheckResourceAttr("kong_plugin_acl.acl", "allow.#", "2"),
+					resource.TestCheckResourceAttr("kong_plugin_acl.acl", "allow.0", "admins"),
+					resource.TestCheckResourceAttr("kong_plugin_acl.acl", "allow.1", "users"),
+					resource.TestCheckResourceAttr("kong_plugin_acl.acl", "deny.#", "0"),
+					resource.TestCheckResourceAttr("kong_plugin_acl.acl", "hide_groups_header", "true"),
+					resource.TestCheckResourceAttr("kong_plugin_acl.acl", "always_use_authenticated_groups", "true"),
+				),
+			},
+			{
+				Config: testUpdateAclPluginConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckKongPluginExists("kong_plugin_acl.acl"),
+					resource.TestCheckResourceAttr("kong_plugin_acl.acl", "allow.#", "0"),
+					resource.TestCheckResourceAttr("kong_plugin_acl.acl", "deny.#", "1"),
+					resource.TestCheckResourceAttr("kong_plugin_acl.acl", "deny.0", "blocked"),
+					resource.TestCheckResourceAttr("kong_plugin_acl.acl", "hide_groups_header", "false"),
+					resource.TestCheckResourceAttr("kong_plugin_acl.acl", "always_use_authenticated_groups", "false"),
+				),
+			},
+			{
+				ResourceName:      "kong_plugin_acl.acl",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccKongPluginAclWithoutGroups(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      testCreateAclPluginWithoutGroupsConfig,
+				ExpectError: regexp.MustCompile("one of allow or deny must be set"),
+			},
+		},
+	})
+}
+
+func TestKongPluginAclSendsBooleans(t *testing.T) {
+
+	server := newTypedPluginServer(t, "", nil)
+	defer server.Close()
+
+	r := resourceKongPluginAcl()
+	d := r.TestResourceData()
+	d.Set("allow", []string{"admins"})
+	d.Set("hide_groups_header", true)
+	d.Set("always_use_authenticated_groups", true)
+	d.Set("enabled", true)
+
+	if err := r.Create(d, newKongClient(&gokong.Config{HostAddress: server.URL})); err != nil {
+		t.Fatalf("could not create acl plugin: %v", err)
+	}
+
+	if len(server.sent) != 1 {
+		t.Fatalf("expected the plugin to be created, kong was sent: %v", server.sent)
+	}
+	created := server.sent[0]
+
+	config, _ := created["config"].(map[string]interface{})
+	for _, key := range []string{"hide_groups_header", "always_use_authenticated_groups"} {
+		if value, ok := config[key].(bool); !ok || !value {
+			t.Errorf("expected %s to be sent as the json boolean true, got: %#v", key, config[key])
+		}
+	}
+
+	if created["name"] != "acl" {
+		t.Errorf("expected an acl plugin to be created, got: %v", created["name"])
+	}
+
+	if d.Id() != "plugin-id" || !d.Get("hide_groups_header").(bool) || !d.Get("always_use_authenticated_groups").(bool) {
+		t.Errorf("expected the booleans to be read back from kong, got hide_groups_header: %v always_use_authenticated_groups: %v",
+			d.Get("hide_groups_header"), d.Get("always_use_authenticated_groups"))
+	}
+}
+
+func TestKongPluginAclConsumerGroups(t *testing.T) {
+
+	kongVersion := "3.6.1"
+	var created map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/":
+			json.NewEncoder(w).Encode(map[string]string{"version": kongVersion})
+		case r.URL.Path == "/consumer_groups/gold":
+			w.Write([]byte(`{"consumer_group":{"id":"gold-id","name":"gold"},"consumers":[]}`))
+		case r.URL.Path == "/consumer_groups/silver":
+			w.Write([]byte(`{"id":"silver-id","name":"silver"}`))
+		case r.URL.Path == "/plugins/" && r.Method == http.MethodPost:
+			created = map[string]interface{}{}
+			json.NewDecoder(r.Body).Decode(&created)
+			created["id"] = "plugin-id"
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(created)
+		case r.URL.Path == "/plugins/plugin-id":
+			json.NewEncoder(w).Encode(created)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	r := resourceKongPluginAcl()
+	newAclPlugin := func(consumerGroups ...string) *schema.ResourceData {
+		d := r.TestResourceData()
+		d.Set("allow", []string{"admins"})
+		d.Set("allow_consumer_groups", consumerGroups)
+		d.Set("enabled", true)
+		return d
+	}
+
+	d := newAclPlugin("gold", "silver")
+	if err := r.Create(d, newKongClient(&gokong.Config{HostAddress: server.URL})); err != nil {
+		t.Fatalf("could not create acl plugin: %v", err)
+	}
+
+	config, _ := created["config"].(map[string]interface{})
+	if allow, _ := json.Marshal(config["allow"]); string(allow) != `["admins","gold","silver"]` || config["include_consumer_groups"] != true {
+		t.Errorf("expected the consumer group names to be sent in allow with include_consumer_groups, got: %v", config)
+	}
+
+	if allow, groups := d.Get("allow").([]interface{}), d.Get("allow_consumer_groups").([]interface{}); len(allow) != 1 || len(groups) != 2 {
+		t.Errorf("expected the consumer groups to be read back apart from allow, got allow: %v allow_consumer_groups: %v", allow, groups)
+	}
+
+	created = nil
+	if err := r.Create(newAclPlugin("missing"), newKongClient(&gokong.Config{HostAddress: server.URL})); err == nil ||
+		!strings.Contains(err.Error(), "could not find kong consumer group missing") {
+		t.Errorf("expected an unknown consumer group to be reported, got: %v", err)
+	}
+	if created != nil {
+		t.Errorf("expected nothing to be sent for an unknown consumer group, sent: %v", created)
+	}
+
+	if err := r.Create(newAclPlugin(), newKongClient(&gokong.Config{HostAddress: server.URL})); err != nil {
+		t.Fatalf("could not create acl plugin: %v", err)
+	}
+	if config, _ := created["config"].(map[string]interface{}); config["include_consumer_groups"] != nil {
+		t.Errorf("expected include_consumer_groups not to be sent without consumer groups, got: %v", config)
+	}
+
+	kongVersion = "3.3.0"
+	if err := r.Create(newAclPlugin("gold"), newKongClient(&gokong.Config{HostAddress: server.URL})); err == nil ||
+		!strings.Contains(err.Error(), "requires kong 3.4.0") {
+		t.Errorf("expected consumer groups to require kong 3.4, got: %v", err)
+	}
+}
+
+func testAccCheckKongTypedPluginDestroy(resourceType string) resource.TestCheckFunc {
+
+	return func(state *terraform.State) error {
+		client := testAccProvider.Meta().(*kongClient)
+
+		plugins := getResourcesByType(resourceType, state)
+
+		if len(plugins) != 1 {
+			return fmt.Errorf("expecting only 1 %s resource found %v", resourceType, len(plugins))
+		}
+
+		response, err := client.Plugins().GetById(plugins[0].Primary.ID)
+
+		if err != nil {
+			return fmt.Errorf("error calling get plugin by id: %v", err)
+		}
+
+		if response != nil {
+			return fmt.Errorf("plugin %s still exists, %+v", plugins[0].Primary.ID, response)
+		}
+
+		return nil
+	}
+}
+
+const testCreateAclPluginConfig = `
+resource "kong_service" "service" {
+	name     = "test"
+	protocol = "http"
+	host     = "test.org"
+}
+
+resource "kong_plugin_acl" "acl" {
+	service_id                      = "${kong_service.service.id}"
+	allow                           = ["admins", "users"]
+	hide_groups_header              = true
+	always_use_authenticated_groups = true
+}
+`
+
+const testUpdateAclPluginConfig = `
+resource "kong_service" "service" {
+	name     = "test"
+	protocol = "http"
+	host     = "test.org"
+}
+
+resource "kong_plugin_acl" "acl" {
+	service_id = "${kong_service.service.id}"
+	deny       = ["blocked"]
+}
+`
+
+const testCreateAclPluginWithoutGroupsConfig = `
+resource "kong_plugin_acl" "acl" {
+	hide_groups_header = true
+}
+`
@@ -0,0 +1,368 @@
+package kong
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+var acmeStorages = []string{"kong", "shm", "redis", "consul", "vault"}
+var acmeCertTypes = []string{"rsa", "ecc"}
+var acmeRsaKeySizes = []int{2048, 3072, 4096}
+
+// acmeStorageBackends are the blocks of storage_config, one for each storage the certificates and account keys can be
+// kept in (kong keeps them in its database and has no settings). The attributes of each block are sent with their
+// terraform type and read back by it, see expandAcmeStorageBackend and flattenAcmeStorageBackend.
+var acmeStorageBackends = map[string]map[string]*schema.Schema{
+	"shm": {
+		"shm_name": &schema.Schema{
+			Type:     schema.TypeString,
+			Optional: true,
+			Default:  "kong",
+		},
+	},
+	"redis": {
+		"host": &schema.Schema{
+			Type:     schema.TypeString,
+			Required: true,
+		},
+		"port": &schema.Schema{
+			Type:     schema.TypeInt,
+			Optional: true,
+			Default:  6379,
+		},
+		"database": &schema.Schema{
+			Type:     schema.TypeInt,
+			Optional: true,
+			Default:  0,
+		},
+		"auth": &schema.Schema{
+			Type:      schema.TypeString,
+			Optional:  true,
+			Sensitive: true,
+		},
+		"ssl": &schema.Schema{
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  false,
+		},
+		// prefixes the keys, so several acme plugins can share one redis
+		"namespace": &schema.Schema{
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+	},
+	"consul": {
+		"host": &schema.Schema{
+			Type:     schema.TypeString,
+			Required: true,
+		},
+		"port": &schema.Schema{
+			Type:     schema.TypeInt,
+			Optional: true,
+			Default:  8500,
+		},
+		"kv_path": &schema.Schema{
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"https": &schema.Schema{
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  false,
+		},
+		"token": &schema.Schema{
+			Type:      schema.TypeString,
+			Optional:  true,
+			Sensitive: true,
+		},
+	},
+	"vault": {
+		"host": &schema.Schema{
+			Type:     schema.TypeString,
+			Required: true,
+		},
+		"port": &schema.Schema{
+			Type:     schema.TypeInt,
+			Optional: true,
+			Default:  8200,
+		},
+		"kv_path": &schema.Schema{
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"https": &schema.Schema{
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  false,
+		},
+		"tls_verify": &schema.Schema{
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  true,
+		},
+		"token": &schema.Schema{
+			Type:      schema.TypeString,
+			Optional:  true,
+			Sensitive: true,
+		},
+	},
+}
+
+func resourceKongPluginAcme() *schema.Resource {
+	storageConfigSchema := map[string]*schema.Schema{}
+	for backend, attributes := range acmeStorageBackends {
+		storageConfigSchema[backend] = &schema.Schema{
+			Type:     schema.TypeList,
+			Optional: true,
+			MaxItems: 1,
+			Elem:     &schema.Resource{Schema: attributes},
+		}
+	}
+
+	return resourceKongTypedPlugin(&typedPlugin{
+		name: "acme",
+		schema: map[string]*schema.Schema{
+			"account_email": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			// the directory of the acme server, kong defaults to the let's encrypt production directory
+			"api_uri": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			// the acme server's terms of service have to be accepted before kong can request certificates
+			"tos_accepted": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			// the domains certificates are requested for, allow_any_domain requests them for any domain instead
+			"domains": &schema.Schema{
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"allow_any_domain": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"cert_type": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "rsa",
+				ValidateFunc: validateAcmeCertType,
+			},
+			"rsa_key_size": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      4096,
+				ValidateFunc: validateAcmeRsaKeySize,
+			},
+			"renew_threshold_days": &schema.Schema{
+				Type:     schema.TypeInt,
+				Optional: true,
+				Default:  14,
+			},
+			"fail_backoff_minutes": &schema.Schema{
+				Type:     schema.TypeInt,
+				Optional: true,
+				Default:  5,
+			},
+			// the external account binding some acme servers require to create the account
+			"eab_kid": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"eab_hmac_key": &schema.Schema{
+				Type:      schema.TypeString,
+				Optional:  true,
+				Sensitive: true,
+			},
+			"storage": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "shm",
+				ValidateFunc: validateAcmeStorage,
+			},
+			"storage_config": &schema.Schema{
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Elem:     &schema.Resource{Schema: storageConfigSchema},
+			},
+		},
+		expandConfig:  expandAcmePluginConfig,
+		flattenConfig: flattenAcmePluginConfig,
+	})
+}
+
+func expandAcmePluginConfig(d *schema.ResourceData) (map[string]interface{}, error) {
+	// domains and the account binding are sent as null when they are not set, kong merges the config of an update so
+	// removing them would otherwise keep them
+	config := map[string]interface{}{
+		"account_email":        d.Get("account_email").(string),
+		"tos_accepted":         d.Get("tos_accepted").(bool),
+		"domains":              nil,
+		"allow_any_domain":     d.Get("allow_any_domain").(bool),
+		"cert_type":            d.Get("cert_type").(string),
+		"rsa_key_size":         d.Get("rsa_key_size").(int),
+		"renew_threshold_days": d.Get("renew_threshold_days").(int),
+		"fail_backoff_minutes": d.Get("fail_backoff_minutes").(int),
+		"eab_kid":              nil,
+		"eab_hmac_key":         nil,
+		"storage":              d.Get("storage").(string),
+	}
+
+	if domains := readStringArrayFromResource(d, "domains"); len(domains) > 0 {
+		config["domains"] = domains
+	}
+	if apiUri := readStringFromResource(d, "api_uri"); apiUri != "" {
+		config["api_uri"] = apiUri
+	}
+	if eabKid := readStringFromResource(d, "eab_kid"); eabKid != "" {
+		config["eab_kid"] = eabKid
+	}
+	if eabHmacKey := readStringFromResource(d, "eab_hmac_key"); eabHmacKey != "" {
+		config["eab_hmac_key"] = eabHmacKey
+	}
+
+	// kong merges the config of an update, the host of a backend that needs one is sent as null when its block is not
+	// set so a removed backend is not read back from kong
+	storage := d.Get("storage").(string)
+	storageConfig := map[string]interface{}{}
+	for backend, attributes := range acmeStorageBackends {
+		block := acmeStorageBackendBlock(d, backend)
+		if block != nil {
+			storageConfig[backend] = expandAcmeStorageBackend(attributes, block)
+			continue
+		}
+		if attributes["host"] == nil {
+			continue
+		}
+		if backend == storage {
+			return nil, fmt.Errorf("storage_config must have a %s block when storage is %s", storage, storage)
+		}
+		storageConfig[backend] = map[string]interface{}{"host": nil}
+	}
+	config["storage_config"] = storageConfig
+
+	return config, nil
+}
+
+func flattenAcmePluginConfig(d *schema.ResourceData, config map[string]interface{}) {
+	d.Set("account_email", configString(config["account_email"]))
+	d.Set("api_uri", configString(config["api_uri"]))
+	d.Set("tos_accepted", configBool(config["tos_accepted"]))
+	d.Set("domains", configStrings(config["domains"]))
+	d.Set("allow_any_domain", configBool(config["allow_any_domain"]))
+	d.Set("cert_type", configString(config["cert_type"]))
+	d.Set("rsa_key_size", configInt(config["rsa_key_size"]))
+	d.Set("renew_threshold_days", configInt(config["renew_threshold_days"]))
+	d.Set("fail_backoff_minutes", configInt(config["fail_backoff_minutes"]))
+	d.Set("eab_kid", configString(config["eab_kid"]))
+	d.Set("storage", configString(config["storage"]))
+
+	// the hmac key is kept from state when kong does not return it
+	eabHmacKey := configString(config["eab_hmac_key"])
+	if eabHmacKey == "" {
+		eabHmacKey = readStringFromResource(d, "eab_hmac_key")
+	}
+	d.Set("eab_hmac_key", eabHmacKey)
+
+	// kong returns the defaults of every backend, a backend is only in state when it was configured or (for the ones
+	// that need a host) kong has a host for it
+	storageConfig, _ := config["storage_config"].(map[string]interface{})
+	flattened := map[string]interface{}{}
+	for backend, attributes := range acmeStorageBackends {
+		kongBackend, _ := storageConfig[backend].(map[string]interface{})
+		state := acmeStorageBackendBlock(d, backend)
+		if kongBackend == nil || (state == nil && (attributes["host"] == nil || configString(kongBackend["host"]) == "")) {
+			continue
+		}
+		flattened[backend] = []map[string]interface{}{flattenAcmeStorageBackend(attributes, kongBackend, state)}
+	}
+
+	if len(flattened) == 0 {
+		d.Set("storage_config", nil)
+		return
+	}
+	d.Set("storage_config", []map[string]interface{}{flattened})
+}
+
+// acmeStorageBackendBlock returns the attributes of the backend's block in storage_config, nil when it is not set
+func acmeStorageBackendBlock(d *schema.ResourceData, backend string) map[string]interface{} {
+	blocks, _ := d.Get("storage_config.0." + backend).([]interface{})
+	if len(blocks) == 0 {
+		return nil
+	}
+	block, _ := blocks[0].(map[string]interface{})
+	if block == nil {
+		// a block without attributes, e.g. shm {} to keep the defaults
+		return map[string]interface{}{}
+	}
+	return block
+}
+
+func expandAcmeStorageBackend(attributes map[string]*schema.Schema, block map[string]interface{}) map[string]interface{} {
+	backend := map[string]interface{}{}
+	for name, attribute := range attributes {
+		value, ok := block[name]
+		if !ok {
+			value = attribute.Default
+		}
+		// strings that are not set are sent as null, kong rejects an empty string for most of them
+		if s, isString := value.(string); isString && s == "" {
+			value = nil
+		}
+		backend[name] = value
+	}
+	return backend
+}
+
+// flattenAcmeStorageBackend reads the attributes of a backend from kong, a sensitive attribute kong does not return is
+// kept from state
+func flattenAcmeStorageBackend(attributes map[string]*schema.Schema, kongBackend map[string]interface{}, state map[string]interface{}) map[string]interface{} {
+	backend := map[string]interface{}{}
+	for name, attribute := range attributes {
+		switch attribute.Type {
+		case schema.TypeInt:
+			backend[name] = configInt(kongBackend[name])
+		case schema.TypeBool:
+			backend[name] = configBool(kongBackend[name])
+		default:
+			value := configString(kongBackend[name])
+			if value == "" && attribute.Sensitive {
+				value, _ = state[name].(string)
+			}
+			backend[name] = value
+		}
+	}
+	return backend
+}
+
+func validateAcmeStorage(value interface{}, k string) ([]string, []error) {
+	if !contains(acmeStorages, value.(string)) {
+		return nil, []error{fmt.Errorf("%s must be one of %v, got: %s", k, acmeStorages, value)}
+	}
+	return nil, nil
+}
+
+func validateAcmeCertType(value interface{}, k string) ([]string, []error) {
+	if !contains(acmeCertTypes, value.(string)) {
+		return nil, []error{fmt.Errorf("%s must be one of %v, got: %s", k, acmeCertTypes, value)}
+	}
+	return nil, nil
+}
+
+func validateAcmeRsaKeySize(value interface{}, k string) ([]string, []error) {
+	for _, size := range acmeRsaKeySizes {
+		if value.(int) == size {
+			return nil, nil
+		}
+	}
+	return nil, []error{fmt.Errorf("%s must be one of %v, got: %d", k, acmeRsaKeySizes, value)}
+}
@@ -0,0 +1,177 @@
+package kong
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/kevholditch/gokong"
+)
+
+// newTestAcmeServer mocks a kong node keeping the plugin it is sent, it fills in the defaults of every storage backend
+// like kong does
+func newTestAcmeServer(t *testing.T) *typedPluginServer {
+	return newTypedPluginServer(t, "", func(config map[string]interface{}) {
+		if config["api_uri"] == nil {
+			config["api_uri"] = "https://acme-v02.api.letsencrypt.org/directory"
+		}
+		storageConfig, _ := config["storage_config"].(map[string]interface{})
+		if storageConfig == nil {
+			storageConfig = map[string]interface{}{}
+		}
+		defaults := map[string]interface{}{
+			"shm":    map[string]interface{}{"shm_name": "kong"},
+			"kong":   map[string]interface{}{},
+			"redis":  map[string]interface{}{"host": nil, "port": 6379, "database": 0, "auth": nil, "ssl": false, "namespace": ""},
+			"consul": map[string]interface{}{"host": nil, "port": 8500, "kv_path": nil, "https": false, "token": nil, "timeout": nil},
+			"vault":  map[string]interface{}{"host": nil, "port": 8200, "kv_path": nil, "https": false, "tls_verify": true, "token": nil},
+		}
+		for backend, values := range defaults {
+			if storageConfig[backend] == nil {
+				storageConfig[backend] = values
+			}
+		}
+		// kong returns every field of a configured backend too
+		if vault, ok := storageConfig["vault"].(map[string]interface{}); ok {
+			vault["auth_method"] = "token"
+			vault["timeout"] = 2000
+		}
+		config["storage_config"] = storageConfig
+	})
+}
+
+func TestKongPluginAcmeStorageConfig(t *testing.T) {
+
+	server := newTestAcmeServer(t)
+	defer server.Close()
+
+	client := newKongClient(&gokong.Config{HostAddress: server.URL})
+	r := resourceKongPluginAcme()
+	d := r.TestResourceData()
+	d.Set("account_email", "certs@example.com")
+	d.Set("tos_accepted", true)
+	d.Set("domains", []string{"example.com", "*.example.com", "api.example.com"})
+	d.Set("storage", "vault")
+	d.Set("storage_config", []interface{}{map[string]interface{}{
+		"vault": []interface{}{map[string]interface{}{"host": "vault.internal", "port": 8200, "kv_path": "acme", "https": true,
+			"tls_verify": true, "token": "s.vault-token"}},
+	}})
+	d.Set("cert_type", "ecc")
+	d.Set("rsa_key_size", 4096)
+	d.Set("renew_threshold_days", 30)
+	d.Set("fail_backoff_minutes", 5)
+	d.Set("enabled", true)
+
+	if err := r.Create(d, client); err != nil {
+		t.Fatalf("could not create acme plugin: %v", err)
+	}
+
+	config := server.sent[0]["config"].(map[string]interface{})
+	expectedStorageConfig := map[string]interface{}{
+		"vault":  map[string]interface{}{"host": "vault.internal", "port": 8200.0, "kv_path": "acme", "https": true, "tls_verify": true, "token": "s.vault-token"},
+		"redis":  map[string]interface{}{"host": nil},
+		"consul": map[string]interface{}{"host": nil},
+	}
+	if !reflect.DeepEqual(config["storage_config"], expectedStorageConfig) {
+		t.Errorf("expected the vault backend to be sent and the hosts of the others to be cleared, kong was sent: %v", config["storage_config"])
+	}
+	if !reflect.DeepEqual(config["domains"], []interface{}{"example.com", "*.example.com", "api.example.com"}) || config["tos_accepted"] != true ||
+		config["renew_threshold_days"] != 30.0 || config["eab_hmac_key"] != nil {
+		t.Errorf("expected the typed config to be sent, kong was sent: %v", config)
+	}
+	if _, ok := config["api_uri"]; ok {
+		t.Errorf("expected api_uri not to be sent when it is not set, kong was sent: %v", config)
+	}
+
+	if domains := readStringArrayFromResource(d, "domains"); strings.Join(domains, ",") != "example.com,*.example.com,api.example.com" {
+		t.Errorf("expected the domains to be read back in order, got: %v", domains)
+	}
+	if d.Get("api_uri") != "https://acme-v02.api.letsencrypt.org/directory" {
+		t.Errorf("expected kong's default api_uri to be read back, got: %v", d.Get("api_uri"))
+	}
+
+	// the defaults kong fills in for the other backends and the vault fields the block does not have are not read back
+	storageConfig := d.Get("storage_config").([]interface{})
+	if len(storageConfig) != 1 {
+		t.Fatalf("expected one storage_config block, got: %v", storageConfig)
+	}
+	backends := storageConfig[0].(map[string]interface{})
+	if len(backends["redis"].([]interface{})) != 0 || len(backends["consul"].([]interface{})) != 0 || len(backends["shm"].([]interface{})) != 0 {
+		t.Errorf("expected only the vault backend to be read back, got: %v", backends)
+	}
+	vault := d.Get("storage_config.0.vault.0").(map[string]interface{})
+	if len(vault) != 6 || vault["host"] != "vault.internal" || vault["kv_path"] != "acme" || vault["https"] != true || vault["token"] != "s.vault-token" {
+		t.Errorf("expected the vault backend to be read back, got: %v", vault)
+	}
+
+	// switching to redis sends its block and clears the vault host, the domains that were removed are sent as null
+	d.Set("storage", "redis")
+	d.Set("domains", []string{})
+	d.Set("storage_config", []interface{}{map[string]interface{}{
+		"redis": []interface{}{map[string]interface{}{"host": "redis.internal", "port": 6380, "database": 1, "auth": "redis-password"}},
+		"vault": []interface{}{},
+	}})
+	if err := r.Update(d, client); err != nil {
+		t.Fatalf("could not update acme plugin: %v", err)
+	}
+
+	config = server.sent[1]["config"].(map[string]interface{})
+	redis, _ := config["storage_config"].(map[string]interface{})["redis"].(map[string]interface{})
+	if config["domains"] != nil || !reflect.DeepEqual(redis, map[string]interface{}{"host": "redis.internal", "port": 6380.0, "database": 1.0,
+		"auth": "redis-password", "ssl": false, "namespace": nil}) || !reflect.DeepEqual(config["storage_config"].(map[string]interface{})["vault"], map[string]interface{}{"host": nil}) {
+		t.Errorf("expected the redis backend to be sent and the domains to be cleared, kong was sent: %v", config)
+	}
+	if d.Get("storage_config.0.redis.0.auth") != "redis-password" || d.Get("storage_config.0.redis.0.port") != 6380 ||
+		len(d.Get("storage_config.0.vault").([]interface{})) != 0 {
+		t.Errorf("expected the redis backend to be read back, got: %v", d.Get("storage_config"))
+	}
+}
+
+func TestKongPluginAcmeInvalidConfig(t *testing.T) {
+
+	server := newTestAcmeServer(t)
+	defer server.Close()
+
+	client := newKongClient(&gokong.Config{HostAddress: server.URL})
+	r := resourceKongPluginAcme()
+	d := r.TestResourceData()
+	d.Set("account_email", "certs@example.com")
+	d.Set("storage", "consul")
+
+	if err := r.Create(d, client); err == nil || !strings.Contains(err.Error(), "storage_config must have a consul block") {
+		t.Errorf("expected the consul storage to need a consul block, got: %v", err)
+	}
+	if len(server.sent) != 0 {
+		t.Errorf("expected nothing to be sent to kong, kong was sent: %v", server.sent)
+	}
+
+	// the defaults of shm and kong do not need a block
+	d.Set("storage", "kong")
+	if err := r.Create(d, client); err != nil {
+		t.Errorf("expected the kong storage not to need a block, got: %v", err)
+	}
+	if storageConfig := server.sent[0]["config"].(map[string]interface{})["storage_config"].(map[string]interface{}); len(storageConfig) != 3 || storageConfig["shm"] != nil {
+		t.Errorf("expected only the hosts of the backends that need one to be sent, kong was sent: %v", storageConfig)
+	}
+
+	if _, errors := validateAcmeStorage("s3", "storage"); len(errors) != 1 {
+		t.Errorf("expected an unknown storage to be rejected, got: %v", errors)
+	}
+	if _, errors := validateAcmeRsaKeySize(1024, "rsa_key_size"); len(errors) != 1 {
+		t.Errorf("expected a 1024 bit key to be rejected, got: %v", errors)
+	}
+	if _, errors := validateAcmeCertType("dsa", "cert_type"); len(errors) != 1 {
+		t.Errorf("expected an unknown cert type to be rejected, got: %v", errors)
+	}
+
+	for _, path := range [][]string{{"eab_hmac_key"}, {"storage_config", "redis", "auth"}, {"storage_config", "consul", "token"}, {"storage_config", "vault", "token"}} {
+		attribute := r.Schema[path[0]]
+		for _, key := range path[1:] {
+			attribute = attribute.Elem.(*schema.Resource).Schema[key]
+		}
+		if !attribute.Sensitive {
+			t.Errorf("expected %s to be sensitive", strings.Join(path, "."))
+		}
+	}
+}
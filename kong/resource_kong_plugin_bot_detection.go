@@ -0,0 +1,82 @@
+package kong
+
+import (
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+const botDetectionPluginName = "bot-detection"
+
+func resourceKongPluginBotDetection() *schema.Resource {
+	return resourceKongTypedPlugin(&typedPlugin{
+		name: botDetectionPluginName,
+		schema: map[string]*schema.Schema{
+			// regexes matched against the user agent, an allowed user agent is let through even when kong's own list
+			// of bots has it
+			"allow": &schema.Schema{
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validateBotDetectionRegex,
+				},
+			},
+			"deny": &schema.Schema{
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validateBotDetectionRegex,
+				},
+			},
+		},
+		expandConfig:  expandBotDetectionPluginConfig,
+		flattenConfig: flattenBotDetectionPluginConfig,
+		resolveConfig: func(client *kongClient, d *schema.ResourceData, config map[string]interface{}) error {
+			return applyPluginConfigAliases(client, botDetectionPluginName, config)
+		},
+	})
+}
+
+func expandBotDetectionPluginConfig(d *schema.ResourceData) (map[string]interface{}, error) {
+	// a list that is not set is sent as null, kong merges the config of an update so removing it would otherwise keep it
+	config := map[string]interface{}{
+		"allow": nil,
+		"deny":  nil,
+	}
+
+	if allow := readStringSetFromResource(d, "allow"); len(allow) > 0 {
+		config["allow"] = allow
+	}
+	if deny := readStringSetFromResource(d, "deny"); len(deny) > 0 {
+		config["deny"] = deny
+	}
+
+	return config, nil
+}
+
+func flattenBotDetectionPluginConfig(d *schema.ResourceData, config map[string]interface{}) {
+	// kong before 2.1 returns the lists as whitelist and blacklist
+	normalizePluginConfigAliases(botDetectionPluginName, config)
+
+	d.Set("allow", configStrings(config["allow"]))
+	d.Set("deny", configStrings(config["deny"]))
+}
+
+// validateBotDetectionRegex rejects a regex that does not compile. Kong matches with PCRE, the lookarounds and
+// backreferences go's regexp does not have are only a warning as kong may still accept them.
+func validateBotDetectionRegex(v interface{}, k string) ([]string, []error) {
+	value := v.(string)
+	_, err := regexp.Compile(value)
+	if err == nil {
+		return nil, nil
+	}
+
+	if syntaxErr, ok := err.(*syntax.Error); ok && (syntaxErr.Code == syntax.ErrInvalidPerlOp || syntaxErr.Code == syntax.ErrInvalidEscape) {
+		return []string{fmt.Sprintf("%s could not be checked, kong validates it: %v", k, err)}, nil
+	}
+	return nil, []error{fmt.Errorf("%s must be a valid regex, got: %s (%v)", k, value, err)}
+}
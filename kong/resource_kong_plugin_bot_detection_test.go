@@ -0,0 +1,95 @@
+package kong
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/config"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/kevholditch/gokong"
+)
+
+func TestKongPluginBotDetection(t *testing.T) {
+
+	server := newTypedPluginServer(t, "3.6.1", func(config map[string]interface{}) {
+		// kong returns the lists that were not set as empty lists and the others in another order
+		for _, key := range []string{"allow", "deny"} {
+			list, _ := config[key].([]interface{})
+			sort.Slice(list, func(i, j int) bool { return list[i].(string) > list[j].(string) })
+			if list == nil {
+				list = []interface{}{}
+			}
+			config[key] = list
+		}
+	})
+	defer server.Close()
+
+	r := resourceKongPluginBotDetection()
+	rawConfig, err := config.NewRawConfig(map[string]interface{}{
+		"deny": []interface{}{"(?i)^curl/", "python-requests", `^Scrapy/\d+`},
+	})
+	if err != nil {
+		t.Fatalf("could not build config: %v", err)
+	}
+	resourceConfig := terraform.NewResourceConfig(rawConfig)
+	if warnings, errors := r.Validate(resourceConfig); len(warnings) != 0 || len(errors) != 0 {
+		t.Fatalf("expected the regexes to be valid, got: %v %v", warnings, errors)
+	}
+
+	client := newKongClient(&gokong.Config{HostAddress: server.URL})
+	diff, err := r.Diff(nil, resourceConfig)
+	if err != nil {
+		t.Fatalf("could not diff bot-detection plugin: %v", err)
+	}
+	state, err := r.Apply(nil, diff, client)
+	if err != nil {
+		t.Fatalf("could not create bot-detection plugin: %v", err)
+	}
+
+	sentConfig := server.sent[0]["config"].(map[string]interface{})
+	if deny, _ := sentConfig["deny"].([]interface{}); server.sent[0]["name"] != "bot-detection" || len(deny) != 3 || sentConfig["allow"] != nil {
+		t.Errorf("expected the deny regexes to be sent and allow to be null, kong was sent: %v", server.sent[0])
+	}
+
+	// the order kong returns the regexes in is not a change
+	state, err = r.Refresh(state, client)
+	if err != nil {
+		t.Fatalf("could not refresh bot-detection plugin: %v", err)
+	}
+	if diff, err := r.Diff(state, resourceConfig); err != nil || !diff.Empty() {
+		t.Errorf("expected no changes after the refresh, got: %v %v", diff, err)
+	}
+
+	rawConfig, err = config.NewRawConfig(map[string]interface{}{
+		"allow": []interface{}{"^googlebot", "[unclosed"},
+	})
+	if err != nil {
+		t.Fatalf("could not build config: %v", err)
+	}
+	if _, errors := r.Validate(terraform.NewResourceConfig(rawConfig)); len(errors) != 1 || !strings.Contains(errors[0].Error(), "must be a valid regex, got: [unclosed") {
+		t.Errorf("expected the invalid regex to be rejected, got: %v", errors)
+	}
+}
+
+func TestValidateBotDetectionRegex(t *testing.T) {
+
+	for _, regex := range []string{"^curl/", `(?i)bot\b`, "Mozilla/5.0 \\(compatible; [a-z]+\\)"} {
+		if warnings, errors := validateBotDetectionRegex(regex, "deny"); len(warnings) != 0 || len(errors) != 0 {
+			t.Errorf("expected %s to be valid, got: %v %v", regex, warnings, errors)
+		}
+	}
+
+	for _, regex := range []string{"[a-", "(unclosed", "*bot"} {
+		if _, errors := validateBotDetectionRegex(regex, "deny"); len(errors) != 1 {
+			t.Errorf("expected %s to be rejected, got: %v", regex, errors)
+		}
+	}
+
+	// pcre lookarounds and backreferences are left to kong
+	for _, regex := range []string{"^(?!friendly).*bot", `(a)\1`} {
+		if warnings, errors := validateBotDetectionRegex(regex, "deny"); len(warnings) != 1 || len(errors) != 0 {
+			t.Errorf("expected %s to only be a warning, got: %v %v", regex, warnings, errors)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package kong
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func resourceKongPluginCors() *schema.Resource {
+	return resourceKongTypedPlugin(&typedPlugin{
+		name: "cors",
+		schema: map[string]*schema.Schema{
+			// the lists are sets, kong does not care about the order of origins, methods or headers
+			"origins": &schema.Schema{
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			// kong defaults methods to all of them, it is computed so that default is not a change when it is not set
+			"methods": &schema.Schema{
+				Type:     schema.TypeSet,
+				Optional: true,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"headers": &schema.Schema{
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"exposed_headers": &schema.Schema{
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"credentials": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			// 0 leaves Access-Control-Max-Age out of preflight responses
+			"max_age": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				ValidateFunc: validateCorsMaxAge,
+			},
+		},
+		expandConfig:  expandCorsPluginConfig,
+		flattenConfig: flattenCorsPluginConfig,
+	})
+}
+
+func expandCorsPluginConfig(d *schema.ResourceData) (map[string]interface{}, error) {
+	// lists that are not set are sent as null, kong merges the config of an update so removing one would otherwise
+	// keep it
+	config := map[string]interface{}{
+		"origins":         nil,
+		"headers":         nil,
+		"exposed_headers": nil,
+		"credentials":     d.Get("credentials").(bool),
+		"max_age":         nil,
+	}
+
+	for _, key := range []string{"origins", "methods", "headers", "exposed_headers"} {
+		if values := readStringSetFromResource(d, key); len(values) > 0 {
+			sort.Strings(values)
+			config[key] = values
+		}
+	}
+
+	if maxAge := d.Get("max_age").(int); maxAge > 0 {
+		config["max_age"] = maxAge
+	}
+
+	return config, nil
+}
+
+func flattenCorsPluginConfig(d *schema.ResourceData, config map[string]interface{}) {
+	d.Set("origins", configStrings(config["origins"]))
+	d.Set("methods", configStrings(config["methods"]))
+	d.Set("headers", configStrings(config["headers"]))
+	d.Set("exposed_headers", configStrings(config["exposed_headers"]))
+	d.Set("credentials", configBool(config["credentials"]))
+	d.Set("max_age", configInt(config["max_age"]))
+}
+
+func validateCorsMaxAge(v interface{}, k string) ([]string, []error) {
+	if maxAge := v.(int); maxAge < 0 {
+		return nil, []error{fmt.Errorf("%s must not be negative, got: %d", k, maxAge)}
+	}
+	return nil, nil
+}
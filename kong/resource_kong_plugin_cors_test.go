@@ -0,0 +1,153 @@
+package kong
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/kevholditch/gokong"
+)
+
+func TestAccKongPluginCors(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckKongTypedPluginDestroy("kong_plugin_cors"),
+		Steps: []resource.TestStep{
+			{
+				Config: testCreateCorsPluginConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckKongPluginExists("kong_plugin_cors.cors"),
+					testAccCheckForChildIdCorrect("kong_service.service", "kong_plugin_cors.cors", "service_id"),
+					resource.TestCheckResourceAttr("kong_plugin_cors.cors", "origins.#", "2"),
+					resource.TestCheckResourceAttr("kong_plugin_cors.cors", "methods.#", "2"),
+					resource.TestCheckResourceAttr("kong_plugin_cors.cors", "exposed_headers.#", "1"),
+					resource.TestCheckResourceAttr("kong_plugin_cors.cors", "credentials", "true"),
+					resource.TestCheckResourceAttr("kong_plugin_cors.cors", "max_age", "3600"),
+				),
+			},
+			{
+				Config: testUpdateCorsPluginConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckKongPluginExists("kong_plugin_cors.cors"),
+					resource.TestCheckResourceAttr("kong_plugin_cors.cors", "origins.#", "1"),
+					resource.TestCheckResourceAttr("kong_plugin_cors.cors", "exposed_headers.#", "0"),
+					resource.TestCheckResourceAttr("kong_plugin_cors.cors", "credentials", "false"),
+					resource.TestCheckResourceAttr("kong_plugin_cors.cors", "max_age", "0"),
+				),
+			},
+			{
+				ResourceName:      "kong_plugin_cors.cors",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestKongPluginCorsSendsTypedConfig(t *testing.T) {
+
+	server := newTypedPluginServer(t, "", func(config map[string]interface{}) {
+		// kong fills in the default methods and returns the lists in its own order
+		if _, ok := config["methods"]; !ok {
+			config["methods"] = []interface{}{"GET", "HEAD", "PUT", "PATCH", "POST", "DELETE", "OPTIONS", "TRACE", "CONNECT"}
+		}
+		if origins, ok := config["origins"].([]interface{}); ok {
+			for i, j := 0, len(origins)-1; i < j; i, j = i+1, j-1 {
+				origins[i], origins[j] = origins[j], origins[i]
+			}
+		}
+	})
+	defer server.Close()
+
+	client := newKongClient(&gokong.Config{HostAddress: server.URL})
+
+	r := resourceKongPluginCors()
+	d := r.TestResourceData()
+	d.Set("origins", []string{"https://b.example.com", "https://a.example.com"})
+	d.Set("credentials", true)
+	d.Set("max_age", 3600)
+	d.Set("enabled", true)
+
+	if err := r.Create(d, client); err != nil {
+		t.Fatalf("could not create cors plugin: %v", err)
+	}
+
+	d.Set("credentials", false)
+	d.Set("max_age", 0)
+	d.Set("exposed_headers", []string{"X-Request-Id"})
+	if err := r.Update(d, client); err != nil {
+		t.Fatalf("could not update cors plugin: %v", err)
+	}
+
+	if len(server.sent) != 2 {
+		t.Fatalf("expected the plugin to be created and updated, kong was sent: %v", server.sent)
+	}
+
+	origins := []interface{}{"https://a.example.com", "https://b.example.com"}
+	expected := []map[string]interface{}{
+		{"origins": origins, "headers": nil, "exposed_headers": nil, "credentials": true, "max_age": float64(3600)},
+		{"origins": origins, "headers": nil, "exposed_headers": []interface{}{"X-Request-Id"}, "credentials": false, "max_age": nil,
+			"methods": []interface{}{"CONNECT", "DELETE", "GET", "HEAD", "OPTIONS", "PATCH", "POST", "PUT", "TRACE"}},
+	}
+
+	for i, request := range server.sent {
+		if config := request["config"]; !reflect.DeepEqual(config, expected[i]) {
+			t.Errorf("request %d: expected the config %#v with a json list of origins and boolean credentials, got: %#v", i, expected[i], config)
+		}
+	}
+
+	if server.sent[0]["name"] != "cors" {
+		t.Errorf("expected a cors plugin to be created, got: %v", server.sent[0]["name"])
+	}
+
+	// the origins kong returned in another order are the same set
+	if originsRead := readStringSetFromResource(d, "origins"); d.Id() != "plugin-id" || len(originsRead) != 2 || d.Get("credentials").(bool) ||
+		d.Get("methods").(*schema.Set).Len() != 9 {
+		t.Errorf("expected the config to be read back from kong, got origins: %v credentials: %v methods: %v",
+			originsRead, d.Get("credentials"), d.Get("methods"))
+	}
+}
+
+func TestValidateCorsMaxAge(t *testing.T) {
+
+	if _, errors := validateCorsMaxAge(-1, "max_age"); len(errors) != 1 {
+		t.Errorf("expected a negative max_age to be rejected, got: %v", errors)
+	}
+
+	if _, errors := validateCorsMaxAge(0, "max_age"); len(errors) != 0 {
+		t.Errorf("expected max_age 0 to be valid, got: %v", errors)
+	}
+}
+
+const testCreateCorsPluginConfig = `
+resource "kong_service" "service" {
+	name     = "test"
+	protocol = "http"
+	host     = "test.org"
+}
+
+resource "kong_plugin_cors" "cors" {
+	service_id      = "${kong_service.service.id}"
+	origins         = ["https://a.example.com", "https://b.example.com"]
+	methods         = ["GET", "POST"]
+	exposed_headers = ["X-Request-Id"]
+	credentials     = true
+	max_age         = 3600
+}
+`
+
+const testUpdateCorsPluginConfig = `
+resource "kong_service" "service" {
+	name     = "test"
+	protocol = "http"
+	host     = "test.org"
+}
+
+resource "kong_plugin_cors" "cors" {
+	service_id = "${kong_service.service.id}"
+	origins    = ["https://a.example.com"]
+	methods    = ["GET", "POST"]
+}
+`
@@ -0,0 +1,129 @@
+package kong
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+var httpLogMethods = []string{"POST", "PUT", "PATCH"}
+
+func resourceKongPluginHttpLog() *schema.Resource {
+	return resourceKongTypedPlugin(&typedPlugin{
+		name: "http-log",
+		schema: map[string]*schema.Schema{
+			// kong normalizes the url it is sent (see normalizeUrl), the normalized url read back is not a change
+			"http_endpoint": &schema.Schema{
+				Type:             schema.TypeString,
+				Required:         true,
+				DiffSuppressFunc: suppressEquivalentUrl,
+			},
+			"method": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "POST",
+				ValidateFunc: validateHttpLogMethod,
+			},
+			"content_type": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "application/json",
+			},
+			// in milliseconds
+			"timeout": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      10000,
+				ValidateFunc: validateHttpLogPositive,
+			},
+			// in milliseconds
+			"keepalive": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      60000,
+				ValidateFunc: validateHttpLogPositive,
+			},
+			// in seconds, kong 3 replaced flush_timeout and retry_count with its queue settings and may not return
+			// them, they are only sent when set
+			"flush_timeout": &schema.Schema{
+				Type:     schema.TypeFloat,
+				Optional: true,
+				Computed: true,
+			},
+			"retry_count": &schema.Schema{
+				Type:     schema.TypeInt,
+				Optional: true,
+				Computed: true,
+			},
+			// field name to the lua code that computes its value, e.g. { "route_name" = "return kong.router.get_route().name" }
+			"custom_fields_by_lua": &schema.Schema{
+				Type:     schema.TypeMap,
+				Optional: true,
+				Elem:     schema.TypeString,
+			},
+		},
+		expandConfig:  expandHttpLogPluginConfig,
+		flattenConfig: flattenHttpLogPluginConfig,
+	})
+}
+
+func expandHttpLogPluginConfig(d *schema.ResourceData) (map[string]interface{}, error) {
+	// custom_fields_by_lua is sent as null when it is not set, kong merges the config of an update so removing it
+	// would otherwise keep the fields
+	config := map[string]interface{}{
+		"http_endpoint":        d.Get("http_endpoint").(string),
+		"method":               d.Get("method").(string),
+		"content_type":         d.Get("content_type").(string),
+		"timeout":              d.Get("timeout").(int),
+		"keepalive":            d.Get("keepalive").(int),
+		"custom_fields_by_lua": nil,
+	}
+
+	if flushTimeout, ok := d.GetOk("flush_timeout"); ok {
+		config["flush_timeout"] = flushTimeout.(float64)
+	}
+	if retryCount, ok := d.GetOk("retry_count"); ok {
+		config["retry_count"] = retryCount.(int)
+	}
+	if fields := readMapFromResource(d, "custom_fields_by_lua"); len(fields) > 0 {
+		config["custom_fields_by_lua"] = fields
+	}
+
+	return config, nil
+}
+
+func flattenHttpLogPluginConfig(d *schema.ResourceData, config map[string]interface{}) {
+	d.Set("http_endpoint", configString(config["http_endpoint"]))
+	d.Set("method", configString(config["method"]))
+	d.Set("content_type", configString(config["content_type"]))
+	d.Set("timeout", configInt(config["timeout"]))
+	d.Set("keepalive", configInt(config["keepalive"]))
+	d.Set("flush_timeout", configFloat(config["flush_timeout"]))
+	d.Set("retry_count", configInt(config["retry_count"]))
+
+	fields := map[string]string{}
+	if values, ok := config["custom_fields_by_lua"].(map[string]interface{}); ok {
+		for name, value := range values {
+			fields[name] = configString(value)
+		}
+	}
+	d.Set("custom_fields_by_lua", fields)
+}
+
+func suppressEquivalentUrl(k, old, new string, d *schema.ResourceData) bool {
+	return normalizeUrl(old) == normalizeUrl(new)
+}
+
+func validateHttpLogMethod(value interface{}, k string) ([]string, []error) {
+	if !contains(httpLogMethods, value.(string)) {
+		return nil, []error{fmt.Errorf("%s must be one of %v, got: %s", k, httpLogMethods, value)}
+	}
+	return nil, nil
+}
+
+func validateHttpLogPositive(v interface{}, k string) ([]string, []error) {
+	if value := v.(int); value <= 0 {
+		return nil, []error{fmt.Errorf("%s must be greater than 0, got: %d", k, value)}
+	}
+	return nil, nil
+}
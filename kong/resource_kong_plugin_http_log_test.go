@@ -0,0 +1,81 @@
+package kong
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/config"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/kevholditch/gokong"
+)
+
+func TestKongPluginHttpLog(t *testing.T) {
+
+	server := newTypedPluginServer(t, "", func(config map[string]interface{}) {
+		// kong normalizes the endpoint and fills in the queue settings of kong 3
+		config["http_endpoint"] = normalizeUrl(config["http_endpoint"].(string))
+		config["queue"] = map[string]interface{}{"max_batch_size": 1, "max_coalescing_delay": 1}
+		if config["retry_count"] == nil {
+			config["retry_count"] = 10
+		}
+	})
+	defer server.Close()
+
+	r := resourceKongPluginHttpLog()
+	raw := map[string]interface{}{
+		"http_endpoint":        "HTTPS://Logs.Example.com:443/",
+		"method":               "PUT",
+		"timeout":              5000,
+		"keepalive":            30000,
+		"flush_timeout":        1.5,
+		"custom_fields_by_lua": map[string]interface{}{"route_name": "return kong.router.get_route().name"},
+	}
+	rawConfig, err := config.NewRawConfig(raw)
+	if err != nil {
+		t.Fatalf("could not build config: %v", err)
+	}
+
+	diff, err := r.Diff(nil, terraform.NewResourceConfig(rawConfig))
+	if err != nil {
+		t.Fatalf("could not diff http-log plugin: %v", err)
+	}
+	state, err := r.Apply(nil, diff, newKongClient(&gokong.Config{HostAddress: server.URL}))
+	if err != nil {
+		t.Fatalf("could not create http-log plugin: %v", err)
+	}
+	if len(server.bodies) != 1 {
+		t.Fatalf("expected the plugin to be created, kong was sent: %v", server.bodies)
+	}
+	body := server.bodies[0]
+
+	for _, expected := range []string{`"timeout":5000}`, `"keepalive":30000,`, `"flush_timeout":1.5,`, `"method":"PUT"`,
+		`"custom_fields_by_lua":{"route_name":"return kong.router.get_route().name"}`} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("expected %s to be sent to kong, kong was sent: %s", expected, body)
+		}
+	}
+	if strings.Contains(body, "retry_count") {
+		t.Errorf("expected retry_count not to be sent when it is not set, kong was sent: %s", body)
+	}
+
+	if state.Attributes["http_endpoint"] != "https://logs.example.com" || state.Attributes["retry_count"] != "10" ||
+		state.Attributes["flush_timeout"] != "1.5" || state.Attributes["custom_fields_by_lua.route_name"] != "return kong.router.get_route().name" {
+		t.Errorf("expected the config to be read back from kong, got: %v", state.Attributes)
+	}
+
+	// the endpoint kong normalized is the one that was configured
+	diff, err = r.Diff(state, terraform.NewResourceConfig(rawConfig))
+	if err != nil {
+		t.Fatalf("could not diff http-log plugin: %v", err)
+	}
+	if diff != nil && !diff.Empty() {
+		t.Errorf("expected no diff after kong normalized the endpoint, got: %v", diff.Attributes)
+	}
+
+	if _, errors := validateHttpLogMethod("GET", "method"); len(errors) != 1 {
+		t.Errorf("expected GET to be rejected, got: %v", errors)
+	}
+	if _, errors := validateHttpLogPositive(0, "timeout"); len(errors) != 1 {
+		t.Errorf("expected a timeout of 0 to be rejected, got: %v", errors)
+	}
+}
@@ -0,0 +1,111 @@
+package kong
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+const ipRestrictionPluginName = "ip-restriction"
+
+func resourceKongPluginIpRestriction() *schema.Resource {
+	return resourceKongTypedPlugin(&typedPlugin{
+		name: ipRestrictionPluginName,
+		schema: map[string]*schema.Schema{
+			// ips or cidr ranges, kong matches them in any order
+			"allow": &schema.Schema{
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validateIpRestrictionCidr,
+				},
+			},
+			"deny": &schema.Schema{
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validateIpRestrictionCidr,
+				},
+			},
+			// the response to a denied request, computed as kong fills in its defaults (403 and "Your IP address is
+			// not allowed") when they are not set
+			"status": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Computed:     true,
+				ValidateFunc: validateIpRestrictionStatus,
+			},
+			"message": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+		},
+		expandConfig:  expandIpRestrictionPluginConfig,
+		flattenConfig: flattenIpRestrictionPluginConfig,
+		resolveConfig: func(client *kongClient, d *schema.ResourceData, config map[string]interface{}) error {
+			return applyPluginConfigAliases(client, ipRestrictionPluginName, config)
+		},
+	})
+}
+
+func expandIpRestrictionPluginConfig(d *schema.ResourceData) (map[string]interface{}, error) {
+	allow := readStringSetFromResource(d, "allow")
+	deny := readStringSetFromResource(d, "deny")
+	if len(allow) == 0 && len(deny) == 0 {
+		return nil, fmt.Errorf("at least one of allow or deny must be set")
+	}
+
+	// a list that is not set is sent as null, kong merges the config of an update so removing it would otherwise keep it
+	config := map[string]interface{}{
+		"allow": nil,
+		"deny":  nil,
+	}
+
+	if len(allow) > 0 {
+		config["allow"] = allow
+	}
+	if len(deny) > 0 {
+		config["deny"] = deny
+	}
+	if status, ok := d.GetOk("status"); ok {
+		config["status"] = status.(int)
+	}
+	if message := readStringFromResource(d, "message"); message != "" {
+		config["message"] = message
+	}
+
+	return config, nil
+}
+
+func flattenIpRestrictionPluginConfig(d *schema.ResourceData, config map[string]interface{}) {
+	// kong before 2.1 returns the lists as whitelist and blacklist
+	normalizePluginConfigAliases(ipRestrictionPluginName, config)
+
+	d.Set("allow", configStrings(config["allow"]))
+	d.Set("deny", configStrings(config["deny"]))
+	d.Set("status", configInt(config["status"]))
+	d.Set("message", configString(config["message"]))
+}
+
+// validateIpRestrictionCidr accepts an ip or a cidr range, a single ip is the range of that address
+func validateIpRestrictionCidr(v interface{}, k string) ([]string, []error) {
+	value := v.(string)
+	if net.ParseIP(value) != nil {
+		return nil, nil
+	}
+	if _, _, err := net.ParseCIDR(value); err != nil {
+		return nil, []error{fmt.Errorf("%s must be an ip or a cidr range such as 10.0.0.0/8, got: %s", k, value)}
+	}
+	return nil, nil
+}
+
+func validateIpRestrictionStatus(v interface{}, k string) ([]string, []error) {
+	if value := v.(int); value < 100 || value > 599 {
+		return nil, []error{fmt.Errorf("%s must be an http status between 100 and 599, got: %d", k, value)}
+	}
+	return nil, nil
+}
@@ -0,0 +1,128 @@
+package kong
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/config"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/kevholditch/gokong"
+)
+
+func TestKongPluginIpRestriction(t *testing.T) {
+
+	server := newTypedPluginServer(t, "2.8.1", func(config map[string]interface{}) {
+		// kong fills in the deny response and returns the lists in another order
+		if config["status"] == nil {
+			config["status"] = 403
+		}
+		if config["message"] == nil {
+			config["message"] = "Your IP address is not allowed"
+		}
+		for _, key := range []string{"allow", "whitelist"} {
+			if list, ok := config[key].([]interface{}); ok {
+				sort.Slice(list, func(i, j int) bool { return list[i].(string) > list[j].(string) })
+			}
+		}
+	})
+	defer server.Close()
+
+	r := resourceKongPluginIpRestriction()
+	raw := map[string]interface{}{
+		"allow": []interface{}{"10.0.0.0/8", "192.168.1.10", "fd00::/64"},
+	}
+	rawConfig, err := config.NewRawConfig(raw)
+	if err != nil {
+		t.Fatalf("could not build config: %v", err)
+	}
+
+	client := newKongClient(&gokong.Config{HostAddress: server.URL})
+	diff, err := r.Diff(nil, terraform.NewResourceConfig(rawConfig))
+	if err != nil {
+		t.Fatalf("could not diff ip-restriction plugin: %v", err)
+	}
+	state, err := r.Apply(nil, diff, client)
+	if err != nil {
+		t.Fatalf("could not create ip-restriction plugin: %v", err)
+	}
+
+	sentConfig := server.sent[0]["config"].(map[string]interface{})
+	if allow, _ := sentConfig["allow"].([]interface{}); len(allow) != 3 || sentConfig["deny"] != nil {
+		t.Errorf("expected the allow list to be sent and deny to be null, kong was sent: %v", sentConfig)
+	}
+	if _, ok := sentConfig["status"]; ok {
+		t.Errorf("expected status not to be sent when it is not set, kong was sent: %v", sentConfig)
+	}
+	if state.Attributes["allow.#"] != "3" || state.Attributes["status"] != "403" || state.Attributes["message"] != "Your IP address is not allowed" {
+		t.Errorf("expected the config to be read back from kong, got: %v", state.Attributes)
+	}
+
+	// kong returned the list in another order and the config lists it in yet another, neither is a change
+	raw["allow"] = []interface{}{"fd00::/64", "10.0.0.0/8", "192.168.1.10"}
+	rawConfig, _ = config.NewRawConfig(raw)
+	diff, err = r.Diff(state, terraform.NewResourceConfig(rawConfig))
+	if err != nil {
+		t.Fatalf("could not diff ip-restriction plugin: %v", err)
+	}
+	if diff != nil && !diff.Empty() {
+		t.Errorf("expected no diff for a reordered allow list, got: %v", diff.Attributes)
+	}
+
+	// switching to deny on kong before 2.1 sends the old name and clears the allow list
+	server.kongVersion = "2.0.5"
+	client = newKongClient(&gokong.Config{HostAddress: server.URL})
+	rawConfig, _ = config.NewRawConfig(map[string]interface{}{"deny": []interface{}{"203.0.113.0/24"}, "status": 401, "message": "go away"})
+	diff, err = r.Diff(state, terraform.NewResourceConfig(rawConfig))
+	if err != nil {
+		t.Fatalf("could not diff ip-restriction plugin: %v", err)
+	}
+	state, err = r.Apply(state, diff, client)
+	if err != nil {
+		t.Fatalf("could not update ip-restriction plugin: %v", err)
+	}
+	sentConfig = server.sent[1]["config"].(map[string]interface{})
+	if blacklist, _ := sentConfig["blacklist"].([]interface{}); len(blacklist) != 1 || sentConfig["whitelist"] != nil || sentConfig["status"] != 401.0 ||
+		sentConfig["message"] != "go away" {
+		t.Errorf("expected the deny list to be sent as blacklist with the deny response, kong was sent: %v", sentConfig)
+	}
+	if state.Attributes["deny.#"] != "1" || state.Attributes["allow.#"] != "0" || state.Attributes["status"] != "401" {
+		t.Errorf("expected the blacklist to be read back as deny, got: %v", state.Attributes)
+	}
+}
+
+func TestKongPluginIpRestrictionInvalidCidrs(t *testing.T) {
+
+	r := resourceKongPluginIpRestriction()
+	cases := []struct {
+		raw      map[string]interface{}
+		expected string
+	}{
+		{map[string]interface{}{"allow": []interface{}{"10.0.0.0/8", "10.0.0.0/33"}}, "10.0.0.0/33"},
+		{map[string]interface{}{"deny": []interface{}{"not-an-ip"}}, "not-an-ip"},
+		{map[string]interface{}{"deny": []interface{}{"192.168.1.256"}}, "192.168.1.256"},
+		{map[string]interface{}{"allow": []interface{}{"10.0.0.1"}, "status": 99}, "between 100 and 599"},
+	}
+
+	for _, c := range cases {
+		rawConfig, err := config.NewRawConfig(c.raw)
+		if err != nil {
+			t.Fatalf("could not build config: %v", err)
+		}
+		_, errors := r.Validate(terraform.NewResourceConfig(rawConfig))
+		if len(errors) != 1 || !strings.Contains(errors[0].Error(), c.expected) {
+			t.Errorf("%v: expected %s to be rejected, got: %v", c.raw, c.expected, errors)
+		}
+	}
+
+	for _, valid := range []string{"10.0.0.1", "10.0.0.0/8", "0.0.0.0/0", "fd00::1", "fd00::/64"} {
+		if _, errors := validateIpRestrictionCidr(valid, "allow"); len(errors) != 0 {
+			t.Errorf("expected %s to be valid, got: %v", valid, errors)
+		}
+	}
+
+	d := r.TestResourceData()
+	if _, err := expandIpRestrictionPluginConfig(d); err == nil || !strings.Contains(err.Error(), "at least one of allow or deny") {
+		t.Errorf("expected a plugin without allow or deny to be rejected, got: %v", err)
+	}
+}
@@ -0,0 +1,99 @@
+package kong
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+var jwtPluginClaims = []string{"exp", "nbf"}
+
+func resourceKongPluginJwt() *schema.Resource {
+	return resourceKongTypedPlugin(&typedPlugin{
+		name: "jwt",
+		schema: map[string]*schema.Schema{
+			"claims_to_verify": &schema.Schema{
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validateJwtPluginClaim,
+				},
+			},
+			"key_claim_name": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "iss",
+			},
+			"secret_is_base64": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"run_on_preflight": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+			// in seconds, 0 does not limit how far in the future exp can be
+			"maximum_expiration": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				ValidateFunc: validateJwtMaximumExpiration,
+			},
+		},
+		expandConfig:  expandJwtPluginConfig,
+		flattenConfig: flattenJwtPluginConfig,
+	})
+}
+
+func expandJwtPluginConfig(d *schema.ResourceData) (map[string]interface{}, error) {
+	claims := readStringSetFromResource(d, "claims_to_verify")
+	sort.Strings(claims)
+
+	maximumExpiration := d.Get("maximum_expiration").(int)
+	if maximumExpiration > 0 && !contains(claims, "exp") {
+		return nil, fmt.Errorf("maximum_expiration can only be set when claims_to_verify has exp")
+	}
+
+	// claims_to_verify is sent as null when it is not set, kong merges the config of an update so removing it would
+	// otherwise keep the claims
+	config := map[string]interface{}{
+		"claims_to_verify":   nil,
+		"key_claim_name":     d.Get("key_claim_name").(string),
+		"secret_is_base64":   d.Get("secret_is_base64").(bool),
+		"run_on_preflight":   d.Get("run_on_preflight").(bool),
+		"maximum_expiration": maximumExpiration,
+	}
+
+	if len(claims) > 0 {
+		config["claims_to_verify"] = claims
+	}
+
+	return config, nil
+}
+
+func flattenJwtPluginConfig(d *schema.ResourceData, config map[string]interface{}) {
+	d.Set("claims_to_verify", configStrings(config["claims_to_verify"]))
+	d.Set("key_claim_name", configString(config["key_claim_name"]))
+	d.Set("secret_is_base64", configBool(config["secret_is_base64"]))
+	d.Set("run_on_preflight", configBool(config["run_on_preflight"]))
+	d.Set("maximum_expiration", configInt(config["maximum_expiration"]))
+}
+
+func validateJwtPluginClaim(value interface{}, k string) ([]string, []error) {
+	if !contains(jwtPluginClaims, value.(string)) {
+		return nil, []error{fmt.Errorf("%s must be one of %v, got: %s", k, jwtPluginClaims, value)}
+	}
+	return nil, nil
+}
+
+// kong limits maximum_expiration to a year
+func validateJwtMaximumExpiration(v interface{}, k string) ([]string, []error) {
+	if seconds := v.(int); seconds < 0 || seconds > 31536000 {
+		return nil, []error{fmt.Errorf("%s must be between 0 and 31536000, got: %d", k, seconds)}
+	}
+	return nil, nil
+}
@@ -0,0 +1,163 @@
+package kong
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/kevholditch/gokong"
+)
+
+func TestAccKongPluginJwt(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckKongTypedPluginDestroy("kong_plugin_jwt"),
+		Steps: []resource.TestStep{
+			{
+				Config: testCreateJwtPluginConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckKongPluginExists("kong_plugin_jwt.jwt"),
+					testAccCheckForChildIdCorrect("kong_service.service", "kong_plugin_jwt.jwt", "service_id"),
+					resource.TestCheckResourceAttr("kong_plugin_jwt.jwt", "claims_to_verify.#", "2"),
+					resource.TestCheckResourceAttr("kong_plugin_jwt.jwt", "key_claim_name", "kid"),
+					resource.TestCheckResourceAttr("kong_plugin_jwt.jwt", "secret_is_base64", "true"),
+					resource.TestCheckResourceAttr("kong_plugin_jwt.jwt", "run_on_preflight", "false"),
+					resource.TestCheckResourceAttr("kong_plugin_jwt.jwt", "maximum_expiration", "3600"),
+				),
+			},
+			{
+				Config: testUpdateJwtPluginConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckKongPluginExists("kong_plugin_jwt.jwt"),
+					resource.TestCheckResourceAttr("kong_plugin_jwt.jwt", "claims_to_verify.#", "0"),
+					resource.TestCheckResourceAttr("kong_plugin_jwt.jwt", "key_claim_name", "iss"),
+					resource.TestCheckResourceAttr("kong_plugin_jwt.jwt", "secret_is_base64", "false"),
+					resource.TestCheckResourceAttr("kong_plugin_jwt.jwt", "run_on_preflight", "true"),
+					resource.TestCheckResourceAttr("kong_plugin_jwt.jwt", "maximum_expiration", "0"),
+				),
+			},
+			{
+				ResourceName:      "kong_plugin_jwt.jwt",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccKongPluginJwtMaximumExpirationWithoutExp(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      testCreateJwtPluginMaximumExpirationWithoutExpConfig,
+				ExpectError: regexp.MustCompile("maximum_expiration can only be set when claims_to_verify has exp"),
+			},
+		},
+	})
+}
+
+func TestKongPluginJwtSendsTypedConfig(t *testing.T) {
+
+	server := newTypedPluginServer(t, "", nil)
+	defer server.Close()
+
+	client := newKongClient(&gokong.Config{HostAddress: server.URL})
+
+	r := resourceKongPluginJwt()
+	d := r.TestResourceData()
+	d.Set("claims_to_verify", []string{"nbf", "exp"})
+	d.Set("key_claim_name", "kid")
+	d.Set("secret_is_base64", true)
+	d.Set("run_on_preflight", false)
+	d.Set("maximum_expiration", 3600)
+	d.Set("enabled", true)
+
+	if err := r.Create(d, client); err != nil {
+		t.Fatalf("could not create jwt plugin: %v", err)
+	}
+
+	d.Set("claims_to_verify", []string{})
+	d.Set("maximum_expiration", 0)
+	if err := r.Update(d, client); err != nil {
+		t.Fatalf("could not update jwt plugin: %v", err)
+	}
+
+	if len(server.sent) != 2 {
+		t.Fatalf("expected the plugin to be created and updated, kong was sent: %v", server.sent)
+	}
+
+	expected := []map[string]interface{}{
+		{"claims_to_verify": []interface{}{"exp", "nbf"}, "key_claim_name": "kid", "secret_is_base64": true, "run_on_preflight": false,
+			"maximum_expiration": float64(3600)},
+		{"claims_to_verify": nil, "key_claim_name": "kid", "secret_is_base64": true, "run_on_preflight": false, "maximum_expiration": float64(0)},
+	}
+
+	for i, request := range server.sent {
+		if config := request["config"]; !reflect.DeepEqual(config, expected[i]) {
+			t.Errorf("request %d: expected the config %#v with a json list of claims and a number, got: %#v", i, expected[i], config)
+		}
+	}
+
+	if server.sent[0]["name"] != "jwt" {
+		t.Errorf("expected a jwt plugin to be created, got: %v", server.sent[0]["name"])
+	}
+
+	if claims := readStringSetFromResource(d, "claims_to_verify"); d.Id() != "plugin-id" || len(claims) != 0 || d.Get("key_claim_name") != "kid" ||
+		!d.Get("secret_is_base64").(bool) {
+		t.Errorf("expected the config to be read back from kong, got claims_to_verify: %v key_claim_name: %v secret_is_base64: %v",
+			claims, d.Get("key_claim_name"), d.Get("secret_is_base64"))
+	}
+}
+
+func TestValidateJwtPluginConfig(t *testing.T) {
+
+	if _, errors := validateJwtPluginClaim("iat", "claims_to_verify"); len(errors) != 1 {
+		t.Errorf("expected a claim kong can not verify to be rejected, got: %v", errors)
+	}
+
+	for _, seconds := range []int{-1, 31536001} {
+		if _, errors := validateJwtMaximumExpiration(seconds, "maximum_expiration"); len(errors) != 1 {
+			t.Errorf("expected maximum_expiration %d to be rejected, got: %v", seconds, errors)
+		}
+	}
+}
+
+const testCreateJwtPluginConfig = `
+resource "kong_service" "service" {
+	name     = "test"
+	protocol = "http"
+	host     = "test.org"
+}
+
+resource "kong_plugin_jwt" "jwt" {
+	service_id         = "${kong_service.service.id}"
+	claims_to_verify   = ["exp", "nbf"]
+	key_claim_name     = "kid"
+	secret_is_base64   = true
+	run_on_preflight   = false
+	maximum_expiration = 3600
+}
+`
+
+const testUpdateJwtPluginConfig = `
+resource "kong_service" "service" {
+	name     = "test"
+	protocol = "http"
+	host     = "test.org"
+}
+
+resource "kong_plugin_jwt" "jwt" {
+	service_id = "${kong_service.service.id}"
+}
+`
+
+const testCreateJwtPluginMaximumExpirationWithoutExpConfig = `
+resource "kong_plugin_jwt" "jwt" {
+	claims_to_verify   = ["nbf"]
+	maximum_expiration = 3600
+}
+`
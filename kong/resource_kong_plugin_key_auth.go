@@ -0,0 +1,72 @@
+package kong
+
+import (
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func resourceKongPluginKeyAuth() *schema.Resource {
+	return resourceKongTypedPlugin(&typedPlugin{
+		name: "key-auth",
+		schema: map[string]*schema.Schema{
+			// kong defaults key_names to ["apikey"], it is computed so that default is not a change when it is not set
+			"key_names": &schema.Schema{
+				Type:     schema.TypeList,
+				Optional: true,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"key_in_header": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+			"key_in_query": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+			"key_in_body": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"hide_credentials": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"run_on_preflight": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+		},
+		expandConfig:  expandKeyAuthPluginConfig,
+		flattenConfig: flattenKeyAuthPluginConfig,
+	})
+}
+
+func expandKeyAuthPluginConfig(d *schema.ResourceData) (map[string]interface{}, error) {
+	config := map[string]interface{}{
+		"key_in_header":    d.Get("key_in_header").(bool),
+		"key_in_query":     d.Get("key_in_query").(bool),
+		"key_in_body":      d.Get("key_in_body").(bool),
+		"hide_credentials": d.Get("hide_credentials").(bool),
+		"run_on_preflight": d.Get("run_on_preflight").(bool),
+	}
+
+	if keyNames := readStringArrayFromResource(d, "key_names"); len(keyNames) > 0 {
+		config["key_names"] = keyNames
+	}
+
+	return config, nil
+}
+
+func flattenKeyAuthPluginConfig(d *schema.ResourceData, config map[string]interface{}) {
+	d.Set("key_names", configStrings(config["key_names"]))
+	d.Set("key_in_header", configBool(config["key_in_header"]))
+	d.Set("key_in_query", configBool(config["key_in_query"]))
+	d.Set("key_in_body", configBool(config["key_in_body"]))
+	d.Set("hide_credentials", configBool(config["hide_credentials"]))
+	d.Set("run_on_preflight", configBool(config["run_on_preflight"]))
+}
@@ -0,0 +1,138 @@
+package kong
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/kevholditch/gokong"
+)
+
+func TestAccKongPluginKeyAuth(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckKongTypedPluginDestroy("kong_plugin_key_auth"),
+		Steps: []resource.TestStep{
+			{
+				Config: testCreateKeyAuthPluginConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckKongPluginExists("kong_plugin_key_auth.key_auth"),
+					testAccCheckForChildIdCorrect("kong_service.service", "kong_plugin_key_auth.key_auth", "service_id"),
+					resource.TestCheckResourceAttr("kong_plugin_key_auth.key_auth", "key_names.#", "2"),
+					resource.TestCheckResourceAttr("kong_plugin_key_auth.key_auth", "key_names.0", "apikey"),
+					resource.TestCheckResourceAttr("kong_plugin_key_auth.key_auth", "key_names.1", "x-api-key"),
+					resource.TestCheckResourceAttr("kong_plugin_key_auth.key_auth", "key_in_header", "true"),
+					resource.TestCheckResourceAttr("kong_plugin_key_auth.key_auth", "key_in_query", "false"),
+					resource.TestCheckResourceAttr("kong_plugin_key_auth.key_auth", "key_in_body", "false"),
+					resource.TestCheckResourceAttr("kong_plugin_key_auth.key_auth", "hide_credentials", "true"),
+					resource.TestCheckResourceAttr("kong_plugin_key_auth.key_auth", "run_on_preflight", "true"),
+				),
+			},
+			{
+				Config: testUpdateKeyAuthPluginConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckKongPluginExists("kong_plugin_key_auth.key_auth"),
+					resource.TestCheckResourceAttr("kong_plugin_key_auth.key_auth", "key_names.#", "1"),
+					resource.TestCheckResourceAttr("kong_plugin_key_auth.key_auth", "key_names.0", "token"),
+					resource.TestCheckResourceAttr("kong_plugin_key_auth.key_auth", "key_in_query", "true"),
+					resource.TestCheckResourceAttr("kong_plugin_key_auth.key_auth", "key_in_body", "true"),
+					resource.TestCheckResourceAttr("kong_plugin_key_auth.key_auth", "hide_credentials", "false"),
+					resource.TestCheckResourceAttr("kong_plugin_key_auth.key_auth", "run_on_preflight", "false"),
+				),
+			},
+			{
+				ResourceName:      "kong_plugin_key_auth.key_auth",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestKongPluginKeyAuthSendsTypedConfig(t *testing.T) {
+
+	server := newTypedPluginServer(t, "", func(config map[string]interface{}) {
+		// kong fills in the default key_names when they are not sent
+		if _, ok := config["key_names"]; !ok {
+			config["key_names"] = []interface{}{"apikey"}
+		}
+	})
+	defer server.Close()
+
+	client := newKongClient(&gokong.Config{HostAddress: server.URL})
+
+	r := resourceKongPluginKeyAuth()
+	d := r.TestResourceData()
+	d.Set("key_in_header", true)
+	d.Set("key_in_query", false)
+	d.Set("hide_credentials", true)
+	d.Set("run_on_preflight", true)
+	d.Set("enabled", true)
+
+	if err := r.Create(d, client); err != nil {
+		t.Fatalf("could not create key-auth plugin: %v", err)
+	}
+
+	d.Set("key_names", []string{"apikey", "x-api-key"})
+	d.Set("key_in_body", true)
+	if err := r.Update(d, client); err != nil {
+		t.Fatalf("could not update key-auth plugin: %v", err)
+	}
+
+	if len(server.sent) != 2 {
+		t.Fatalf("expected the plugin to be created and updated, kong was sent: %v", server.sent)
+	}
+
+	expected := []map[string]interface{}{
+		{"key_in_header": true, "key_in_query": false, "key_in_body": false, "hide_credentials": true, "run_on_preflight": true},
+		{"key_in_header": true, "key_in_query": false, "key_in_body": true, "hide_credentials": true, "run_on_preflight": true,
+			"key_names": []interface{}{"apikey", "x-api-key"}},
+	}
+
+	for i, request := range server.sent {
+		if config := request["config"]; !reflect.DeepEqual(config, expected[i]) {
+			t.Errorf("request %d: expected the config %#v with json booleans and lists, got: %#v", i, expected[i], config)
+		}
+	}
+
+	if server.sent[0]["name"] != "key-auth" {
+		t.Errorf("expected a key-auth plugin to be created, got: %v", server.sent[0]["name"])
+	}
+
+	if keyNames := readStringArrayFromResource(d, "key_names"); d.Id() != "plugin-id" || !reflect.DeepEqual(keyNames, []string{"apikey", "x-api-key"}) ||
+		!d.Get("key_in_body").(bool) || d.Get("key_in_query").(bool) {
+		t.Errorf("expected the config to be read back from kong, got key_names: %v key_in_body: %v key_in_query: %v",
+			keyNames, d.Get("key_in_body"), d.Get("key_in_query"))
+	}
+}
+
+const testCreateKeyAuthPluginConfig = `
+resource "kong_service" "service" {
+	name     = "test"
+	protocol = "http"
+	host     = "test.org"
+}
+
+resource "kong_plugin_key_auth" "key_auth" {
+	service_id       = "${kong_service.service.id}"
+	key_names        = ["apikey", "x-api-key"]
+	key_in_query     = false
+	hide_credentials = true
+}
+`
+
+const testUpdateKeyAuthPluginConfig = `
+resource "kong_service" "service" {
+	name     = "test"
+	protocol = "http"
+	host     = "test.org"
+}
+
+resource "kong_plugin_key_auth" "key_auth" {
+	service_id       = "${kong_service.service.id}"
+	key_names        = ["token"]
+	key_in_body      = true
+	run_on_preflight = false
+}
+`
@@ -0,0 +1,178 @@
+package kong
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// oauth2GrantAttributes are the grants of the oauth2 plugin, kong rejects a config that enables none of them
+var oauth2GrantAttributes = []string{"enable_authorization_code", "enable_implicit_grant", "enable_client_credentials", "enable_password_grant"}
+
+var oauth2PkceModes = []string{"none", "lax", "strict"}
+
+func resourceKongPluginOauth2() *schema.Resource {
+	oauth2Schema := map[string]*schema.Schema{
+		"scopes": &schema.Schema{
+			Type:     schema.TypeList,
+			Optional: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+		"mandatory_scope": &schema.Schema{
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  false,
+		},
+		// in seconds
+		"token_expiration": &schema.Schema{
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Default:      7200,
+			ValidateFunc: validateOauth2Expiration,
+		},
+		// in seconds, 0 never expires the refresh tokens
+		"refresh_token_ttl": &schema.Schema{
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Default:      1209600,
+			ValidateFunc: validateOauth2Expiration,
+		},
+		"reuse_refresh_token": &schema.Schema{
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  false,
+		},
+		// kong generates a provision key when it is not set, it is kept in state so it is not shown in plans
+		"provision_key": &schema.Schema{
+			Type:      schema.TypeString,
+			Optional:  true,
+			Computed:  true,
+			Sensitive: true,
+		},
+		"hide_credentials": &schema.Schema{
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  false,
+		},
+		"accept_http_if_already_terminated": &schema.Schema{
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  false,
+		},
+		"global_credentials": &schema.Schema{
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  false,
+		},
+		"auth_header_name": &schema.Schema{
+			Type:     schema.TypeString,
+			Optional: true,
+			Default:  "authorization",
+		},
+		// the id of the consumer to use when authentication fails, empty fails the request
+		"anonymous": &schema.Schema{
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		// kong 2.0 and later defaults pkce to lax, it is computed so that default is not a change when it is not set
+		"pkce": &schema.Schema{
+			Type:         schema.TypeString,
+			Optional:     true,
+			Computed:     true,
+			ValidateFunc: validateOauth2Pkce,
+		},
+	}
+
+	for _, grant := range oauth2GrantAttributes {
+		oauth2Schema[grant] = &schema.Schema{
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  false,
+		}
+	}
+
+	return resourceKongTypedPlugin(&typedPlugin{
+		name:          "oauth2",
+		schema:        oauth2Schema,
+		expandConfig:  expandOauth2PluginConfig,
+		flattenConfig: flattenOauth2PluginConfig,
+	})
+}
+
+func expandOauth2PluginConfig(d *schema.ResourceData) (map[string]interface{}, error) {
+	// scopes and anonymous are sent as null when they are not set, kong merges the config of an update so removing
+	// them would otherwise keep them
+	config := map[string]interface{}{
+		"scopes":                            nil,
+		"mandatory_scope":                   d.Get("mandatory_scope").(bool),
+		"token_expiration":                  d.Get("token_expiration").(int),
+		"refresh_token_ttl":                 d.Get("refresh_token_ttl").(int),
+		"reuse_refresh_token":               d.Get("reuse_refresh_token").(bool),
+		"hide_credentials":                  d.Get("hide_credentials").(bool),
+		"accept_http_if_already_terminated": d.Get("accept_http_if_already_terminated").(bool),
+		"global_credentials":                d.Get("global_credentials").(bool),
+		"auth_header_name":                  d.Get("auth_header_name").(string),
+		"anonymous":                         nil,
+	}
+
+	enabled := false
+	for _, grant := range oauth2GrantAttributes {
+		config[grant] = d.Get(grant).(bool)
+		enabled = enabled || d.Get(grant).(bool)
+	}
+	if !enabled {
+		return nil, fmt.Errorf("the oauth2 plugin needs at least one of %v to be true", oauth2GrantAttributes)
+	}
+
+	scopes := readStringArrayFromResource(d, "scopes")
+	if len(scopes) > 0 {
+		config["scopes"] = scopes
+	} else if d.Get("mandatory_scope").(bool) {
+		return nil, fmt.Errorf("mandatory_scope of the oauth2 plugin needs scopes to be set")
+	}
+
+	if anonymous := readStringFromResource(d, "anonymous"); anonymous != "" {
+		config["anonymous"] = anonymous
+	}
+	if provisionKey := readStringFromResource(d, "provision_key"); provisionKey != "" {
+		config["provision_key"] = provisionKey
+	}
+	if pkce := readStringFromResource(d, "pkce"); pkce != "" {
+		config["pkce"] = pkce
+	}
+
+	return config, nil
+}
+
+func flattenOauth2PluginConfig(d *schema.ResourceData, config map[string]interface{}) {
+	d.Set("scopes", configStrings(config["scopes"]))
+	d.Set("mandatory_scope", configBool(config["mandatory_scope"]))
+	d.Set("token_expiration", configInt(config["token_expiration"]))
+	d.Set("refresh_token_ttl", configInt(config["refresh_token_ttl"]))
+	d.Set("reuse_refresh_token", configBool(config["reuse_refresh_token"]))
+	d.Set("provision_key", configString(config["provision_key"]))
+	d.Set("hide_credentials", configBool(config["hide_credentials"]))
+	d.Set("accept_http_if_already_terminated", configBool(config["accept_http_if_already_terminated"]))
+	d.Set("global_credentials", configBool(config["global_credentials"]))
+	d.Set("auth_header_name", configString(config["auth_header_name"]))
+	d.Set("anonymous", configString(config["anonymous"]))
+	d.Set("pkce", configString(config["pkce"]))
+
+	for _, grant := range oauth2GrantAttributes {
+		d.Set(grant, configBool(config[grant]))
+	}
+}
+
+func validateOauth2Expiration(v interface{}, k string) ([]string, []error) {
+	if value := v.(int); value < 0 {
+		return nil, []error{fmt.Errorf("%s must not be negative, got: %d", k, value)}
+	}
+	return nil, nil
+}
+
+func validateOauth2Pkce(value interface{}, k string) ([]string, []error) {
+	if !contains(oauth2PkceModes, value.(string)) {
+		return nil, []error{fmt.Errorf("%s must be one of %v, got: %s", k, oauth2PkceModes, value)}
+	}
+	return nil, nil
+}
@@ -0,0 +1,99 @@
+package kong
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kevholditch/gokong"
+)
+
+func TestKongPluginOauth2SendsTypedConfig(t *testing.T) {
+
+	server := newTypedPluginServer(t, "", func(config map[string]interface{}) {
+		// kong generates the provision key and fills in pkce when they are not sent
+		if _, ok := config["provision_key"]; !ok {
+			config["provision_key"] = "generated-provision-key"
+		}
+		if _, ok := config["pkce"]; !ok {
+			config["pkce"] = "lax"
+		}
+	})
+	defer server.Close()
+
+	client := newKongClient(&gokong.Config{HostAddress: server.URL})
+
+	r := resourceKongPluginOauth2()
+	d := r.TestResourceData()
+	d.Set("scopes", []string{"email", "profile"})
+	d.Set("mandatory_scope", true)
+	d.Set("token_expiration", 3600)
+	d.Set("refresh_token_ttl", 1209600)
+	d.Set("enable_authorization_code", true)
+	d.Set("auth_header_name", "authorization")
+	d.Set("enabled", true)
+
+	if err := r.Create(d, client); err != nil {
+		t.Fatalf("could not create oauth2 plugin: %v", err)
+	}
+
+	d.Set("scopes", []string{})
+	d.Set("mandatory_scope", false)
+	d.Set("enable_client_credentials", true)
+	d.Set("anonymous", "anonymous-consumer-id")
+	if err := r.Update(d, client); err != nil {
+		t.Fatalf("could not update oauth2 plugin: %v", err)
+	}
+
+	if len(server.sent) != 2 {
+		t.Fatalf("expected the plugin to be created and updated, kong was sent: %v", server.sent)
+	}
+
+	expected := []map[string]interface{}{
+		{"scopes": []interface{}{"email", "profile"}, "mandatory_scope": true, "token_expiration": 3600.0, "refresh_token_ttl": 1209600.0,
+			"reuse_refresh_token": false, "hide_credentials": false, "accept_http_if_already_terminated": false, "global_credentials": false,
+			"auth_header_name": "authorization", "anonymous": nil, "enable_authorization_code": true, "enable_implicit_grant": false,
+			"enable_client_credentials": false, "enable_password_grant": false},
+		// the generated provision key and pkce read back are sent as they are, the removed scopes are sent as null
+		{"scopes": nil, "mandatory_scope": false, "token_expiration": 3600.0, "refresh_token_ttl": 1209600.0,
+			"reuse_refresh_token": false, "hide_credentials": false, "accept_http_if_already_terminated": false, "global_credentials": false,
+			"auth_header_name": "authorization", "anonymous": "anonymous-consumer-id", "enable_authorization_code": true,
+			"enable_implicit_grant": false, "enable_client_credentials": true, "enable_password_grant": false,
+			"provision_key": "generated-provision-key", "pkce": "lax"},
+	}
+
+	for i, request := range server.sent {
+		if config := request["config"]; !reflect.DeepEqual(config, expected[i]) {
+			t.Errorf("request %d: expected the config %#v with json booleans and lists, got: %#v", i, expected[i], config)
+		}
+	}
+
+	if d.Get("provision_key") != "generated-provision-key" || d.Get("pkce") != "lax" || !d.Get("enable_client_credentials").(bool) ||
+		len(d.Get("scopes").([]interface{})) != 0 {
+		t.Errorf("expected the config to be read back from kong, got provision_key: %v pkce: %v scopes: %v",
+			d.Get("provision_key"), d.Get("pkce"), d.Get("scopes"))
+	}
+}
+
+func TestKongPluginOauth2InvalidConfig(t *testing.T) {
+
+	r := resourceKongPluginOauth2()
+
+	d := r.TestResourceData()
+	if _, err := expandOauth2PluginConfig(d); err == nil || !strings.Contains(err.Error(), "needs at least one of") {
+		t.Errorf("expected a config without a grant to be rejected, got: %v", err)
+	}
+
+	d.Set("enable_password_grant", true)
+	d.Set("mandatory_scope", true)
+	if _, err := expandOauth2PluginConfig(d); err == nil || !strings.Contains(err.Error(), "needs scopes to be set") {
+		t.Errorf("expected mandatory_scope without scopes to be rejected, got: %v", err)
+	}
+
+	if _, errors := validateOauth2Pkce("required", "pkce"); len(errors) != 1 {
+		t.Errorf("expected an unknown pkce to be rejected, got: %v", errors)
+	}
+	if _, errors := validateOauth2Expiration(-1, "token_expiration"); len(errors) != 1 {
+		t.Errorf("expected a negative token_expiration to be rejected, got: %v", errors)
+	}
+}
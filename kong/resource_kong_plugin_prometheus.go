@@ -0,0 +1,49 @@
+package kong
+
+import (
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// prometheusPluginMetrics are the boolean config fields of the prometheus plugin, each turns on a group of metrics.
+// per_consumer was added in kong 2.0 and the others in kong 3.0.
+var prometheusPluginMetrics = []string{
+	"per_consumer",
+	"status_code_metrics",
+	"latency_metrics",
+	"bandwidth_metrics",
+	"upstream_health_metrics",
+}
+
+func resourceKongPluginPrometheus() *schema.Resource {
+	pluginSchema := map[string]*schema.Schema{}
+	for _, key := range prometheusPluginMetrics {
+		pluginSchema[key] = &schema.Schema{
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  false,
+		}
+	}
+
+	return resourceKongTypedPlugin(&typedPlugin{
+		name:          "prometheus",
+		schema:        pluginSchema,
+		expandConfig:  expandPrometheusPluginConfig,
+		flattenConfig: flattenPrometheusPluginConfig,
+	})
+}
+
+// expandPrometheusPluginConfig sends every boolean, including the ones that are false, kong merges the config of an
+// update so a metric that is turned off in terraform would otherwise stay on in kong
+func expandPrometheusPluginConfig(d *schema.ResourceData) (map[string]interface{}, error) {
+	config := map[string]interface{}{}
+	for _, key := range prometheusPluginMetrics {
+		config[key] = d.Get(key).(bool)
+	}
+	return config, nil
+}
+
+func flattenPrometheusPluginConfig(d *schema.ResourceData, config map[string]interface{}) {
+	for _, key := range prometheusPluginMetrics {
+		d.Set(key, configBool(config[key]))
+	}
+}
@@ -0,0 +1,124 @@
+package kong
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/kevholditch/gokong"
+)
+
+func TestAccKongPluginPrometheus(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheckKongVersion(t, "3.0.0") },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckKongTypedPluginDestroy("kong_plugin_prometheus"),
+		Steps: []resource.TestStep{
+			{
+				Config: testCreatePrometheusPluginConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckKongPluginExists("kong_plugin_prometheus.prometheus"),
+					testAccCheckForChildIdCorrect("kong_service.service", "kong_plugin_prometheus.prometheus", "service_id"),
+					resource.TestCheckResourceAttr("kong_plugin_prometheus.prometheus", "status_code_metrics", "true"),
+					resource.TestCheckResourceAttr("kong_plugin_prometheus.prometheus", "latency_metrics", "true"),
+					resource.TestCheckResourceAttr("kong_plugin_prometheus.prometheus", "bandwidth_metrics", "false"),
+					resource.TestCheckResourceAttr("kong_plugin_prometheus.prometheus", "upstream_health_metrics", "false"),
+					resource.TestCheckResourceAttr("kong_plugin_prometheus.prometheus", "per_consumer", "false"),
+				),
+			},
+			{
+				Config: testUpdatePrometheusPluginConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckKongPluginExists("kong_plugin_prometheus.prometheus"),
+					resource.TestCheckResourceAttr("kong_plugin_prometheus.prometheus", "status_code_metrics", "false"),
+					resource.TestCheckResourceAttr("kong_plugin_prometheus.prometheus", "latency_metrics", "false"),
+					resource.TestCheckResourceAttr("kong_plugin_prometheus.prometheus", "bandwidth_metrics", "true"),
+					resource.TestCheckResourceAttr("kong_plugin_prometheus.prometheus", "upstream_health_metrics", "true"),
+				),
+			},
+			{
+				ResourceName:      "kong_plugin_prometheus.prometheus",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestKongPluginPrometheusSendsBooleans(t *testing.T) {
+
+	server := newTypedPluginServer(t, "", nil)
+	defer server.Close()
+
+	client := newKongClient(&gokong.Config{HostAddress: server.URL})
+
+	r := resourceKongPluginPrometheus()
+	d := r.TestResourceData()
+	d.Set("status_code_metrics", true)
+	d.Set("latency_metrics", true)
+	d.Set("enabled", true)
+
+	if err := r.Create(d, client); err != nil {
+		t.Fatalf("could not create prometheus plugin: %v", err)
+	}
+
+	d.Set("latency_metrics", false)
+	if err := r.Update(d, client); err != nil {
+		t.Fatalf("could not update prometheus plugin: %v", err)
+	}
+
+	expected := []map[string]bool{
+		{"status_code_metrics": true, "latency_metrics": true},
+		{"status_code_metrics": true},
+	}
+
+	if len(server.sent) != len(expected) {
+		t.Fatalf("expected the plugin to be created and updated, kong was sent: %v", server.sent)
+	}
+
+	for i, request := range server.sent {
+		config, _ := request["config"].(map[string]interface{})
+		for _, key := range prometheusPluginMetrics {
+			if value, ok := config[key].(bool); !ok || value != expected[i][key] {
+				t.Errorf("request %d: expected %s to be sent as the json boolean %t, got: %#v", i, key, expected[i][key], config[key])
+			}
+		}
+	}
+
+	if server.sent[0]["name"] != "prometheus" {
+		t.Errorf("expected a prometheus plugin to be created, got: %v", server.sent[0]["name"])
+	}
+
+	if d.Id() != "plugin-id" || !d.Get("status_code_metrics").(bool) || d.Get("latency_metrics").(bool) {
+		t.Errorf("expected the booleans to be read back from kong, got status_code_metrics: %v latency_metrics: %v",
+			d.Get("status_code_metrics"), d.Get("latency_metrics"))
+	}
+}
+
+const testCreatePrometheusPluginConfig = `
+resource "kong_service" "service" {
+	name     = "test"
+	protocol = "http"
+	host     = "test.org"
+}
+
+resource "kong_plugin_prometheus" "prometheus" {
+	service_id          = "${kong_service.service.id}"
+	status_code_metrics = true
+	latency_metrics     = true
+}
+`
+
+const testUpdatePrometheusPluginConfig = `
+resource "kong_service" "service" {
+	name     = "test"
+	protocol = "http"
+	host     = "test.org"
+}
+
+resource "kong_plugin_prometheus" "prometheus" {
+	service_id              = "${kong_service.service.id}"
+	bandwidth_metrics       = true
+	upstream_health_metrics = true
+}
+`
@@ -0,0 +1,202 @@
+package kong
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+var rateLimitingAdvancedStrategies = []string{"local", "cluster", "redis"}
+var rateLimitingAdvancedWindowTypes = []string{"sliding", "fixed"}
+var rateLimitingAdvancedIdentifiers = []string{"ip", "credential", "consumer", "consumer-group", "service", "header", "path"}
+
+func resourceKongPluginRateLimitingAdvanced() *schema.Resource {
+	return resourceKongTypedPlugin(&typedPlugin{
+		name: rateLimitingAdvancedPluginName,
+		schema: map[string]*schema.Schema{
+			// limit and window_size are pairs, the nth limit is the number of requests allowed in the nth window
+			"limit": &schema.Schema{
+				Type:     schema.TypeList,
+				Required: true,
+				Elem:     &schema.Schema{Type: schema.TypeInt},
+			},
+			// in seconds
+			"window_size": &schema.Schema{
+				Type:     schema.TypeList,
+				Required: true,
+				Elem:     &schema.Schema{Type: schema.TypeInt},
+			},
+			"window_type": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "sliding",
+				ValidateFunc: validateRateLimitingAdvancedWindowType,
+			},
+			"identifier": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "consumer",
+				ValidateFunc: validateRateLimitingAdvancedIdentifier,
+			},
+			"strategy": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "local",
+				ValidateFunc: validateRateLimitingAdvancedStrategy,
+			},
+			// in seconds, computed as kong's default depends on the strategy
+			"sync_rate": &schema.Schema{
+				Type:     schema.TypeFloat,
+				Optional: true,
+				Computed: true,
+			},
+			// kong generates a namespace when it is not set
+			"namespace": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			"hide_client_headers": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"redis": &schema.Schema{
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Description: "The redis the counters are kept in when strategy is redis",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"host": &schema.Schema{
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"port": &schema.Schema{
+							Type:     schema.TypeInt,
+							Optional: true,
+							Default:  6379,
+						},
+						"password": &schema.Schema{
+							Type:      schema.TypeString,
+							Optional:  true,
+							Sensitive: true,
+						},
+						"database": &schema.Schema{
+							Type:     schema.TypeInt,
+							Optional: true,
+							Default:  0,
+						},
+					},
+				},
+			},
+		},
+		expandConfig:  expandRateLimitingAdvancedPluginConfig,
+		flattenConfig: flattenRateLimitingAdvancedPluginConfig,
+		resolveConfig: func(client *kongClient, d *schema.ResourceData, config map[string]interface{}) error {
+			return client.requireEnterprise("kong_plugin_rate_limiting_advanced")
+		},
+	})
+}
+
+func expandRateLimitingAdvancedPluginConfig(d *schema.ResourceData) (map[string]interface{}, error) {
+	limits := d.Get("limit").([]interface{})
+	windowSizes := d.Get("window_size").([]interface{})
+	if len(limits) == 0 || len(limits) != len(windowSizes) {
+		return nil, fmt.Errorf("limit and window_size must have the same number of values, got %d limits and %d window sizes", len(limits), len(windowSizes))
+	}
+
+	strategy := d.Get("strategy").(string)
+	redis := d.Get("redis").([]interface{})
+	if strategy == "redis" && len(redis) == 0 {
+		return nil, fmt.Errorf("redis must be set when strategy is redis")
+	}
+
+	// redis is sent as null when it is not set, kong merges the config of an update so removing it would otherwise
+	// keep it
+	config := map[string]interface{}{
+		"limit":               limits,
+		"window_size":         windowSizes,
+		"window_type":         d.Get("window_type").(string),
+		"identifier":          d.Get("identifier").(string),
+		"strategy":            strategy,
+		"hide_client_headers": d.Get("hide_client_headers").(bool),
+		"redis":               nil,
+	}
+
+	if syncRate, ok := d.GetOk("sync_rate"); ok {
+		config["sync_rate"] = syncRate.(float64)
+	}
+	if namespace := readStringFromResource(d, "namespace"); namespace != "" {
+		config["namespace"] = namespace
+	}
+
+	if len(redis) > 0 && redis[0] != nil {
+		attributes := redis[0].(map[string]interface{})
+		redisConfig := map[string]interface{}{
+			"host":     attributes["host"].(string),
+			"port":     attributes["port"].(int),
+			"database": attributes["database"].(int),
+			"password": nil,
+		}
+		if password := attributes["password"].(string); password != "" {
+			redisConfig["password"] = password
+		}
+		config["redis"] = redisConfig
+	}
+
+	return config, nil
+}
+
+func flattenRateLimitingAdvancedPluginConfig(d *schema.ResourceData, config map[string]interface{}) {
+	d.Set("limit", configInts(config["limit"]))
+	d.Set("window_size", configInts(config["window_size"]))
+	d.Set("window_type", configString(config["window_type"]))
+	d.Set("identifier", configString(config["identifier"]))
+	d.Set("strategy", configString(config["strategy"]))
+	d.Set("sync_rate", configFloat(config["sync_rate"]))
+	d.Set("namespace", configString(config["namespace"]))
+	d.Set("hide_client_headers", configBool(config["hide_client_headers"]))
+
+	// kong returns every redis field (timeouts, sentinel, cluster and ssl settings), only the ones the resource has are
+	// kept and redis is only in state when it was configured or kong has a host for it
+	redis, _ := config["redis"].(map[string]interface{})
+	if configString(redis["host"]) == "" {
+		d.Set("redis", nil)
+		return
+	}
+
+	// the password is kept from state when kong does not return it
+	password := configString(redis["password"])
+	if password == "" {
+		password = readStringFromResource(d, "redis.0.password")
+	}
+
+	d.Set("redis", []map[string]interface{}{{
+		"host":     configString(redis["host"]),
+		"port":     configInt(redis["port"]),
+		"password": password,
+		"database": configInt(redis["database"]),
+	}})
+}
+
+func validateRateLimitingAdvancedStrategy(value interface{}, k string) ([]string, []error) {
+	if !contains(rateLimitingAdvancedStrategies, value.(string)) {
+		return nil, []error{fmt.Errorf("%s must be one of %v, got: %s", k, rateLimitingAdvancedStrategies, value)}
+	}
+	return nil, nil
+}
+
+func validateRateLimitingAdvancedWindowType(value interface{}, k string) ([]string, []error) {
+	if !contains(rateLimitingAdvancedWindowTypes, value.(string)) {
+		return nil, []error{fmt.Errorf("%s must be one of %v, got: %s", k, rateLimitingAdvancedWindowTypes, value)}
+	}
+	return nil, nil
+}
+
+func validateRateLimitingAdvancedIdentifier(value interface{}, k string) ([]string, []error) {
+	if !contains(rateLimitingAdvancedIdentifiers, value.(string)) {
+		return nil, []error{fmt.Errorf("%s must be one of %v, got: %s", k, rateLimitingAdvancedIdentifiers, value)}
+	}
+	return nil, nil
+}
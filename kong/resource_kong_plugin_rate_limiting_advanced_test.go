@@ -0,0 +1,136 @@
+package kong
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kevholditch/gokong"
+)
+
+// newTestRateLimitingAdvancedServer mocks a kong node keeping the plugin it is sent, the redis config it returns has
+// every field kong fills in and a namespace is generated like kong does
+func newTestRateLimitingAdvancedServer(t *testing.T, kongVersion string) *typedPluginServer {
+	return newTypedPluginServer(t, kongVersion, func(config map[string]interface{}) {
+		if config["namespace"] == nil {
+			config["namespace"] = "generated-namespace"
+		}
+		if config["sync_rate"] == nil {
+			config["sync_rate"] = -1
+		}
+		if redis, ok := config["redis"].(map[string]interface{}); ok {
+			redis["timeout"] = 2000
+			redis["ssl"] = false
+			redis["sentinel_master"] = nil
+			redis["cluster_addresses"] = nil
+		} else {
+			config["redis"] = map[string]interface{}{"host": nil, "port": 6379, "database": 0, "timeout": 2000}
+		}
+	})
+}
+
+func TestKongPluginRateLimitingAdvancedRedis(t *testing.T) {
+
+	server := newTestRateLimitingAdvancedServer(t, "3.4.3.1")
+	defer server.Close()
+
+	client := newKongClient(&gokong.Config{HostAddress: server.URL})
+	r := resourceKongPluginRateLimitingAdvanced()
+	d := r.TestResourceData()
+	d.Set("limit", []interface{}{10, 600})
+	d.Set("window_size", []interface{}{60, 3600})
+	d.Set("strategy", "redis")
+	d.Set("window_type", "sliding")
+	d.Set("identifier", "consumer")
+	d.Set("enabled", true)
+	d.Set("redis", []interface{}{map[string]interface{}{"host": "redis.internal", "port": 6380, "password": "s3cr3t", "database": 2}})
+
+	if err := r.Create(d, client); err != nil {
+		t.Fatalf("could not create rate-limiting-advanced plugin: %v", err)
+	}
+
+	if body := server.bodies[0]; !strings.Contains(body, `"limit":[10,600]`) || !strings.Contains(body, `"window_size":[60,3600]`) ||
+		!strings.Contains(body, `"redis":{"database":2,"host":"redis.internal","password":"s3cr3t","port":6380}`) || strings.Contains(body, "namespace") {
+		t.Errorf("expected the limits as json integer arrays and only the configured redis fields to be sent, kong was sent: %s", body)
+	}
+
+	if limits := d.Get("limit").([]interface{}); len(limits) != 2 || limits[0] != 10 || limits[1] != 600 {
+		t.Errorf("expected the limits to be read back in order, got: %v", limits)
+	}
+	if d.Get("namespace") != "generated-namespace" || d.Get("sync_rate").(float64) != -1 {
+		t.Errorf("expected the namespace and sync rate kong filled in to be read back, got: %v %v", d.Get("namespace"), d.Get("sync_rate"))
+	}
+
+	redis := d.Get("redis").([]interface{})
+	if len(redis) != 1 || len(redis[0].(map[string]interface{})) != 4 || d.Get("redis.0.host") != "redis.internal" ||
+		d.Get("redis.0.port") != 6380 || d.Get("redis.0.password") != "s3cr3t" || d.Get("redis.0.database") != 2 {
+		t.Errorf("expected the redis config to be read back without the fields kong fills in, got: %v", redis)
+	}
+
+	// the generated namespace is kept so the counters are not reset, removing redis sends it as null
+	d.Set("strategy", "local")
+	d.Set("redis", nil)
+	if err := r.Update(d, client); err != nil {
+		t.Fatalf("could not update rate-limiting-advanced plugin: %v", err)
+	}
+
+	if body := server.bodies[1]; !strings.Contains(body, `"redis":null`) || !strings.Contains(body, `"namespace":"generated-namespace"`) {
+		t.Errorf("expected redis to be cleared and the namespace kept, kong was sent: %s", body)
+	}
+	if len(d.Get("redis").([]interface{})) != 0 {
+		t.Errorf("expected kong's redis defaults not to be read into state, got: %v", d.Get("redis"))
+	}
+}
+
+func TestKongPluginRateLimitingAdvancedInvalidConfig(t *testing.T) {
+
+	server := newTestRateLimitingAdvancedServer(t, "3.4.3.1")
+	defer server.Close()
+
+	client := newKongClient(&gokong.Config{HostAddress: server.URL})
+	r := resourceKongPluginRateLimitingAdvanced()
+
+	cases := []struct {
+		limits      []interface{}
+		windowSizes []interface{}
+		strategy    string
+		expected    string
+	}{
+		{[]interface{}{10, 600}, []interface{}{60}, "local", "limit and window_size must have the same number of values, got 2 limits and 1 window sizes"},
+		{[]interface{}{10}, []interface{}{60}, "redis", "redis must be set when strategy is redis"},
+	}
+
+	for _, c := range cases {
+		d := r.TestResourceData()
+		d.Set("limit", c.limits)
+		d.Set("window_size", c.windowSizes)
+		d.Set("strategy", c.strategy)
+
+		if err := r.Create(d, client); err == nil || !strings.Contains(err.Error(), c.expected) {
+			t.Errorf("expected the error %q, got: %v", c.expected, err)
+		}
+	}
+
+	if len(server.bodies) != 0 {
+		t.Errorf("expected nothing to be sent for an invalid config, got: %v", server.bodies)
+	}
+
+	if _, errors := validateRateLimitingAdvancedStrategy("memory", "strategy"); len(errors) != 1 {
+		t.Errorf("expected an unknown strategy to be rejected, got: %v", errors)
+	}
+}
+
+func TestKongPluginRateLimitingAdvancedRequiresEnterprise(t *testing.T) {
+
+	server := newTestRateLimitingAdvancedServer(t, "3.4.2")
+	defer server.Close()
+
+	r := resourceKongPluginRateLimitingAdvanced()
+	d := r.TestResourceData()
+	d.Set("limit", []interface{}{10})
+	d.Set("window_size", []interface{}{60})
+
+	err := r.Create(d, newKongClient(&gokong.Config{HostAddress: server.URL}))
+	if err == nil || !strings.Contains(err.Error(), "kong_plugin_rate_limiting_advanced") || len(server.bodies) != 0 {
+		t.Errorf("expected creating the plugin on kong open source to fail before anything is sent, got: %v", err)
+	}
+}
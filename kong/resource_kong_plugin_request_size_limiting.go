@@ -0,0 +1,57 @@
+package kong
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+var requestSizeLimitingSizeUnits = []string{"megabytes", "kilobytes", "bytes"}
+
+func resourceKongPluginRequestSizeLimiting() *schema.Resource {
+	return resourceKongTypedPlugin(&typedPlugin{
+		name: "request-size-limiting",
+		schema: map[string]*schema.Schema{
+			"allowed_payload_size": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      128,
+				ValidateFunc: validateAllowedPayloadSize,
+			},
+			"size_unit": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "megabytes",
+				ValidateFunc: validateRequestSizeLimitingSizeUnit,
+			},
+		},
+		expandConfig:  expandRequestSizeLimitingPluginConfig,
+		flattenConfig: flattenRequestSizeLimitingPluginConfig,
+	})
+}
+
+func expandRequestSizeLimitingPluginConfig(d *schema.ResourceData) (map[string]interface{}, error) {
+	return map[string]interface{}{
+		"allowed_payload_size": d.Get("allowed_payload_size").(int),
+		"size_unit":            d.Get("size_unit").(string),
+	}, nil
+}
+
+func flattenRequestSizeLimitingPluginConfig(d *schema.ResourceData, config map[string]interface{}) {
+	d.Set("allowed_payload_size", configInt(config["allowed_payload_size"]))
+	d.Set("size_unit", configString(config["size_unit"]))
+}
+
+func validateAllowedPayloadSize(v interface{}, k string) ([]string, []error) {
+	if size := v.(int); size <= 0 {
+		return nil, []error{fmt.Errorf("%s must be greater than 0, got: %d", k, size)}
+	}
+	return nil, nil
+}
+
+func validateRequestSizeLimitingSizeUnit(value interface{}, k string) ([]string, []error) {
+	if !contains(requestSizeLimitingSizeUnits, value.(string)) {
+		return nil, []error{fmt.Errorf("%s must be one of %v, got: %s", k, requestSizeLimitingSizeUnits, value)}
+	}
+	return nil, nil
+}
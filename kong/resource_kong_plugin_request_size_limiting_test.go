@@ -0,0 +1,117 @@
+package kong
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/kevholditch/gokong"
+)
+
+func TestAccKongPluginRequestSizeLimiting(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheckKongVersion(t, "2.0.0") },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckKongTypedPluginDestroy("kong_plugin_request_size_limiting"),
+		Steps: []resource.TestStep{
+			{
+				Config: testCreateRequestSizeLimitingPluginConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckKongPluginExists("kong_plugin_request_size_limiting.limit"),
+					testAccCheckForChildIdCorrect("kong_service.service", "kong_plugin_request_size_limiting.limit", "service_id"),
+					resource.TestCheckResourceAttr("kong_plugin_request_size_limiting.limit", "allowed_payload_size", "64"),
+					resource.TestCheckResourceAttr("kong_plugin_request_size_limiting.limit", "size_unit", "kilobytes"),
+				),
+			},
+			{
+				Config: testUpdateRequestSizeLimitingPluginConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckKongPluginExists("kong_plugin_request_size_limiting.limit"),
+					resource.TestCheckResourceAttr("kong_plugin_request_size_limiting.limit", "allowed_payload_size", "128"),
+					resource.TestCheckResourceAttr("kong_plugin_request_size_limiting.limit", "size_unit", "megabytes"),
+				),
+			},
+			{
+				ResourceName:      "kong_plugin_request_size_limiting.limit",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccKongPluginRequestSizeLimitingInvalidSizeUnit(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      testCreateRequestSizeLimitingPluginInvalidSizeUnitConfig,
+				ExpectError: regexp.MustCompile("size_unit must be one of"),
+			},
+		},
+	})
+}
+
+func TestKongPluginRequestSizeLimitingSendsIntegers(t *testing.T) {
+
+	server := newTypedPluginServer(t, "", nil)
+	defer server.Close()
+
+	r := resourceKongPluginRequestSizeLimiting()
+	d := r.TestResourceData()
+	d.Set("allowed_payload_size", 64)
+	d.Set("size_unit", "kilobytes")
+	d.Set("enabled", true)
+
+	if err := r.Create(d, newKongClient(&gokong.Config{HostAddress: server.URL})); err != nil {
+		t.Fatalf("could not create request-size-limiting plugin: %v", err)
+	}
+	if len(server.bodies) != 1 {
+		t.Fatalf("expected the plugin to be created, kong was sent: %v", server.bodies)
+	}
+	body := server.bodies[0]
+
+	if !strings.Contains(body, `"allowed_payload_size":64,`) || !strings.Contains(body, `"size_unit":"kilobytes"`) {
+		t.Errorf("expected allowed_payload_size to be sent as the json integer 64, kong was sent: %s", body)
+	}
+
+	if d.Id() != "plugin-id" || d.Get("allowed_payload_size").(int) != 64 || d.Get("size_unit").(string) != "kilobytes" {
+		t.Errorf("expected the config to be read back from kong, got allowed_payload_size: %v size_unit: %v",
+			d.Get("allowed_payload_size"), d.Get("size_unit"))
+	}
+}
+
+const testCreateRequestSizeLimitingPluginConfig = `
+resource "kong_service" "service" {
+	name     = "test"
+	protocol = "http"
+	host     = "test.org"
+}
+
+resource "kong_plugin_request_size_limiting" "limit" {
+	service_id           = "${kong_service.service.id}"
+	allowed_payload_size = 64
+	size_unit            = "kilobytes"
+}
+`
+
+const testUpdateRequestSizeLimitingPluginConfig = `
+resource "kong_service" "service" {
+	name     = "test"
+	protocol = "http"
+	host     = "test.org"
+}
+
+resource "kong_plugin_request_size_limiting" "limit" {
+	service_id = "${kong_service.service.id}"
+}
+`
+
+const testCreateRequestSizeLimitingPluginInvalidSizeUnitConfig = `
+resource "kong_plugin_request_size_limiting" "limit" {
+	size_unit = "gigabytes"
+}
+`
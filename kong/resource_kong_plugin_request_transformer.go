@@ -0,0 +1,22 @@
+package kong
+
+import (
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// requestTransformerFields are the lists of each request-transformer action, body are the parameters of a form or json
+// body
+var requestTransformerFields = []string{"headers", "querystring", "body"}
+
+func resourceKongPluginRequestTransformer() *schema.Resource {
+	return resourceKongTypedPlugin(&typedPlugin{
+		name:   "request-transformer",
+		schema: transformerPluginSchema(requestTransformerFields),
+		expandConfig: func(d *schema.ResourceData) (map[string]interface{}, error) {
+			return expandTransformerPluginConfig(d, requestTransformerFields), nil
+		},
+		flattenConfig: func(d *schema.ResourceData, config map[string]interface{}) {
+			flattenTransformerPluginConfig(d, config, requestTransformerFields)
+		},
+	})
+}
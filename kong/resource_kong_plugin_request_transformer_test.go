@@ -0,0 +1,61 @@
+package kong
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kevholditch/gokong"
+)
+
+func TestKongPluginRequestTransformerActions(t *testing.T) {
+
+	server := newTestTransformerServer(t, requestTransformerFields)
+	defer server.Close()
+
+	client := newKongClient(&gokong.Config{HostAddress: server.URL})
+	r := resourceKongPluginRequestTransformer()
+	d := r.TestResourceData()
+	d.Set("rename", []interface{}{map[string]interface{}{"querystring": []interface{}{"q:query"}}})
+	d.Set("append", []interface{}{map[string]interface{}{
+		"headers":     []interface{}{"x-forwarded-prefix:/api", "x-client:terraform"},
+		"querystring": []interface{}{"version:2"},
+		"body":        []interface{}{"source:kong"},
+	}})
+	d.Set("enabled", true)
+
+	if err := r.Create(d, client); err != nil {
+		t.Fatalf("could not create request-transformer plugin: %v", err)
+	}
+
+	empty := map[string]interface{}{"headers": nil, "querystring": nil, "body": nil}
+	expected := map[string]interface{}{
+		"remove":  empty,
+		"rename":  map[string]interface{}{"headers": nil, "querystring": []interface{}{"q:query"}, "body": nil},
+		"replace": empty,
+		"add":     empty,
+		"append": map[string]interface{}{"headers": []interface{}{"x-forwarded-prefix:/api", "x-client:terraform"},
+			"querystring": []interface{}{"version:2"}, "body": []interface{}{"source:kong"}},
+	}
+	if config := server.sent[0]["config"]; server.sent[0]["name"] != "request-transformer" || !reflect.DeepEqual(config, expected) {
+		t.Errorf("expected the actions to be sent in kong's shape, kong was sent: %v", server.sent[0])
+	}
+
+	if headers := readStringArrayFromResource(d, "append.0.headers"); !reflect.DeepEqual(headers, []string{"x-forwarded-prefix:/api", "x-client:terraform"}) {
+		t.Errorf("expected the appended headers to be read back in order, got: %v", headers)
+	}
+	if query := readStringArrayFromResource(d, "rename.0.querystring"); !reflect.DeepEqual(query, []string{"q:query"}) {
+		t.Errorf("expected the renamed querystring to be read back, got: %v", query)
+	}
+	if blocks := d.Get("add").([]interface{}); len(blocks) != 0 {
+		t.Errorf("expected add not to be read back, got: %v", blocks)
+	}
+
+	for _, value := range []string{"a&b:1", "page=1:2"} {
+		if _, errors := validateTransformerEntry("add", "querystring")(value, "add.0.querystring.0"); len(errors) != 1 {
+			t.Errorf("expected querystring entry %q to be rejected, got: %v", value, errors)
+		}
+	}
+	if _, errors := validateTransformerEntry("rename", "querystring")("q:a b", "rename.0.querystring.0"); len(errors) != 1 {
+		t.Errorf("expected a querystring renamed to a name with a space to be rejected, got: %v", errors)
+	}
+}
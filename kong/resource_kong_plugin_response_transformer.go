@@ -0,0 +1,21 @@
+package kong
+
+import (
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// responseTransformerFields are the lists of each response-transformer action, json are the keys of a json body
+var responseTransformerFields = []string{"headers", "json"}
+
+func resourceKongPluginResponseTransformer() *schema.Resource {
+	return resourceKongTypedPlugin(&typedPlugin{
+		name:   "response-transformer",
+		schema: transformerPluginSchema(responseTransformerFields),
+		expandConfig: func(d *schema.ResourceData) (map[string]interface{}, error) {
+			return expandTransformerPluginConfig(d, responseTransformerFields), nil
+		},
+		flattenConfig: func(d *schema.ResourceData, config map[string]interface{}) {
+			flattenTransformerPluginConfig(d, config, responseTransformerFields)
+		},
+	})
+}
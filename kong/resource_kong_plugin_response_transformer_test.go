@@ -0,0 +1,117 @@
+package kong
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kevholditch/gokong"
+)
+
+// newTestTransformerServer mocks a kong node keeping the plugin it is sent, like kong it returns every action with the
+// lists that were not set as empty lists
+func newTestTransformerServer(t *testing.T, fields []string) *typedPluginServer {
+	return newTypedPluginServer(t, "", func(config map[string]interface{}) {
+		for _, action := range transformerActions {
+			lists, _ := config[action].(map[string]interface{})
+			if lists == nil {
+				lists = map[string]interface{}{}
+			}
+			for _, field := range fields {
+				if lists[field] == nil {
+					lists[field] = []interface{}{}
+				}
+			}
+			config[action] = lists
+		}
+	})
+}
+
+func TestKongPluginResponseTransformerActions(t *testing.T) {
+
+	server := newTestTransformerServer(t, responseTransformerFields)
+	defer server.Close()
+
+	client := newKongClient(&gokong.Config{HostAddress: server.URL})
+	r := resourceKongPluginResponseTransformer()
+	d := r.TestResourceData()
+	d.Set("remove", []interface{}{map[string]interface{}{"headers": []interface{}{"server", "x-powered-by"}}})
+	d.Set("add", []interface{}{map[string]interface{}{
+		"headers": []interface{}{"x-b:2", "x-a:1"},
+		"json":    []interface{}{"source:kong", "url:https://example.com"},
+	}})
+	d.Set("enabled", true)
+
+	if err := r.Create(d, client); err != nil {
+		t.Fatalf("could not create response-transformer plugin: %v", err)
+	}
+
+	empty := map[string]interface{}{"headers": nil, "json": nil}
+	expected := map[string]interface{}{
+		"remove":  map[string]interface{}{"headers": []interface{}{"server", "x-powered-by"}, "json": nil},
+		"rename":  empty,
+		"replace": empty,
+		"add":     map[string]interface{}{"headers": []interface{}{"x-b:2", "x-a:1"}, "json": []interface{}{"source:kong", "url:https://example.com"}},
+		"append":  empty,
+	}
+	if config := server.sent[0]["config"]; server.sent[0]["name"] != "response-transformer" || !reflect.DeepEqual(config, expected) {
+		t.Errorf("expected the actions to be sent in kong's shape, kong was sent: %v", server.sent[0])
+	}
+
+	// the entries are read back in order, the actions kong returns empty are not
+	if headers := readStringArrayFromResource(d, "add.0.headers"); !reflect.DeepEqual(headers, []string{"x-b:2", "x-a:1"}) {
+		t.Errorf("expected the added headers to be read back in order, got: %v", headers)
+	}
+	if value := d.Get("add.0.json.1"); value != "url:https://example.com" {
+		t.Errorf("expected the json value with a colon to be read back, got: %v", value)
+	}
+	for _, action := range []string{"rename", "replace", "append"} {
+		if blocks := d.Get(action).([]interface{}); len(blocks) != 0 {
+			t.Errorf("expected %s not to be read back, got: %v", action, blocks)
+		}
+	}
+
+	// a removed action is sent with its lists cleared
+	d.Set("remove", []interface{}{})
+	if err := r.Update(d, client); err != nil {
+		t.Fatalf("could not update response-transformer plugin: %v", err)
+	}
+	if remove := server.sent[1]["config"].(map[string]interface{})["remove"]; !reflect.DeepEqual(remove, empty) {
+		t.Errorf("expected the removed action to be cleared, kong was sent: %v", remove)
+	}
+	if blocks := d.Get("remove").([]interface{}); len(blocks) != 0 {
+		t.Errorf("expected the removed action not to be read back, got: %v", blocks)
+	}
+}
+
+func TestValidateTransformerEntry(t *testing.T) {
+
+	valid := []struct{ action, field, value string }{
+		{"remove", "headers", "x-powered-by"},
+		{"remove", "json", "data.secret"},
+		{"rename", "headers", "x-old:x-new"},
+		{"add", "headers", "x-empty:"},
+		{"add", "json", "url:https://example.com"},
+		{"append", "querystring", "page:1"},
+	}
+	for _, c := range valid {
+		if _, errors := validateTransformerEntry(c.action, c.field)(c.value, c.action); len(errors) != 0 {
+			t.Errorf("expected %s %s entry %q to be valid, got: %v", c.action, c.field, c.value, errors)
+		}
+	}
+
+	invalid := []struct{ action, field, value string }{
+		{"remove", "headers", "x powered by"},
+		{"remove", "json", ""},
+		{"rename", "headers", "x-old"},
+		{"rename", "headers", "x-old:"},
+		{"rename", "headers", "x-old:x new"},
+		{"add", "headers", "x-a"},
+		{"add", "json", ":value"},
+		{"replace", "headers", "x(a):1"},
+	}
+	for _, c := range invalid {
+		if _, errors := validateTransformerEntry(c.action, c.field)(c.value, c.action); len(errors) != 1 {
+			t.Errorf("expected %s %s entry %q to be rejected, got: %v", c.action, c.field, c.value, errors)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package kong
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// kong 2.3 gave the pre-function and post-function plugins a list of functions for each phase, before that they had a
+// single functions list run in the access phase. Kong 3.0 removed the functions list.
+const serverlessPhasesMinimumKongVersion = "2.3.0"
+
+var serverlessPhases = []string{"certificate", "rewrite", "access", "header_filter", "body_filter", "log"}
+
+const defaultServerlessPhase = "access"
+
+func resourceKongPluginPreFunction() *schema.Resource {
+	return resourceKongServerlessPlugin("pre-function")
+}
+
+func resourceKongPluginPostFunction() *schema.Resource {
+	return resourceKongServerlessPlugin("post-function")
+}
+
+func resourceKongServerlessPlugin(name string) *schema.Resource {
+	return resourceKongTypedPlugin(&typedPlugin{
+		name: name,
+		schema: map[string]*schema.Schema{
+			// lua code run in order, each one a chunk that can return a function
+			"functions": &schema.Schema{
+				Type:     schema.TypeList,
+				Required: true,
+				MinItems: 1,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			// the phase the functions run in, kong before 2.3 only runs them in the access phase
+			"phase": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      defaultServerlessPhase,
+				ValidateFunc: validateServerlessPhase,
+			},
+		},
+		expandConfig:  expandServerlessPluginConfig,
+		flattenConfig: flattenServerlessPluginConfig,
+		resolveConfig: resolveServerlessPluginPhase,
+	})
+}
+
+// expandServerlessPluginConfig sends the functions in the shape of kong 2.3 and later, resolveServerlessPluginPhase
+// changes it for older versions. Every other phase is sent empty, kong merges the config of an update so the functions
+// of a phase that is no longer used would otherwise still run.
+func expandServerlessPluginConfig(d *schema.ResourceData) (map[string]interface{}, error) {
+	config := map[string]interface{}{}
+	for _, phase := range serverlessPhases {
+		config[phase] = []string{}
+	}
+	config[d.Get("phase").(string)] = readStringArrayFromResource(d, "functions")
+	return config, nil
+}
+
+func resolveServerlessPluginPhase(client *kongClient, d *schema.ResourceData, config map[string]interface{}) error {
+	kongVersion, err := client.version()
+	if err != nil {
+		return fmt.Errorf("could not check kong version for the %s phase: %v", d.Get("phase"), err)
+	}
+
+	if !kongVersion.LessThan(version.Must(version.NewVersion(serverlessPhasesMinimumKongVersion))) {
+		return nil
+	}
+
+	if phase := d.Get("phase").(string); phase != defaultServerlessPhase {
+		return fmt.Errorf("phase %s requires kong %s or later, kong version is %s", phase, serverlessPhasesMinimumKongVersion, kongVersion)
+	}
+
+	functions := config[defaultServerlessPhase]
+	for _, phase := range serverlessPhases {
+		delete(config, phase)
+	}
+	config["functions"] = functions
+	return nil
+}
+
+// flattenServerlessPluginConfig reads the functions of the phase, or the functions list of kong before 2.3 (kong 2.3 to
+// 2.8 return both, the list is empty when the phases are used). The functions are kept in the order kong runs them.
+func flattenServerlessPluginConfig(d *schema.ResourceData, config map[string]interface{}) {
+	phase := d.Get("phase").(string)
+	if phase == "" {
+		// an imported plugin has no phase in state yet
+		phase = defaultServerlessPhase
+	}
+	functions := configStrings(config[phase])
+	if len(functions) == 0 {
+		functions = configStrings(config["functions"])
+	}
+
+	// a phase that was changed outside of terraform is read back when the configured one has no functions
+	if len(functions) == 0 {
+		for _, kongPhase := range serverlessPhases {
+			if kongFunctions := configStrings(config[kongPhase]); len(kongFunctions) > 0 {
+				phase, functions = kongPhase, kongFunctions
+				break
+			}
+		}
+	}
+
+	d.Set("phase", phase)
+	d.Set("functions", functions)
+}
+
+func validateServerlessPhase(value interface{}, k string) ([]string, []error) {
+	if !contains(serverlessPhases, value.(string)) {
+		return nil, []error{fmt.Errorf("%s must be one of %v, got: %s", k, serverlessPhases, value)}
+	}
+	return nil, nil
+}
@@ -0,0 +1,122 @@
+package kong
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kevholditch/gokong"
+)
+
+// newTestServerlessServer mocks a kong node of kongVersion keeping the plugin it is sent, kong 2.3 to 2.8 return the
+// deprecated functions list next to the phases
+func newTestServerlessServer(t *testing.T, kongVersion string) *typedPluginServer {
+	return newTypedPluginServer(t, kongVersion, func(config map[string]interface{}) {
+		if _, ok := config["functions"]; !ok && strings.HasPrefix(kongVersion, "2.") {
+			config["functions"] = []interface{}{}
+		}
+	})
+}
+
+func TestKongPluginPreFunctionPhases(t *testing.T) {
+
+	server := newTestServerlessServer(t, "2.8.1")
+	defer server.Close()
+
+	client := newKongClient(&gokong.Config{HostAddress: server.URL})
+	r := resourceKongPluginPreFunction()
+	d := r.TestResourceData()
+	functions := []string{"kong.log.err('first')", "return function() kong.log.err('second') end", "kong.log.err('third')"}
+	d.Set("functions", functions)
+	d.Set("phase", "access")
+	d.Set("enabled", true)
+
+	if err := r.Create(d, client); err != nil {
+		t.Fatalf("could not create pre-function plugin: %v", err)
+	}
+
+	expected := map[string]interface{}{"certificate": []interface{}{}, "rewrite": []interface{}{}, "access": []interface{}{functions[0], functions[1], functions[2]},
+		"header_filter": []interface{}{}, "body_filter": []interface{}{}, "log": []interface{}{}}
+	if config := server.sent[0]["config"]; server.sent[0]["name"] != "pre-function" || !reflect.DeepEqual(config, expected) {
+		t.Errorf("expected the functions to be sent in the access phase, kong was sent: %v", server.sent[0])
+	}
+	if read := readStringArrayFromResource(d, "functions"); !reflect.DeepEqual(read, functions) {
+		t.Errorf("expected the functions to be read back in order, got: %v", read)
+	}
+
+	// moving the functions to another phase empties the one they were in
+	d.Set("phase", "log")
+	if err := r.Update(d, client); err != nil {
+		t.Fatalf("could not update pre-function plugin: %v", err)
+	}
+	config := server.sent[1]["config"].(map[string]interface{})
+	if len(config["access"].([]interface{})) != 0 || len(config["log"].([]interface{})) != 3 {
+		t.Errorf("expected the functions to move to the log phase, kong was sent: %v", config)
+	}
+	if d.Get("phase") != "log" || len(readStringArrayFromResource(d, "functions")) != 3 {
+		t.Errorf("expected the log phase to be read back, got: %v %v", d.Get("phase"), d.Get("functions"))
+	}
+}
+
+func TestKongPluginPostFunctionFunctionsList(t *testing.T) {
+
+	server := newTestServerlessServer(t, "2.2.1")
+	defer server.Close()
+
+	client := newKongClient(&gokong.Config{HostAddress: server.URL})
+	r := resourceKongPluginPostFunction()
+	d := r.TestResourceData()
+	functions := []string{"kong.response.set_header('b', '2')", "kong.response.set_header('a', '1')"}
+	d.Set("functions", functions)
+	d.Set("phase", "access")
+	d.Set("enabled", true)
+
+	if err := r.Create(d, client); err != nil {
+		t.Fatalf("could not create post-function plugin: %v", err)
+	}
+
+	// kong before 2.3 only has the functions list
+	expected := map[string]interface{}{"functions": []interface{}{functions[0], functions[1]}}
+	if config := server.sent[0]["config"]; server.sent[0]["name"] != "post-function" || !reflect.DeepEqual(config, expected) {
+		t.Errorf("expected the functions to be sent as the functions list, kong was sent: %v", server.sent[0])
+	}
+	if read := readStringArrayFromResource(d, "functions"); !reflect.DeepEqual(read, functions) {
+		t.Errorf("expected the functions to be read back in order, got: %v", read)
+	}
+
+	d.Set("phase", "log")
+	if err := r.Update(d, client); err == nil || !strings.Contains(err.Error(), "phase log requires kong 2.3.0 or later") {
+		t.Errorf("expected phases other than access to need kong 2.3, got: %v", err)
+	}
+	if len(server.sent) != 1 {
+		t.Errorf("expected the rejected update not to be sent, kong was sent: %v", server.sent[1:])
+	}
+
+	if _, errors := validateServerlessPhase("init_worker", "phase"); len(errors) != 1 {
+		t.Errorf("expected an unknown phase to be rejected, got: %v", errors)
+	}
+}
+
+func TestFlattenServerlessPluginConfig(t *testing.T) {
+
+	cases := []struct {
+		config    map[string]interface{}
+		phase     string
+		functions []string
+	}{
+		// kong 3 has only the phases
+		{map[string]interface{}{"access": []interface{}{"a", "b"}, "log": []interface{}{}}, "access", []string{"a", "b"}},
+		// kong 2.3 to 2.8 can still have functions set in the deprecated list
+		{map[string]interface{}{"functions": []interface{}{"a"}, "access": []interface{}{}}, "access", []string{"a"}},
+		// a phase changed outside of terraform
+		{map[string]interface{}{"access": []interface{}{}, "rewrite": []interface{}{"b", "a"}}, "rewrite", []string{"b", "a"}},
+	}
+
+	for _, c := range cases {
+		d := resourceKongPluginPreFunction().TestResourceData()
+		flattenServerlessPluginConfig(d, c.config)
+		if d.Get("phase") != c.phase || !reflect.DeepEqual(readStringArrayFromResource(d, "functions"), c.functions) {
+			t.Errorf("%v: expected %v in the %s phase, got: %v in the %s phase", c.config, c.functions, c.phase, d.Get("functions"), d.Get("phase"))
+		}
+	}
+}
@@ -1,10 +1,20 @@
 package kong
 
 import (
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
 	"testing"
 
+	"github.com/hashicorp/terraform/config"
 	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/terraform"
 	"github.com/kevholditch/gokong"
 )
@@ -256,9 +266,1208 @@ func TestAccKongPluginImportConfigJson(t *testing.T) {
 	})
 }
 
+func TestAccKongPluginImportForASpecificConsumer(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckKongPluginDestroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testImportPluginForASpecificConsumerConfig,
+			},
+
+			resource.TestStep{
+				ResourceName:      "kong_plugin.request_size_limiting",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccKongPluginWithNumericStringJson(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckKongPluginDestroy,
+		Steps: []resource.TestStep{
+			{
+				// Kong returns allowed_payload_size as a number, the follow up plan must not show a change from the
+				// "64" in config
+				Config: testCreatePluginWithNumericStringJson,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckKongPluginExists("kong_plugin.request_size_limiting"),
+					resource.TestCheckResourceAttr("kong_plugin.request_size_limiting", "config_json", `{"allowed_payload_size":64}`),
+				),
+			},
+		},
+	})
+}
+
+func TestAccKongPluginWithSchemaDefaults(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheckKongVersion(t, "1.0.0") },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckKongPluginDestroy,
+		Steps: []resource.TestStep{
+			{
+				// Kong fills in policy, limit_by and the other rate-limiting defaults, the follow up plan must not show
+				// them as a change from the config that only sets minute
+				Config: testCreatePluginWithSchemaDefaults,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckKongPluginExists("kong_plugin.rate_limiting"),
+					resource.TestMatchResourceAttr("kong_plugin.rate_limiting", "config_json", regexp.MustCompile(`"policy":`)),
+					resource.TestMatchResourceAttr("kong_plugin.rate_limiting", "effective_config_json", regexp.MustCompile(`"limit_by":`)),
+				),
+			},
+		},
+	})
+}
+
+func TestAccKongPluginMigrateConfigToConfigJson(t *testing.T) {
+	var pluginId string
+
+	resource.Test(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckKongPluginDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testCreatePluginWithConfigMap,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckKongPluginExists("kong_plugin.request_size_limiting"),
+					testAccCheckKongPluginIdUnchanged("kong_plugin.request_size_limiting", &pluginId),
+				),
+			},
+			{
+				Config: testCreatePluginWithNumericStringJson,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckKongPluginIdUnchanged("kong_plugin.request_size_limiting", &pluginId),
+					resource.TestCheckResourceAttr("kong_plugin.request_size_limiting", "config.%", "0"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccKongPluginRename(t *testing.T) {
+	var pluginId string
+
+	resource.Test(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckKongPluginDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testRenamePluginConfig, "request-size-limiting", `{"allowed_payload_size": 64}`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckKongPluginExists("kong_plugin.renamed"),
+					testAccCheckKongPluginIdUnchanged("kong_plugin.renamed", &pluginId),
+				),
+			},
+			{
+				Config: fmt.Sprintf(testRenamePluginConfig, "rate-limiting", `{"minute": 10}`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckKongPluginExists("kong_plugin.renamed"),
+					resource.TestCheckResourceAttr("kong_plugin.renamed", "name", "rate-limiting"),
+					testAccCheckKongPluginReplaced("kong_plugin.renamed", &pluginId),
+				),
+			},
+		},
+	})
+}
+
+func TestAccKongPluginWithSensitiveJson(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckKongPluginDestroy,
+		Steps: []resource.TestStep{
+			{
+				// The follow up plan after this step must be empty, so the secret never shows in plan output
+				Config: testCreatePluginWithSensitiveJson,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckKongPluginExists("kong_plugin.datadog_sensitive"),
+					resource.TestCheckResourceAttr("kong_plugin.datadog_sensitive", "sensitive_config_json", fmt.Sprintf(`{"host":"%s"}`, hashSensitiveValue("s3cr3t.example.com"))),
+					testAccCheckKongPluginAttrExcludes("kong_plugin.datadog_sensitive", "config_json", "s3cr3t"),
+				),
+			},
+			{
+				Config: testUpdatePluginWithSensitiveJson,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckKongPluginExists("kong_plugin.datadog_sensitive"),
+					resource.TestCheckResourceAttr("kong_plugin.datadog_sensitive", "sensitive_config_json", fmt.Sprintf(`{"host":"%s"}`, hashSensitiveValue("r0tated.example.com"))),
+					testAccCheckKongPluginAttrExcludes("kong_plugin.datadog_sensitive", "config_json", "r0tated"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccKongPluginForASpecificServiceAndRoute(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckKongPluginDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testCreatePluginForASpecificServiceAndRouteConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckKongPluginExists("kong_plugin.rate_limit"),
+					testAccCheckForChildIdCorrect("kong_service.service", "kong_plugin.rate_limit", "service_id"),
+					testAccCheckForChildIdCorrect("kong_route.route", "kong_plugin.rate_limit", "route_id"),
+					resource.TestCheckResourceAttr("kong_plugin.rate_limit", "consumer_group_id", ""),
+				),
+			},
+		},
+	})
+}
+
+func TestAccKongPluginForAServiceByName(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckKongPluginDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testCreatePluginForAServiceByNameConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckKongPluginExists("kong_plugin.rate_limit"),
+					testAccCheckKongPluginScopedToService("kong_plugin.rate_limit", "kong_service.service"),
+					resource.TestCheckResourceAttr("kong_plugin.rate_limit", "service_name", "test"),
+					resource.TestCheckResourceAttr("kong_plugin.rate_limit", "service_id", ""),
+				),
+			},
+		},
+	})
+}
+
+func TestAccKongPluginForAMissingServiceName(t *testing.T) {
+
+	// the plugin is never created so there is nothing for a destroy check to look up
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      testCreatePluginForAMissingServiceNameConfig,
+				ExpectError: regexp.MustCompile("could not find kong service with name: does-not-exist"),
+			},
+		},
+	})
+}
+
+func TestAccKongPluginInvalidScope(t *testing.T) {
+
+	// the plugin is never created so there is nothing for a destroy check to look up
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      testCreatePluginForConsumerAndConsumerGroupConfig,
+				ExpectError: regexp.MustCompile("can not be scoped to both consumer_id and consumer_group_id"),
+			},
+			{
+				Config:      testCreatePluginForApiAndServiceConfig,
+				ExpectError: regexp.MustCompile("scoped to api_id can only also be scoped to consumer_id"),
+			},
+		},
+	})
+}
+
+func TestValidatePluginScope(t *testing.T) {
+
+	validScopes := []struct {
+		pluginRequest   gokong.PluginRequest
+		consumerGroupId string
+	}{
+		{gokong.PluginRequest{}, ""},
+		{gokong.PluginRequest{ServiceId: "s", RouteId: "r"}, ""},
+		{gokong.PluginRequest{ServiceId: "s", RouteId: "r", ConsumerId: "c"}, ""},
+		{gokong.PluginRequest{ServiceId: "s", RouteId: "r"}, "g"},
+		{gokong.PluginRequest{}, "g"},
+		{gokong.PluginRequest{ApiId: "a", ConsumerId: "c"}, ""},
+	}
+
+	for _, scope := range validScopes {
+		if err := validatePluginScope(&scope.pluginRequest, scope.consumerGroupId); err != nil {
+			t.Errorf("expected scope %+v consumer group %q to be valid, got: %v", scope.pluginRequest, scope.consumerGroupId, err)
+		}
+	}
+
+	invalidScopes := []struct {
+		pluginRequest   gokong.PluginRequest
+		consumerGroupId string
+	}{
+		{gokong.PluginRequest{ConsumerId: "c"}, "g"},
+		{gokong.PluginRequest{ServiceId: "s", ConsumerId: "c"}, "g"},
+		{gokong.PluginRequest{ApiId: "a", ServiceId: "s"}, ""},
+		{gokong.PluginRequest{ApiId: "a", RouteId: "r"}, ""},
+		{gokong.PluginRequest{ApiId: "a"}, "g"},
+	}
+
+	for _, scope := range invalidScopes {
+		if err := validatePluginScope(&scope.pluginRequest, scope.consumerGroupId); err == nil {
+			t.Errorf("expected scope %+v consumer group %q to be rejected", scope.pluginRequest, scope.consumerGroupId)
+		}
+	}
+}
+
+func TestKongPluginCreateErrorRedactsSecrets(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"message":"schema violation"}`))
+	}))
+	defer server.Close()
+
+	d := schema.TestResourceDataRaw(t, resourceKongPlugin().Schema, map[string]interface{}{
+		"name":                  "openid-connect",
+		"config_json":           `{"client_id":"my-client","client_secret":"t0p-s3cr3t","session":{"redis_password":"r3d1s-pw"}}`,
+		"sensitive_config_json": `{"issuer":"https://h1dden.example.com"}`,
+	})
+
+	err := resourceKongPluginCreate(d, newKongClient(&gokong.Config{HostAddress: server.URL}))
+
+	if err == nil {
+		t.Fatalf("expected the plugin create to fail")
+	}
+
+	for _, secret := range []string{"t0p-s3cr3t", "r3d1s-pw", "h1dden"} {
+		if strings.Contains(err.Error(), secret) {
+			t.Errorf("expected %s to be redacted from the error: %v", secret, err)
+		}
+	}
+
+	if !strings.Contains(err.Error(), "my-client") {
+		t.Errorf("expected the values that are not secret to stay in the error: %v", err)
+	}
+}
+
+func testAccCheckKongPluginAttrExcludes(resourceKey string, attribute string, value string) resource.TestCheckFunc {
+
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resourceKey]
+
+		if !ok {
+			return fmt.Errorf("not found: %s", resourceKey)
+		}
+
+		if strings.Contains(rs.Primary.Attributes[attribute], value) {
+			return fmt.Errorf("expected %s of %s not to contain %s", attribute, resourceKey, value)
+		}
+
+		return nil
+	}
+}
+
+func TestAccKongPluginConfigJsonFile(t *testing.T) {
+
+	file, err := ioutil.TempFile("", "plugin-config")
+	if err != nil {
+		t.Fatalf("could not create config file: %v", err)
+	}
+	defer os.Remove(file.Name())
+	file.Close()
+
+	writeConfig := func(config string) func() {
+		return func() {
+			if err := ioutil.WriteFile(file.Name(), []byte(config), 0600); err != nil {
+				t.Fatalf("could not write config file: %v", err)
+			}
+		}
+	}
+	writeConfig(`{"allowed_payload_size": 64}`)()
+
+	resource.Test(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckKongPluginDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testCreatePluginConfigJsonFileConfig, file.Name()),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckKongPluginExists("kong_plugin.request_size_limiting"),
+					resource.TestCheckResourceAttr("kong_plugin.request_size_limiting", "config_json", `{"allowed_payload_size":64}`),
+				),
+			},
+			{
+				// the path is the same, the changed contents are planned as an update
+				PreConfig: writeConfig(`{"allowed_payload_size": 128}`),
+				Config:    fmt.Sprintf(testCreatePluginConfigJsonFileConfig, file.Name()),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckKongPluginExists("kong_plugin.request_size_limiting"),
+					resource.TestCheckResourceAttr("kong_plugin.request_size_limiting", "config_json", `{"allowed_payload_size":128}`),
+				),
+			},
+		},
+	})
+}
+
+func TestKongPluginConfigJsonFile(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "plugin-config")
+	if err != nil {
+		t.Fatalf("could not create config directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	valid := filepath.Join(dir, "valid.json")
+	reformatted := filepath.Join(dir, "reformatted.json")
+	changed := filepath.Join(dir, "changed.json")
+	invalid := filepath.Join(dir, "invalid.json")
+	for path, contents := range map[string]string{
+		valid:       `{"minute": 10, "policy": "local"}`,
+		reformatted: "{\n  \"policy\": \"local\",\n  \"minute\": 10\n}\n",
+		changed:     `{"minute": 20, "policy": "local"}`,
+		invalid:     `{"minute": 10,`,
+	} {
+		if err := ioutil.WriteFile(path, []byte(contents), 0600); err != nil {
+			t.Fatalf("could not write %s: %v", path, err)
+		}
+	}
+
+	if _, errs := validatePluginConfigFile(valid, "config_json_file"); len(errs) != 0 {
+		t.Errorf("expected %s to be valid, got: %v", valid, errs)
+	}
+
+	for _, path := range []string{invalid, filepath.Join(dir, "missing.json")} {
+		if _, errs := validatePluginConfigFile(path, "config_json_file"); len(errs) != 1 {
+			t.Errorf("expected %s to be rejected", path)
+		}
+	}
+
+	state := pluginConfigFileState(valid)
+	if pluginConfigFilePath(state) != valid || !strings.HasPrefix(state, valid+pluginConfigFileHashSeparator) {
+		t.Errorf("expected the state of %s to be its path and hash, was %s", valid, state)
+	}
+
+	hash := strings.TrimPrefix(state, valid)
+	if strings.TrimPrefix(pluginConfigFileState(reformatted), reformatted) != hash {
+		t.Errorf("expected reformatting the file not to change its hash")
+	}
+	if strings.TrimPrefix(pluginConfigFileState(changed), changed) == hash {
+		t.Errorf("expected changing the config in the file to change its hash")
+	}
+
+	r := resourceKongPlugin()
+	for path, changes := range map[string]bool{valid: false, changed: true} {
+		rawConfig, err := config.NewRawConfig(map[string]interface{}{"name": "rate-limiting", "config_json_file": path})
+		if err != nil {
+			t.Fatalf("could not build config: %v", err)
+		}
+
+		diff, err := r.Diff(&terraform.InstanceState{
+			ID:         "plugin-id",
+			Attributes: map[string]string{"id": "plugin-id", "name": "rate-limiting", "enabled": "true", "fail_on_missing": "false", "config_json_file": strings.Replace(state, valid, path, 1)},
+		}, terraform.NewResourceConfig(rawConfig))
+		if err != nil {
+			t.Fatalf("could not diff plugin: %v", err)
+		}
+
+		if planned := diff != nil && diff.Attributes["config_json_file"] != nil; planned != changes {
+			t.Errorf("expected a change of config_json_file to be planned for %s: %t, got: %v", path, changes, diff)
+		}
+	}
+
+	// the request is built the same from the configured path and from the path and hash in state
+	for _, value := range []string{valid, state} {
+		d := schema.TestResourceDataRaw(t, resourceKongPlugin().Schema, map[string]interface{}{
+			"name":             "rate-limiting",
+			"config_json_file": value,
+		})
+
+		pluginRequest, err := createKongPluginRequestFromResourceData(d)
+		if err != nil {
+			t.Fatalf("could not build plugin request from %s: %v", value, err)
+		}
+
+		if pluginRequest.Config["minute"] != 10.0 || pluginRequest.Config["policy"] != "local" {
+			t.Errorf("expected the config from %s to be sent, got: %v", value, pluginRequest.Config)
+		}
+	}
+}
+
+func TestKongPluginImportConsumerGroupScope(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/plugins/plugin-id" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"plugin-id","name":"rate-limiting","enabled":true,"created_at":1700000000,"service":null,"route":null,"consumer":null,"consumer_group":{"id":"group-id"},"config":{"minute":10}}`))
+	}))
+	defer server.Close()
+
+	client := newKongClient(&gokong.Config{HostAddress: server.URL})
+	r := resourceKongPlugin()
+
+	imported, err := resourceKongPluginImport(r.Data(&terraform.InstanceState{ID: "plugin-id"}), client)
+	if err != nil {
+		t.Fatalf("could not import plugin: %v", err)
+	}
+
+	d := r.Data(imported[0].State())
+	if err := resourceKongPluginRead(d, client); err != nil {
+		t.Fatalf("could not refresh plugin: %v", err)
+	}
+
+	if d.Get("consumer_group_id") != "group-id" {
+		t.Errorf("expected consumer_group_id group-id to be read but was %s", d.Get("consumer_group_id"))
+	}
+
+	rawConfig, err := config.NewRawConfig(map[string]interface{}{
+		"name":              "rate-limiting",
+		"consumer_group_id": "group-id",
+		"config_json":       `{"minute": 10}`,
+	})
+	if err != nil {
+		t.Fatalf("could not build config: %v", err)
+	}
+
+	diff, err := r.Diff(d.State(), terraform.NewResourceConfig(rawConfig))
+	if err != nil {
+		t.Fatalf("could not diff plugin: %v", err)
+	}
+
+	if diff != nil && !diff.Empty() {
+		t.Errorf("expected no diff after importing a consumer group scoped plugin, got: %v", diff.Attributes)
+	}
+}
+
+func TestKongPluginProtocolsSchemaDefault(t *testing.T) {
+
+	protocols := `["grpc","grpcs","http","https"]`
+	var patched map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/schemas/plugins/rate-limiting":
+			w.Write([]byte(`{"fields":[{"protocols":{"type":"set","default":["grpc","grpcs","http","https"]}},` +
+				`{"config":{"type":"record","fields":[{"minute":{"type":"number"}}]}}]}`))
+		case r.URL.Path == "/plugins/plugin-id" && r.Method == http.MethodPatch:
+			patched = map[string]interface{}{}
+			json.NewDecoder(r.Body).Decode(&patched)
+			w.Write([]byte(`{"id":"plugin-id"}`))
+		case r.URL.Path == "/plugins/plugin-id":
+			w.Write([]byte(`{"id":"plugin-id","name":"rate-limiting","enabled":true,"protocols":` + protocols + `,"config":{"minute":10}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := newKongClient(&gokong.Config{HostAddress: server.URL})
+	r := withPluginSchemaDefaults(resourceKongPlugin(), func() interface{} { return client })
+
+	cases := []struct {
+		upstream   string
+		configured []interface{}
+		changes    bool
+	}{
+		{`["grpc","grpcs","http","https"]`, nil, false},
+		{`["https","http","grpcs","grpc"]`, nil, false},
+		{`["http","https"]`, nil, true},
+		{`["grpc","grpcs","http","https"]`, []interface{}{"https"}, true},
+		{`["https"]`, []interface{}{"https"}, false},
+	}
+
+	for _, c := range cases {
+		protocols = c.upstream
+		d := r.Data(&terraform.InstanceState{
+			ID: "plugin-id",
+			Attributes: map[string]string{"id": "plugin-id", "name": "rate-limiting", "enabled": "true", "fail_on_missing": "false",
+				"create_before_rename": "false", "config_merge_strategy": "replace", "config_json": `{"minute":10}`},
+		})
+		if err := resourceKongPluginRead(d, client); err != nil {
+			t.Fatalf("could not read plugin: %v", err)
+		}
+
+		raw := map[string]interface{}{"name": "rate-limiting", "config_json": `{"minute":10}`}
+		if c.configured != nil {
+			raw["protocols"] = c.configured
+		}
+		rawConfig, err := config.NewRawConfig(raw)
+		if err != nil {
+			t.Fatalf("could not build config: %v", err)
+		}
+
+		diff, err := r.Diff(d.State(), terraform.NewResourceConfig(rawConfig))
+		if err != nil {
+			t.Fatalf("could not diff plugin: %v", err)
+		}
+
+		if changes := diff != nil && !diff.Empty(); changes != c.changes {
+			t.Errorf("protocols %s in kong and %v configured: expected a diff: %t, got: %v", c.upstream, c.configured, c.changes, diff)
+		}
+	}
+
+	// removing the configured protocols sets them back to the schema default
+	protocols = `["https"]`
+	d := r.Data(&terraform.InstanceState{
+		ID:         "plugin-id",
+		Attributes: map[string]string{"id": "plugin-id", "name": "rate-limiting", "config_json": `{"minute":10}`},
+	})
+	if err := resourceKongPluginRead(d, client); err != nil {
+		t.Fatalf("could not read plugin: %v", err)
+	}
+
+	state := d.State()
+	_, err := r.Apply(state, &terraform.InstanceDiff{Attributes: map[string]*terraform.ResourceAttrDiff{
+		"protocols.#":          &terraform.ResourceAttrDiff{Old: "1", New: "0"},
+		"protocols.1893520017": &terraform.ResourceAttrDiff{Old: "https", New: "", NewRemoved: true},
+	}}, client)
+	if err != nil {
+		t.Fatalf("could not update plugin: %v", err)
+	}
+
+	if sent, _ := json.Marshal(patched["protocols"]); string(sent) != `["grpc","grpcs","http","https"]` {
+		t.Errorf("expected the schema default protocols to be sent when they were removed, got: %s", sent)
+	}
+}
+
+func TestKongPluginReadNestedConfig(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"plugin-id","created_at":1700000000,"name":"acme","enabled":true,"config":{"id":"computed","created_at":1700000000,` +
+			`"account_email":"ops@example.com","domains":["example.com"],"storage":"redis","storage_config":` +
+			`{"redis":{"host":"redis.example.com","port":6379,"database":0},"consul":{"host":"consul.example.com","kv_path":"acme","id":"kept"}}}}`))
+	}))
+	defer server.Close()
+
+	d := resourceKongPlugin().Data(&terraform.InstanceState{
+		ID:         "plugin-id",
+		Attributes: map[string]string{"id": "plugin-id", "name": "acme"},
+	})
+
+	if err := resourceKongPluginRead(d, newKongClient(&gokong.Config{HostAddress: server.URL})); err != nil {
+		t.Fatalf("could not read plugin: %v", err)
+	}
+
+	expected := `{"account_email":"ops@example.com","domains":["example.com"],"storage":"redis","storage_config":` +
+		`{"consul":{"host":"consul.example.com","id":"kept","kv_path":"acme"},"redis":{"database":0,"host":"redis.example.com","port":6379}}}`
+	if d.Get("config_json") != expected {
+		t.Errorf("expected config_json %s with only the top level computed properties removed but was %s", expected, d.Get("config_json"))
+	}
+
+	// the same config written in a different order, with a nested number as a string, is not a change
+	configured := `{"storage_config":{"redis":{"port":"6379","host":"redis.example.com","database":0},"consul":{"kv_path":"acme","id":"kept","host":"consul.example.com"}},` +
+		`"storage":"redis","domains":["example.com"],"account_email":"ops@example.com"}`
+	if !suppressEquivalentConfigJson("config_json", d.Get("config_json").(string), configured, d) {
+		t.Errorf("expected %s to be equivalent to %s", configured, d.Get("config_json"))
+	}
+
+	if normalized := normalizeDataJSON(configured); normalized != strings.Replace(expected, `"port":6379`, `"port":"6379"`, 1) {
+		t.Errorf("expected the nested config to be kept when normalized but was %s", normalized)
+	}
+
+	for _, changed := range []string{
+		strings.Replace(configured, `"kv_path":"acme"`, `"kv_path":"acme2"`, 1),
+		strings.Replace(configured, `,"id":"kept"`, ``, 1),
+	} {
+		if suppressEquivalentConfigJson("config_json", d.Get("config_json").(string), changed, d) {
+			t.Errorf("expected a change inside storage_config to be a diff: %s", changed)
+		}
+	}
+}
+
+func TestKongPluginReadKeepsVaultReferences(t *testing.T) {
+
+	configured := `{"headers":["{vault://env/header}","x-static"],"redis":{"host":"redis.example.com","password":"{vault://env/redis-password}"}}`
+
+	cases := []struct {
+		upstream string
+		expected string
+	}{
+		// kong echoes the references back unresolved
+		{configured, configured},
+		// kong returns the secrets resolved, they stay out of state
+		{`{"headers":["s3cr3t-header","x-static"],"redis":{"host":"redis.example.com","password":"s3cr3t"}}`, configured},
+		// the reference was changed outside of terraform, that is a change
+		{`{"headers":["{vault://env/header}","x-static"],"redis":{"host":"redis.example.com","password":"{vault://aws/redis}"}}`,
+			`{"headers":["{vault://env/header}","x-static"],"redis":{"host":"redis.example.com","password":"{vault://aws/redis}"}}`},
+		// values that are not references are read as they are
+		{`{"headers":["{vault://env/header}","x-other"],"redis":{"host":"other.example.com","password":"s3cr3t"}}`,
+			`{"headers":["{vault://env/header}","x-other"],"redis":{"host":"other.example.com","password":"{vault://env/redis-password}"}}`},
+	}
+
+	for _, c := range cases {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"id":"plugin-id","name":"http-log","enabled":true,"config":` + c.upstream + `}`))
+		}))
+
+		d := resourceKongPlugin().Data(&terraform.InstanceState{
+			ID:         "plugin-id",
+			Attributes: map[string]string{"id": "plugin-id", "name": "http-log", "config_json": configured},
+		})
+
+		err := resourceKongPluginRead(d, newKongClient(&gokong.Config{HostAddress: server.URL}))
+		server.Close()
+		if err != nil {
+			t.Fatalf("could not read plugin: %v", err)
+		}
+
+		if d.Get("config_json") != c.expected {
+			t.Errorf("kong returned %s: expected config_json %s but was %s", c.upstream, c.expected, d.Get("config_json"))
+		}
+
+		if strings.Contains(d.Get("config_json").(string), "s3cr3t") {
+			t.Errorf("kong returned %s: expected the resolved secrets to stay out of state but config_json was %s", c.upstream, d.Get("config_json"))
+		}
+	}
+}
+
+func TestKongPluginReadKeepsEncryptedConfigValues(t *testing.T) {
+
+	configured := `{"client_id":["my-client"],"client_secret":["s3cr3t"],"session_secret":"another-s3cr3t"}`
+
+	cases := []struct {
+		upstream string
+		expected string
+	}{
+		// kong enterprise returns the encrypted fields as a placeholder, the configured values are kept
+		{`{"client_id":["my-client"],"client_secret":["******"],"session_secret":"******"}`, configured},
+		// kong returns the values decrypted
+		{configured, configured},
+		// a field that is not encrypted changed outside of terraform is still a change
+		{`{"client_id":["other-client"],"client_secret":["******"],"session_secret":"******"}`,
+			`{"client_id":["other-client"],"client_secret":["s3cr3t"],"session_secret":"another-s3cr3t"}`},
+	}
+
+	for _, c := range cases {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"id":"plugin-id","name":"openid-connect","enabled":true,"config":` + c.upstream + `}`))
+		}))
+
+		d := resourceKongPlugin().Data(&terraform.InstanceState{
+			ID:         "plugin-id",
+			Attributes: map[string]string{"id": "plugin-id", "name": "openid-connect", "config_json": configured},
+		})
+
+		err := resourceKongPluginRead(d, newKongClient(&gokong.Config{HostAddress: server.URL}))
+		server.Close()
+		if err != nil {
+			t.Fatalf("could not read plugin: %v", err)
+		}
+
+		if d.Get("config_json") != c.expected {
+			t.Errorf("kong returned %s: expected config_json %s but was %s", c.upstream, c.expected, d.Get("config_json"))
+		}
+		if strings.Contains(d.Get("effective_config_json").(string), "***") {
+			t.Errorf("kong returned %s: expected the placeholder to stay out of state but effective_config_json was %s", c.upstream, d.Get("effective_config_json"))
+		}
+	}
+
+	// a placeholder is only replaced with a configured value
+	upstream := map[string]interface{}{"secret": "******", "other": "******", "nested": map[string]interface{}{"key": "***"}}
+	keepEncryptedConfigValues(upstream, map[string]interface{}{"secret": "s3cr3t", "nested": map[string]interface{}{"key": "k3y"}})
+	if upstream["secret"] != "s3cr3t" || upstream["other"] != "******" || upstream["nested"].(map[string]interface{})["key"] != "k3y" {
+		t.Errorf("expected only the configured placeholders to be replaced, got: %v", upstream)
+	}
+}
+
+func TestKongPluginReadEffectiveConfig(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"plugin-id","name":"rate-limiting","enabled":true,"config":{"id":"computed","created_at":1700000000,` +
+			`"minute":10,"policy":"redis","fault_tolerant":true,"hide_client_headers":false,"redis_password":"s3cr3t"}}`))
+	}))
+	defer server.Close()
+
+	for _, strategy := range []string{"replace", "deep"} {
+		d := resourceKongPlugin().Data(&terraform.InstanceState{
+			ID: "plugin-id",
+			Attributes: map[string]string{
+				"id":                    "plugin-id",
+				"name":                  "rate-limiting",
+				"config_merge_strategy": strategy,
+				"config_json":           `{"minute":10,"policy":"redis"}`,
+				"sensitive_config_json": `{"redis_password":"` + hashSensitiveValue("s3cr3t") + `"}`,
+			},
+		})
+
+		if err := resourceKongPluginRead(d, newKongClient(&gokong.Config{HostAddress: server.URL})); err != nil {
+			t.Fatalf("could not read plugin: %v", err)
+		}
+
+		expected := `{"fault_tolerant":true,"hide_client_headers":false,"minute":10,"policy":"redis"}`
+		if d.Get("effective_config_json") != expected {
+			t.Errorf("config_merge_strategy %s: expected effective_config_json %s with kong's defaults and without the secret but was %s",
+				strategy, expected, d.Get("effective_config_json"))
+		}
+
+		if expected := `{"minute":10,"policy":"redis"}`; strategy == "deep" && d.Get("config_json") != expected {
+			t.Errorf("config_merge_strategy %s: expected config_json %s to keep only the configured keys but was %s", strategy, expected, d.Get("config_json"))
+		}
+	}
+}
+
+func TestKongPluginReadMissing(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	for _, failOnMissing := range []bool{false, true} {
+		d := resourceKongPlugin().Data(&terraform.InstanceState{
+			ID: "plugin-id",
+			Attributes: map[string]string{
+				"id":              "plugin-id",
+				"name":            "request-size-limiting",
+				"fail_on_missing": fmt.Sprintf("%t", failOnMissing),
+			},
+		})
+
+		err := resourceKongPluginRead(d, newKongClient(&gokong.Config{HostAddress: server.URL}))
+
+		if failOnMissing {
+			if err == nil || !strings.Contains(err.Error(), "kong plugin plugin-id no longer exists and fail_on_missing is set") {
+				t.Errorf("expected a missing plugin to be an error with fail_on_missing set, got: %v", err)
+			}
+			if d.Id() != "plugin-id" {
+				t.Errorf("expected the plugin to be kept in state with fail_on_missing set")
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("expected a missing plugin not to be an error by default, got: %v", err)
+		}
+		if d.Id() != "" {
+			t.Errorf("expected a missing plugin to be removed from state by default but id was %s", d.Id())
+		}
+	}
+}
+
+func TestAccKongPluginToggleEnabled(t *testing.T) {
+
+	var pluginId string
+
+	steps := []resource.TestStep{}
+	for _, enabled := range []bool{false, true, false, true} {
+		steps = append(steps, resource.TestStep{
+			Config: fmt.Sprintf(testTogglePluginEnabledConfig, enabled),
+			Check: resource.ComposeTestCheckFunc(
+				testAccCheckKongPluginExists("kong_plugin.request_size_limiting"),
+				testAccCheckKongPluginIdUnchanged("kong_plugin.request_size_limiting", &pluginId),
+				resource.TestCheckResourceAttr("kong_plugin.request_size_limiting", "enabled", fmt.Sprintf("%t", enabled)),
+				resource.TestCheckResourceAttr("kong_plugin.request_size_limiting", "config_json", `{"allowed_payload_size":64}`),
+			),
+		})
+	}
+
+	resource.Test(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckKongPluginDestroy,
+		Steps:        steps,
+	})
+}
+
+func TestKongPluginToggleEnabledOnlyPatchesEnabled(t *testing.T) {
+
+	var patches []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPatch {
+			body, _ := ioutil.ReadAll(r.Body)
+			patches = append(patches, string(body))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"plugin-id","name":"request-size-limiting","enabled":false,"config":{"allowed_payload_size":64}}`))
+	}))
+	defer server.Close()
+
+	state := &terraform.InstanceState{
+		ID: "plugin-id",
+		Attributes: map[string]string{
+			"id":          "plugin-id",
+			"name":        "request-size-limiting",
+			"enabled":     "true",
+			"config_json": `{"allowed_payload_size":64}`,
+		},
+	}
+	diff := &terraform.InstanceDiff{
+		Attributes: map[string]*terraform.ResourceAttrDiff{
+			"enabled": {Old: "true", New: "false"},
+		},
+	}
+
+	newState, err := resourceKongPlugin().Apply(state, diff, newKongClient(&gokong.Config{HostAddress: server.URL}))
+
+	if err != nil {
+		t.Fatalf("could not toggle plugin: %v", err)
+	}
+
+	if len(patches) != 1 || patches[0] != `{"enabled":false}` {
+		t.Errorf("expected a single patch of enabled but kong was sent: %v", patches)
+	}
+
+	if newState.Attributes["enabled"] != "false" || newState.Attributes["config_json"] != `{"allowed_payload_size":64}` {
+		t.Errorf("expected enabled to be false and config_json to be unchanged: %v", newState.Attributes)
+	}
+}
+
+func TestKongPluginConfigMovedToConfigJsonIsNotSent(t *testing.T) {
+
+	var patches []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPatch {
+			body, _ := ioutil.ReadAll(r.Body)
+			patches = append(patches, string(body))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"plugin-id","name":"request-size-limiting","enabled":true,"config":{"allowed_payload_size":64}}`))
+	}))
+	defer server.Close()
+
+	client := newKongClient(&gokong.Config{HostAddress: server.URL})
+
+	for _, c := range []struct {
+		configJson string
+		patched    bool
+	}{
+		{`{"allowed_payload_size":"64"}`, false},
+		{`{"allowed_payload_size":128}`, true},
+	} {
+		patches = nil
+		state := &terraform.InstanceState{
+			ID: "plugin-id",
+			Attributes: map[string]string{
+				"id":                          "plugin-id",
+				"name":                        "request-size-limiting",
+				"enabled":                     "true",
+				"config.%":                    "1",
+				"config.allowed_payload_size": "64",
+				"config_json":                 `{"allowed_payload_size":64}`,
+			},
+		}
+		diff := &terraform.InstanceDiff{
+			Attributes: map[string]*terraform.ResourceAttrDiff{
+				"config.%":                    {Old: "1", New: "0"},
+				"config.allowed_payload_size": {Old: "64", New: "", NewRemoved: true},
+				"config_json":                 {Old: `{"allowed_payload_size":64}`, New: c.configJson},
+			},
+		}
+
+		newState, err := resourceKongPlugin().Apply(state, diff, client)
+		if err != nil {
+			t.Fatalf("could not move the config to config_json: %v", err)
+		}
+
+		if patched := len(patches) > 0; patched != c.patched {
+			t.Errorf("moving the config to config_json %s: expected the config to be sent: %t, kong was sent: %v", c.configJson, c.patched, patches)
+		}
+
+		if newState.ID != "plugin-id" || newState.Attributes["config.%"] != "" && newState.Attributes["config.%"] != "0" {
+			t.Errorf("expected the plugin to be updated in place without the config map: %v", newState)
+		}
+	}
+}
+
+func TestKongPluginUpdateKeepsUnchangedSensitiveConfig(t *testing.T) {
+
+	var sent []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPatch {
+			body, _ := ioutil.ReadAll(r.Body)
+			sent = append(sent, string(body))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"plugin-id","name":"datadog","enabled":true,"config":{"host":"s3cr3t.example.com","port":8126}}`))
+	}))
+	defer server.Close()
+
+	state := &terraform.InstanceState{
+		ID: "plugin-id",
+		Attributes: map[string]string{
+			"id":                    "plugin-id",
+			"name":                  "datadog",
+			"enabled":               "true",
+			"config_json":           `{"port":8125}`,
+			"sensitive_config_json": `{"host":"` + hashSensitiveValue("s3cr3t.example.com") + `"}`,
+		},
+	}
+	diff := &terraform.InstanceDiff{
+		Attributes: map[string]*terraform.ResourceAttrDiff{
+			"config_json": {Old: `{"port":8125}`, New: `{"port":8126}`},
+		},
+	}
+
+	newState, err := resourceKongPlugin().Apply(state, diff, newKongClient(&gokong.Config{HostAddress: server.URL}))
+	if err != nil {
+		t.Fatalf("could not update plugin: %v", err)
+	}
+
+	if len(sent) != 1 || strings.Contains(sent[0], sensitiveValueHashPrefix) || strings.Contains(sent[0], `"host"`) {
+		t.Errorf("expected only the changed config to be sent without the hashed sensitive value, kong was sent: %v", sent)
+	}
+
+	if newState.Attributes["sensitive_config_json"] != state.Attributes["sensitive_config_json"] || newState.Attributes["config_json"] != `{"port":8126}` {
+		t.Errorf("expected the hash of the sensitive value to stay in state and the new config in config_json: %v", newState.Attributes)
+	}
+}
+
+func TestKongPluginConfigMergeStrategyDeep(t *testing.T) {
+
+	for _, c := range []struct {
+		strategy string
+		sent     string
+		state    string
+	}{
+		// the keys kong has are sent back, nested objects merged and the array replaced, state only has the configured keys
+		{"deep",
+			`{"headers":["x-new"],"other_team":"kept","redis":{"host":"redis.example.com","port":6380,"timeout":2000}}`,
+			`{"headers":["x-new"],"redis":{"port":6380}}`},
+		// the config is sent as it is and the keys kong kept show in state
+		{"replace",
+			`{"headers":["x-new"],"redis":{"port":6380}}`,
+			`{"headers":["x-new"],"other_team":"kept","redis":{"port":6380}}`},
+	} {
+		config := `{"headers":["x-old","x-other"],"other_team":"kept","redis":{"host":"redis.example.com","port":6379,"timeout":2000}}`
+		var sent []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPatch {
+				body := map[string]json.RawMessage{}
+				json.NewDecoder(r.Body).Decode(&body)
+				sent = append(sent, string(body["config"]))
+				// kong keeps the keys that are not sent
+				merged := map[string]interface{}{}
+				json.Unmarshal([]byte(config), &merged)
+				patch := map[string]interface{}{}
+				json.Unmarshal(body["config"], &patch)
+				for key, val := range patch {
+					merged[key] = val
+				}
+				mergedJson, _ := json.Marshal(merged)
+				config = string(mergedJson)
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"id":"plugin-id","name":"http-log","enabled":true,"config":` + config + `}`))
+		}))
+
+		state := &terraform.InstanceState{
+			ID: "plugin-id",
+			Attributes: map[string]string{
+				"id":                    "plugin-id",
+				"name":                  "http-log",
+				"enabled":               "true",
+				"config_merge_strategy": c.strategy,
+				"config_json":           `{"headers":["x-old","x-other"],"redis":{"port":6379}}`,
+			},
+		}
+		diff := &terraform.InstanceDiff{
+			Attributes: map[string]*terraform.ResourceAttrDiff{
+				"config_json": {Old: state.Attributes["config_json"], New: `{"headers":["x-new"],"redis":{"port":6380}}`},
+			},
+		}
+
+		newState, err := resourceKongPlugin().Apply(state, diff, newKongClient(&gokong.Config{HostAddress: server.URL}))
+		server.Close()
+		if err != nil {
+			t.Fatalf("config_merge_strategy %s: could not update plugin: %v", c.strategy, err)
+		}
+
+		if len(sent) != 1 || sent[0] != c.sent {
+			t.Errorf("config_merge_strategy %s: expected the config %s to be sent, kong was sent: %v", c.strategy, c.sent, sent)
+		}
+
+		if newState.Attributes["config_json"] != c.state {
+			t.Errorf("config_merge_strategy %s: expected config_json %s in state but was %s", c.strategy, c.state, newState.Attributes["config_json"])
+		}
+	}
+
+	if _, errors := validateConfigMergeStrategy("shallow", "config_merge_strategy"); len(errors) != 1 {
+		t.Errorf("expected an unknown config_merge_strategy to be rejected, got: %v", errors)
+	}
+}
+
+func TestKongPluginRename(t *testing.T) {
+
+	var calls []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/plugins/":
+			calls = append(calls, "create")
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id":"new-id"}`))
+		case r.Method == http.MethodDelete:
+			calls = append(calls, "delete "+strings.TrimPrefix(r.URL.Path, "/plugins/"))
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == http.MethodGet && r.URL.Path == "/plugins/new-id":
+			w.Write([]byte(`{"id":"new-id","name":"rate-limiting","enabled":true,"service":{"id":"service-id"},"config":{"minute":10}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := newKongClient(&gokong.Config{HostAddress: server.URL})
+
+	for _, c := range []struct {
+		createBeforeRename string
+		oldServiceId       string
+		expected           string
+	}{
+		{"true", "service-id", "create,delete old-id"},
+		{"false", "service-id", "delete old-id,create"},
+		{"true", "other-service-id", "delete old-id,create"},
+	} {
+		calls = nil
+		state := &terraform.InstanceState{
+			ID: "old-id",
+			Attributes: map[string]string{
+				"id":                   "old-id",
+				"name":                 "request-size-limiting",
+				"enabled":              "true",
+				"service_id":           c.oldServiceId,
+				"create_before_rename": c.createBeforeRename,
+				"config_json":          `{"minute":10}`,
+			},
+		}
+		diff := &terraform.InstanceDiff{
+			Attributes: map[string]*terraform.ResourceAttrDiff{
+				"name": {Old: "request-size-limiting", New: "rate-limiting"},
+			},
+		}
+		if c.oldServiceId != "service-id" {
+			diff.Attributes["service_id"] = &terraform.ResourceAttrDiff{Old: c.oldServiceId, New: "service-id"}
+		}
+
+		newState, err := resourceKongPlugin().Apply(state, diff, client)
+		if err != nil {
+			t.Fatalf("could not rename plugin: %v", err)
+		}
+
+		if strings.Join(calls, ",") != c.expected {
+			t.Errorf("renaming with create_before_rename %s from service %s: expected kong to be called with %s, got: %v", c.createBeforeRename, c.oldServiceId, c.expected, calls)
+		}
+
+		if newState.ID != "new-id" || newState.Attributes["name"] != "rate-limiting" {
+			t.Errorf("expected the renamed plugin to replace the old one in state: %v", newState)
+		}
+	}
+}
+
+func TestKongPluginRenameKeepsOldPluginWhenCreateFails(t *testing.T) {
+
+	deletes := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodPost:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"message":"schema violation"}`))
+		case http.MethodDelete:
+			deletes++
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	d := resourceKongPlugin().Data(&terraform.InstanceState{
+		ID: "old-id",
+		Attributes: map[string]string{
+			"id":                   "old-id",
+			"name":                 "rate-limiting",
+			"enabled":              "true",
+			"create_before_rename": "true",
+			"config_json":          `{"minute":10}`,
+		},
+	})
+
+	err := resourceKongPluginRename(d, newKongClient(&gokong.Config{HostAddress: server.URL}))
+	if err == nil || !strings.Contains(err.Error(), "failed to create kong plugin") {
+		t.Errorf("expected the rename to fail creating the new plugin, got: %v", err)
+	}
+
+	if deletes != 0 || d.Id() != "old-id" {
+		t.Errorf("expected the old plugin to be kept when the new one could not be created, deleted %d times with id %s", deletes, d.Id())
+	}
+}
+
+// testAccCheckKongPluginScopedToService checks the plugin is scoped to the service in kong, not just in state
+func testAccCheckKongPluginScopedToService(pluginKey string, serviceKey string) resource.TestCheckFunc {
+
+	return func(s *terraform.State) error {
+		pluginState, ok := s.RootModule().Resources[pluginKey]
+		if !ok {
+			return fmt.Errorf("not found: %s", pluginKey)
+		}
+
+		serviceState, ok := s.RootModule().Resources[serviceKey]
+		if !ok {
+			return fmt.Errorf("not found: %s", serviceKey)
+		}
+
+		plugin, err := getKongScopedPlugin(testAccProvider.Meta().(*kongClient), pluginState.Primary.ID)
+		if err != nil {
+			return err
+		}
+
+		if plugin == nil {
+			return fmt.Errorf("plugin with id %v not found", pluginState.Primary.ID)
+		}
+
+		if serviceId := firstNonEmpty(plugin.ServiceId, plugin.Service.id()); serviceId != serviceState.Primary.ID {
+			return fmt.Errorf("expected plugin to be scoped to service %s but was scoped to %s", serviceState.Primary.ID, serviceId)
+		}
+
+		return nil
+	}
+}
+
+// testAccCheckKongPluginIdUnchanged fails if the plugin was replaced since the previous step
+func testAccCheckKongPluginIdUnchanged(resourceKey string, pluginId *string) resource.TestCheckFunc {
+
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resourceKey]
+
+		if !ok {
+			return fmt.Errorf("not found: %s", resourceKey)
+		}
+
+		if *pluginId != "" && *pluginId != rs.Primary.ID {
+			return fmt.Errorf("expected plugin %s to be updated in place but it was replaced by %s", *pluginId, rs.Primary.ID)
+		}
+
+		*pluginId = rs.Primary.ID
+		return nil
+	}
+}
+
+// testAccCheckKongPluginReplaced checks the plugin got a new id and the plugin it replaced no longer exists
+func testAccCheckKongPluginReplaced(resourceKey string, oldPluginId *string) resource.TestCheckFunc {
+
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resourceKey]
+
+		if !ok {
+			return fmt.Errorf("not found: %s", resourceKey)
+		}
+
+		if rs.Primary.ID == *oldPluginId {
+			return fmt.Errorf("expected plugin %s to be replaced", *oldPluginId)
+		}
+
+		plugin, err := testAccProvider.Meta().(*kongClient).Plugins().GetById(*oldPluginId)
+		if err != nil {
+			return err
+		}
+
+		if plugin != nil {
+			return fmt.Errorf("plugin %s was replaced by %s but still exists", *oldPluginId, rs.Primary.ID)
+		}
+
+		return nil
+	}
+}
+
 func testAccCheckKongPluginDestroy(state *terraform.State) error {
 
-	client := testAccProvider.Meta().(*gokong.KongAdminClient)
+	client := testAccProvider.Meta().(*kongClient)
 
 	plugins := getResourcesByType("kong_plugin", state)
 
@@ -327,7 +1536,7 @@ func testAccCheckKongPluginExists(resourceKey string) resource.TestCheckFunc {
 			return fmt.Errorf("no ID is set")
 		}
 
-		api, err := testAccProvider.Meta().(*gokong.KongAdminClient).Plugins().GetById(rs.Primary.ID)
+		api, err := testAccProvider.Meta().(*kongClient).Plugins().GetById(rs.Primary.ID)
 
 		if err != nil {
 			return err
@@ -685,3 +1894,380 @@ resource "kong_plugin" "datadog_test" {
 	EOT
 }
 `
+
+const testCreatePluginWithNumericStringJson = `
+resource "kong_plugin" "request_size_limiting" {
+	name  = "request-size-limiting"
+	config_json = <<EOT
+	{
+	  "allowed_payload_size": "64"
+	}
+	EOT
+}
+`
+
+const testRenamePluginConfig = `
+resource "kong_service" "service" {
+	name     = "renamed-plugin-service"
+	protocol = "http"
+	host     = "test.org"
+}
+
+resource "kong_plugin" "renamed" {
+	name                 = "%s"
+	service_id           = "${kong_service.service.id}"
+	create_before_rename = true
+	config_json          = <<EOT
+	%s
+	EOT
+}
+`
+
+const testCreatePluginWithConfigMap = `
+resource "kong_plugin" "request_size_limiting" {
+	name   = "request-size-limiting"
+	config = {
+		allowed_payload_size = "64"
+	}
+}
+`
+
+const testCreatePluginWithSchemaDefaults = `
+resource "kong_plugin" "rate_limiting" {
+	name  = "rate-limiting"
+	config_json = <<EOT
+	{
+	  "minute": 10
+	}
+	EOT
+}
+`
+
+const testCreatePluginWithSensitiveJson = `
+resource "kong_plugin" "datadog_sensitive" {
+	name  = "datadog"
+	config_json = <<EOT
+	{
+	  "prefix": "kong",
+	  "port": 8125,
+	  "metrics": [
+	    {
+	      "sample_rate": 1,
+	      "name": "request_count",
+	      "stat_type": "counter"
+	    }
+	  ]
+	}
+	EOT
+	sensitive_config_json = <<EOT
+	{
+	  "host": "s3cr3t.example.com"
+	}
+	EOT
+}
+`
+
+const testUpdatePluginWithSensitiveJson = `
+resource "kong_plugin" "datadog_sensitive" {
+	name  = "datadog"
+	config_json = <<EOT
+	{
+	  "prefix": "kong",
+	  "port": 8125,
+	  "metrics": [
+	    {
+	      "sample_rate": 1,
+	      "name": "request_count",
+	      "stat_type": "counter"
+	    }
+	  ]
+	}
+	EOT
+	sensitive_config_json = <<EOT
+	{
+	  "host": "r0tated.example.com"
+	}
+	EOT
+}
+`
+
+const testCreatePluginForASpecificServiceAndRouteConfig = `
+resource "kong_service" "service" {
+	name     = "test"
+	protocol = "http"
+	host     = "test.org"
+}
+
+resource "kong_route" "route" {
+	protocols 		= [ "http", "https" ]
+	methods 		= [ "GET", "POST" ]
+	hosts 			= [ "example2.com" ]
+	paths 			= [ "/test" ]
+	strip_path 		= false
+	preserve_host 	= true
+	service_id 		= "${kong_service.service.id}"
+}
+
+resource "kong_plugin" "rate_limit" {
+	name        = "response-ratelimiting"
+	service_id  = "${kong_service.service.id}"
+	route_id    = "${kong_route.route.id}"
+	config 		= {
+		limits.sms.minute = 20
+	}
+}
+`
+
+const testCreatePluginForConsumerAndConsumerGroupConfig = `
+resource "kong_consumer" "plugin_consumer" {
+	username  = "PluginUser"
+	custom_id = "111"
+}
+
+resource "kong_plugin" "rate_limit" {
+	name              = "response-ratelimiting"
+	consumer_id       = "${kong_consumer.plugin_consumer.id}"
+	consumer_group_id = "00000000-0000-0000-0000-000000000000"
+	config 		= {
+		limits.sms.minute = 20
+	}
+}
+`
+
+const testCreatePluginForApiAndServiceConfig = `
+resource "kong_service" "service" {
+	name     = "test"
+	protocol = "http"
+	host     = "test.org"
+}
+
+resource "kong_plugin" "rate_limit" {
+	name        = "response-ratelimiting"
+	api_id      = "00000000-0000-0000-0000-000000000000"
+	service_id  = "${kong_service.service.id}"
+	config 		= {
+		limits.sms.minute = 20
+	}
+}
+`
+
+const testImportPluginForASpecificConsumerConfig = `
+resource "kong_consumer" "plugin_consumer" {
+	username  = "PluginUser"
+	custom_id = "111"
+}
+
+resource "kong_plugin" "request_size_limiting" {
+	name        = "request-size-limiting"
+	consumer_id = "${kong_consumer.plugin_consumer.id}"
+	config_json = <<EOT
+{
+	"allowed_payload_size": 64
+}
+EOT
+}
+`
+
+const testTogglePluginEnabledConfig = `
+resource "kong_plugin" "request_size_limiting" {
+	name        = "request-size-limiting"
+	enabled     = %t
+	config_json = <<EOT
+	{
+	  "allowed_payload_size": 64
+	}
+	EOT
+}
+`
+
+const testCreatePluginForAServiceByNameConfig = `
+resource "kong_service" "service" {
+	name     = "test"
+	protocol = "http"
+	host     = "test.org"
+}
+
+resource "kong_plugin" "rate_limit" {
+	name         = "response-ratelimiting"
+	service_name = "${kong_service.service.name}"
+	config 		 = {
+		limits.sms.minute = 20
+	}
+}
+`
+
+const testCreatePluginForAMissingServiceNameConfig = `
+resource "kong_plugin" "rate_limit" {
+	name         = "response-ratelimiting"
+	service_name = "does-not-exist"
+	config 		 = {
+		limits.sms.minute = 20
+	}
+}
+`
+
+const testCreatePluginConfigJsonFileConfig = `
+resource "kong_plugin" "request_size_limiting" {
+	name             = "request-size-limiting"
+	config_json_file = "%s"
+}
+`
+
+func TestKongPluginConfigJsonIndent(t *testing.T) {
+
+	var sent []string
+	upstreamConfig := `{}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != gokong.PluginsPath && r.URL.Path != gokong.PluginsPath+"plugin-id" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if r.Method == http.MethodPost {
+			body := map[string]json.RawMessage{}
+			json.NewDecoder(r.Body).Decode(&body)
+			sent = append(sent, string(body["config"]))
+			upstreamConfig = string(body["config"])
+			w.WriteHeader(http.StatusCreated)
+		}
+		w.Write([]byte(`{"id":"plugin-id","name":"http-log","enabled":true,"config":` + upstreamConfig + `}`))
+	}))
+	defer server.Close()
+
+	client := newKongClient(&gokong.Config{HostAddress: server.URL})
+	client.configJsonIndent = "  "
+
+	r := resourceKongPlugin()
+	rawConfig, err := config.NewRawConfig(map[string]interface{}{
+		"name":        "http-log",
+		"config_json": `{"timeout": 5000, "http_endpoint": "https://logs.example.com", "headers": {"x-team": "billing"}}`,
+	})
+	if err != nil {
+		t.Fatalf("could not build config: %v", err)
+	}
+
+	diff, err := r.Diff(nil, terraform.NewResourceConfig(rawConfig))
+	if err != nil {
+		t.Fatalf("could not diff plugin: %v", err)
+	}
+	state, err := r.Apply(nil, diff, client)
+	if err != nil {
+		t.Fatalf("could not create plugin: %v", err)
+	}
+
+	// kong is sent the config as it always is
+	if len(sent) != 1 || sent[0] != `{"headers":{"x-team":"billing"},"http_endpoint":"https://logs.example.com","timeout":5000}` {
+		t.Errorf("expected the config to be sent to kong on one line, kong was sent: %v", sent)
+	}
+
+	expected := "{\n  \"headers\": {\n    \"x-team\": \"billing\"\n  },\n  \"http_endpoint\": \"https://logs.example.com\",\n  \"timeout\": 5000\n}"
+	if state.Attributes["config_json"] != expected {
+		t.Errorf("expected config_json to be stored indented with sorted keys, got: %s", state.Attributes["config_json"])
+	}
+
+	for i := 0; i < 2; i++ {
+		refreshed, err := r.Refresh(state, client)
+		if err != nil {
+			t.Fatalf("could not refresh plugin: %v", err)
+		}
+		if refreshed.Attributes["config_json"] != expected {
+			t.Errorf("expected the same config_json on every refresh, got: %s", refreshed.Attributes["config_json"])
+		}
+		state = refreshed
+	}
+
+	diff, err = r.Diff(state, terraform.NewResourceConfig(rawConfig))
+	if err != nil {
+		t.Fatalf("could not diff plugin: %v", err)
+	}
+	if diff != nil && !diff.Empty() {
+		t.Errorf("expected no diff between the indented config_json and the config, got: %v", diff.Attributes)
+	}
+
+	if _, errors := validateConfigJsonIndent(-1, "config_json_indent"); len(errors) != 1 {
+		t.Errorf("expected a negative config_json_indent to be rejected, got: %v", errors)
+	}
+}
+
+func TestKongPluginProtocolsKongVersion(t *testing.T) {
+
+	kongVersion := "2.8.1"
+	var sent []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/":
+			json.NewEncoder(w).Encode(map[string]string{"version": kongVersion})
+		case strings.HasPrefix(r.URL.Path, "/plugins") && (r.Method == http.MethodPost || r.Method == http.MethodPatch):
+			sent = append(sent, r.Method)
+			if r.Method == http.MethodPost {
+				w.WriteHeader(http.StatusCreated)
+			}
+			w.Write([]byte(`{"id":"plugin-id","name":"ip-restriction","enabled":true,"protocols":["http"],"config":{}}`))
+		case r.URL.Path == "/plugins/plugin-id":
+			w.Write([]byte(`{"id":"plugin-id","name":"ip-restriction","enabled":true,"protocols":["http"],"config":{}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	r := resourceKongPlugin()
+	diff := func(state *terraform.InstanceState, protocols []interface{}) *terraform.InstanceDiff {
+		rawConfig, err := config.NewRawConfig(map[string]interface{}{"name": "ip-restriction", "protocols": protocols})
+		if err != nil {
+			t.Fatalf("could not build config: %v", err)
+		}
+		instanceDiff, err := r.Diff(state, terraform.NewResourceConfig(rawConfig))
+		if err != nil {
+			t.Fatalf("could not diff plugin: %v", err)
+		}
+		return instanceDiff
+	}
+
+	cases := []struct {
+		kongVersion string
+		protocols   []interface{}
+		unsupported string
+	}{
+		{"1.2.0", []interface{}{"grpc", "http"}, "grpc (requires kong 1.3.0)"},
+		{"1.3.0", []interface{}{"grpc", "http"}, ""},
+		{"2.8.1", []interface{}{"ws", "https"}, "ws (requires kong 3.0.0)"},
+		{"3.0.0", []interface{}{"ws", "wss"}, ""},
+	}
+
+	var created *terraform.InstanceState
+	for _, c := range cases {
+		kongVersion = c.kongVersion
+		sent = nil
+		client := newKongClient(&gokong.Config{HostAddress: server.URL})
+
+		state, err := r.Apply(nil, diff(nil, c.protocols), client)
+		if c.unsupported == "" {
+			if err != nil {
+				t.Errorf("expected %v to be created on kong %s, got: %v", c.protocols, c.kongVersion, err)
+			}
+			created = state
+			continue
+		}
+
+		if err == nil || !strings.Contains(err.Error(), c.unsupported) {
+			t.Errorf("expected %v to be rejected on kong %s, got: %v", c.protocols, c.kongVersion, err)
+		}
+		if len(sent) != 0 {
+			t.Errorf("expected the rejected plugin not to be sent to kong %s, kong was sent: %v", c.kongVersion, sent)
+		}
+	}
+
+	// changing a plugin to a protocol its kong does not support is rejected before it is patched
+	kongVersion = "2.8.1"
+	sent = nil
+	client := newKongClient(&gokong.Config{HostAddress: server.URL})
+	if _, err := r.Apply(created, diff(created, []interface{}{"wss"}), client); err == nil || !strings.Contains(err.Error(), "wss (requires kong 3.0.0)") {
+		t.Errorf("expected wss to be rejected on kong 2.8.1, got: %v", err)
+	}
+	if len(sent) != 0 {
+		t.Errorf("expected the rejected update not to be sent, kong was sent: %v", sent)
+	}
+}
@@ -0,0 +1,168 @@
+package kong
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+const rbacUsersPath = "/rbac/users/"
+
+type rbacUser struct {
+	Id        string  `json:"id,omitempty"`
+	Name      string  `json:"name,omitempty"`
+	Comment   *string `json:"comment,omitempty"`
+	Enabled   *bool   `json:"enabled,omitempty"`
+	UserToken string  `json:"user_token,omitempty"`
+}
+
+func resourceKongRbacUser() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceKongRbacUserCreate,
+		Read:   resourceKongRbacUserRead,
+		Delete: resourceKongRbacUserDelete,
+		Update: resourceKongRbacUserUpdate,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: false,
+			},
+			"comment": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: false,
+			},
+			"enabled": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				ForceNew: false,
+				Default:  true,
+			},
+			// kong only keeps a hash of the token, it can not be read back so state keeps the one that was sent
+			"user_token": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				Sensitive:   true,
+				Description: "The token the user authenticates to the admin api with, one is generated when it is not set",
+			},
+		},
+	}
+}
+
+func resourceKongRbacUserCreate(d *schema.ResourceData, meta interface{}) error {
+
+	client := meta.(*kongClient)
+	if err := client.requireEnterprise("kong_rbac_user"); err != nil {
+		return err
+	}
+
+	userRequest := createKongRbacUserRequestFromResourceData(d)
+	if userRequest.UserToken == "" {
+		token, err := generateRbacUserToken()
+		if err != nil {
+			return fmt.Errorf("could not generate a token for kong rbac user %s: %v", userRequest.Name, err)
+		}
+		userRequest.UserToken = token
+	}
+
+	createdUser := &rbacUser{}
+	err := client.post(rbacUsersPath, userRequest, createdUser)
+
+	// the token is left out of the error
+	if err != nil {
+		return fmt.Errorf("failed to create kong rbac user %s error: %v", userRequest.Name, err)
+	}
+
+	d.SetId(createdUser.Id)
+	d.Set("user_token", userRequest.UserToken)
+
+	return resourceKongRbacUserRead(d, meta)
+}
+
+func resourceKongRbacUserUpdate(d *schema.ResourceData, meta interface{}) error {
+	d.Partial(false)
+
+	client := meta.(*kongClient)
+	if err := client.requireEnterprise("kong_rbac_user"); err != nil {
+		return err
+	}
+
+	userRequest := createKongRbacUserRequestFromResourceData(d)
+	// the token is only sent when it was changed, removing it keeps the one the user has
+	if !d.HasChange("user_token") {
+		userRequest.UserToken = ""
+	}
+
+	if err := client.patch(rbacUsersPath+d.Id(), userRequest, nil); err != nil {
+		return fmt.Errorf("error updating kong rbac user: %s", err)
+	}
+
+	return resourceKongRbacUserRead(d, meta)
+}
+
+func resourceKongRbacUserRead(d *schema.ResourceData, meta interface{}) error {
+
+	user := &rbacUser{}
+	found, err := meta.(*kongClient).get(rbacUsersPath+d.Id(), user)
+
+	if err != nil {
+		return fmt.Errorf("could not find kong rbac user: %v", err)
+	}
+
+	if !found || user.Id == "" {
+		d.SetId("")
+		return nil
+	}
+
+	d.Set("name", user.Name)
+	if user.Comment != nil {
+		d.Set("comment", *user.Comment)
+	} else {
+		d.Set("comment", "")
+	}
+	if user.Enabled != nil {
+		d.Set("enabled", *user.Enabled)
+	}
+
+	return nil
+}
+
+func resourceKongRbacUserDelete(d *schema.ResourceData, meta interface{}) error {
+
+	// kong removes the roles of the user along with it
+	if err := meta.(*kongClient).delete(rbacUsersPath + d.Id()); err != nil {
+		return fmt.Errorf("could not delete kong rbac user: %v", err)
+	}
+
+	return nil
+}
+
+func createKongRbacUserRequestFromResourceData(d *schema.ResourceData) *rbacUser {
+
+	comment := readStringFromResource(d, "comment")
+	enabled := d.Get("enabled").(bool)
+
+	return &rbacUser{
+		Name:      readStringFromResource(d, "name"),
+		Comment:   &comment,
+		Enabled:   &enabled,
+		UserToken: readStringFromResource(d, "user_token"),
+	}
+}
+
+// generateRbacUserToken returns 32 random bytes as hex, kong accepts any string as a token
+func generateRbacUserToken() (string, error) {
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(token), nil
+}
@@ -0,0 +1,175 @@
+package kong
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+const rbacRolesPath = "/rbac/roles/"
+
+type rbacRole struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type rbacUserRoles struct {
+	Roles []*rbacRole `json:"roles"`
+}
+
+// rbacUserRolesRequest names the roles to add or remove, kong takes a comma separated list of role names
+type rbacUserRolesRequest struct {
+	Roles string `json:"roles"`
+}
+
+func resourceKongRbacUserRole() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceKongRbacUserRoleCreate,
+		Read:   resourceKongRbacUserRoleRead,
+		Delete: resourceKongRbacUserRoleDelete,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"user_id": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"role_id": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+		},
+	}
+}
+
+func rbacUserRoleId(userId string, roleId string) string {
+	return userId + "|" + roleId
+}
+
+func splitRbacUserRoleId(id string) (string, string, error) {
+	idSplit := strings.Split(id, "|")
+
+	if len(idSplit) != 2 || idSplit[0] == "" || idSplit[1] == "" {
+		return "", "", fmt.Errorf("kong rbac user role id should be pipe separated as userId|roleId found: %v", id)
+	}
+
+	return idSplit[0], idSplit[1], nil
+}
+
+func rbacUserRolesPath(userId string) string {
+	return rbacUsersPath + userId + "/roles"
+}
+
+// getKongRbacRole returns nil without an error when the role does not exist
+func getKongRbacRole(client *kongClient, roleId string) (*rbacRole, error) {
+	role := &rbacRole{}
+	found, err := client.get(rbacRolesPath+roleId, role)
+	if err != nil {
+		return nil, err
+	}
+
+	if !found || role.Id == "" {
+		return nil, nil
+	}
+
+	return role, nil
+}
+
+func resourceKongRbacUserRoleCreate(d *schema.ResourceData, meta interface{}) error {
+
+	client := meta.(*kongClient)
+	if err := client.requireEnterprise("kong_rbac_user_role"); err != nil {
+		return err
+	}
+
+	userId := readStringFromResource(d, "user_id")
+	roleId := readStringFromResource(d, "role_id")
+
+	// roles are given to a user by name
+	role, err := getKongRbacRole(client, roleId)
+	if err != nil {
+		return fmt.Errorf("could not find kong rbac role %s: %v", roleId, err)
+	}
+
+	if role == nil {
+		return fmt.Errorf("could not find kong rbac role %s", roleId)
+	}
+
+	if err := client.post(rbacUserRolesPath(userId), &rbacUserRolesRequest{Roles: role.Name}, nil); err != nil {
+		return fmt.Errorf("failed to add kong rbac role %s to user %s error: %v", roleId, userId, err)
+	}
+
+	d.SetId(rbacUserRoleId(userId, role.Id))
+
+	return resourceKongRbacUserRoleRead(d, meta)
+}
+
+func resourceKongRbacUserRoleRead(d *schema.ResourceData, meta interface{}) error {
+
+	userId, roleId, err := splitRbacUserRoleId(d.Id())
+	if err != nil {
+		return err
+	}
+
+	role, err := getKongRbacUserRole(meta.(*kongClient), userId, roleId)
+	if err != nil {
+		return fmt.Errorf("could not find kong rbac user role: %v", err)
+	}
+
+	if role == nil {
+		d.SetId("")
+		return nil
+	}
+
+	d.Set("user_id", userId)
+	d.Set("role_id", role.Id)
+
+	return nil
+}
+
+func resourceKongRbacUserRoleDelete(d *schema.ResourceData, meta interface{}) error {
+
+	userId, roleId, err := splitRbacUserRoleId(d.Id())
+	if err != nil {
+		return err
+	}
+
+	client := meta.(*kongClient)
+	role, err := getKongRbacUserRole(client, userId, roleId)
+	if err != nil {
+		return fmt.Errorf("could not find kong rbac user role: %v", err)
+	}
+
+	// the user no longer has the role, it may have been removed along with the user or the role
+	if role == nil {
+		return nil
+	}
+
+	if err := client.deleteWithBody(rbacUserRolesPath(userId), &rbacUserRolesRequest{Roles: role.Name}); err != nil {
+		return fmt.Errorf("could not remove kong rbac role %s from user %s: %v", roleId, userId, err)
+	}
+
+	return nil
+}
+
+// getKongRbacUserRole returns the role when the user has it, nil when the user does not have it or does not exist
+func getKongRbacUserRole(client *kongClient, userId string, roleId string) (*rbacRole, error) {
+	userRoles := &rbacUserRoles{}
+	found, err := client.get(rbacUserRolesPath(userId), userRoles)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	for _, role := range userRoles.Roles {
+		if role != nil && (role.Id == roleId || role.Name == roleId) {
+			return role, nil
+		}
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,93 @@
+package kong
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/kevholditch/gokong"
+)
+
+func TestKongRbacUserRoleLifecycle(t *testing.T) {
+
+	rbac := newTestRbac()
+	server := newTestRbacServer(t, "3.4.3.1", rbac)
+	defer server.Close()
+
+	client := newKongClient(&gokong.Config{HostAddress: server.URL})
+	user := schema.TestResourceDataRaw(t, resourceKongRbacUser().Schema, map[string]interface{}{"name": "ci-admin"})
+	if err := resourceKongRbacUserCreate(user, client); err != nil {
+		t.Fatalf("could not create rbac user: %v", err)
+	}
+
+	d := schema.TestResourceDataRaw(t, resourceKongRbacUserRole().Schema, map[string]interface{}{
+		"user_id": user.Id(),
+		"role_id": "admin-role-id",
+	})
+
+	if err := resourceKongRbacUserRoleCreate(d, client); err != nil {
+		t.Fatalf("could not add the role to the rbac user: %v", err)
+	}
+
+	if d.Id() != "user-id|admin-role-id" || strings.Join(rbac.userRoles["user-id"], ",") != "admin" {
+		t.Fatalf("expected the admin role to be added to user-id by name, id was %s and roles %v", d.Id(), rbac.userRoles)
+	}
+
+	// importing by id reads the user and role back
+	imported := resourceKongRbacUserRole().Data(&terraform.InstanceState{ID: "user-id|admin-role-id"})
+	if err := resourceKongRbacUserRoleRead(imported, client); err != nil {
+		t.Fatalf("could not read rbac user role: %v", err)
+	}
+	if imported.Id() == "" || imported.Get("user_id") != "user-id" || imported.Get("role_id") != "admin-role-id" {
+		t.Errorf("expected the rbac user role to be read from its id, got id: %s user_id: %v role_id: %v", imported.Id(), imported.Get("user_id"), imported.Get("role_id"))
+	}
+
+	// a role given to the user outside of terraform is left alone
+	rbac.userRoles["user-id"] = append(rbac.userRoles["user-id"], "read-only")
+
+	if err := resourceKongRbacUserRoleDelete(d, client); err != nil {
+		t.Fatalf("could not remove the role from the rbac user: %v", err)
+	}
+
+	if strings.Join(rbac.userRoles["user-id"], ",") != "read-only" {
+		t.Errorf("expected only the admin role to be removed, roles: %v", rbac.userRoles["user-id"])
+	}
+
+	if err := resourceKongRbacUserRoleRead(d, client); err != nil || d.Id() != "" {
+		t.Errorf("expected the removed role to be removed from state, id: %s error: %v", d.Id(), err)
+	}
+
+	// the roles of a deleted user go with it
+	d.SetId("user-id|read-only-role-id")
+	if err := resourceKongRbacUserDelete(user, client); err != nil {
+		t.Fatalf("could not delete rbac user: %v", err)
+	}
+	if err := resourceKongRbacUserRoleRead(d, client); err != nil || d.Id() != "" {
+		t.Errorf("expected the role of a deleted user to be removed from state, id: %s error: %v", d.Id(), err)
+	}
+	if err := resourceKongRbacUserRoleDelete(resourceKongRbacUserRole().Data(&terraform.InstanceState{ID: "user-id|read-only-role-id"}), client); err != nil {
+		t.Errorf("expected removing the role of a deleted user to succeed, got: %v", err)
+	}
+}
+
+func TestKongRbacUserRoleUnknownRole(t *testing.T) {
+
+	rbac := newTestRbac()
+	server := newTestRbacServer(t, "3.4.3.1", rbac)
+	defer server.Close()
+
+	d := schema.TestResourceDataRaw(t, resourceKongRbacUserRole().Schema, map[string]interface{}{
+		"user_id": "user-id",
+		"role_id": "missing-role-id",
+	})
+
+	err := resourceKongRbacUserRoleCreate(d, newKongClient(&gokong.Config{HostAddress: server.URL}))
+	if err == nil || !strings.Contains(err.Error(), "could not find kong rbac role missing-role-id") {
+		t.Errorf("expected an unknown role to be reported, got: %v", err)
+	}
+
+	if _, _, err := splitRbacUserRoleId("user-id"); err == nil {
+		t.Errorf("expected an id without a role to be rejected")
+	}
+}
@@ -0,0 +1,220 @@
+package kong
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/kevholditch/gokong"
+)
+
+// testRbac is the rbac state of the kong node mocked by newTestRbacServer
+type testRbac struct {
+	users     map[string]*rbacUser
+	roles     map[string]string
+	userRoles map[string][]string
+	sent      []*rbacUser
+}
+
+// newTestRbacServer mocks the /rbac endpoints of a kong node reporting version, users are created with the id user-id
+func newTestRbacServer(t *testing.T, kongVersion string, rbac *testRbac) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.URL.Path == "/" {
+			json.NewEncoder(w).Encode(map[string]string{"version": kongVersion})
+			return
+		}
+
+		if strings.HasPrefix(r.URL.Path, rbacRolesPath) {
+			id := strings.TrimPrefix(r.URL.Path, rbacRolesPath)
+			if name, ok := rbac.roles[id]; ok {
+				json.NewEncoder(w).Encode(&rbacRole{Id: id, Name: name})
+				return
+			}
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if strings.HasSuffix(r.URL.Path, "/roles") {
+			userId := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, rbacUsersPath), "/roles")
+			if rbac.users[userId] == nil {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+
+			request := &rbacUserRolesRequest{}
+			if r.Method == http.MethodPost || r.Method == http.MethodDelete {
+				if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+					t.Fatalf("could not decode rbac user roles request: %v", err)
+				}
+			}
+
+			switch r.Method {
+			case http.MethodPost:
+				rbac.userRoles[userId] = append(rbac.userRoles[userId], strings.Split(request.Roles, ",")...)
+				w.WriteHeader(http.StatusCreated)
+			case http.MethodDelete:
+				kept := []string{}
+				for _, name := range rbac.userRoles[userId] {
+					if !contains(strings.Split(request.Roles, ","), name) {
+						kept = append(kept, name)
+					}
+				}
+				rbac.userRoles[userId] = kept
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			userRoles := &rbacUserRoles{Roles: []*rbacRole{}}
+			for id, name := range rbac.roles {
+				if contains(rbac.userRoles[userId], name) {
+					userRoles.Roles = append(userRoles.Roles, &rbacRole{Id: id, Name: name})
+				}
+			}
+			json.NewEncoder(w).Encode(userRoles)
+			return
+		}
+
+		id := strings.TrimPrefix(r.URL.Path, rbacUsersPath)
+		request := &rbacUser{}
+		if r.Method == http.MethodPost || r.Method == http.MethodPatch {
+			if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+				t.Fatalf("could not decode rbac user request: %v", err)
+			}
+			rbac.sent = append(rbac.sent, request)
+		}
+
+		switch {
+		case r.Method == http.MethodPost && id == "":
+			id = "user-id"
+			request.Id = id
+			rbac.users[id] = request
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodPatch && rbac.users[id] != nil:
+			user := rbac.users[id]
+			if request.Name != "" {
+				user.Name = request.Name
+			}
+			if request.Comment != nil {
+				user.Comment = request.Comment
+			}
+			if request.Enabled != nil {
+				user.Enabled = request.Enabled
+			}
+			if request.UserToken != "" {
+				user.UserToken = request.UserToken
+			}
+		case r.Method == http.MethodDelete:
+			delete(rbac.users, id)
+			delete(rbac.userRoles, id)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		case r.Method == http.MethodGet && rbac.users[id] != nil:
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		// kong only returns a hash of the token
+		user := *rbac.users[id]
+		user.UserToken = "$2b$09$hashed"
+		json.NewEncoder(w).Encode(&user)
+	}))
+}
+
+func newTestRbac() *testRbac {
+	return &testRbac{
+		users:     map[string]*rbacUser{},
+		roles:     map[string]string{"admin-role-id": "admin", "read-only-role-id": "read-only"},
+		userRoles: map[string][]string{},
+	}
+}
+
+func TestKongRbacUserLifecycle(t *testing.T) {
+
+	rbac := newTestRbac()
+	server := newTestRbacServer(t, "3.4.3.1", rbac)
+	defer server.Close()
+
+	client := newKongClient(&gokong.Config{HostAddress: server.URL})
+	d := schema.TestResourceDataRaw(t, resourceKongRbacUser().Schema, map[string]interface{}{
+		"name":    "ci-admin",
+		"comment": "deploys from ci",
+	})
+
+	if err := resourceKongRbacUserCreate(d, client); err != nil {
+		t.Fatalf("could not create rbac user: %v", err)
+	}
+
+	token := d.Get("user_token").(string)
+	if d.Id() != "user-id" || len(token) != 64 || rbac.users["user-id"].UserToken != token {
+		t.Fatalf("expected user-id to be created with a generated token kept in state, id was %s token %q and users %v", d.Id(), token, rbac.users)
+	}
+
+	if d.Get("name") != "ci-admin" || d.Get("comment") != "deploys from ci" || !d.Get("enabled").(bool) {
+		t.Errorf("expected the user to be read back, got name: %v comment: %v enabled: %v", d.Get("name"), d.Get("comment"), d.Get("enabled"))
+	}
+
+	d.Set("enabled", false)
+	if err := resourceKongRbacUserUpdate(d, client); err != nil {
+		t.Fatalf("could not update rbac user: %v", err)
+	}
+
+	if update := rbac.sent[len(rbac.sent)-1]; update.UserToken != "" || update.Enabled == nil || *update.Enabled {
+		t.Errorf("expected the update to disable the user without resending the token, sent: %+v", update)
+	}
+
+	if d.Get("enabled").(bool) || d.Get("user_token") != token {
+		t.Errorf("expected the user to be disabled and the token to stay in state, got enabled: %v token: %v", d.Get("enabled"), d.Get("user_token"))
+	}
+
+	if err := resourceKongRbacUserDelete(d, client); err != nil {
+		t.Fatalf("could not delete rbac user: %v", err)
+	}
+
+	if err := resourceKongRbacUserRead(d, client); err != nil || d.Id() != "" {
+		t.Errorf("expected the deleted user to be removed from state, id: %s error: %v", d.Id(), err)
+	}
+}
+
+func TestKongRbacUserConfiguredToken(t *testing.T) {
+
+	rbac := newTestRbac()
+	server := newTestRbacServer(t, "3.4.3.1", rbac)
+	defer server.Close()
+
+	d := schema.TestResourceDataRaw(t, resourceKongRbacUser().Schema, map[string]interface{}{
+		"name":       "ci-admin",
+		"user_token": "configured-token",
+	})
+
+	if err := resourceKongRbacUserCreate(d, newKongClient(&gokong.Config{HostAddress: server.URL})); err != nil {
+		t.Fatalf("could not create rbac user: %v", err)
+	}
+
+	if rbac.users["user-id"].UserToken != "configured-token" || d.Get("user_token") != "configured-token" {
+		t.Errorf("expected the configured token to be sent and kept, sent: %s state: %s", rbac.users["user-id"].UserToken, d.Get("user_token"))
+	}
+}
+
+func TestKongRbacUserRequiresEnterprise(t *testing.T) {
+
+	rbac := newTestRbac()
+	server := newTestRbacServer(t, "3.4.2", rbac)
+	defer server.Close()
+
+	d := schema.TestResourceDataRaw(t, resourceKongRbacUser().Schema, map[string]interface{}{"name": "ci-admin"})
+	err := resourceKongRbacUserCreate(d, newKongClient(&gokong.Config{HostAddress: server.URL}))
+
+	if err == nil || !strings.Contains(err.Error(), "kong_rbac_user") {
+		t.Errorf("expected creating an rbac user on kong open source to fail, got: %v", err)
+	}
+
+	if len(rbac.users) != 0 {
+		t.Errorf("expected nothing to be created, got: %v", rbac.users)
+	}
+}
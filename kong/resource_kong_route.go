@@ -1,8 +1,12 @@
 package kong
 
 import (
+	"encoding/json"
 	"fmt"
+	"net"
+	"strconv"
 
+	"github.com/hashicorp/terraform/helper/hashcode"
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/kevholditch/gokong"
 )
@@ -46,29 +50,136 @@ func resourceKongRoute() *schema.Resource {
 				Type:     schema.TypeBool,
 				Optional: true,
 				ForceNew: false,
-				Default:  true,
+				Default:  defaultRouteStripPath,
 			},
 			"preserve_host": &schema.Schema{
 				Type:     schema.TypeBool,
 				Optional: true,
 				ForceNew: false,
+				Default:  defaultRoutePreserveHost,
 			},
 			"service_id": &schema.Schema{
 				Type:     schema.TypeString,
 				Required: true,
 				ForceNew: false,
 			},
+			"snis": &schema.Schema{
+				Type:        schema.TypeSet,
+				Optional:    true,
+				ForceNew:    false,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "SNIs matched by the route, only used with the https, grpcs and tls protocols",
+			},
+			"sources": &schema.Schema{
+				Type:        schema.TypeSet,
+				Optional:    true,
+				ForceNew:    false,
+				Elem:        routeEndpointResource(),
+				Set:         routeEndpointHash,
+				Description: "Source ip and/or port matched by the route, only used with stream (tcp, tls, udp) protocols",
+			},
+			"destinations": &schema.Schema{
+				Type:        schema.TypeSet,
+				Optional:    true,
+				ForceNew:    false,
+				Elem:        routeEndpointResource(),
+				Set:         routeEndpointHash,
+				Description: "Destination ip and/or port matched by the route, only used with stream (tcp, tls, udp) protocols",
+			},
+			"ca_certificates": &schema.Schema{
+				Type:        schema.TypeSet,
+				Optional:    true,
+				ForceNew:    false,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Ids of the kong_ca_certificates client certificates are verified against, only used with the https, grpcs and tls protocols",
+			},
+		},
+	}
+}
+
+func routeEndpointResource() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"ip": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"port": &schema.Schema{
+				Type:     schema.TypeInt,
+				Optional: true,
+			},
 		},
 	}
 }
 
+// routeEndpoint is an entry of the sources or destinations of a stream route, kong requires at least one of ip or port
+type routeEndpoint struct {
+	Ip   string `json:"ip,omitempty"`
+	Port int    `json:"port,omitempty"`
+}
+
+// routeRequest adds the fields gokong does not know about to its route request. They are omitted when they have never
+// been set so routes keep working against kong versions without them, once set an empty list is sent to clear them.
+type routeRequest struct {
+	*gokong.RouteRequest
+	Snis         *[]string        `json:"snis,omitempty"`
+	Sources      *[]routeEndpoint `json:"sources,omitempty"`
+	Destinations *[]routeEndpoint `json:"destinations,omitempty"`
+	// CaCertificates are the ids of the ca certificates client certificates are verified against
+	CaCertificates *[]string `json:"ca_certificates,omitempty"`
+}
+
+type route struct {
+	gokong.Route
+	Snis           []string        `json:"snis"`
+	Sources        []routeEndpoint `json:"sources"`
+	Destinations   []routeEndpoint `json:"destinations"`
+	CaCertificates []string        `json:"ca_certificates"`
+}
+
+// the ca_certificates of routes need the ca certificates kong 1.3 added
+const routeCaCertificatesMinimumKongVersion = "1.3.0"
+
+// the values kong gives strip_path and preserve_host when a route does not set them, a route read back with null for
+// either has the default
+const (
+	defaultRouteStripPath    = true
+	defaultRoutePreserveHost = false
+)
+
+var streamRouteProtocols = []string{"tcp", "tls", "udp", "tls_passthrough"}
+
+var sniRouteProtocols = []string{"https", "grpcs", "tls", "tls_passthrough"}
+
 func resourceKongRouteCreate(d *schema.ResourceData, meta interface{}) error {
 
-	routeRequest := createKongRouteRequestFromResourceData(d)
+	routeRequest, err := createKongRouteRequestFromResourceData(d)
+	if err != nil {
+		return err
+	}
+
+	if err := validateProtocols(meta.(*kongClient), gokong.StringValueSlice(routeRequest.Protocols)); err != nil {
+		return fmt.Errorf("invalid kong route protocols: %v", err)
+	}
 
-	route, err := meta.(*gokong.KongAdminClient).Routes().AddRoute(routeRequest)
+	if err := validateRouteCaCertificates(meta.(*kongClient), routeRequest); err != nil {
+		return err
+	}
+
+	// routes have no name, a route is identified by everything it is created with
+	identity, err := json.Marshal(routeRequest)
 	if err != nil {
-		return fmt.Errorf("failed to create kong route: %v error: %v", routeRequest, err)
+		return fmt.Errorf("failed to create kong route: %v error: %v", requestString(routeRequest), err)
+	}
+
+	route := &route{}
+	err = meta.(*kongClient).create(gokong.RoutesPath, []string{string(identity)}, routeRequest, route)
+	if err != nil {
+		return fmt.Errorf("failed to create kong route: %v error: %v", requestString(routeRequest), err)
+	}
+
+	if route.Id == nil {
+		return fmt.Errorf("failed to create kong route: %v error: kong did not return an id", requestString(routeRequest))
 	}
 
 	d.SetId(*route.Id)
@@ -79,9 +190,20 @@ func resourceKongRouteCreate(d *schema.ResourceData, meta interface{}) error {
 func resourceKongRouteUpdate(d *schema.ResourceData, meta interface{}) error {
 	d.Partial(false)
 
-	routeRequest := createKongRouteRequestFromResourceData(d)
+	routeRequest, err := createKongRouteRequestFromResourceData(d)
+	if err != nil {
+		return err
+	}
+
+	if err := validateProtocols(meta.(*kongClient), gokong.StringValueSlice(routeRequest.Protocols)); err != nil {
+		return fmt.Errorf("invalid kong route protocols: %v", err)
+	}
+
+	if err := validateRouteCaCertificates(meta.(*kongClient), routeRequest); err != nil {
+		return err
+	}
 
-	_, err := meta.(*gokong.KongAdminClient).Routes().UpdateRoute(d.Id(), routeRequest)
+	err = meta.(*kongClient).patch(gokong.RoutesPath+d.Id(), routeRequest, nil)
 
 	if err != nil {
 		return fmt.Errorf("error updating kong route: %s", err)
@@ -92,15 +214,34 @@ func resourceKongRouteUpdate(d *schema.ResourceData, meta interface{}) error {
 
 func resourceKongRouteRead(d *schema.ResourceData, meta interface{}) error {
 
-	route, err := meta.(*gokong.KongAdminClient).Routes().GetRoute(d.Id())
+	route := &route{}
+	found, err := meta.(*kongClient).get(gokong.RoutesPath+d.Id(), route)
 
 	if err != nil {
 		return fmt.Errorf("could not find kong route: %v", err)
 	}
 
-	if route == nil {
+	if !found || route.Id == nil {
 		d.SetId("")
 	} else {
+		serviceId := ""
+		if route.Service != nil {
+			serviceId = route.Service.Id
+		}
+
+		stripPath := boolValueOrDefault(route.StripPath, defaultRouteStripPath)
+		preserveHost := boolValueOrDefault(route.PreserveHost, defaultRoutePreserveHost)
+
+		logDrift(meta.(*kongClient), "kong_route", d, map[string]interface{}{
+			"protocols":     gokong.StringValueSlice(route.Protocols),
+			"methods":       gokong.StringValueSlice(route.Methods),
+			"hosts":         gokong.StringValueSlice(route.Hosts),
+			"paths":         gokong.StringValueSlice(route.Paths),
+			"strip_path":    stripPath,
+			"preserve_host": preserveHost,
+			"service_id":    serviceId,
+		})
+
 		if &route.Protocols != nil {
 			d.Set("protocols", gokong.StringValueSlice(route.Protocols))
 		}
@@ -117,17 +258,17 @@ func resourceKongRouteRead(d *schema.ResourceData, meta interface{}) error {
 			d.Set("paths", gokong.StringValueSlice(route.Paths))
 		}
 
-		if &route.StripPath != nil {
-			d.Set("strip_path", route.StripPath)
-		}
+		d.Set("strip_path", stripPath)
+		d.Set("preserve_host", preserveHost)
 
-		if &route.PreserveHost != nil {
-			d.Set("preserve_host", route.PreserveHost)
-		}
+		// kong versions that allow routes without a service return null once the service is gone, the empty id then
+		// shows as a change that attaches the route to the configured service again
+		d.Set("service_id", serviceId)
 
-		if route.Service != nil {
-			d.Set("service_id", route.Service.Id)
-		}
+		d.Set("snis", route.Snis)
+		d.Set("sources", flattenRouteEndpoints(route.Sources, readRouteEndpointsFromResource(d, "sources")))
+		d.Set("destinations", flattenRouteEndpoints(route.Destinations, readRouteEndpointsFromResource(d, "destinations")))
+		d.Set("ca_certificates", route.CaCertificates)
 	}
 
 	return nil
@@ -135,7 +276,7 @@ func resourceKongRouteRead(d *schema.ResourceData, meta interface{}) error {
 
 func resourceKongRouteDelete(d *schema.ResourceData, meta interface{}) error {
 
-	err := meta.(*gokong.KongAdminClient).Routes().DeleteRoute(d.Id())
+	err := meta.(*kongClient).delete(gokong.RoutesPath + d.Id())
 
 	if err != nil {
 		return fmt.Errorf("could not delete kong route: %v", err)
@@ -144,18 +285,158 @@ func resourceKongRouteDelete(d *schema.ResourceData, meta interface{}) error {
 	return nil
 }
 
-func createKongRouteRequestFromResourceData(d *schema.ResourceData) *gokong.RouteRequest {
+func createKongRouteRequestFromResourceData(d *schema.ResourceData) (*routeRequest, error) {
 	service := gokong.RouteServiceObject{
 		Id: readStringFromResource(d, "service_id"),
 	}
 
-	return &gokong.RouteRequest{
-		Protocols:    readStringArrayPtrFromResource(d, "protocols"),
-		Methods:      readStringArrayPtrFromResource(d, "methods"),
-		Hosts:        readStringArrayPtrFromResource(d, "hosts"),
-		Paths:        readStringArrayPtrFromResource(d, "paths"),
-		StripPath:    readBoolPtrFromResource(d, "strip_path"),
-		PreserveHost: readBoolPtrFromResource(d, "preserve_host"),
-		Service:      &service,
+	routeRequest := &routeRequest{
+		RouteRequest: &gokong.RouteRequest{
+			Protocols:    readStringArrayPtrFromResource(d, "protocols"),
+			Methods:      readStringArrayPtrFromResource(d, "methods"),
+			Hosts:        readStringArrayPtrFromResource(d, "hosts"),
+			Paths:        readStringArrayPtrFromResource(d, "paths"),
+			StripPath:    readBoolPtrFromResource(d, "strip_path"),
+			PreserveHost: readBoolPtrFromResource(d, "preserve_host"),
+			Service:      &service,
+		},
+	}
+
+	if snis := readStringSetFromResource(d, "snis"); len(snis) > 0 || d.HasChange("snis") {
+		routeRequest.Snis = &snis
+	}
+
+	if sources := readRouteEndpointsFromResource(d, "sources"); len(sources) > 0 || d.HasChange("sources") {
+		routeRequest.Sources = &sources
+	}
+
+	if destinations := readRouteEndpointsFromResource(d, "destinations"); len(destinations) > 0 || d.HasChange("destinations") {
+		routeRequest.Destinations = &destinations
+	}
+
+	if caCertificates := readStringSetFromResource(d, "ca_certificates"); len(caCertificates) > 0 || d.HasChange("ca_certificates") {
+		routeRequest.CaCertificates = &caCertificates
+	}
+
+	if err := validateRouteProtocolFields(routeRequest); err != nil {
+		return routeRequest, err
+	}
+
+	return routeRequest, nil
+}
+
+// validateRouteProtocolFields checks snis, sources, destinations and ca_certificates are only used with protocols kong
+// matches them on
+func validateRouteProtocolFields(routeRequest *routeRequest) error {
+	protocols := gokong.StringValueSlice(routeRequest.Protocols)
+
+	stream := false
+	sni := false
+	for _, protocol := range protocols {
+		stream = stream || contains(streamRouteProtocols, protocol)
+		sni = sni || contains(sniRouteProtocols, protocol)
+	}
+
+	if !stream && (routeRequest.Sources != nil && len(*routeRequest.Sources) > 0 || routeRequest.Destinations != nil && len(*routeRequest.Destinations) > 0) {
+		return fmt.Errorf("kong route sources and destinations can only be used with the stream protocols %v, protocols are: %v", streamRouteProtocols, protocols)
+	}
+
+	if !sni && routeRequest.Snis != nil && len(*routeRequest.Snis) > 0 {
+		return fmt.Errorf("kong route snis can only be used with the protocols %v, protocols are: %v", sniRouteProtocols, protocols)
+	}
+
+	if !sni && routeRequest.CaCertificates != nil && len(*routeRequest.CaCertificates) > 0 {
+		return fmt.Errorf("kong route ca_certificates can only be used with the protocols %v, protocols are: %v", sniRouteProtocols, protocols)
+	}
+
+	for _, endpoints := range []*[]routeEndpoint{routeRequest.Sources, routeRequest.Destinations} {
+		if endpoints == nil {
+			continue
+		}
+		for _, endpoint := range *endpoints {
+			if endpoint.Ip == "" && endpoint.Port == 0 {
+				return fmt.Errorf("kong route sources and destinations must have at least one of ip or port set")
+			}
+		}
 	}
+
+	return nil
+}
+
+// validateRouteCaCertificates checks kong supports the ca_certificates of a route and that each of them is the id of a
+// ca certificate
+func validateRouteCaCertificates(client *kongClient, routeRequest *routeRequest) error {
+	if routeRequest.CaCertificates == nil {
+		return nil
+	}
+
+	return validateCaCertificateIds(client, "route", routeCaCertificatesMinimumKongVersion, *routeRequest.CaCertificates)
+}
+
+func readRouteEndpointsFromResource(d *schema.ResourceData, key string) []routeEndpoint {
+	endpoints := []routeEndpoint{}
+
+	if attr, ok := d.GetOk(key); ok {
+		for _, item := range attr.(*schema.Set).List() {
+			endpoint := item.(map[string]interface{})
+			endpoints = append(endpoints, routeEndpoint{
+				Ip:   endpoint["ip"].(string),
+				Port: endpoint["port"].(int),
+			})
+		}
+	}
+
+	return endpoints
+}
+
+// flattenRouteEndpoints keeps the configured form of an endpoint kong normalized, e.g. 10.0.0.1 that kong returns as
+// 10.0.0.1/32, so it is not a change
+func flattenRouteEndpoints(endpoints []routeEndpoint, configured []routeEndpoint) []map[string]interface{} {
+	flattened := make([]map[string]interface{}, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		for _, configuredEndpoint := range configured {
+			if configuredEndpoint.normalized() == endpoint.normalized() {
+				endpoint = configuredEndpoint
+				break
+			}
+		}
+
+		flattened = append(flattened, map[string]interface{}{
+			"ip":   endpoint.Ip,
+			"port": endpoint.Port,
+		})
+	}
+	return flattened
+}
+
+// normalized is the endpoint as kong matches it: a cidr is its network (10.0.0.5/24 is 10.0.0.0/24), a cidr of a single
+// address is that address (10.0.0.1/32 is 10.0.0.1) and ipv6 addresses are in their canonical form. An ip that can not
+// be parsed is kept as it is. Either the ip or the port is empty for the ip-only and port-only endpoints.
+func (endpoint routeEndpoint) normalized() string {
+	ip := endpoint.Ip
+	if _, network, err := net.ParseCIDR(ip); err == nil {
+		if ones, bits := network.Mask.Size(); ones == bits {
+			ip = network.IP.String()
+		} else {
+			ip = network.String()
+		}
+	} else if parsed := net.ParseIP(ip); parsed != nil {
+		ip = parsed.String()
+	}
+
+	port := ""
+	if endpoint.Port != 0 {
+		port = strconv.Itoa(endpoint.Port)
+	}
+
+	return ip + "|" + port
+}
+
+// routeEndpointHash hashes the normalized endpoint, so the endpoints of sources and destinations kong returns
+// normalized or in another order are the same set as the configured ones
+func routeEndpointHash(v interface{}) int {
+	endpoint := v.(map[string]interface{})
+	ip, _ := endpoint["ip"].(string)
+	port, _ := endpoint["port"].(int)
+	return hashcode.String(routeEndpoint{Ip: ip, Port: port}.normalized())
 }
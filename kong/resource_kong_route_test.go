@@ -1,9 +1,17 @@
 package kong
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
 	"testing"
 
+	"github.com/hashicorp/terraform/config"
 	"github.com/hashicorp/terraform/helper/resource"
 	"github.com/hashicorp/terraform/terraform"
 	"github.com/kevholditch/gokong"
@@ -44,6 +52,147 @@ func TestAccKongRoute(t *testing.T) {
 	})
 }
 
+func TestAccKongRouteDefaultBooleans(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckKongRouteDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testCreateRouteWithDefaultsConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckKongRouteExists("kong_route.route"),
+					resource.TestCheckResourceAttr("kong_route.route", "strip_path", "true"),
+					resource.TestCheckResourceAttr("kong_route.route", "preserve_host", "false"),
+				),
+			},
+			{
+				// neither boolean is set, the plan after refreshing the route from kong must be empty
+				Config:   testCreateRouteWithDefaultsConfig,
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestKongRouteReadDefaultBooleans(t *testing.T) {
+
+	body := ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	client := newKongClient(&gokong.Config{HostAddress: server.URL})
+
+	cases := []struct {
+		body         string
+		stripPath    string
+		preserveHost string
+	}{
+		{`{"id":"route-id","paths":["/"]}`, "true", "false"},
+		{`{"id":"route-id","paths":["/"],"strip_path":null,"preserve_host":null}`, "true", "false"},
+		{`{"id":"route-id","paths":["/"],"strip_path":false,"preserve_host":true}`, "false", "true"},
+	}
+
+	for _, c := range cases {
+		body = c.body
+		d := resourceKongRoute().Data(&terraform.InstanceState{
+			ID:         "route-id",
+			Attributes: map[string]string{"id": "route-id", "strip_path": "true", "preserve_host": "false"},
+		})
+
+		if err := resourceKongRouteRead(d, client); err != nil {
+			t.Fatalf("could not read route: %v", err)
+		}
+
+		state := d.State().Attributes
+		if state["strip_path"] != c.stripPath || state["preserve_host"] != c.preserveHost {
+			t.Errorf("expected strip_path %s and preserve_host %s reading %s, got %s and %s", c.stripPath, c.preserveHost, c.body, state["strip_path"], state["preserve_host"])
+		}
+	}
+}
+
+func TestKongRouteMovesBetweenServices(t *testing.T) {
+
+	var requests []string
+	stored := map[string]interface{}{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		if r.Method == http.MethodPost || r.Method == http.MethodPatch {
+			request := map[string]interface{}{}
+			json.NewDecoder(r.Body).Decode(&request)
+			for key, value := range request {
+				stored[key] = value
+			}
+			stored["id"] = "route-id"
+			if r.Method == http.MethodPost {
+				w.WriteHeader(http.StatusCreated)
+			}
+		}
+		json.NewEncoder(w).Encode(stored)
+	}))
+	defer server.Close()
+
+	client := newKongClient(&gokong.Config{HostAddress: server.URL})
+	r := resourceKongRoute()
+	diff := func(state *terraform.InstanceState, serviceId string) *terraform.InstanceDiff {
+		rawConfig, err := config.NewRawConfig(map[string]interface{}{
+			"protocols":  []interface{}{"http"},
+			"paths":      []interface{}{"/orders"},
+			"service_id": serviceId,
+		})
+		if err != nil {
+			t.Fatalf("could not build config: %v", err)
+		}
+		diff, err := r.Diff(state, terraform.NewResourceConfig(rawConfig))
+		if err != nil {
+			t.Fatalf("could not diff route: %v", err)
+		}
+		return diff
+	}
+
+	state, err := r.Apply(nil, diff(nil, "service-a"), client)
+	if err != nil {
+		t.Fatalf("could not create route: %v", err)
+	}
+
+	// moving the route to another service updates it in place
+	moved := diff(state, "service-b")
+	if moved.RequiresNew() {
+		t.Fatalf("expected moving the route to another service not to recreate it, got: %v", moved.Attributes)
+	}
+	requests = nil
+	state, err = r.Apply(state, moved, client)
+	if err != nil {
+		t.Fatalf("could not move route: %v", err)
+	}
+	if len(requests) == 0 || requests[0] != "PATCH /routes/route-id" || state.ID != "route-id" || state.Attributes["service_id"] != "service-b" {
+		t.Errorf("expected the route to be patched onto service-b, requests: %v state: %v", requests, state.Attributes)
+	}
+	if unchanged := diff(state, "service-b"); unchanged != nil && !unchanged.Empty() {
+		t.Errorf("expected no diff once the route was moved, got: %v", unchanged.Attributes)
+	}
+
+	// the service was deleted and kong keeps the route without one, it is attached to the configured service again
+	stored["service"] = nil
+	state, err = r.Refresh(state, client)
+	if err != nil {
+		t.Fatalf("could not refresh route: %v", err)
+	}
+	if state.ID != "route-id" || state.Attributes["service_id"] != "" {
+		t.Fatalf("expected the route without a service to be read with an empty service_id, got: %v", state.Attributes)
+	}
+	reattach := diff(state, "service-c")
+	if reattach.RequiresNew() || reattach.Attributes["service_id"] == nil || reattach.Attributes["service_id"].New != "service-c" {
+		t.Fatalf("expected the route to be attached to service-c in place, got: %v", reattach)
+	}
+	if state, err = r.Apply(state, reattach, client); err != nil || state.Attributes["service_id"] != "service-c" {
+		t.Errorf("expected the route to be attached to service-c, got: %v %v", err, state)
+	}
+}
+
 func TestAccKongRouteImport(t *testing.T) {
 
 	resource.Test(t, resource.TestCase{
@@ -63,9 +212,265 @@ func TestAccKongRouteImport(t *testing.T) {
 	})
 }
 
+func TestAccKongRouteTlsWithSnisAndDestinations(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheckKongVersion(t, "1.0.0") },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckKongRouteDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testCreateTlsRouteConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckKongRouteExists("kong_route.route"),
+					resource.TestCheckResourceAttr("kong_route.route", "protocols.0", "tls"),
+					resource.TestCheckResourceAttr("kong_route.route", "snis.#", "2"),
+					resource.TestCheckResourceAttr("kong_route.route", "destinations.#", "2"),
+					resource.TestCheckResourceAttr("kong_route.route", "sources.#", "0"),
+				),
+			},
+			{
+				// same snis and destinations in a different order, nothing should change
+				Config:   testReorderedTlsRouteConfig,
+				PlanOnly: true,
+			},
+			{
+				Config: testUpdateTlsRouteConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckKongRouteExists("kong_route.route"),
+					resource.TestCheckResourceAttr("kong_route.route", "snis.#", "1"),
+					resource.TestCheckResourceAttr("kong_route.route", "destinations.#", "1"),
+					resource.TestCheckResourceAttr("kong_route.route", "sources.#", "1"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccKongRouteInvalidStreamFields(t *testing.T) {
+	// the route is never created so there is nothing for a destroy check to look up
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      testCreateHttpRouteWithDestinationsConfig,
+				ExpectError: regexp.MustCompile("sources and destinations can only be used with the stream protocols"),
+			},
+		},
+	})
+}
+
+func TestKongRouteStreamEndpointsDoNotChurn(t *testing.T) {
+
+	// kong returns the endpoints in another order with the cidr source as its network and a single address as a /32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"route-id","protocols":["tcp"],"service":{"id":"service-id"},
+			"sources":[{"ip":"10.0.0.0/24"},{"ip":"fd00::1/128","port":9000}],
+			"destinations":[{"port":8443},{"ip":"10.0.1.2/32","port":443}]}`))
+	}))
+	defer server.Close()
+
+	client := newKongClient(&gokong.Config{HostAddress: server.URL})
+	r := resourceKongRoute()
+
+	raw := map[string]interface{}{
+		"protocols":  []interface{}{"tcp"},
+		"service_id": "service-id",
+		"sources": []interface{}{
+			map[string]interface{}{"ip": "10.0.0.5/24"},
+			map[string]interface{}{"ip": "fd00:0::1", "port": 9000},
+		},
+		"destinations": []interface{}{
+			map[string]interface{}{"ip": "10.0.1.2", "port": 443},
+			map[string]interface{}{"port": 8443},
+		},
+	}
+
+	d := r.Data(nil)
+	d.SetId("route-id")
+	d.Set("protocols", raw["protocols"])
+	d.Set("service_id", "service-id")
+	d.Set("sources", raw["sources"])
+	d.Set("destinations", raw["destinations"])
+
+	state, err := r.Refresh(d.State(), client)
+	if err != nil {
+		t.Fatalf("could not refresh route: %v", err)
+	}
+
+	if ip := state.Attributes["sources."+strconv.Itoa(routeEndpointHash(map[string]interface{}{"ip": "10.0.0.0/24"}))+".ip"]; ip != "10.0.0.5/24" {
+		t.Errorf("expected the configured cidr to be kept, got: %v", state.Attributes)
+	}
+
+	// the endpoints listed in another order than kong returns them are the same
+	raw["destinations"] = []interface{}{raw["destinations"].([]interface{})[1], raw["destinations"].([]interface{})[0]}
+	rawConfig, err := config.NewRawConfig(raw)
+	if err != nil {
+		t.Fatalf("could not build config: %v", err)
+	}
+	diff, err := r.Diff(state, terraform.NewResourceConfig(rawConfig))
+	if err != nil {
+		t.Fatalf("could not diff route: %v", err)
+	}
+	if diff != nil && !diff.Empty() {
+		t.Errorf("expected no diff for endpoints kong normalized and reordered, got: %v", diff.Attributes)
+	}
+}
+
+func TestRouteEndpointNormalized(t *testing.T) {
+
+	cases := []struct {
+		endpoint routeEndpoint
+		expected string
+	}{
+		{routeEndpoint{Ip: "10.0.0.1"}, "10.0.0.1|"},
+		{routeEndpoint{Ip: "10.0.0.1/32", Port: 80}, "10.0.0.1|80"},
+		{routeEndpoint{Ip: "10.0.0.5/24"}, "10.0.0.0/24|"},
+		{routeEndpoint{Ip: "fd00:0:0::1/128"}, "fd00::1|"},
+		{routeEndpoint{Ip: "fd00::5/64"}, "fd00::/64|"},
+		{routeEndpoint{Port: 8443}, "|8443"},
+		{routeEndpoint{Ip: "not-an-ip"}, "not-an-ip|"},
+	}
+
+	for _, c := range cases {
+		if normalized := c.endpoint.normalized(); normalized != c.expected {
+			t.Errorf("expected %+v to normalize to %s, got: %s", c.endpoint, c.expected, normalized)
+		}
+	}
+}
+
+func TestKongRouteCaCertificates(t *testing.T) {
+
+	kongVersion := "3.4.0"
+	var sent []map[string]interface{}
+	stored := map[string]interface{}{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/":
+			json.NewEncoder(w).Encode(map[string]string{"version": kongVersion})
+		case strings.HasPrefix(r.URL.Path, caCertificatesPath):
+			if id := strings.TrimPrefix(r.URL.Path, caCertificatesPath); id != "ca-1" && id != "ca-2" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write([]byte(`{}`))
+		default:
+			if r.Method == http.MethodPost || r.Method == http.MethodPatch {
+				request := map[string]interface{}{}
+				json.NewDecoder(r.Body).Decode(&request)
+				sent = append(sent, request)
+				for key, value := range request {
+					stored[key] = value
+				}
+				stored["id"] = "route-id"
+				if r.Method == http.MethodPost {
+					w.WriteHeader(http.StatusCreated)
+				}
+			}
+			json.NewEncoder(w).Encode(stored)
+		}
+	}))
+	defer server.Close()
+
+	client := newKongClient(&gokong.Config{HostAddress: server.URL})
+	r := resourceKongRoute()
+	apply := func(state *terraform.InstanceState, caCertificates []interface{}) (*terraform.InstanceState, error) {
+		rawConfig, err := config.NewRawConfig(map[string]interface{}{
+			"protocols":       []interface{}{"https"},
+			"service_id":      "service-id",
+			"ca_certificates": caCertificates,
+		})
+		if err != nil {
+			t.Fatalf("could not build config: %v", err)
+		}
+		diff, err := r.Diff(state, terraform.NewResourceConfig(rawConfig))
+		if err != nil {
+			t.Fatalf("could not diff route: %v", err)
+		}
+		return r.Apply(state, diff, client)
+	}
+
+	state, err := apply(nil, []interface{}{"ca-1", "ca-2"})
+	if err != nil {
+		t.Fatalf("could not create route: %v", err)
+	}
+	if state.Attributes["ca_certificates.#"] != "2" {
+		t.Errorf("expected both ca certificates to be attached, got: %v", state.Attributes)
+	}
+
+	// detaching one sends the one that is left, detaching them all sends an empty list to clear them
+	state, err = apply(state, []interface{}{"ca-2"})
+	if err != nil {
+		t.Fatalf("could not update route: %v", err)
+	}
+	if caCertificates := sent[1]["ca_certificates"]; !reflect.DeepEqual(caCertificates, []interface{}{"ca-2"}) || state.Attributes["ca_certificates.#"] != "1" {
+		t.Errorf("expected only ca-2 to be attached, kong was sent: %v state: %v", caCertificates, state.Attributes)
+	}
+
+	state, err = apply(state, []interface{}{})
+	if err != nil {
+		t.Fatalf("could not update route: %v", err)
+	}
+	if caCertificates, ok := sent[2]["ca_certificates"].([]interface{}); !ok || len(caCertificates) != 0 {
+		t.Errorf("expected an empty list to be sent to detach the ca certificates, kong was sent: %v", sent[2])
+	}
+	if state.Attributes["ca_certificates.#"] != "0" {
+		t.Errorf("expected no ca certificates to be attached, got: %v", state.Attributes)
+	}
+
+	// an id that is not a ca certificate is rejected before the route is changed
+	if _, err := apply(state, []interface{}{"ca-1", "missing-ca"}); err == nil || !strings.Contains(err.Error(), "no kong ca certificate with that id") {
+		t.Errorf("expected an unknown ca certificate to be rejected, got: %v", err)
+	}
+
+	kongVersion = "1.2.0"
+	client = newKongClient(&gokong.Config{HostAddress: server.URL})
+	if _, err := apply(state, []interface{}{"ca-1"}); err == nil || !strings.Contains(err.Error(), "requires kong 1.3.0 or later") {
+		t.Errorf("expected route ca certificates to need kong 1.3, got: %v", err)
+	}
+	if len(sent) != 3 {
+		t.Errorf("expected the rejected updates not to be sent, kong was sent: %v", sent[3:])
+	}
+}
+
+func TestValidateRouteProtocolFields(t *testing.T) {
+	destinations := []routeEndpoint{{Ip: "10.0.0.1", Port: 443}}
+	snis := []string{"example.com"}
+	missingIpAndPort := []routeEndpoint{{}}
+
+	valid := []*routeRequest{
+		{RouteRequest: &gokong.RouteRequest{Protocols: gokong.StringSlice([]string{"http"})}},
+		{RouteRequest: &gokong.RouteRequest{Protocols: gokong.StringSlice([]string{"tls"})}, Snis: &snis, Destinations: &destinations},
+		{RouteRequest: &gokong.RouteRequest{Protocols: gokong.StringSlice([]string{"tcp"})}, Sources: &destinations},
+		{RouteRequest: &gokong.RouteRequest{Protocols: gokong.StringSlice([]string{"https"})}, Snis: &snis},
+		{RouteRequest: &gokong.RouteRequest{Protocols: gokong.StringSlice([]string{"http"})}, Snis: &[]string{}, Sources: &[]routeEndpoint{}},
+	}
+
+	for _, routeRequest := range valid {
+		if err := validateRouteProtocolFields(routeRequest); err != nil {
+			t.Errorf("expected route request %+v to be valid, got: %v", routeRequest, err)
+		}
+	}
+
+	invalid := []*routeRequest{
+		{RouteRequest: &gokong.RouteRequest{Protocols: gokong.StringSlice([]string{"http", "https"})}, Destinations: &destinations},
+		{RouteRequest: &gokong.RouteRequest{Protocols: gokong.StringSlice([]string{"grpc"})}, Sources: &destinations},
+		{RouteRequest: &gokong.RouteRequest{Protocols: gokong.StringSlice([]string{"http"})}, Snis: &snis},
+		{RouteRequest: &gokong.RouteRequest{Protocols: gokong.StringSlice([]string{"tcp"})}, Destinations: &missingIpAndPort},
+		{RouteRequest: &gokong.RouteRequest{Protocols: gokong.StringSlice([]string{"http"})}, CaCertificates: &[]string{"ca-id"}},
+	}
+
+	for _, routeRequest := range invalid {
+		if err := validateRouteProtocolFields(routeRequest); err == nil {
+			t.Errorf("expected route request %+v to be rejected", routeRequest)
+		}
+	}
+}
+
 func testAccCheckKongRouteDestroy(state *terraform.State) error {
 
-	client := testAccProvider.Meta().(*gokong.KongAdminClient)
+	client := testAccProvider.Meta().(*kongClient)
 
 	routes := getResourcesByType("kong_route", state)
 
@@ -99,7 +504,7 @@ func testAccCheckKongRouteExists(resourceKey string) resource.TestCheckFunc {
 			return fmt.Errorf("no ID is set")
 		}
 
-		route, err := testAccProvider.Meta().(*gokong.KongAdminClient).Routes().GetRoute(rs.Primary.ID)
+		route, err := testAccProvider.Meta().(*kongClient).Routes().GetRoute(rs.Primary.ID)
 
 		if err != nil {
 			return err
@@ -164,3 +569,103 @@ resource "kong_route" "route" {
 	service_id		= "${kong_service.service.id}"
 }
 `
+const testCreateTlsRouteConfig = `
+resource "kong_service" "service" {
+	name     = "test"
+	protocol = "tcp"
+	host     = "test.org"
+	port     = 443
+}
+
+resource "kong_route" "route" {
+	protocols 		= [ "tls" ]
+	snis 			= [ "example.com", "example.org" ]
+	service_id  	= "${kong_service.service.id}"
+
+	destinations {
+		ip   = "10.0.0.1"
+		port = 443
+	}
+
+	destinations {
+		port = 8443
+	}
+}
+`
+const testReorderedTlsRouteConfig = `
+resource "kong_service" "service" {
+	name     = "test"
+	protocol = "tcp"
+	host     = "test.org"
+	port     = 443
+}
+
+resource "kong_route" "route" {
+	protocols 		= [ "tls" ]
+	snis 			= [ "example.org", "example.com" ]
+	service_id  	= "${kong_service.service.id}"
+
+	destinations {
+		port = 8443
+	}
+
+	destinations {
+		ip   = "10.0.0.1"
+		port = 443
+	}
+}
+`
+const testUpdateTlsRouteConfig = `
+resource "kong_service" "service" {
+	name     = "test"
+	protocol = "tcp"
+	host     = "test.org"
+	port     = 443
+}
+
+resource "kong_route" "route" {
+	protocols 		= [ "tls" ]
+	snis 			= [ "example.com" ]
+	service_id  	= "${kong_service.service.id}"
+
+	sources {
+		ip = "192.168.0.0/16"
+	}
+
+	destinations {
+		ip   = "10.0.0.1"
+		port = 443
+	}
+}
+`
+const testCreateHttpRouteWithDestinationsConfig = `
+resource "kong_service" "service" {
+	name     = "test"
+	protocol = "http"
+	host     = "test.org"
+}
+
+resource "kong_route" "route" {
+	protocols 		= [ "http" ]
+	paths 			= [ "/" ]
+	service_id  	= "${kong_service.service.id}"
+
+	destinations {
+		ip   = "10.0.0.1"
+		port = 80
+	}
+}
+`
+const testCreateRouteWithDefaultsConfig = `
+resource "kong_service" "service" {
+	name     = "test"
+	protocol = "http"
+	host     = "test.org"
+}
+
+resource "kong_route" "route" {
+	protocols 		= [ "http" ]
+	paths 			= [ "/" ]
+	service_id  	= "${kong_service.service.id}"
+}
+`
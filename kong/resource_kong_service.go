@@ -67,17 +67,58 @@ func resourceKongService() *schema.Resource {
 				ForceNew: false,
 				Default:  60000,
 			},
+			"ca_certificates": &schema.Schema{
+				Type:        schema.TypeSet,
+				Optional:    true,
+				ForceNew:    false,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Ids of the kong_ca_certificates the certificate of the upstream server is verified against",
+			},
 		},
 	}
 }
 
+// serviceRequest adds the fields gokong does not know about to its service request. They are omitted when they have
+// never been set so services keep working against kong versions without them, once set an empty list is sent to clear
+// them.
+type serviceRequest struct {
+	*gokong.ServiceRequest
+	// CaCertificates are the ids of the ca certificates the certificate of the upstream server is verified against
+	CaCertificates *[]string `json:"ca_certificates,omitempty"`
+}
+
+type service struct {
+	gokong.Service
+	CaCertificates []string `json:"ca_certificates"`
+}
+
+// the ca_certificates of services came with the upstream tls verification of kong 2.1
+const serviceCaCertificatesMinimumKongVersion = "2.1.0"
+
 func resourceKongServiceCreate(d *schema.ResourceData, meta interface{}) error {
+	defer meta.(*kongClient).clearServiceCache()
+
+	serviceRequest, err := createKongServiceRequestFromResourceData(d)
+	if err != nil {
+		return err
+	}
+
+	if err := validateProtocols(meta.(*kongClient), []string{readStringFromResource(d, "protocol")}); err != nil {
+		return fmt.Errorf("invalid kong service protocol: %v", err)
+	}
 
-	serviceRequest := createKongServiceRequestFromResourceData(d)
+	if err := validateServiceCaCertificates(meta.(*kongClient), serviceRequest); err != nil {
+		return err
+	}
 
-	service, err := meta.(*gokong.KongAdminClient).Services().AddService(serviceRequest)
+	service := &service{}
+	err = meta.(*kongClient).create(gokong.ServicesPath, []string{readStringFromResource(d, "name")}, serviceRequest, service)
 	if err != nil {
-		return fmt.Errorf("failed to create kong service: %v error: %v", serviceRequest, err)
+		return fmt.Errorf("failed to create kong service: %v error: %v", requestString(serviceRequest), err)
+	}
+
+	if service.Id == nil {
+		return fmt.Errorf("failed to create kong service: %v error: kong did not return an id", requestString(serviceRequest))
 	}
 
 	d.SetId(*service.Id)
@@ -86,11 +127,24 @@ func resourceKongServiceCreate(d *schema.ResourceData, meta interface{}) error {
 }
 
 func resourceKongServiceUpdate(d *schema.ResourceData, meta interface{}) error {
+	defer meta.(*kongClient).clearServiceCache()
+
 	d.Partial(false)
 
-	serviceRequest := createKongServiceRequestFromResourceData(d)
+	serviceRequest, err := createKongServiceRequestFromResourceData(d)
+	if err != nil {
+		return err
+	}
 
-	_, err := meta.(*gokong.KongAdminClient).Services().UpdateServiceById(d.Id(), serviceRequest)
+	if err := validateProtocols(meta.(*kongClient), []string{readStringFromResource(d, "protocol")}); err != nil {
+		return fmt.Errorf("invalid kong service protocol: %v", err)
+	}
+
+	if err := validateServiceCaCertificates(meta.(*kongClient), serviceRequest); err != nil {
+		return err
+	}
+
+	err = meta.(*kongClient).patch(gokong.ServicesPath+d.Id(), serviceRequest, nil)
 
 	if err != nil {
 		return fmt.Errorf("error updating kong service: %s", err)
@@ -101,15 +155,28 @@ func resourceKongServiceUpdate(d *schema.ResourceData, meta interface{}) error {
 
 func resourceKongServiceRead(d *schema.ResourceData, meta interface{}) error {
 
-	service, err := meta.(*gokong.KongAdminClient).Services().GetServiceById(d.Id())
+	service := &service{}
+	found, err := meta.(*kongClient).get(gokong.ServicesPath+d.Id(), service)
 
 	if err != nil {
 		return fmt.Errorf("could not find kong service: %v", err)
 	}
 
-	if service == nil {
+	if !found || service.Id == nil {
 		d.SetId("")
 	} else {
+		logDrift(meta.(*kongClient), "kong_service", d, map[string]interface{}{
+			"name":            stringValue(service.Name),
+			"protocol":        stringValue(service.Protocol),
+			"host":            stringValue(service.Host),
+			"port":            intValue(service.Port),
+			"path":            stringValue(service.Path),
+			"retries":         intValue(service.Retries),
+			"connect_timeout": intValue(service.ConnectTimeout),
+			"write_timeout":   intValue(service.WriteTimeout),
+			"read_timeout":    intValue(service.ReadTimeout),
+		})
+
 		if service.Name != nil {
 			d.Set("name", service.Name)
 		}
@@ -126,8 +193,12 @@ func resourceKongServiceRead(d *schema.ResourceData, meta interface{}) error {
 			d.Set("port", service.Port)
 		}
 
+		// kong returns null for a service without a path (grpc services can not have one), so clear it rather than
+		// keeping whatever was in state
 		if service.Path != nil {
 			d.Set("path", service.Path)
+		} else {
+			d.Set("path", "")
 		}
 
 		if service.Retries != nil {
@@ -145,14 +216,17 @@ func resourceKongServiceRead(d *schema.ResourceData, meta interface{}) error {
 		if service.ReadTimeout != nil {
 			d.Set("read_timeout", service.ReadTimeout)
 		}
+
+		d.Set("ca_certificates", service.CaCertificates)
 	}
 
 	return nil
 }
 
 func resourceKongServiceDelete(d *schema.ResourceData, meta interface{}) error {
+	defer meta.(*kongClient).clearServiceCache()
 
-	err := meta.(*gokong.KongAdminClient).Services().DeleteServiceById(d.Id())
+	err := meta.(*kongClient).delete(gokong.ServicesPath + d.Id())
 
 	if err != nil {
 		return fmt.Errorf("could not delete kong service: %v", err)
@@ -161,8 +235,8 @@ func resourceKongServiceDelete(d *schema.ResourceData, meta interface{}) error {
 	return nil
 }
 
-func createKongServiceRequestFromResourceData(d *schema.ResourceData) *gokong.ServiceRequest {
-	return &gokong.ServiceRequest{
+func createKongServiceRequestFromResourceData(d *schema.ResourceData) (*serviceRequest, error) {
+	serviceRequest := &serviceRequest{ServiceRequest: &gokong.ServiceRequest{
 		Name:           readStringPtrFromResource(d, "name"),
 		Protocol:       readStringPtrFromResource(d, "protocol"),
 		Host:           readStringPtrFromResource(d, "host"),
@@ -172,5 +246,40 @@ func createKongServiceRequestFromResourceData(d *schema.ResourceData) *gokong.Se
 		ConnectTimeout: readIntPtrFromResource(d, "connect_timeout"),
 		WriteTimeout:   readIntPtrFromResource(d, "write_timeout"),
 		ReadTimeout:    readIntPtrFromResource(d, "read_timeout"),
+	}}
+
+	if caCertificates := readStringSetFromResource(d, "ca_certificates"); len(caCertificates) > 0 || d.HasChange("ca_certificates") {
+		serviceRequest.CaCertificates = &caCertificates
+	}
+
+	if err := validateServicePath(serviceRequest.ServiceRequest); err != nil {
+		return serviceRequest, err
 	}
+
+	return serviceRequest, nil
+}
+
+var pathlessServiceProtocols = []string{"grpc", "grpcs"}
+
+// kong rejects a path on grpc services, catch that here with a message that says why
+func validateServicePath(serviceRequest *gokong.ServiceRequest) error {
+	if serviceRequest.Protocol == nil || serviceRequest.Path == nil || *serviceRequest.Path == "" {
+		return nil
+	}
+
+	if contains(pathlessServiceProtocols, *serviceRequest.Protocol) {
+		return fmt.Errorf("kong service path can not be set when protocol is %s, path is: %s", *serviceRequest.Protocol, *serviceRequest.Path)
+	}
+
+	return nil
+}
+
+// validateServiceCaCertificates checks kong supports the ca_certificates of a service and that each of them is the id
+// of a ca certificate
+func validateServiceCaCertificates(client *kongClient, serviceRequest *serviceRequest) error {
+	if serviceRequest.CaCertificates == nil {
+		return nil
+	}
+
+	return validateCaCertificateIds(client, "service", serviceCaCertificatesMinimumKongVersion, *serviceRequest.CaCertificates)
 }
@@ -1,9 +1,16 @@
 package kong
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"strings"
 	"testing"
 
+	"github.com/hashicorp/terraform/config"
 	"github.com/hashicorp/terraform/helper/resource"
 	"github.com/hashicorp/terraform/terraform"
 	"github.com/kevholditch/gokong"
@@ -66,9 +73,172 @@ func TestAccKongServiceImport(t *testing.T) {
 	})
 }
 
+func TestAccKongServiceGrpc(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheckKongVersion(t, "1.3.0") },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckKongServiceDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testCreateGrpcServiceConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckKongServiceExists("kong_service.service"),
+					resource.TestCheckResourceAttr("kong_service.service", "protocol", "grpc"),
+					resource.TestCheckResourceAttr("kong_service.service", "host", "grpc.test.org"),
+					resource.TestCheckResourceAttr("kong_service.service", "port", "9000"),
+					resource.TestCheckResourceAttr("kong_service.service", "path", ""),
+				),
+			},
+			{
+				Config: testUpdateGrpcsServiceConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckKongServiceExists("kong_service.service"),
+					resource.TestCheckResourceAttr("kong_service.service", "protocol", "grpcs"),
+					resource.TestCheckResourceAttr("kong_service.service", "port", "9443"),
+					resource.TestCheckResourceAttr("kong_service.service", "path", ""),
+				),
+			},
+		},
+	})
+}
+
+func TestAccKongServiceGrpcWithPath(t *testing.T) {
+	// the service is never created so there is nothing for a destroy check to look up
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      testCreateGrpcServiceWithPathConfig,
+				ExpectError: regexp.MustCompile("kong service path can not be set when protocol is grpc"),
+			},
+		},
+	})
+}
+
+func TestKongServiceCaCertificates(t *testing.T) {
+
+	kongVersion := "3.4.0"
+	var sent []map[string]interface{}
+	stored := map[string]interface{}{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/":
+			json.NewEncoder(w).Encode(map[string]string{"version": kongVersion})
+		case strings.HasPrefix(r.URL.Path, caCertificatesPath):
+			if id := strings.TrimPrefix(r.URL.Path, caCertificatesPath); id != "ca-1" && id != "ca-2" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write([]byte(`{}`))
+		default:
+			if r.Method == http.MethodPost || r.Method == http.MethodPatch {
+				request := map[string]interface{}{}
+				json.NewDecoder(r.Body).Decode(&request)
+				sent = append(sent, request)
+				for key, value := range request {
+					stored[key] = value
+				}
+				stored["id"] = "service-id"
+				if r.Method == http.MethodPost {
+					w.WriteHeader(http.StatusCreated)
+				}
+			}
+			json.NewEncoder(w).Encode(stored)
+		}
+	}))
+	defer server.Close()
+
+	client := newKongClient(&gokong.Config{HostAddress: server.URL})
+	r := resourceKongService()
+	apply := func(state *terraform.InstanceState, caCertificates []interface{}) (*terraform.InstanceState, error) {
+		rawConfig, err := config.NewRawConfig(map[string]interface{}{
+			"name":            "test",
+			"protocol":        "https",
+			"host":            "test.org",
+			"ca_certificates": caCertificates,
+		})
+		if err != nil {
+			t.Fatalf("could not build config: %v", err)
+		}
+		diff, err := r.Diff(state, terraform.NewResourceConfig(rawConfig))
+		if err != nil {
+			t.Fatalf("could not diff service: %v", err)
+		}
+		return r.Apply(state, diff, client)
+	}
+
+	state, err := apply(nil, []interface{}{"ca-1", "ca-2"})
+	if err != nil {
+		t.Fatalf("could not create service: %v", err)
+	}
+	if state.Attributes["ca_certificates.#"] != "2" {
+		t.Errorf("expected both ca certificates to be attached, got: %v", state.Attributes)
+	}
+
+	// detaching one sends the one that is left, detaching them all sends an empty list to clear them
+	state, err = apply(state, []interface{}{"ca-2"})
+	if err != nil {
+		t.Fatalf("could not update service: %v", err)
+	}
+	if caCertificates := sent[1]["ca_certificates"]; !reflect.DeepEqual(caCertificates, []interface{}{"ca-2"}) || state.Attributes["ca_certificates.#"] != "1" {
+		t.Errorf("expected only ca-2 to be attached, kong was sent: %v state: %v", caCertificates, state.Attributes)
+	}
+
+	state, err = apply(state, []interface{}{})
+	if err != nil {
+		t.Fatalf("could not update service: %v", err)
+	}
+	if caCertificates, ok := sent[2]["ca_certificates"].([]interface{}); !ok || len(caCertificates) != 0 {
+		t.Errorf("expected an empty list to be sent to detach the ca certificates, kong was sent: %v", sent[2])
+	}
+	if state.Attributes["ca_certificates.#"] != "0" {
+		t.Errorf("expected no ca certificates to be attached, got: %v", state.Attributes)
+	}
+
+	// an id that is not a ca certificate is rejected before the service is changed
+	if _, err := apply(state, []interface{}{"ca-1", "missing-ca"}); err == nil || !strings.Contains(err.Error(), "no kong ca certificate with that id") {
+		t.Errorf("expected an unknown ca certificate to be rejected, got: %v", err)
+	}
+
+	kongVersion = "2.0.5"
+	client = newKongClient(&gokong.Config{HostAddress: server.URL})
+	if _, err := apply(state, []interface{}{"ca-1"}); err == nil || !strings.Contains(err.Error(), "requires kong 2.1.0 or later") {
+		t.Errorf("expected service ca certificates to need kong 2.1, got: %v", err)
+	}
+	if len(sent) != 3 {
+		t.Errorf("expected the rejected updates not to be sent, kong was sent: %v", sent[3:])
+	}
+}
+
+func TestValidateServicePath(t *testing.T) {
+	valid := []*gokong.ServiceRequest{
+		{Protocol: gokong.String("http"), Path: gokong.String("/mypath")},
+		{Protocol: gokong.String("grpc")},
+		{Protocol: gokong.String("grpcs"), Path: gokong.String("")},
+	}
+
+	for _, serviceRequest := range valid {
+		if err := validateServicePath(serviceRequest); err != nil {
+			t.Errorf("expected service request %+v to be valid, got: %v", serviceRequest, err)
+		}
+	}
+
+	invalid := []*gokong.ServiceRequest{
+		{Protocol: gokong.String("grpc"), Path: gokong.String("/mypath")},
+		{Protocol: gokong.String("grpcs"), Path: gokong.String("/")},
+	}
+
+	for _, serviceRequest := range invalid {
+		if err := validateServicePath(serviceRequest); err == nil {
+			t.Errorf("expected service request %+v to be rejected", serviceRequest)
+		}
+	}
+}
+
 func testAccCheckKongServiceDestroy(state *terraform.State) error {
 
-	client := testAccProvider.Meta().(*gokong.KongAdminClient)
+	client := testAccProvider.Meta().(*kongClient)
 
 	services := getResourcesByType("kong_service", state)
 
@@ -102,7 +272,7 @@ func testAccCheckKongServiceExists(resourceKey string) resource.TestCheckFunc {
 			return fmt.Errorf("no ID is set")
 		}
 
-		service, err := testAccProvider.Meta().(*gokong.KongAdminClient).Services().GetServiceById(rs.Primary.ID)
+		service, err := testAccProvider.Meta().(*kongClient).Services().GetServiceById(rs.Primary.ID)
 
 		if err != nil {
 			return err
@@ -155,3 +325,28 @@ resource "kong_service" "service" {
 	read_timeout  	= 10000
 }
 `
+const testCreateGrpcServiceConfig = `
+resource "kong_service" "service" {
+	name     = "grpc"
+	protocol = "grpc"
+	host     = "grpc.test.org"
+	port     = 9000
+}
+`
+const testUpdateGrpcsServiceConfig = `
+resource "kong_service" "service" {
+	name     = "grpc"
+	protocol = "grpcs"
+	host     = "grpc.test.org"
+	port     = 9443
+}
+`
+const testCreateGrpcServiceWithPathConfig = `
+resource "kong_service" "service" {
+	name     = "grpc"
+	protocol = "grpc"
+	host     = "grpc.test.org"
+	port     = 9000
+	path     = "/mypath"
+}
+`
@@ -12,6 +12,7 @@ func resourceKongSni() *schema.Resource {
 		Create: resourceKongSniCreate,
 		Read:   resourceKongSniRead,
 		Delete: resourceKongSniDelete,
+		Update: resourceKongSniUpdate,
 		Importer: &schema.ResourceImporter{
 			State: schema.ImportStatePassthrough,
 		},
@@ -23,22 +24,63 @@ func resourceKongSni() *schema.Resource {
 				ForceNew: true,
 			},
 			"certificate_id": &schema.Schema{
-				Type:     schema.TypeString,
-				Required: true,
-				ForceNew: true,
+				Type:          schema.TypeString,
+				Optional:      true,
+				Computed:      true,
+				ForceNew:      true,
+				ConflictsWith: []string{"certificate_sni"},
+			},
+			// another sni of the certificate, the id of its certificate is looked up when the sni is created
+			"certificate_sni": &schema.Schema{
+				Type:          schema.TypeString,
+				Optional:      true,
+				ForceNew:      true,
+				ConflictsWith: []string{"certificate_id"},
+			},
+			"tags": &schema.Schema{
+				Type:     schema.TypeSet,
+				Optional: true,
+				ForceNew: false,
+				Elem:     &schema.Schema{Type: schema.TypeString},
 			},
 		},
 	}
 }
 
-func resourceKongSniCreate(d *schema.ResourceData, meta interface{}) error {
+type sniRequest struct {
+	*gokong.SnisRequest
+	Tags *[]string `json:"tags,omitempty"`
+}
 
-	sniRequest := createKongSniRequestFromResourceData(d)
+type sni struct {
+	certificateSni
+	Tags []string `json:"tags"`
+}
 
-	sni, err := meta.(*gokong.KongAdminClient).Snis().Create(sniRequest)
+func resourceKongSniCreate(d *schema.ResourceData, meta interface{}) error {
 
+	sniRequest, err := createKongSniRequestFromResourceData(meta.(*kongClient), d)
 	if err != nil {
-		return fmt.Errorf("failed to create kong sni: %v error: %v", sniRequest, err)
+		return err
+	}
+
+	if certificateSni := readStringFromResource(d, "certificate_sni"); certificateSni != "" {
+		certificateId, err := getKongCertificateIdBySni(meta.(*kongClient), certificateSni)
+		if err != nil {
+			return fmt.Errorf("could not find the certificate of kong sni %s: %v", sniRequest.Name, err)
+		}
+		sniRequest.SslCertificateId = certificateId
+	}
+
+	if sniRequest.SslCertificateId == "" {
+		return fmt.Errorf("kong sni %s needs one of certificate_id or certificate_sni", sniRequest.Name)
+	}
+
+	sni := &sni{}
+	err = meta.(*kongClient).post(gokong.SnisPath, sniRequest, sni)
+
+	if err != nil || sni.Name == "" {
+		return fmt.Errorf("failed to create kong sni: %v error: %v", requestString(sniRequest), err)
 	}
 
 	d.SetId(sni.Name)
@@ -46,19 +88,39 @@ func resourceKongSniCreate(d *schema.ResourceData, meta interface{}) error {
 	return resourceKongSniRead(d, meta)
 }
 
+// resourceKongSniUpdate only has the tags to change, the name and certificate are replaced
+func resourceKongSniUpdate(d *schema.ResourceData, meta interface{}) error {
+	d.Partial(false)
+
+	tags, err := readTagsFromResource(meta.(*kongClient), "kong_sni", d)
+	if err != nil {
+		return fmt.Errorf("could not check kong version for tags: %v", err)
+	}
+
+	if tags != nil && d.HasChange("tags") {
+		if err := syncTags(meta.(*kongClient), "snis", d.Id(), *tags); err != nil {
+			return fmt.Errorf("error updating kong sni: %s", err)
+		}
+	}
+
+	return resourceKongSniRead(d, meta)
+}
+
 func resourceKongSniRead(d *schema.ResourceData, meta interface{}) error {
 
-	sni, err := meta.(*gokong.KongAdminClient).Snis().GetByName(d.Id())
+	sni := &sni{}
+	found, err := meta.(*kongClient).get(gokong.SnisPath+d.Id(), sni)
 
 	if err != nil {
 		return fmt.Errorf("could not find kong sni: %v", err)
 	}
 
-	if sni == nil {
+	if !found || sni.Name == "" {
 		d.SetId("")
 	} else {
 		d.Set("name", sni.Name)
-		d.Set("certificate_id", sni.SslCertificateId)
+		d.Set("certificate_id", firstNonEmpty(sni.SslCertificateId, sni.Certificate.id()))
+		setTagsFromKong(meta.(*kongClient), d, sni.Tags)
 	}
 
 	return nil
@@ -66,7 +128,7 @@ func resourceKongSniRead(d *schema.ResourceData, meta interface{}) error {
 
 func resourceKongSniDelete(d *schema.ResourceData, meta interface{}) error {
 
-	err := meta.(*gokong.KongAdminClient).Snis().DeleteByName(d.Id())
+	err := meta.(*kongClient).delete(gokong.SnisPath + d.Id())
 
 	if err != nil {
 		return fmt.Errorf("could not delete kong sni: %v", err)
@@ -75,12 +137,18 @@ func resourceKongSniDelete(d *schema.ResourceData, meta interface{}) error {
 	return nil
 }
 
-func createKongSniRequestFromResourceData(d *schema.ResourceData) *gokong.SnisRequest {
+func createKongSniRequestFromResourceData(client *kongClient, d *schema.ResourceData) (*sniRequest, error) {
 
-	sniRequest := &gokong.SnisRequest{}
+	sniRequest := &sniRequest{SnisRequest: &gokong.SnisRequest{}}
 
 	sniRequest.Name = readStringFromResource(d, "name")
 	sniRequest.SslCertificateId = readStringFromResource(d, "certificate_id")
 
-	return sniRequest
+	tags, err := readTagsFromResource(client, "kong_sni", d)
+	if err != nil {
+		return nil, fmt.Errorf("could not check kong version for tags: %v", err)
+	}
+	sniRequest.Tags = tags
+
+	return sniRequest, nil
 }
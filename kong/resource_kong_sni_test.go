@@ -1,10 +1,15 @@
 package kong
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/terraform"
 	"github.com/kevholditch/gokong"
 )
@@ -52,9 +57,97 @@ func TestAccKongSniImport(t *testing.T) {
 	})
 }
 
+func TestKongSniCertificateSni(t *testing.T) {
+
+	// kong 1.0 and later refer to the certificate of an sni by a reference
+	snis := map[string]map[string]interface{}{
+		"api.example.com": {"name": "api.example.com", "certificate": map[string]string{"id": "certificate-id"}},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/":
+			json.NewEncoder(w).Encode(map[string]string{"version": "1.0.3"})
+		case r.Method == http.MethodPost && r.URL.Path == gokong.SnisPath:
+			request := &gokong.SnisRequest{}
+			json.NewDecoder(r.Body).Decode(request)
+			snis[request.Name] = map[string]interface{}{"name": request.Name, "ssl_certificate_id": request.SslCertificateId}
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(snis[request.Name])
+		case r.Method == http.MethodGet && snis[strings.TrimPrefix(r.URL.Path, gokong.SnisPath)] != nil:
+			json.NewEncoder(w).Encode(snis[strings.TrimPrefix(r.URL.Path, gokong.SnisPath)])
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := newKongClient(&gokong.Config{HostAddress: server.URL})
+
+	d := schema.TestResourceDataRaw(t, resourceKongSni().Schema, map[string]interface{}{
+		"name":            "www.example.com",
+		"certificate_sni": "api.example.com",
+	})
+	if err := resourceKongSniCreate(d, client); err != nil {
+		t.Fatalf("could not create sni: %v", err)
+	}
+
+	if d.Id() != "www.example.com" || d.Get("certificate_id") != "certificate-id" || snis["www.example.com"]["ssl_certificate_id"] != "certificate-id" {
+		t.Errorf("expected the sni to be created for the certificate of api.example.com, got: %v (certificate_id %v)", snis["www.example.com"], d.Get("certificate_id"))
+	}
+
+	d = schema.TestResourceDataRaw(t, resourceKongSni().Schema, map[string]interface{}{
+		"name":            "docs.example.com",
+		"certificate_sni": "missing.example.com",
+	})
+	if err := resourceKongSniCreate(d, client); err == nil || !strings.Contains(err.Error(), "there is no kong sni missing.example.com") {
+		t.Errorf("expected an sni that does not exist to be reported, got: %v", err)
+	}
+
+	d = schema.TestResourceDataRaw(t, resourceKongSni().Schema, map[string]interface{}{"name": "docs.example.com"})
+	if err := resourceKongSniCreate(d, client); err == nil || !strings.Contains(err.Error(), "needs one of certificate_id or certificate_sni") {
+		t.Errorf("expected an sni without a certificate to be rejected, got: %v", err)
+	}
+	if snis["docs.example.com"] != nil {
+		t.Errorf("expected the rejected snis not to be created, kong has: %v", snis["docs.example.com"])
+	}
+}
+
+func TestKongSniTags(t *testing.T) {
+
+	server := newTaggedEntityServer(gokong.SnisPath, "example.com")
+	defer server.Close()
+
+	client := newKongClient(&gokong.Config{HostAddress: server.URL})
+	r := resourceKongSni()
+
+	state := applyTaggedResource(t, r, client, nil, map[string]interface{}{
+		"name":           "example.com",
+		"certificate_id": "certificate-id",
+		"tags":           []interface{}{"team-a"},
+	})
+
+	if len(server.sentTags) != 1 || fmt.Sprint(server.sentTags[0]) != "[team-a]" {
+		t.Fatalf("expected the tags to be sent with the sni, kong was sent: %v", server.sentTags)
+	}
+	if state.ID != "example.com" || state.Attributes["tags.#"] != "1" || state.Attributes["certificate_id"] != "certificate-id" {
+		t.Errorf("expected the sni to be read back, got: %v", state.Attributes)
+	}
+
+	// changing only the tags updates the sni in place
+	state = applyTaggedResource(t, r, client, state, map[string]interface{}{
+		"name":           "example.com",
+		"certificate_id": "certificate-id",
+		"tags":           []interface{}{"team-a", "billing"},
+	})
+	if len(server.sentTags) != 2 || fmt.Sprint(server.sentTags[1]) != "[team-a billing]" || state.Attributes["tags.#"] != "2" {
+		t.Errorf("expected the added tag to be sent, kong was sent: %v state: %v", server.sentTags, state.Attributes)
+	}
+}
+
 func testAccCheckKongSniDestroy(state *terraform.State) error {
 
-	client := testAccProvider.Meta().(*gokong.KongAdminClient)
+	client := testAccProvider.Meta().(*kongClient)
 
 	snis := getResourcesByType("kong_sni", state)
 
@@ -88,7 +181,7 @@ func testAccCheckKongSniExists(resourceKey string) resource.TestCheckFunc {
 			return fmt.Errorf("no ID is set")
 		}
 
-		api, err := testAccProvider.Meta().(*gokong.KongAdminClient).Snis().GetByName(rs.Primary.ID)
+		api, err := testAccProvider.Meta().(*kongClient).Snis().GetByName(rs.Primary.ID)
 
 		if err != nil {
 			return err
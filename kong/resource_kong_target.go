@@ -0,0 +1,300 @@
+package kong
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/kevholditch/gokong"
+)
+
+// defaultTargetPort is the port kong adds to a target without one. The port of a target whose host has SRV records is
+// not used, kong balances over the ports of the records instead.
+const defaultTargetPort = "8000"
+
+// targetHostPattern matches a hostname, labels can start with an underscore for SRV names like _http._tcp.example.com
+var targetHostPattern = regexp.MustCompile(`^[A-Za-z0-9_]([A-Za-z0-9_-]*[A-Za-z0-9])?(\.[A-Za-z0-9_]([A-Za-z0-9_-]*[A-Za-z0-9])?)*\.?$`)
+
+type targetRequest struct {
+	Target string `json:"target"`
+	Weight int    `json:"weight"`
+}
+
+type target struct {
+	Id        string  `json:"id,omitempty"`
+	Target    string  `json:"target,omitempty"`
+	Weight    int     `json:"weight"`
+	CreatedAt float64 `json:"created_at,omitempty"`
+}
+
+func resourceKongTarget() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceKongTargetCreate,
+		Read:   resourceKongTargetRead,
+		Delete: resourceKongTargetDelete,
+		Update: resourceKongTargetUpdate,
+		Importer: &schema.ResourceImporter{
+			State: resourceKongTargetImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"upstream_id": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			// host:port, or a host without a port for one with SRV records
+			"target": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validateTarget,
+			},
+			// Kong targets are append only, a weight change adds a new entry for the target which then becomes the
+			// resource id. Setting the weight to 0 drains the target without removing it from terraform.
+			"weight": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ForceNew:     false,
+				Default:      100,
+				ValidateFunc: validateTargetWeight,
+			},
+		},
+	}
+}
+
+func resourceKongTargetCreate(d *schema.ResourceData, meta interface{}) error {
+
+	targetRequest := createKongTargetRequestFromResourceData(d)
+
+	target, err := createKongTargetEntry(meta.(*kongClient), readStringFromResource(d, "upstream_id"), targetRequest)
+
+	if err != nil {
+		return fmt.Errorf("failed to create kong target: %v error: %v", requestString(targetRequest), err)
+	}
+
+	d.SetId(target.Id)
+
+	return resourceKongTargetRead(d, meta)
+}
+
+func resourceKongTargetUpdate(d *schema.ResourceData, meta interface{}) error {
+	d.Partial(false)
+
+	targetRequest := createKongTargetRequestFromResourceData(d)
+
+	target, err := createKongTargetEntry(meta.(*kongClient), readStringFromResource(d, "upstream_id"), targetRequest)
+
+	if err != nil {
+		return fmt.Errorf("error updating kong target: %s", err)
+	}
+
+	d.SetId(target.Id)
+
+	return resourceKongTargetRead(d, meta)
+}
+
+func resourceKongTargetRead(d *schema.ResourceData, meta interface{}) error {
+
+	upstreamId := readStringFromResource(d, "upstream_id")
+	targetName := readStringFromResource(d, "target")
+
+	target, err := getLatestKongTarget(meta.(*kongClient), upstreamId, kongTargetName(targetName))
+
+	if err != nil {
+		return fmt.Errorf("could not find kong target %s on upstream %s: %v", targetName, upstreamId, err)
+	}
+
+	if target == nil {
+		d.SetId("")
+	} else {
+		// target is kept as it was configured, kong returns a target without a port with the default port
+		d.SetId(target.Id)
+		d.Set("weight", target.Weight)
+	}
+
+	return nil
+}
+
+func resourceKongTargetDelete(d *schema.ResourceData, meta interface{}) error {
+
+	upstreamId := readStringFromResource(d, "upstream_id")
+	targetName := readStringFromResource(d, "target")
+
+	// Kong deletes a target with all of its entries. When the resource is replaced with create_before_destroy by one
+	// for the same target the new entry was added first, deleting the target would then leave the upstream without it.
+	latest, err := getLatestKongTarget(meta.(*kongClient), upstreamId, kongTargetName(targetName))
+	if err != nil {
+		return fmt.Errorf("could not find kong target %s on upstream %s: %v", targetName, upstreamId, err)
+	}
+
+	if latest == nil {
+		return nil
+	}
+
+	if latest.Id != d.Id() {
+		log.Printf("[INFO] kong target %s on upstream %s has a newer entry %s than %s, it replaced this one and is kept", targetName, upstreamId, latest.Id, d.Id())
+		return nil
+	}
+
+	err = meta.(*kongClient).delete(upstreamTargetsPath(upstreamId) + latest.Target)
+
+	if err != nil {
+		return fmt.Errorf("could not delete kong target: %v", err)
+	}
+
+	return nil
+}
+
+// Targets are imported using upstream_id|target as the id, e.g. 9a8b...|10.0.0.1:8080
+func resourceKongTargetImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	idSplit := strings.Split(d.Id(), "|")
+
+	if len(idSplit) != 2 {
+		return nil, fmt.Errorf("failed to import kong target, id should be pipe separated as upstreamId|target found: %v", d.Id())
+	}
+
+	d.Set("upstream_id", idSplit[0])
+	d.Set("target", idSplit[1])
+
+	return []*schema.ResourceData{d}, nil
+}
+
+func createKongTargetRequestFromResourceData(d *schema.ResourceData) *targetRequest {
+
+	targetRequest := &targetRequest{}
+
+	targetRequest.Target = readStringFromResource(d, "target")
+	targetRequest.Weight = d.Get("weight").(int)
+
+	return targetRequest
+}
+
+// kongTargetName is the name kong keeps the target under, kong adds the default port to a target without one
+func kongTargetName(targetName string) string {
+	if _, _, err := net.SplitHostPort(targetName); err == nil {
+		return targetName
+	}
+	if strings.Contains(targetName, ":") && !strings.HasPrefix(targetName, "[") {
+		// an ipv6 address without a port
+		return "[" + targetName + "]:" + defaultTargetPort
+	}
+	return targetName + ":" + defaultTargetPort
+}
+
+func upstreamTargetsPath(upstreamId string) string {
+	return "/upstreams/" + upstreamId + "/targets/"
+}
+
+func createKongTargetEntry(client *kongClient, upstreamId string, targetRequest *targetRequest) (*target, error) {
+	target := &target{}
+
+	err := client.post(upstreamTargetsPath(upstreamId), targetRequest, target)
+	if err != nil {
+		return nil, err
+	}
+
+	if target.Id == "" {
+		return nil, fmt.Errorf("kong did not return an id for the target")
+	}
+
+	return target, nil
+}
+
+// listKongTargetEntries returns the full history of target entries on the upstream, including ones that have been
+// replaced by a later entry or drained to weight 0.
+func listKongTargetEntries(client *kongClient, upstreamId string) ([]*target, error) {
+	rawTargets, err := client.listAll(upstreamTargetsPath(upstreamId) + "all")
+	if err != nil {
+		return nil, err
+	}
+
+	targets := make([]*target, 0, len(rawTargets))
+	for _, rawTarget := range rawTargets {
+		target := &target{}
+		if err := json.Unmarshal(rawTarget, target); err != nil {
+			return nil, fmt.Errorf("could not parse target, error: %v", err)
+		}
+		targets = append(targets, target)
+	}
+
+	return targets, nil
+}
+
+// getLatestKongTarget finds the most recent entry for the target (host:port) which is the one kong uses, nil is
+// returned when the upstream or the target does not exist.
+func getLatestKongTarget(client *kongClient, upstreamId string, targetName string) (*target, error) {
+	targets, err := getLatestKongTargets(client, upstreamId)
+	if err != nil {
+		return nil, err
+	}
+
+	return targets[targetName], nil
+}
+
+// getLatestKongTargets finds the most recent entry of every target on the upstream by target (host:port), the map is
+// nil when the upstream does not exist.
+func getLatestKongTargets(client *kongClient, upstreamId string) (map[string]*target, error) {
+	upstream := &gokong.Upstream{}
+	found, err := client.get(gokong.UpstreamsPath+upstreamId, upstream)
+	if err != nil {
+		return nil, err
+	}
+
+	if !found || upstream.Id == "" {
+		return nil, nil
+	}
+
+	targets, err := listKongTargetEntries(client, upstreamId)
+	if err != nil {
+		return nil, err
+	}
+
+	latest := map[string]*target{}
+	for _, target := range targets {
+		if current := latest[target.Target]; current == nil || target.CreatedAt > current.CreatedAt {
+			latest[target.Target] = target
+		}
+	}
+
+	return latest, nil
+}
+
+func validateTargetWeight(v interface{}, k string) ([]string, []error) {
+	weight := v.(int)
+	if weight < 0 || weight > 1000 {
+		return nil, []error{fmt.Errorf("%s must be between 0 and 1000, got: %d", k, weight)}
+	}
+	return nil, nil
+}
+
+// validateTarget accepts a host:port or a host without a port. Kong resolves the host of a target without a port through
+// dns, when it has SRV records (e.g. _http._tcp.service.consul) the ports of the records are used so it needs none.
+func validateTarget(v interface{}, k string) ([]string, []error) {
+	value := v.(string)
+	host, port, err := net.SplitHostPort(value)
+	hasPort := err == nil
+	if !hasPort {
+		host = value
+	}
+	if !hasPort && strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]") {
+		host = strings.TrimSuffix(strings.TrimPrefix(value, "["), "]")
+	}
+
+	if net.ParseIP(host) == nil && (strings.Contains(host, ":") || !targetHostPattern.MatchString(host)) {
+		return nil, []error{fmt.Errorf("%s must be a host:port or a host without a port such as _http._tcp.example.com, got: %s", k, value)}
+	}
+
+	if hasPort {
+		if number, err := strconv.Atoi(port); err != nil || number < 1 || number > 65535 {
+			return nil, []error{fmt.Errorf("%s must have a port between 1 and 65535, got: %s", k, value)}
+		}
+	}
+
+	return nil, nil
+}
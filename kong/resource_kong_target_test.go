@@ -0,0 +1,334 @@
+package kong
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/kevholditch/gokong"
+)
+
+func TestAccKongTarget(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckKongTargetDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testTargetConfig, 100),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckKongTargetExists("kong_target.target", 100),
+					resource.TestCheckResourceAttr("kong_target.target", "target", "10.0.0.1:8080"),
+					resource.TestCheckResourceAttr("kong_target.target", "weight", "100"),
+				),
+			},
+			{
+				Config: fmt.Sprintf(testTargetConfig, 0),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckKongTargetExists("kong_target.target", 0),
+					resource.TestCheckResourceAttr("kong_target.target", "target", "10.0.0.1:8080"),
+					resource.TestCheckResourceAttr("kong_target.target", "weight", "0"),
+				),
+			},
+			{
+				Config: fmt.Sprintf(testTargetConfig, 50),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckKongTargetExists("kong_target.target", 50),
+					resource.TestCheckResourceAttr("kong_target.target", "target", "10.0.0.1:8080"),
+					resource.TestCheckResourceAttr("kong_target.target", "weight", "50"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccKongTargetCreateBeforeDestroy(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckKongTargetDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testTargetCreateBeforeDestroyConfig, "10.0.0.1:8080"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckKongTargetExists("kong_target.target", 100),
+					resource.TestCheckResourceAttr("kong_target.target", "target", "10.0.0.1:8080"),
+				),
+			},
+			{
+				Config: fmt.Sprintf(testTargetCreateBeforeDestroyConfig, "10.0.0.2:8080"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckKongTargetExists("kong_target.target", 100),
+					resource.TestCheckResourceAttr("kong_target.target", "target", "10.0.0.2:8080"),
+				),
+			},
+		},
+	})
+}
+
+// upstreamTargetHistory mocks the targets of an upstream on kong 1.0 or later, a target is deleted with all of its
+// entries and a target without a port gets the default port. The smallest number of active targets seen after each change is recorded.
+type upstreamTargetHistory struct {
+	entries   []*target
+	minActive int
+}
+
+func (history *upstreamTargetHistory) active() []string {
+	latest := map[string]*target{}
+	for _, entry := range history.entries {
+		latest[entry.Target] = entry
+	}
+
+	var active []string
+	for name, entry := range latest {
+		if entry.Weight > 0 {
+			active = append(active, name)
+		}
+	}
+	return active
+}
+
+func (history *upstreamTargetHistory) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	targets := upstreamTargetsPath("upstream-id")
+
+	switch {
+	case r.URL.Path == "/upstreams/upstream-id":
+		json.NewEncoder(w).Encode(map[string]string{"id": "upstream-id", "name": "upstream"})
+	case r.Method == http.MethodGet && r.URL.Path == targets+"all":
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": history.entries})
+	case r.Method == http.MethodPost && r.URL.Path == targets:
+		entry := &target{}
+		json.NewDecoder(r.Body).Decode(entry)
+		if _, _, err := net.SplitHostPort(entry.Target); err != nil {
+			entry.Target += ":8000"
+		}
+		entry.Id = fmt.Sprintf("target-%d", len(history.entries))
+		entry.CreatedAt = float64(len(history.entries))
+		history.entries = append(history.entries, entry)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(entry)
+	case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, targets):
+		name := strings.TrimPrefix(r.URL.Path, targets)
+		var kept []*target
+		for _, entry := range history.entries {
+			if entry.Target != name && entry.Id != name {
+				kept = append(kept, entry)
+			}
+		}
+		history.entries = kept
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if active := len(history.active()); r.Method != http.MethodGet && active < history.minActive {
+		history.minActive = active
+	}
+}
+
+func TestKongTargetCreateBeforeDestroyNeverEmptiesUpstream(t *testing.T) {
+
+	// a changed target, and the same target replaced e.g. after it was tainted
+	for _, replacement := range []string{"10.0.0.2:8080", "10.0.0.1:8080"} {
+		history := &upstreamTargetHistory{}
+		server := httptest.NewServer(http.HandlerFunc(history.serveHTTP))
+		client := newKongClient(&gokong.Config{HostAddress: server.URL})
+
+		old := schema.TestResourceDataRaw(t, resourceKongTarget().Schema, map[string]interface{}{
+			"upstream_id": "upstream-id",
+			"target":      "10.0.0.1:8080",
+		})
+		if err := resourceKongTargetCreate(old, client); err != nil {
+			t.Fatalf("could not create target: %v", err)
+		}
+		history.minActive = len(history.active())
+
+		replacing := schema.TestResourceDataRaw(t, resourceKongTarget().Schema, map[string]interface{}{
+			"upstream_id": "upstream-id",
+			"target":      replacement,
+		})
+		if err := resourceKongTargetCreate(replacing, client); err != nil {
+			t.Fatalf("could not create the replacing target: %v", err)
+		}
+		if err := resourceKongTargetDelete(old, client); err != nil {
+			t.Fatalf("could not delete the replaced target: %v", err)
+		}
+
+		if err := resourceKongTargetRead(replacing, client); err != nil {
+			t.Fatalf("could not read the replacing target: %v", err)
+		}
+		server.Close()
+
+		if history.minActive == 0 {
+			t.Errorf("replacing with %s: expected the upstream to always have a target", replacement)
+		}
+
+		if active := history.active(); len(active) != 1 || active[0] != replacement || replacing.Id() == "" {
+			t.Errorf("replacing with %s: expected only the replacing target to be left, got: %v (id %q)", replacement, active, replacing.Id())
+		}
+	}
+}
+
+func TestKongTargetSrvHostWithoutPort(t *testing.T) {
+
+	history := &upstreamTargetHistory{}
+	server := httptest.NewServer(http.HandlerFunc(history.serveHTTP))
+	defer server.Close()
+	client := newKongClient(&gokong.Config{HostAddress: server.URL})
+
+	d := schema.TestResourceDataRaw(t, resourceKongTarget().Schema, map[string]interface{}{
+		"upstream_id": "upstream-id",
+		"target":      "_http._tcp.service.consul",
+		"weight":      50,
+	})
+	if err := resourceKongTargetCreate(d, client); err != nil {
+		t.Fatalf("could not create target: %v", err)
+	}
+
+	// kong keeps the target with its default port, the configured target is kept in state
+	if d.Id() != "target-0" || d.Get("target") != "_http._tcp.service.consul" || d.Get("weight") != 50 {
+		t.Errorf("expected the srv target to be read back as configured, got: %v %v (id %q)", d.Get("target"), d.Get("weight"), d.Id())
+	}
+
+	if err := resourceKongTargetDelete(d, client); err != nil {
+		t.Fatalf("could not delete target: %v", err)
+	}
+	if len(history.entries) != 0 {
+		t.Errorf("expected the srv target to be deleted, got: %v", history.entries)
+	}
+}
+
+func TestValidateTarget(t *testing.T) {
+
+	for _, target := range []string{"10.0.0.1:8080", "10.0.0.1", "example.com:80", "service.consul", "_http._tcp.service.consul", "[::1]:8080", "::1", "[::1]"} {
+		if _, errors := validateTarget(target, "target"); len(errors) != 0 {
+			t.Errorf("expected target %s to be valid, got: %v", target, errors)
+		}
+	}
+
+	for _, target := range []string{"", "http://example.com", "example.com:", "example.com:http", "example.com:0", "example.com:65536", "example..com", "-example.com", "example.com/path"} {
+		if _, errors := validateTarget(target, "target"); len(errors) != 1 {
+			t.Errorf("expected target %s to be rejected, got: %v", target, errors)
+		}
+	}
+
+	for name, expected := range map[string]string{"10.0.0.1:8080": "10.0.0.1:8080", "_http._tcp.service.consul": "_http._tcp.service.consul:8000", "::1": "[::1]:8000", "[::1]": "[::1]:8000"} {
+		if kongName := kongTargetName(name); kongName != expected {
+			t.Errorf("expected target %s to be kept by kong as %s, got: %s", name, expected, kongName)
+		}
+	}
+}
+
+func TestValidateTargetWeight(t *testing.T) {
+
+	for _, weight := range []int{0, 100, 1000} {
+		if _, errors := validateTargetWeight(weight, "weight"); len(errors) != 0 {
+			t.Errorf("expected weight %d to be valid, got: %v", weight, errors)
+		}
+	}
+
+	for _, weight := range []int{-1, 1001} {
+		if _, errors := validateTargetWeight(weight, "weight"); len(errors) != 1 {
+			t.Errorf("expected weight %d to be rejected, got: %v", weight, errors)
+		}
+	}
+}
+
+func testAccCheckKongTargetDestroy(state *terraform.State) error {
+
+	client := testAccProvider.Meta().(*kongClient)
+
+	targets := getResourcesByType("kong_target", state)
+
+	if len(targets) != 1 {
+		return fmt.Errorf("expecting only 1 target resource found %v", len(targets))
+	}
+
+	upstreamId := targets[0].Primary.Attributes["upstream_id"]
+	targetName := targets[0].Primary.Attributes["target"]
+
+	target, err := getLatestKongTarget(client, upstreamId, targetName)
+
+	if err != nil {
+		return fmt.Errorf("error calling get target: %v", err)
+	}
+
+	// older kong versions record a delete as a new entry with weight 0
+	if target != nil && target.Weight != 0 {
+		return fmt.Errorf("target %s still exists on upstream %s, %+v", targetName, upstreamId, target)
+	}
+
+	return nil
+}
+
+func testAccCheckKongTargetExists(resourceKey string, expectedWeight int) resource.TestCheckFunc {
+
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resourceKey]
+
+		if !ok {
+			return fmt.Errorf("not found: %s", resourceKey)
+		}
+
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("no ID is set")
+		}
+
+		target, err := getLatestKongTarget(testAccProvider.Meta().(*kongClient), rs.Primary.Attributes["upstream_id"], rs.Primary.Attributes["target"])
+
+		if err != nil {
+			return err
+		}
+
+		if target == nil {
+			return fmt.Errorf("target with id %v not found", rs.Primary.ID)
+		}
+
+		if target.Id != rs.Primary.ID {
+			return fmt.Errorf("expected latest target entry to be %v but was %v", rs.Primary.ID, target.Id)
+		}
+
+		if target.Weight != expectedWeight {
+			return fmt.Errorf("expected target weight %d but was %d", expectedWeight, target.Weight)
+		}
+
+		return nil
+	}
+}
+
+const testTargetConfig = `
+resource "kong_upstream" "upstream" {
+	name  		= "TargetUpstream"
+	slots 		= 10
+}
+
+resource "kong_target" "target" {
+	upstream_id = "${kong_upstream.upstream.id}"
+	target      = "10.0.0.1:8080"
+	weight      = %d
+}
+`
+
+const testTargetCreateBeforeDestroyConfig = `
+resource "kong_upstream" "upstream" {
+	name  = "TargetCreateBeforeDestroyUpstream"
+}
+
+resource "kong_target" "target" {
+	upstream_id = "${kong_upstream.upstream.id}"
+	target      = "%s"
+
+	lifecycle {
+		create_before_destroy = true
+	}
+}
+`
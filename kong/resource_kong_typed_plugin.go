@@ -0,0 +1,233 @@
+package kong
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/kevholditch/gokong"
+)
+
+// typedPlugin describes a kong_plugin_<name> resource, a plugin whose config is set with typed attributes instead of
+// the string map or config_json of kong_plugin. Attributes are sent with their terraform type, so booleans and numbers
+// reach kong as json booleans and numbers.
+type typedPlugin struct {
+	// name of the plugin in kong
+	name string
+	// config attributes of the resource, the scope attributes are added to them
+	schema map[string]*schema.Schema
+	// expandConfig builds the plugin config from the config attributes
+	expandConfig func(d *schema.ResourceData) (map[string]interface{}, error)
+	// flattenConfig sets the config attributes from the config read from kong
+	flattenConfig func(d *schema.ResourceData, config map[string]interface{})
+	// resolveConfig is optional, it completes the expanded config with what has to be looked up in kong first
+	resolveConfig func(client *kongClient, d *schema.ResourceData, config map[string]interface{}) error
+}
+
+func resourceKongTypedPlugin(plugin *typedPlugin) *schema.Resource {
+	resourceSchema := map[string]*schema.Schema{
+		"service_id": &schema.Schema{
+			Type:     schema.TypeString,
+			Optional: true,
+			ForceNew: false,
+		},
+		"route_id": &schema.Schema{
+			Type:     schema.TypeString,
+			Optional: true,
+			ForceNew: false,
+		},
+		"consumer_id": &schema.Schema{
+			Type:     schema.TypeString,
+			Optional: true,
+			ForceNew: false,
+		},
+		"enabled": &schema.Schema{
+			Type:     schema.TypeBool,
+			Optional: true,
+			ForceNew: false,
+			Default:  true,
+		},
+	}
+
+	for key, attribute := range plugin.schema {
+		resourceSchema[key] = attribute
+	}
+
+	return &schema.Resource{
+		Create: func(d *schema.ResourceData, meta interface{}) error {
+			return resourceKongTypedPluginCreate(plugin, d, meta)
+		},
+		Read: func(d *schema.ResourceData, meta interface{}) error {
+			return resourceKongTypedPluginRead(plugin, d, meta)
+		},
+		Update: func(d *schema.ResourceData, meta interface{}) error {
+			return resourceKongTypedPluginUpdate(plugin, d, meta)
+		},
+		Delete: resourceKongPluginDelete,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		Schema: resourceSchema,
+	}
+}
+
+func resourceKongTypedPluginCreate(plugin *typedPlugin, d *schema.ResourceData, meta interface{}) error {
+
+	pluginRequest, err := createKongTypedPluginRequestFromResourceData(plugin, meta.(*kongClient), d)
+	if err != nil {
+		return err
+	}
+
+	createdPlugin := &gokong.Plugin{}
+	err = meta.(*kongClient).post(gokong.PluginsPath, pluginRequest, createdPlugin)
+
+	if err != nil {
+		return fmt.Errorf("failed to create kong %s plugin: %s error: %v", plugin.name, pluginRequest.redacted(), err)
+	}
+
+	d.SetId(createdPlugin.Id)
+
+	return resourceKongTypedPluginRead(plugin, d, meta)
+}
+
+func resourceKongTypedPluginUpdate(plugin *typedPlugin, d *schema.ResourceData, meta interface{}) error {
+	d.Partial(false)
+
+	pluginRequest, err := createKongTypedPluginRequestFromResourceData(plugin, meta.(*kongClient), d)
+	if err != nil {
+		return err
+	}
+
+	err = meta.(*kongClient).patch(gokong.PluginsPath+d.Id(), pluginRequest, nil)
+
+	if err != nil {
+		return fmt.Errorf("error updating kong %s plugin: %s", plugin.name, err)
+	}
+
+	return resourceKongTypedPluginRead(plugin, d, meta)
+}
+
+func resourceKongTypedPluginRead(plugin *typedPlugin, d *schema.ResourceData, meta interface{}) error {
+
+	scopedPlugin, err := getKongScopedPlugin(meta.(*kongClient), d.Id())
+
+	if err != nil {
+		return fmt.Errorf("could not find kong %s plugin: %v", plugin.name, err)
+	}
+
+	if scopedPlugin == nil {
+		d.SetId("")
+		return nil
+	}
+
+	if scopedPlugin.Name != plugin.name {
+		return fmt.Errorf("kong plugin %s is a %s plugin, it can not be managed as a %s plugin", d.Id(), scopedPlugin.Name, plugin.name)
+	}
+
+	d.Set("service_id", firstNonEmpty(scopedPlugin.ServiceId, scopedPlugin.Service.id()))
+	d.Set("route_id", firstNonEmpty(scopedPlugin.RouteId, scopedPlugin.Route.id()))
+	d.Set("consumer_id", firstNonEmpty(scopedPlugin.ConsumerId, scopedPlugin.Consumer.id()))
+	d.Set("enabled", scopedPlugin.Enabled)
+
+	config := scopedPlugin.Config
+	if config == nil {
+		config = map[string]interface{}{}
+	}
+	plugin.flattenConfig(d, config)
+
+	return nil
+}
+
+// typedPluginRequest uses nested entity references like the kong versions the typed plugins are written for, they are
+// not omitted when empty so an update sends null and clears them.
+type typedPluginRequest struct {
+	Name     string                 `json:"name"`
+	Service  *entityReference       `json:"service"`
+	Route    *entityReference       `json:"route"`
+	Consumer *entityReference       `json:"consumer"`
+	Config   map[string]interface{} `json:"config"`
+	Enabled  bool                   `json:"enabled"`
+}
+
+// redacted is the request as it is shown in errors, the values of sensitive config keys are masked (see redactConfig)
+func (pluginRequest *typedPluginRequest) redacted() string {
+	redacted := *pluginRequest
+	redacted.Config = redactConfig(pluginRequest.Config, nil)
+	return redactedJson(&redacted)
+}
+
+func createKongTypedPluginRequestFromResourceData(plugin *typedPlugin, client *kongClient, d *schema.ResourceData) (*typedPluginRequest, error) {
+
+	config, err := plugin.expandConfig(d)
+	if err != nil {
+		return nil, fmt.Errorf("invalid kong %s plugin config: %v", plugin.name, err)
+	}
+
+	if plugin.resolveConfig != nil {
+		if err := plugin.resolveConfig(client, d, config); err != nil {
+			return nil, fmt.Errorf("invalid kong %s plugin config: %v", plugin.name, err)
+		}
+	}
+
+	return &typedPluginRequest{
+		Name:     plugin.name,
+		Service:  newEntityReference(readStringFromResource(d, "service_id")),
+		Route:    newEntityReference(readStringFromResource(d, "route_id")),
+		Consumer: newEntityReference(readStringFromResource(d, "consumer_id")),
+		Config:   config,
+		Enabled:  d.Get("enabled").(bool),
+	}, nil
+}
+
+// configStrings converts a list read from plugin config, kong returns null for lists that were never set
+func configStrings(value interface{}) []string {
+	values, _ := value.([]interface{})
+	strings := make([]string, 0, len(values))
+	for _, item := range values {
+		if s, ok := item.(string); ok {
+			strings = append(strings, s)
+		}
+	}
+	return strings
+}
+
+// configBool reads a boolean from plugin config, the 1 and 0 some kong and database combinations return are true and
+// false (see coerceConfigBooleans), anything else that is not a boolean is false
+func configBool(value interface{}) bool {
+	if number, ok := value.(float64); ok {
+		return number == 1
+	}
+	b, _ := value.(bool)
+	return b
+}
+
+// configInt reads an integer from plugin config, json numbers are decoded as float64 and anything that is not a number
+// is 0
+func configInt(value interface{}) int {
+	number, _ := value.(float64)
+	return int(number)
+}
+
+// configInts converts a list of numbers read from plugin config, anything that is not a number is left out
+func configInts(value interface{}) []int {
+	values, _ := value.([]interface{})
+	ints := make([]int, 0, len(values))
+	for _, item := range values {
+		if number, ok := item.(float64); ok {
+			ints = append(ints, int(number))
+		}
+	}
+	return ints
+}
+
+// configFloat reads a number from plugin config, anything that is not a number is 0
+func configFloat(value interface{}) float64 {
+	number, _ := value.(float64)
+	return number
+}
+
+// configString reads a string from plugin config, kong returns null for strings that were never set
+func configString(value interface{}) string {
+	s, _ := value.(string)
+	return s
+}
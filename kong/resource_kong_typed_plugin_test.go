@@ -0,0 +1,89 @@
+package kong
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kevholditch/gokong"
+)
+
+// typedPluginServer mocks a kong node keeping the last plugin it is sent, the body of every create and update is kept in
+// bodies and decoded in sent. fill changes the config kong returns the way kong would, like filling in defaults. A node
+// with a kongVersion answers the version lookup. A request that is not json fails the test and is answered with a 400,
+// the handler never stops the test from the server's goroutine.
+type typedPluginServer struct {
+	*httptest.Server
+	kongVersion string
+	bodies      []string
+	sent        []map[string]interface{}
+	stored      map[string]interface{}
+}
+
+func newTypedPluginServer(t *testing.T, kongVersion string, fill func(config map[string]interface{})) *typedPluginServer {
+	server := &typedPluginServer{kongVersion: kongVersion}
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/" && server.kongVersion != "" {
+			json.NewEncoder(w).Encode(map[string]string{"version": server.kongVersion})
+			return
+		}
+
+		if r.Method == http.MethodPost || r.Method == http.MethodPatch {
+			body, _ := ioutil.ReadAll(r.Body)
+			request := map[string]interface{}{}
+			if err := json.Unmarshal(body, &request); err != nil {
+				t.Errorf("could not decode plugin request: %v", err)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			server.bodies = append(server.bodies, string(body))
+			server.sent = append(server.sent, request)
+
+			server.stored = map[string]interface{}{}
+			json.Unmarshal(body, &server.stored)
+			server.stored["id"] = "plugin-id"
+			if config, ok := server.stored["config"].(map[string]interface{}); ok && fill != nil {
+				fill(config)
+			}
+			if r.Method == http.MethodPost {
+				w.WriteHeader(http.StatusCreated)
+			}
+		}
+		json.NewEncoder(w).Encode(server.stored)
+	}))
+	return server
+}
+
+func TestKongTypedPluginCreateErrorRedactsSecrets(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/" {
+			json.NewEncoder(w).Encode(map[string]string{"version": "3.4.3.1"})
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"message":"schema violation"}`))
+	}))
+	defer server.Close()
+
+	r := resourceKongPluginRateLimitingAdvanced()
+	d := r.TestResourceData()
+	d.Set("limit", []interface{}{10})
+	d.Set("window_size", []interface{}{60})
+	d.Set("strategy", "redis")
+	d.Set("redis", []interface{}{map[string]interface{}{"host": "redis.internal", "password": "s3cr3t"}})
+
+	err := r.Create(d, newKongClient(&gokong.Config{HostAddress: server.URL}))
+	if err == nil {
+		t.Fatalf("expected the plugin kong rejected to fail")
+	}
+
+	if message := err.Error(); strings.Contains(message, "s3cr3t") || !strings.Contains(message, `"password":"`+redactedValue+`"`) || !strings.Contains(message, "redis.internal") {
+		t.Errorf("expected the error to show the config with the redis password redacted, got: %s", message)
+	}
+}
@@ -1,17 +1,42 @@
 package kong
 
 import (
+	"encoding/json"
 	"fmt"
 
+	"github.com/hashicorp/go-version"
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/kevholditch/gokong"
 )
 
+// the algorithm attribute of upstreams was added in kong 1.3, round-robin is how older versions balance so it is left
+// out of the request for them
+const upstreamAlgorithmMinimumKongVersion = "1.3.0"
+
+const defaultUpstreamAlgorithm = "round-robin"
+
+var upstreamAlgorithms = []string{defaultUpstreamAlgorithm, "consistent-hashing", "least-connections"}
+
+type upstreamRequest struct {
+	*gokong.UpstreamRequest
+	Algorithm string `json:"algorithm,omitempty"`
+}
+
+type upstreamAlgorithmRequest struct {
+	Algorithm string `json:"algorithm"`
+}
+
+type upstream struct {
+	gokong.Upstream
+	Algorithm string `json:"algorithm"`
+}
+
 func resourceKongUpstream() *schema.Resource {
 	return &schema.Resource{
 		Create: resourceKongUpstreamCreate,
 		Read:   resourceKongUpstreamRead,
 		Delete: resourceKongUpstreamDelete,
+		Update: resourceKongUpstreamUpdate,
 		Importer: &schema.ResourceImporter{
 			State: schema.ImportStatePassthrough,
 		},
@@ -27,18 +52,40 @@ func resourceKongUpstream() *schema.Resource {
 				Required: true,
 				ForceNew: true,
 			},
+			"algorithm": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ForceNew:     false,
+				ValidateFunc: validateUpstreamAlgorithm,
+				Description:  "The load balancing algorithm, one of round-robin, consistent-hashing or least-connections (kong 1.3 or later)",
+			},
+			// Only used by the provider on delete, it is not sent to kong
+			"force_destroy": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
 		},
 	}
 }
 
 func resourceKongUpstreamCreate(d *schema.ResourceData, meta interface{}) error {
 
-	upstreamRequest := createKongUpstreamRequestFromResourceData(d)
+	client := meta.(*kongClient)
+	upstreamRequest := &upstreamRequest{UpstreamRequest: createKongUpstreamRequestFromResourceData(d)}
 
-	upstream, err := meta.(*gokong.KongAdminClient).Upstreams().Create(upstreamRequest)
+	algorithm, err := upstreamAlgorithmForVersion(client, readStringFromResource(d, "algorithm"))
+	if err != nil {
+		return err
+	}
+	upstreamRequest.Algorithm = algorithm
+
+	upstream := &gokong.Upstream{}
+	err = client.post(gokong.UpstreamsPath, upstreamRequest, upstream)
 
 	if err != nil {
-		return fmt.Errorf("failed to create kong upstream: %v error: %v", upstreamRequest, err)
+		return fmt.Errorf("failed to create kong upstream: %v error: %v", requestString(upstreamRequest), err)
 	}
 
 	d.SetId(upstream.Id)
@@ -46,19 +93,47 @@ func resourceKongUpstreamCreate(d *schema.ResourceData, meta interface{}) error
 	return resourceKongUpstreamRead(d, meta)
 }
 
+// algorithm and force_destroy are the only attributes that can change without replacing the upstream, force_destroy
+// only lives in state
+func resourceKongUpstreamUpdate(d *schema.ResourceData, meta interface{}) error {
+	d.Partial(false)
+
+	if d.HasChange("algorithm") {
+		client := meta.(*kongClient)
+
+		algorithm, err := upstreamAlgorithmForVersion(client, readStringFromResource(d, "algorithm"))
+		if err != nil {
+			return err
+		}
+
+		if algorithm != "" {
+			if err := client.patch(gokong.UpstreamsPath+d.Id(), &upstreamAlgorithmRequest{Algorithm: algorithm}, nil); err != nil {
+				return fmt.Errorf("error updating kong upstream: %s", err)
+			}
+		}
+	}
+
+	return resourceKongUpstreamRead(d, meta)
+}
+
 func resourceKongUpstreamRead(d *schema.ResourceData, meta interface{}) error {
 
-	upstream, err := meta.(*gokong.KongAdminClient).Upstreams().GetById(d.Id())
+	upstream := &upstream{}
+	found, err := meta.(*kongClient).get(gokong.UpstreamsPath+d.Id(), upstream)
 
 	if err != nil {
 		return fmt.Errorf("could not find kong upstream: %v", err)
 	}
 
-	if upstream == nil {
+	if !found || upstream.Id == "" {
 		d.SetId("")
 	} else {
 		d.Set("name", upstream.Name)
 		d.Set("slots", upstream.Slots)
+		// kong versions without algorithm do not return it, the configured round-robin is kept
+		if upstream.Algorithm != "" {
+			d.Set("algorithm", upstream.Algorithm)
+		}
 	}
 
 	return nil
@@ -66,7 +141,13 @@ func resourceKongUpstreamRead(d *schema.ResourceData, meta interface{}) error {
 
 func resourceKongUpstreamDelete(d *schema.ResourceData, meta interface{}) error {
 
-	err := meta.(*gokong.KongAdminClient).Upstreams().DeleteById(d.Id())
+	if d.Get("force_destroy").(bool) {
+		if err := deleteKongUpstreamTargets(meta.(*kongClient), d.Id()); err != nil {
+			return fmt.Errorf("could not delete targets of kong upstream: %v", err)
+		}
+	}
+
+	err := meta.(*kongClient).delete(gokong.UpstreamsPath + d.Id())
 
 	if err != nil {
 		return fmt.Errorf("could not delete kong upstream: %v", err)
@@ -84,3 +165,60 @@ func createKongUpstreamRequestFromResourceData(d *schema.ResourceData) *gokong.U
 
 	return upstreamRequest
 }
+
+func validateUpstreamAlgorithm(value interface{}, k string) ([]string, []error) {
+	if !contains(upstreamAlgorithms, value.(string)) {
+		return nil, []error{fmt.Errorf("%s must be one of %v, got: %s", k, upstreamAlgorithms, value)}
+	}
+	return nil, nil
+}
+
+// upstreamAlgorithmForVersion returns the algorithm to send to kong, nothing is sent for an unset algorithm or for
+// round-robin on kong versions that do not have the attribute. Other algorithms need kong 1.3.
+func upstreamAlgorithmForVersion(client *kongClient, algorithm string) (string, error) {
+	if algorithm == "" {
+		return "", nil
+	}
+
+	kongVersion, err := client.version()
+	if err != nil {
+		return "", fmt.Errorf("could not check kong version for upstream algorithm %s: %v", algorithm, err)
+	}
+
+	if kongVersion.LessThan(version.Must(version.NewVersion(upstreamAlgorithmMinimumKongVersion))) {
+		if algorithm == defaultUpstreamAlgorithm {
+			return "", nil
+		}
+		return "", fmt.Errorf("upstream algorithm %s requires kong %s or later, kong version is %s", algorithm, upstreamAlgorithmMinimumKongVersion, kongVersion)
+	}
+
+	return algorithm, nil
+}
+
+// deleteKongUpstreamTargets deletes every target on the upstream, targets are listed page by page and each host:port is
+// deleted once even when kong returns several entries for it.
+func deleteKongUpstreamTargets(client *kongClient, upstreamId string) error {
+	rawTargets, err := client.listAll(upstreamTargetsPath(upstreamId))
+	if err != nil {
+		return err
+	}
+
+	deleted := map[string]bool{}
+	for _, rawTarget := range rawTargets {
+		target := &target{}
+		if err := json.Unmarshal(rawTarget, target); err != nil {
+			return fmt.Errorf("could not parse target, error: %v", err)
+		}
+
+		if deleted[target.Target] {
+			continue
+		}
+
+		if err := client.delete(upstreamTargetsPath(upstreamId) + target.Target); err != nil {
+			return err
+		}
+		deleted[target.Target] = true
+	}
+
+	return nil
+}
@@ -1,10 +1,17 @@
 package kong
 
 import (
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/terraform"
 	"github.com/kevholditch/gokong"
 )
@@ -34,6 +41,111 @@ func TestAccKongUpstream(t *testing.T) {
 		},
 	})
 }
+func TestAccKongUpstreamAlgorithm(t *testing.T) {
+
+	var upstreamId string
+
+	steps := []resource.TestStep{}
+	for _, algorithm := range upstreamAlgorithms {
+		steps = append(steps, resource.TestStep{
+			Config: fmt.Sprintf(testUpstreamAlgorithmConfig, algorithm),
+			Check: resource.ComposeTestCheckFunc(
+				testAccCheckKongUpstreamExists("kong_upstream.upstream"),
+				testAccCheckKongPluginIdUnchanged("kong_upstream.upstream", &upstreamId),
+				resource.TestCheckResourceAttr("kong_upstream.upstream", "algorithm", algorithm),
+			),
+		})
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheckKongVersion(t, upstreamAlgorithmMinimumKongVersion) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckKongUpstreamDestroy,
+		Steps:        steps,
+	})
+}
+
+func TestAccKongUpstreamInvalidAlgorithm(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      fmt.Sprintf(testUpstreamAlgorithmConfig, "random"),
+				ExpectError: regexp.MustCompile("algorithm must be one of"),
+			},
+		},
+	})
+}
+
+func TestKongUpstreamAlgorithmVersions(t *testing.T) {
+
+	cases := []struct {
+		kongVersion string
+		algorithm   string
+		sent        string
+		err         string
+	}{
+		{"0.13.1", "", "", ""},
+		{"0.13.1", "round-robin", "", ""},
+		{"0.13.1", "least-connections", "", "upstream algorithm least-connections requires kong 1.3.0 or later, kong version is 0.13.1"},
+		{"1.2.2", "consistent-hashing", "", "upstream algorithm consistent-hashing requires kong 1.3.0 or later"},
+		{"1.3.0", "round-robin", "round-robin", ""},
+		{"1.3.0", "least-connections", "least-connections", ""},
+		{"3.6.1", "consistent-hashing", "consistent-hashing", ""},
+	}
+
+	for _, c := range cases {
+		var posted map[string]interface{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			switch {
+			case r.URL.Path == "/":
+				json.NewEncoder(w).Encode(map[string]string{"version": c.kongVersion})
+			case r.Method == http.MethodPost:
+				body, _ := ioutil.ReadAll(r.Body)
+				json.Unmarshal(body, &posted)
+				posted["id"] = "upstream-id"
+				w.WriteHeader(http.StatusCreated)
+				json.NewEncoder(w).Encode(posted)
+			default:
+				json.NewEncoder(w).Encode(posted)
+			}
+		}))
+
+		raw := map[string]interface{}{"name": "MyUpstream", "slots": 10}
+		if c.algorithm != "" {
+			raw["algorithm"] = c.algorithm
+		}
+		d := schema.TestResourceDataRaw(t, resourceKongUpstream().Schema, raw)
+
+		err := resourceKongUpstreamCreate(d, newKongClient(&gokong.Config{HostAddress: server.URL}))
+		server.Close()
+
+		if c.err != "" {
+			if err == nil || !strings.Contains(err.Error(), c.err) {
+				t.Errorf("kong %s: expected algorithm %s to fail with %s, got: %v", c.kongVersion, c.algorithm, c.err, err)
+			}
+			if posted != nil {
+				t.Errorf("kong %s: expected nothing to be created with an unsupported algorithm, got: %v", c.kongVersion, posted)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Fatalf("kong %s: could not create upstream with algorithm %q: %v", c.kongVersion, c.algorithm, err)
+		}
+
+		if sent, _ := posted["algorithm"].(string); sent != c.sent {
+			t.Errorf("kong %s: expected algorithm %q to be sent for %q but was %q", c.kongVersion, c.sent, c.algorithm, sent)
+		}
+
+		if d.Get("algorithm") != c.algorithm && d.Get("algorithm") != c.sent {
+			t.Errorf("kong %s: expected algorithm %q in state but was %q", c.kongVersion, c.algorithm, d.Get("algorithm"))
+		}
+	}
+}
+
 func TestAccKongUpstreamImport(t *testing.T) {
 
 	resource.Test(t, resource.TestCase{
@@ -48,6 +160,27 @@ func TestAccKongUpstreamImport(t *testing.T) {
 				ResourceName:      "kong_upstream.upstream",
 				ImportState:       true,
 				ImportStateVerify: true,
+				// force_destroy only exists in state so it can not be imported
+				ImportStateVerifyIgnore: []string{"force_destroy"},
+			},
+		},
+	})
+}
+
+func TestAccKongUpstreamForceDestroy(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckKongUpstreamDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testCreateUpstreamForceDestroyConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckKongUpstreamExists("kong_upstream.upstream"),
+					resource.TestCheckResourceAttr("kong_upstream.upstream", "force_destroy", "true"),
+					// targets added outside of terraform, the upstream destroy has to remove them
+					testAccCreateKongUpstreamTargets("kong_upstream.upstream", "10.0.0.1:8080", "10.0.0.2:8080", "10.0.0.3:8080"),
+				),
 			},
 		},
 	})
@@ -55,7 +188,7 @@ func TestAccKongUpstreamImport(t *testing.T) {
 
 func testAccCheckKongUpstreamDestroy(state *terraform.State) error {
 
-	client := testAccProvider.Meta().(*gokong.KongAdminClient)
+	client := testAccProvider.Meta().(*kongClient)
 
 	upstreams := getResourcesByType("kong_upstream", state)
 
@@ -89,7 +222,7 @@ func testAccCheckKongUpstreamExists(resourceKey string) resource.TestCheckFunc {
 			return fmt.Errorf("no ID is set")
 		}
 
-		api, err := testAccProvider.Meta().(*gokong.KongAdminClient).Upstreams().GetById(rs.Primary.ID)
+		api, err := testAccProvider.Meta().(*kongClient).Upstreams().GetById(rs.Primary.ID)
 
 		if err != nil {
 			return err
@@ -103,6 +236,35 @@ func testAccCheckKongUpstreamExists(resourceKey string) resource.TestCheckFunc {
 	}
 }
 
+func testAccCreateKongUpstreamTargets(resourceKey string, targets ...string) resource.TestCheckFunc {
+
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resourceKey]
+
+		if !ok {
+			return fmt.Errorf("not found: %s", resourceKey)
+		}
+
+		client := testAccProvider.Meta().(*kongClient)
+		for _, target := range targets {
+			if _, err := createKongTargetEntry(client, rs.Primary.ID, &targetRequest{Target: target, Weight: 100}); err != nil {
+				return fmt.Errorf("could not create target %s: %v", target, err)
+			}
+		}
+
+		entries, err := listKongTargetEntries(client, rs.Primary.ID)
+		if err != nil {
+			return err
+		}
+
+		if len(entries) != len(targets) {
+			return fmt.Errorf("expected %d targets on the upstream but found %d", len(targets), len(entries))
+		}
+
+		return nil
+	}
+}
+
 const testCreateUpstreamConfig = `
 resource "kong_upstream" "upstream" {
 	name  		= "MyUpstream"
@@ -115,3 +277,18 @@ resource "kong_upstream" "upstream" {
 	slots 		= 20
 }
 `
+const testCreateUpstreamForceDestroyConfig = `
+resource "kong_upstream" "upstream" {
+	name  		= "MyUpstream"
+	slots 		= 10
+	force_destroy = true
+}
+`
+
+const testUpstreamAlgorithmConfig = `
+resource "kong_upstream" "upstream" {
+	name      = "MyUpstream"
+	slots     = 10
+	algorithm = "%s"
+}
+`
@@ -0,0 +1,234 @@
+package kong
+
+import (
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// maximumTargetWeight is the highest weight kong accepts for a target
+const maximumTargetWeight = 1000
+
+func resourceKongUpstreamTrafficSplit() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceKongUpstreamTrafficSplitCreate,
+		Read:   resourceKongUpstreamTrafficSplitRead,
+		Delete: resourceKongUpstreamTrafficSplitDelete,
+		Update: resourceKongUpstreamTrafficSplitUpdate,
+
+		Schema: map[string]*schema.Schema{
+			"upstream_id": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			// target (host:port) to the percentage of the traffic it gets, they must add up to 100. A target with 0
+			// is drained without being removed from the upstream.
+			"percentages": &schema.Schema{
+				Type:     schema.TypeMap,
+				Required: true,
+				Elem:     schema.TypeInt,
+			},
+			// the weights of the targets add up to base, a higher base splits the traffic more precisely
+			"base": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      100,
+				ValidateFunc: validateTrafficSplitBase,
+			},
+			"weights": &schema.Schema{
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Elem:        schema.TypeInt,
+				Description: "The kong weight of each target",
+			},
+		},
+	}
+}
+
+func resourceKongUpstreamTrafficSplitCreate(d *schema.ResourceData, meta interface{}) error {
+
+	upstreamId := readStringFromResource(d, "upstream_id")
+	if err := applyKongUpstreamTrafficSplit(meta.(*kongClient), upstreamId, nil, d); err != nil {
+		return fmt.Errorf("failed to create kong upstream traffic split: %v", err)
+	}
+
+	d.SetId(upstreamId)
+
+	return resourceKongUpstreamTrafficSplitRead(d, meta)
+}
+
+func resourceKongUpstreamTrafficSplitUpdate(d *schema.ResourceData, meta interface{}) error {
+	d.Partial(false)
+
+	current, err := getLatestKongTargets(meta.(*kongClient), d.Id())
+	if err != nil {
+		return fmt.Errorf("could not find kong targets of upstream %s: %v", d.Id(), err)
+	}
+
+	if err := applyKongUpstreamTrafficSplit(meta.(*kongClient), d.Id(), current, d); err != nil {
+		return fmt.Errorf("error updating kong upstream traffic split: %v", err)
+	}
+
+	return resourceKongUpstreamTrafficSplitRead(d, meta)
+}
+
+// applyKongUpstreamTrafficSplit adds an entry for every target whose weight is not already the one kong has, then
+// deletes the targets that were removed from the split. The new weights are in place before any target is removed so
+// the upstream is never left without the remaining ones.
+func applyKongUpstreamTrafficSplit(client *kongClient, upstreamId string, current map[string]*target, d *schema.ResourceData) error {
+
+	weights, err := trafficSplitWeights(readTrafficSplitPercentages(d, "percentages"), d.Get("base").(int))
+	if err != nil {
+		return err
+	}
+
+	for _, targetName := range sortedKeys(weights) {
+		if latest := current[targetName]; latest != nil && latest.Weight == weights[targetName] {
+			continue
+		}
+		if _, err := createKongTargetEntry(client, upstreamId, &targetRequest{Target: targetName, Weight: weights[targetName]}); err != nil {
+			return fmt.Errorf("could not set the weight of target %s to %d: %v", targetName, weights[targetName], err)
+		}
+	}
+
+	old, _ := d.GetChange("percentages")
+	for targetName := range old.(map[string]interface{}) {
+		if _, kept := weights[targetName]; kept || current[targetName] == nil {
+			continue
+		}
+		if err := client.delete(upstreamTargetsPath(upstreamId) + targetName); err != nil {
+			return fmt.Errorf("could not delete target %s: %v", targetName, err)
+		}
+	}
+
+	return nil
+}
+
+// The percentage of a target whose weight was changed outside of terraform is read back from its weight when that is
+// a whole percentage, otherwise the target is left out so the plan still shows it being set again.
+func resourceKongUpstreamTrafficSplitRead(d *schema.ResourceData, meta interface{}) error {
+
+	upstreamId := d.Id()
+	targets, err := getLatestKongTargets(meta.(*kongClient), upstreamId)
+	if err != nil {
+		return fmt.Errorf("could not find kong targets of upstream %s: %v", upstreamId, err)
+	}
+
+	if targets == nil {
+		d.SetId("")
+		return nil
+	}
+
+	base := d.Get("base").(int)
+	percentages := readTrafficSplitPercentages(d, "percentages")
+	// the percentages in state do not add up to 100 when a target was left out by an earlier read, every weight is
+	// then read back as it is
+	expected, err := trafficSplitWeights(percentages, base)
+	inSync := err == nil
+
+	readPercentages := map[string]interface{}{}
+	weights := map[string]interface{}{}
+	for targetName, percentage := range percentages {
+		target := targets[targetName]
+		if target == nil {
+			log.Printf("[WARN] kong target %s of the traffic split of upstream %s no longer exists", targetName, upstreamId)
+			continue
+		}
+
+		weights[targetName] = target.Weight
+		switch {
+		case inSync && target.Weight == expected[targetName]:
+			readPercentages[targetName] = percentage
+		case target.Weight*100%base == 0 && (!inSync || target.Weight*100/base != percentage):
+			readPercentages[targetName] = target.Weight * 100 / base
+		default:
+			log.Printf("[WARN] kong target %s of upstream %s has the weight %d instead of %d", targetName, upstreamId, target.Weight, expected[targetName])
+		}
+	}
+
+	d.Set("upstream_id", upstreamId)
+	d.Set("percentages", readPercentages)
+	d.Set("weights", weights)
+
+	return nil
+}
+
+func resourceKongUpstreamTrafficSplitDelete(d *schema.ResourceData, meta interface{}) error {
+
+	targets, err := getLatestKongTargets(meta.(*kongClient), d.Id())
+	if err != nil {
+		return fmt.Errorf("could not find kong targets of upstream %s: %v", d.Id(), err)
+	}
+
+	for targetName := range readTrafficSplitPercentages(d, "percentages") {
+		if targets[targetName] == nil {
+			continue
+		}
+		if err := meta.(*kongClient).delete(upstreamTargetsPath(d.Id()) + targetName); err != nil {
+			return fmt.Errorf("could not delete kong target %s: %v", targetName, err)
+		}
+	}
+
+	return nil
+}
+
+func readTrafficSplitPercentages(d *schema.ResourceData, key string) map[string]int {
+	percentages := map[string]int{}
+	for targetName, percentage := range readMapFromResource(d, key) {
+		percentages[targetName] = percentage.(int)
+	}
+	return percentages
+}
+
+// trafficSplitWeights turns the percentages into weights that add up to base. Each target gets the whole part of its
+// share of base and what is left is given one at a time to the targets with the largest remainder, ties go to the
+// target that sorts first so the weights are the same on every plan.
+func trafficSplitWeights(percentages map[string]int, base int) (map[string]int, error) {
+	total := 0
+	for targetName, percentage := range percentages {
+		if percentage < 0 || percentage > 100 {
+			return nil, fmt.Errorf("the percentage of target %s must be between 0 and 100, got: %d", targetName, percentage)
+		}
+		total += percentage
+	}
+
+	if total != 100 {
+		return nil, fmt.Errorf("the percentages of the targets must add up to 100, got: %d", total)
+	}
+
+	targetNames := sortedKeys(percentages)
+	weights := map[string]int{}
+	remaining := base
+	for _, targetName := range targetNames {
+		weights[targetName] = percentages[targetName] * base / 100
+		remaining -= weights[targetName]
+	}
+
+	sort.SliceStable(targetNames, func(i, j int) bool {
+		return percentages[targetNames[i]]*base%100 > percentages[targetNames[j]]*base%100
+	})
+	for i := 0; i < remaining; i++ {
+		weights[targetNames[i]]++
+	}
+
+	return weights, nil
+}
+
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func validateTrafficSplitBase(v interface{}, k string) ([]string, []error) {
+	if base := v.(int); base < 1 || base > maximumTargetWeight {
+		return nil, []error{fmt.Errorf("%s must be between 1 and %d, got: %d", k, maximumTargetWeight, base)}
+	}
+	return nil, nil
+}
@@ -0,0 +1,156 @@
+package kong
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/config"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/kevholditch/gokong"
+)
+
+func TestTrafficSplitWeights(t *testing.T) {
+
+	cases := []struct {
+		percentages map[string]int
+		base        int
+		expected    map[string]int
+	}{
+		{map[string]int{"stable:80": 90, "canary:80": 10}, 100, map[string]int{"stable:80": 90, "canary:80": 10}},
+		{map[string]int{"stable:80": 90, "canary:80": 10}, 1000, map[string]int{"stable:80": 900, "canary:80": 100}},
+		{map[string]int{"stable:80": 100, "canary:80": 0}, 100, map[string]int{"stable:80": 100, "canary:80": 0}},
+		// 2.31, 2.31 and 2.38 of 7, the largest remainder gets the weight that is left
+		{map[string]int{"a:80": 33, "b:80": 33, "c:80": 34}, 7, map[string]int{"a:80": 2, "b:80": 2, "c:80": 3}},
+		// the remainders tie, the target that sorts first gets the weight that is left
+		{map[string]int{"a:80": 50, "b:80": 50}, 1, map[string]int{"a:80": 1, "b:80": 0}},
+	}
+
+	for _, c := range cases {
+		weights, err := trafficSplitWeights(c.percentages, c.base)
+		if err != nil {
+			t.Fatalf("%v of %d: could not compute the weights: %v", c.percentages, c.base, err)
+		}
+
+		total := 0
+		for targetName, weight := range weights {
+			total += weight
+			if weight != c.expected[targetName] {
+				t.Errorf("%v of %d: expected the weights %v, got: %v", c.percentages, c.base, c.expected, weights)
+				break
+			}
+		}
+		if total != c.base {
+			t.Errorf("%v of %d: expected the weights to add up to the base, got: %v", c.percentages, c.base, weights)
+		}
+	}
+
+	if _, err := trafficSplitWeights(map[string]int{"stable:80": 90, "canary:80": 20}, 100); err == nil || !strings.Contains(err.Error(), "add up to 100, got: 110") {
+		t.Errorf("expected percentages that do not add up to 100 to be rejected, got: %v", err)
+	}
+	if _, err := trafficSplitWeights(map[string]int{"stable:80": 110, "canary:80": -10}, 100); err == nil || !strings.Contains(err.Error(), "between 0 and 100") {
+		t.Errorf("expected a negative percentage to be rejected, got: %v", err)
+	}
+	if _, errors := validateTrafficSplitBase(1001, "base"); len(errors) != 1 {
+		t.Errorf("expected a base above the maximum weight to be rejected, got: %v", errors)
+	}
+}
+
+func TestKongUpstreamTrafficSplitLifecycle(t *testing.T) {
+
+	history := &upstreamTargetHistory{}
+	server := httptest.NewServer(http.HandlerFunc(history.serveHTTP))
+	defer server.Close()
+
+	client := newKongClient(&gokong.Config{HostAddress: server.URL})
+	r := resourceKongUpstreamTrafficSplit()
+
+	apply := func(state *terraform.InstanceState, raw map[string]interface{}) *terraform.InstanceState {
+		rawConfig, err := config.NewRawConfig(raw)
+		if err != nil {
+			t.Fatalf("could not build config: %v", err)
+		}
+		diff, err := r.Diff(state, terraform.NewResourceConfig(rawConfig))
+		if err != nil {
+			t.Fatalf("could not diff traffic split: %v", err)
+		}
+		if diff == nil {
+			return state
+		}
+		state, err = r.Apply(state, diff, client)
+		if err != nil {
+			t.Fatalf("could not apply traffic split: %v", err)
+		}
+		return state
+	}
+
+	state := apply(nil, map[string]interface{}{
+		"upstream_id": "upstream-id",
+		"percentages": map[string]interface{}{"10.0.0.1:8080": 90, "10.0.0.2:8080": 10},
+	})
+
+	if state.ID != "upstream-id" || state.Attributes["weights.10.0.0.1:8080"] != "90" || state.Attributes["weights.10.0.0.2:8080"] != "10" {
+		t.Fatalf("expected a 90/10 split to give the weights 90 and 10, got: %v", state.Attributes)
+	}
+	history.minActive = len(history.active())
+
+	// moving the canary to a new target sets the new weights before the old canary is removed
+	state = apply(state, map[string]interface{}{
+		"upstream_id": "upstream-id",
+		"percentages": map[string]interface{}{"10.0.0.1:8080": 50, "10.0.0.3:8080": 50},
+		"base":        1000,
+	})
+
+	active := history.active()
+	sort.Strings(active)
+	if strings.Join(active, ",") != "10.0.0.1:8080,10.0.0.3:8080" || history.minActive < 2 {
+		t.Errorf("expected the old canary to be removed only once the new one has its weight, active: %v fewest active: %d", active, history.minActive)
+	}
+	if state.Attributes["weights.10.0.0.1:8080"] != "500" || state.Attributes["weights.10.0.0.3:8080"] != "500" {
+		t.Errorf("expected a 50/50 split of 1000, got: %v", state.Attributes)
+	}
+
+	// an unchanged weight does not add an entry
+	entries := len(history.entries)
+	apply(state, map[string]interface{}{
+		"upstream_id": "upstream-id",
+		"percentages": map[string]interface{}{"10.0.0.1:8080": 50, "10.0.0.3:8080": 50},
+		"base":        1000,
+	})
+	if len(history.entries) != entries {
+		t.Errorf("expected no new target entries for an unchanged split, got: %v", history.entries[entries:])
+	}
+
+	// a weight changed outside of terraform is read back as a percentage, one that is not a whole percentage leaves
+	// the target out of state
+	drifted := float64(len(history.entries))
+	history.entries = append(history.entries,
+		&target{Id: "drifted-1", Target: "10.0.0.1:8080", Weight: 800, CreatedAt: drifted},
+		&target{Id: "drifted-3", Target: "10.0.0.3:8080", Weight: 205, CreatedAt: drifted + 1})
+
+	refreshed, err := r.Refresh(state, client)
+	if err != nil {
+		t.Fatalf("could not refresh traffic split: %v", err)
+	}
+	if refreshed.Attributes["percentages.10.0.0.1:8080"] != "80" || refreshed.Attributes["percentages.%"] != "1" {
+		t.Errorf("expected the drifted weights to show in the percentages, got: %v", refreshed.Attributes)
+	}
+
+	state = apply(refreshed, map[string]interface{}{
+		"upstream_id": "upstream-id",
+		"percentages": map[string]interface{}{"10.0.0.1:8080": 50, "10.0.0.3:8080": 50},
+		"base":        1000,
+	})
+	if state.Attributes["percentages.%"] != "2" || state.Attributes["weights.10.0.0.1:8080"] != "500" || state.Attributes["weights.10.0.0.3:8080"] != "500" {
+		t.Errorf("expected the drifted weights to be set again, got: %v", state.Attributes)
+	}
+
+	if err := resourceKongUpstreamTrafficSplitDelete(r.Data(state), client); err != nil {
+		t.Fatalf("could not delete traffic split: %v", err)
+	}
+	if len(history.entries) != 0 {
+		t.Errorf("expected every target of the split to be deleted, got: %v", history.entries)
+	}
+}
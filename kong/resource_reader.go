@@ -37,6 +37,18 @@ func readStringArrayPtrFromResource(d *schema.ResourceData, key string) []*strin
 	return nil
 }
 
+func readStringSetFromResource(d *schema.ResourceData, key string) []string {
+	array := []string{}
+
+	if attr, ok := d.GetOk(key); ok {
+		for _, x := range attr.(*schema.Set).List() {
+			array = append(array, x.(string))
+		}
+	}
+
+	return array
+}
+
 func readIntArrayFromResource(d *schema.ResourceData, key string) []int {
 
 	if attr, ok := d.GetOk(key); ok {
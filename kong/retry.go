@@ -0,0 +1,88 @@
+package kong
+
+import (
+	"log"
+	"net"
+	"net/url"
+	"os"
+	"syscall"
+	"time"
+)
+
+// the wait between attempts starts at retryMinWait and doubles up to retryMaxWait
+var (
+	retryMinWait = 250 * time.Millisecond
+	retryMaxWait = 5 * time.Second
+)
+
+// retry calls fn until it succeeds, it returns an error that retryable says can not be fixed by trying again or the
+// last error once timeout has passed.
+func retry(timeout time.Duration, retryable func(error) bool, fn func() error) error {
+	deadline := time.Now().Add(timeout)
+	wait := retryMinWait
+
+	for {
+		err := fn()
+		if err == nil || !retryable(err) {
+			return err
+		}
+
+		if time.Now().Add(wait).After(deadline) {
+			return err
+		}
+
+		log.Printf("[DEBUG] retrying in %s after error: %v", wait, err)
+		time.Sleep(wait)
+
+		wait = wait * 2
+		if wait > retryMaxWait {
+			wait = retryMaxWait
+		}
+	}
+}
+
+// waitForKong checks the admin api can be reached, retrying while kong is not listening yet or its name does not
+// resolve yet for up to timeout. The kong version is read by the check so it is not looked up again later. The circuit
+// breaker opening on the failed checks is retried too, the check after its cooldown tries kong again. A konnect control
+// plane has a fixed version (see useKonnect) so its services are read instead, see checkKonnect.
+func waitForKong(client *kongClient, timeout time.Duration) error {
+	retryable := func(err error) bool {
+		return isConnectionError(err) || isCircuitOpenError(err)
+	}
+	return retry(timeout, retryable, func() error {
+		if client.konnectControlPlaneId != "" {
+			return client.checkKonnect()
+		}
+		_, err := client.version()
+		return err
+	})
+}
+
+// isConnectionError is true for connection refused and dns errors, the errors seen while kong is still starting
+func isConnectionError(err error) bool {
+	requestError, ok := err.(*requestError)
+	if !ok {
+		return false
+	}
+
+	for _, err := range requestError.errs {
+		if urlError, ok := err.(*url.Error); ok {
+			err = urlError.Err
+		}
+		if opError, ok := err.(*net.OpError); ok {
+			err = opError.Err
+		}
+		if syscallError, ok := err.(*os.SyscallError); ok {
+			err = syscallError.Err
+		}
+
+		if _, ok := err.(*net.DNSError); ok {
+			return true
+		}
+		if err == syscall.ECONNREFUSED {
+			return true
+		}
+	}
+
+	return false
+}
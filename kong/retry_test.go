@@ -0,0 +1,130 @@
+package kong
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/kevholditch/gokong"
+)
+
+func TestWaitForKongRetriesUntilKongIsListening(t *testing.T) {
+	defer func(minWait time.Duration) { retryMinWait = minWait }(retryMinWait)
+	retryMinWait = 50 * time.Millisecond
+
+	// reserve an address nothing is listening on, kong "starts" on it after the first attempt has been refused
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("could not reserve an address: %v", err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+
+	var calls int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"version":"1.3.0"}`))
+	}))
+	defer server.Close()
+
+	client := newKongClient(&gokong.Config{HostAddress: "http://" + address})
+	if _, err := client.version(); !isConnectionError(err) {
+		t.Fatalf("expected the first attempt to be refused, got: %v", err)
+	}
+
+	started := make(chan error, 1)
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		listener, err := net.Listen("tcp", address)
+		if err == nil {
+			server.Listener = listener
+			server.Start()
+		}
+		started <- err
+	}()
+
+	if err := waitForKong(client, 10*time.Second); err != nil {
+		t.Fatalf("expected kong to be reached once it was listening, got: %v", err)
+	}
+
+	if err := <-started; err != nil {
+		t.Fatalf("could not start kong on %s: %v", address, err)
+	}
+
+	if calls != 1 {
+		t.Errorf("expected 1 successful call once kong was listening but there were %d", calls)
+	}
+
+	if kongVersion, _ := client.version(); kongVersion == nil || kongVersion.String() != "1.3.0" {
+		t.Errorf("expected the kong version read by the check to be kept, got: %v", kongVersion)
+	}
+}
+
+func TestWaitForKongGivesUp(t *testing.T) {
+	defer func(minWait time.Duration) { retryMinWait = minWait }(retryMinWait)
+	retryMinWait = 10 * time.Millisecond
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	// an error from kong itself is not retried
+	if err := waitForKong(newKongClient(&gokong.Config{HostAddress: server.URL}), 10*time.Second); err == nil || calls != 1 {
+		t.Errorf("expected a single attempt failing with not authorised, got %d attempts and: %v", calls, err)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("could not reserve an address: %v", err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+
+	start := time.Now()
+	err = waitForKong(newKongClient(&gokong.Config{HostAddress: "http://" + address}), 200*time.Millisecond)
+	if !isConnectionError(err) {
+		t.Errorf("expected connection refused once the timeout passed, got: %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("expected to give up after around 200ms but took %s", elapsed)
+	}
+}
+
+func TestProviderConfigureRetrySeconds(t *testing.T) {
+	defer func(minWait time.Duration) { retryMinWait = minWait }(retryMinWait)
+	retryMinWait = 10 * time.Millisecond
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("could not reserve an address: %v", err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+
+	d := schema.TestResourceDataRaw(t, Provider().(*schema.Provider).Schema, map[string]interface{}{
+		"kong_admin_uri":          "http://" + address,
+		"configure_retry_seconds": 1,
+	})
+
+	if _, err := providerConfigure(d); err == nil || !strings.Contains(err.Error(), "could not reach kong admin api") {
+		t.Errorf("expected configure to fail when kong can not be reached, got: %v", err)
+	}
+
+	d = schema.TestResourceDataRaw(t, Provider().(*schema.Provider).Schema, map[string]interface{}{
+		"kong_admin_uri": "http://" + address,
+	})
+
+	if _, err := providerConfigure(d); err != nil {
+		t.Errorf("expected configure not to check kong by default, got: %v", err)
+	}
+}
@@ -0,0 +1,105 @@
+package kong
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// tags were added to the admin api in kong 1.1, older nodes reject requests that have them
+const tagsMinimumKongVersion = "1.1.0"
+
+func tagsSupported(kongVersion *version.Version) bool {
+	return !kongVersion.LessThan(version.Must(version.NewVersion(tagsMinimumKongVersion)))
+}
+
+// readTagsFromResource returns the tags to send to kong, tags can be a list or a set. They are left out with a warning
+// (nil is returned, so the request should omit tags when it is nil) when the node is older than 1.1, which lets one
+// config be applied to nodes of either version.
+func readTagsFromResource(client *kongClient, resourceType string, d *schema.ResourceData) (*[]string, error) {
+	tags := readStringSetFromResource(d, "tags")
+
+	kongVersion, err := client.version()
+	if err != nil {
+		return nil, err
+	}
+
+	if !tagsSupported(kongVersion) {
+		if len(tags) > 0 {
+			log.Printf("[WARN] %s %s: kong %s does not support tags, they are not sent: %v", resourceType, d.Id(), kongVersion, tags)
+		}
+		return nil, nil
+	}
+
+	return &tags, nil
+}
+
+// setTagsFromKong sets the tags read from kong, on nodes that do not support tags the configured ones are kept so they
+// do not show as a change on every plan.
+func setTagsFromKong(client *kongClient, d *schema.ResourceData, tags []string) {
+	if kongVersion, err := client.version(); err == nil && !tagsSupported(kongVersion) {
+		return
+	}
+
+	d.Set("tags", tags)
+}
+
+type taggedEntity struct {
+	Tags []string `json:"tags"`
+}
+
+// tagsDiff returns the desired tags that are missing from current and the current tags that are not desired
+func tagsDiff(current []string, desired []string) (added []string, removed []string) {
+	for _, tag := range desired {
+		if !contains(current, tag) && !contains(added, tag) {
+			added = append(added, tag)
+		}
+	}
+
+	for _, tag := range current {
+		if !contains(desired, tag) && !contains(removed, tag) {
+			removed = append(removed, tag)
+		}
+	}
+
+	return added, removed
+}
+
+// syncTags changes the tags of the entity at /<entityType>/<id> to desired. Kong can only replace all the tags of an
+// entity, so the current tags are read first and nothing is sent when they already match, otherwise the tags that are
+// kept stay in kong's order with the added ones after them. Resources update their other fields without tags and call
+// syncTags for them.
+func syncTags(client *kongClient, entityType string, id string, desired []string) error {
+	path := "/" + entityType + "/" + id
+
+	current := &taggedEntity{}
+	found, err := client.get(path, current)
+	if err != nil {
+		return fmt.Errorf("could not read the tags of %s %s: %v", entityType, id, err)
+	}
+
+	if !found {
+		return fmt.Errorf("could not read the tags of %s %s, it does not exist", entityType, id)
+	}
+
+	added, removed := tagsDiff(current.Tags, desired)
+	if len(added) == 0 && len(removed) == 0 {
+		return nil
+	}
+
+	tags := []string{}
+	for _, tag := range current.Tags {
+		if !contains(removed, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	tags = append(tags, added...)
+
+	if err := client.patch(path, &taggedEntity{Tags: tags}, nil); err != nil {
+		return fmt.Errorf("could not update the tags of %s %s: %v", entityType, id, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,143 @@
+package kong
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/kevholditch/gokong"
+)
+
+func TestKongKeySetTagsAcrossVersions(t *testing.T) {
+
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+
+	for _, kongVersion := range []string{"0.13.1", "1.0.3", "1.1.0", "3.6.1"} {
+		var created map[string]interface{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			switch {
+			case r.URL.Path == "/":
+				json.NewEncoder(w).Encode(map[string]string{"version": kongVersion})
+			case r.Method == http.MethodPost:
+				json.NewDecoder(r.Body).Decode(&created)
+				created["id"] = "key-set-id"
+				w.WriteHeader(http.StatusCreated)
+				json.NewEncoder(w).Encode(created)
+			default:
+				json.NewEncoder(w).Encode(map[string]interface{}{"id": "key-set-id", "name": "set", "tags": created["tags"]})
+			}
+		}))
+
+		logs.Reset()
+		client := newKongClient(&gokong.Config{HostAddress: server.URL})
+
+		d := schema.TestResourceDataRaw(t, resourceKongKeySet().Schema, map[string]interface{}{
+			"name": "set",
+			"tags": []interface{}{"team-a"},
+		})
+
+		request, err := createKongKeySetRequestFromResourceData(client, d)
+		if err != nil {
+			t.Fatalf("kong %s: could not build key set request: %v", kongVersion, err)
+		}
+		if err := client.post(keySetsPath, request, nil); err != nil {
+			t.Fatalf("kong %s: could not create key set: %v", kongVersion, err)
+		}
+		d.SetId("key-set-id")
+		if err := resourceKongKeySetRead(d, client); err != nil {
+			t.Fatalf("kong %s: could not read key set: %v", kongVersion, err)
+		}
+		server.Close()
+
+		_, sent := created["tags"]
+		warned := strings.Contains(logs.String(), "does not support tags")
+		supported := kongVersion != "0.13.1" && kongVersion != "1.0.3"
+
+		if sent != supported || warned == supported {
+			t.Errorf("kong %s: expected tags to be sent: %t and a warning: %t, tags sent: %t warned: %t", kongVersion, supported, !supported, sent, warned)
+		}
+
+		if tags := d.Get("tags").(*schema.Set).List(); len(tags) != 1 || tags[0] != "team-a" {
+			t.Errorf("kong %s: expected the configured tags to be kept in state, got: %v", kongVersion, tags)
+		}
+	}
+}
+
+func TestTagsDiff(t *testing.T) {
+
+	cases := []struct {
+		current []string
+		desired []string
+		added   string
+		removed string
+	}{
+		{nil, nil, "", ""},
+		{[]string{"a", "b"}, []string{"b", "a"}, "", ""},
+		{[]string{"a"}, []string{"a", "b", "b"}, "b", ""},
+		{[]string{"a", "b", "c"}, []string{"b"}, "", "a,c"},
+		{[]string{"a", "b"}, []string{"b", "c"}, "c", "a"},
+		{[]string{"a"}, []string{}, "", "a"},
+	}
+
+	for _, c := range cases {
+		added, removed := tagsDiff(c.current, c.desired)
+		if strings.Join(added, ",") != c.added || strings.Join(removed, ",") != c.removed {
+			t.Errorf("tags %v to %v: expected to add [%s] and remove [%s] but added %v and removed %v", c.current, c.desired, c.added, c.removed, added, removed)
+		}
+	}
+}
+
+func TestSyncTags(t *testing.T) {
+
+	current := []string{"team-a", "billing"}
+	var patched []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/consumers/consumer-id" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Method == http.MethodPatch {
+			body := map[string]interface{}{}
+			json.NewDecoder(r.Body).Decode(&body)
+			patched = append(patched, body)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": "consumer-id", "tags": current})
+	}))
+	defer server.Close()
+
+	client := newKongClient(&gokong.Config{HostAddress: server.URL})
+
+	if err := syncTags(client, "consumers", "consumer-id", []string{"billing", "team-a"}); err != nil {
+		t.Fatalf("could not sync tags: %v", err)
+	}
+
+	if len(patched) != 0 {
+		t.Errorf("expected tags that already match not to be sent, got: %v", patched)
+	}
+
+	if err := syncTags(client, "consumers", "consumer-id", []string{"team-b", "billing"}); err != nil {
+		t.Fatalf("could not sync tags: %v", err)
+	}
+
+	if len(patched) != 1 || len(patched[0]) != 1 {
+		t.Fatalf("expected a single patch of only the tags, got: %v", patched)
+	}
+
+	if tags, _ := json.Marshal(patched[0]["tags"]); string(tags) != `["billing","team-b"]` {
+		t.Errorf("expected the kept tags in kong's order followed by the added ones, got: %s", tags)
+	}
+
+	if err := syncTags(client, "consumers", "missing-id", nil); err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("expected syncing the tags of a missing entity to fail, got: %v", err)
+	}
+}
@@ -0,0 +1,42 @@
+package kong
+
+import (
+	"sync"
+	"time"
+)
+
+// tokenBucket lets requestsPerSecond requests through each second. The bucket holds at most one token so requests are
+// spread out evenly rather than sent in bursts, a request that finds the bucket empty takes the next token and waits
+// until it has been added.
+type tokenBucket struct {
+	requestsPerSecond float64
+
+	lock   sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(requestsPerSecond float64) *tokenBucket {
+	return &tokenBucket{requestsPerSecond: requestsPerSecond, tokens: 1}
+}
+
+// reserve takes a token and returns how long to wait before sending the request it is for
+func (b *tokenBucket) reserve() time.Duration {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	now := time.Now()
+	if !b.last.IsZero() {
+		b.tokens += now.Sub(b.last).Seconds() * b.requestsPerSecond
+		if b.tokens > 1 {
+			b.tokens = 1
+		}
+	}
+	b.last = now
+
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.requestsPerSecond * float64(time.Second))
+}
@@ -0,0 +1,120 @@
+package kong
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func TestAdminTransportThrottlesRequests(t *testing.T) {
+
+	var lock sync.Mutex
+	var received []time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		received = append(received, time.Now())
+		lock.Unlock()
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	transport := newAdminTransport(userAgent(""), false)
+	transport.throttleRequests(20)
+	client := &http.Client{Transport: transport}
+
+	var wg sync.WaitGroup
+	errors := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			response, err := client.Get(server.URL)
+			if err != nil {
+				errors <- err
+				return
+			}
+			ioutil.ReadAll(response.Body)
+			response.Body.Close()
+		}()
+	}
+	wg.Wait()
+	close(errors)
+
+	for err := range errors {
+		t.Errorf("request failed: %v", err)
+	}
+
+	if len(received) != 10 {
+		t.Fatalf("expected every request to be sent, kong received %d", len(received))
+	}
+	sort.Slice(received, func(i, j int) bool { return received[i].Before(received[j]) })
+
+	// 10 requests at 20 a second take at least 9 intervals of 50ms, a little is allowed for the scheduling of the requests
+	if elapsed := received[9].Sub(received[0]); elapsed < 425*time.Millisecond {
+		t.Errorf("expected the requests to be spread over at least 450ms, they were sent within %s", elapsed)
+	}
+	for i := 1; i < len(received); i++ {
+		if gap := received[i].Sub(received[i-1]); gap < 25*time.Millisecond {
+			t.Errorf("expected about 50ms between requests, request %d was sent %s after the one before", i, gap)
+		}
+	}
+}
+
+func TestTokenBucket(t *testing.T) {
+
+	bucket := newTokenBucket(10)
+	if wait := bucket.reserve(); wait != 0 {
+		t.Errorf("expected the first request not to wait, got: %s", wait)
+	}
+
+	// the requests that find the bucket empty queue up behind each other
+	for i := 1; i <= 3; i++ {
+		if wait := bucket.reserve(); wait <= time.Duration(i-1)*100*time.Millisecond || wait > time.Duration(i)*100*time.Millisecond {
+			t.Errorf("expected request %d to wait up to %dms, got: %s", i, i*100, wait)
+		}
+	}
+
+	// an idle bucket only fills up to one token, it does not let a burst through
+	bucket = newTokenBucket(100)
+	bucket.reserve()
+	time.Sleep(50 * time.Millisecond)
+	bucket.reserve()
+	if wait := bucket.reserve(); wait == 0 {
+		t.Errorf("expected the bucket to hold at most one token")
+	}
+}
+
+func TestProviderConfiguresRequestsPerSecond(t *testing.T) {
+
+	for _, c := range []struct {
+		raw               map[string]interface{}
+		requestsPerSecond float64
+	}{
+		{map[string]interface{}{}, 0},
+		{map[string]interface{}{"requests_per_second": 2.5}, 2.5},
+	} {
+		d := schema.TestResourceDataRaw(t, Provider().(*schema.Provider).Schema, c.raw)
+		meta, err := providerConfigure(d)
+		if err != nil {
+			t.Fatalf("%v: could not configure provider: %v", c.raw, err)
+		}
+
+		throttle := meta.(*kongClient).transport.(*adminTransport).throttle
+		if c.requestsPerSecond == 0 && throttle != nil {
+			t.Errorf("%v: expected requests not to be throttled", c.raw)
+		}
+		if c.requestsPerSecond != 0 && (throttle == nil || throttle.requestsPerSecond != c.requestsPerSecond) {
+			t.Errorf("%v: expected %v requests a second, got: %v", c.raw, c.requestsPerSecond, throttle)
+		}
+	}
+
+	if _, errors := validateRequestsPerSecond(-1.0, "requests_per_second"); len(errors) != 1 {
+		t.Errorf("expected a negative rate to be rejected, got: %v", errors)
+	}
+}
@@ -0,0 +1,33 @@
+package kong
+
+import (
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// defaultOperationTimeout is long enough for a busy kong, a single create or update is a few admin api requests
+const defaultOperationTimeout = 2 * time.Minute
+
+// resourceKongTimeouts are the timeouts of the resources that bound their requests with withOperationTimeout, they can
+// be changed with a timeouts block on the resource.
+func resourceKongTimeouts() *schema.ResourceTimeout {
+	return &schema.ResourceTimeout{
+		Create: schema.DefaultTimeout(defaultOperationTimeout),
+		Read:   schema.DefaultTimeout(defaultOperationTimeout),
+		Update: schema.DefaultTimeout(defaultOperationTimeout),
+		Delete: schema.DefaultTimeout(defaultOperationTimeout),
+	}
+}
+
+// withOperationTimeout bounds the requests of one operation of a resource (schema.TimeoutCreate and so on) by the
+// timeout configured for it. Terraform gives every operation it runs a ResourceData with the resource's timeouts, so f
+// itself takes no timeout and can also be called by an importer or another operation with the client it has.
+func withOperationTimeout(operation string, f func(*schema.ResourceData, interface{}) error) func(*schema.ResourceData, interface{}) error {
+	return func(d *schema.ResourceData, meta interface{}) error {
+		client, cancel := meta.(*kongClient).withTimeout(d.Timeout(operation))
+		defer cancel()
+
+		return f(d, client)
+	}
+}
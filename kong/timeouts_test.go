@@ -0,0 +1,109 @@
+package kong
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform/config"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/kevholditch/gokong"
+)
+
+// newSlowKongServer mocks a kong node that never answers a POST, it gives up once the client has or after a few seconds
+func newSlowKongServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			// the server only notices the client went away once the body has been read
+			ioutil.ReadAll(r.Body)
+			select {
+			case <-r.Context().Done():
+			case <-time.After(5 * time.Second):
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+}
+
+// applyWithCreateTimeout creates the resource with a timeouts block that sets create to 100ms
+func applyWithCreateTimeout(t *testing.T, r *schema.Resource, raw map[string]interface{}, meta interface{}) error {
+	rawConfig, err := config.NewRawConfig(raw)
+	if err != nil {
+		t.Fatalf("could not build config: %v", err)
+	}
+	resourceConfig := terraform.NewResourceConfig(rawConfig)
+	resourceConfig.Config[schema.TimeoutsConfigKey] = []map[string]interface{}{{"create": "100ms"}}
+
+	diff, err := r.Diff(nil, resourceConfig)
+	if err != nil {
+		t.Fatalf("could not diff: %v", err)
+	}
+
+	_, err = r.Apply(nil, diff, meta)
+	return err
+}
+
+func TestKongPluginCreateTimeout(t *testing.T) {
+
+	server := newSlowKongServer()
+	defer server.Close()
+
+	started := time.Now()
+	err := applyWithCreateTimeout(t, resourceKongPlugin(), map[string]interface{}{
+		"name":        "key-auth",
+		"config_json": `{"hide_credentials":true}`,
+	}, newKongClient(&gokong.Config{HostAddress: server.URL}))
+
+	if err == nil || !strings.Contains(err.Error(), "timed out calling POST /plugins/") {
+		t.Errorf("expected creating the plugin to time out, got: %v", err)
+	}
+
+	if elapsed := time.Since(started); elapsed > 2*time.Second {
+		t.Errorf("expected the create to give up after its timeout but it took %s", elapsed)
+	}
+}
+
+func TestKongConsumerPluginConfigCreateTimeout(t *testing.T) {
+
+	server := newSlowKongServer()
+	defer server.Close()
+
+	started := time.Now()
+	err := applyWithCreateTimeout(t, resourceKongConsumerPluginConfig(), map[string]interface{}{
+		"consumer_id": "consumer-id",
+		"plugin_name": "key-auth",
+		"config_json": `{"key":"secret"}`,
+	}, newKongClient(&gokong.Config{HostAddress: server.URL}))
+
+	if err == nil || !strings.Contains(err.Error(), "timed out calling POST /consumers/consumer-id/key-auth") {
+		t.Errorf("expected creating the consumer plugin config to time out, got: %v", err)
+	}
+
+	if elapsed := time.Since(started); elapsed > 2*time.Second {
+		t.Errorf("expected the create to give up after its timeout but it took %s", elapsed)
+	}
+}
+
+func TestKongClientWithTimeoutKeepsParentDeadline(t *testing.T) {
+
+	client := newKongClient(&gokong.Config{HostAddress: "http://127.0.0.1:1"})
+
+	parent, cancelParent := client.withTimeout(time.Second)
+	defer cancelParent()
+
+	child, cancelChild := parent.withTimeout(time.Hour)
+	defer cancelChild()
+
+	parentDeadline, _ := parent.ctx.Deadline()
+	if childDeadline, ok := child.ctx.Deadline(); !ok || childDeadline.After(parentDeadline) {
+		t.Errorf("expected the child deadline %s not to be after the parent deadline %s", childDeadline, parentDeadline)
+	}
+
+	if child.kongClientState != client.kongClientState {
+		t.Errorf("expected the client with a timeout to share the state of the provider client")
+	}
+}
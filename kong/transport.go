@@ -0,0 +1,200 @@
+package kong
+
+import (
+	"crypto/tls"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// providerVersion is set when building a release with
+// -ldflags "-X github.com/kevholditch/terraform-provider-kong/kong.providerVersion=<version>"
+var providerVersion = "dev"
+
+func userAgent(suffix string) string {
+	userAgent := "terraform-provider-kong/" + providerVersion
+	if suffix != "" {
+		userAgent = userAgent + " " + suffix
+	}
+	return userAgent
+}
+
+// adminTransport is the RoundTripper every admin api request of a kong client goes through, see kongClient.end. Each
+// provider configuration has its own, so aliases of the provider do not share settings, throttles or breakers.
+type adminTransport struct {
+	userAgent string
+	// bearerToken is sent as the Authorization header of the requests to bearerTokenUrl when set, konnect
+	// authenticates with it, see useBearerToken
+	bearerToken    string
+	bearerTokenUrl *url.URL
+	transport      http.RoundTripper
+	// maxConnsPerHost bounds the requests in flight to each host when set, see limitConnections
+	maxConnsPerHost int
+	hostSlotsLock   sync.Mutex
+	hostSlots       map[string]chan struct{}
+	// throttle spaces out the requests when set, see throttleRequests
+	throttle *tokenBucket
+	// breaker fails requests fast while kong keeps failing when set, see breakCircuit
+	breaker *circuitBreaker
+	// adminUrls are failed over to in turn when connecting fails, see useAdminUrls
+	adminUrls           []*url.URL
+	adminUrlsLock       sync.Mutex
+	activeAdminUrlIndex int
+}
+
+func newAdminTransport(userAgent string, insecureSkipVerify bool) *adminTransport {
+	return &adminTransport{
+		userAgent: userAgent,
+		// same settings gorequest uses for the transport it creates for every request
+		transport: &http.Transport{
+			DisableKeepAlives: true,
+			TLSClientConfig:   &tls.Config{InsecureSkipVerify: insecureSkipVerify},
+		},
+	}
+}
+
+// limitConnections keeps up to maxIdleConns connections open for reuse, by default every request opens a connection of
+// its own that is closed once the response has been read. At most maxConnsPerHost requests are sent to a host at once,
+// the others wait for one of them to finish, which bounds the connections to it as every request in flight holds one.
+// http.Transport only has a limit of its own from go 1.11 on. 0 leaves either unlimited.
+func (t *adminTransport) limitConnections(maxIdleConns int, maxConnsPerHost int) {
+	if transport, ok := t.transport.(*http.Transport); ok && maxIdleConns > 0 {
+		transport.DisableKeepAlives = false
+		transport.MaxIdleConns = maxIdleConns
+		// every request goes to the one admin api, so all of the idle connections can be for that host
+		transport.MaxIdleConnsPerHost = maxIdleConns
+	}
+	t.maxConnsPerHost = maxConnsPerHost
+}
+
+// throttleRequests sends at most requestsPerSecond requests a second, the others wait for their turn. Every attempt
+// counts, so the requests retried after an error and the ones failed over to another admin url are throttled as well.
+// 0 leaves it unlimited.
+func (t *adminTransport) throttleRequests(requestsPerSecond float64) {
+	t.throttle = nil
+	if requestsPerSecond > 0 {
+		t.throttle = newTokenBucket(requestsPerSecond)
+	}
+}
+
+// breakCircuit stops sending requests for cooldown once threshold requests in a row got no response from kong or a 5xx
+// or 429, a request is counted once however many admin urls it was failed over to. 0 never stops sending them.
+func (t *adminTransport) breakCircuit(threshold int, cooldown time.Duration) {
+	t.breaker = nil
+	if threshold > 0 {
+		t.breaker = newCircuitBreaker(threshold, cooldown)
+	}
+}
+
+// useBearerToken authenticates the requests to the scheme and host of address with token, requests to anywhere else
+// (e.g. a redirect) are not sent the token
+func (t *adminTransport) useBearerToken(token string, address string) error {
+	tokenUrl, err := url.Parse(address)
+	if err != nil {
+		return err
+	}
+	t.bearerToken, t.bearerTokenUrl = token, tokenUrl
+	return nil
+}
+
+func (t *adminTransport) hostSlot(host string) chan struct{} {
+	t.hostSlotsLock.Lock()
+	defer t.hostSlotsLock.Unlock()
+
+	if t.hostSlots == nil {
+		t.hostSlots = map[string]chan struct{}{}
+	}
+	if t.hostSlots[host] == nil {
+		t.hostSlots[host] = make(chan struct{}, t.maxConnsPerHost)
+	}
+	return t.hostSlots[host]
+}
+
+// slotReleasingBody gives the slot of its request back once the response body is closed
+type slotReleasingBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (body *slotReleasingBody) Close() error {
+	err := body.ReadCloser.Close()
+	body.release()
+	return err
+}
+
+func (t *adminTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// a RoundTripper must not modify the request it is given
+	r := new(http.Request)
+	*r = *req
+	r.Header = make(http.Header, len(req.Header))
+	for key, values := range req.Header {
+		r.Header[key] = append([]string(nil), values...)
+	}
+	r.Header.Set("User-Agent", t.userAgent)
+	if t.bearerToken != "" && r.URL.Scheme == t.bearerTokenUrl.Scheme && r.URL.Host == t.bearerTokenUrl.Host {
+		r.Header.Set("Authorization", "Bearer "+t.bearerToken)
+	}
+
+	if t.breaker == nil {
+		return t.roundTrip(r)
+	}
+
+	if err := t.breaker.allow(); err != nil {
+		return nil, err
+	}
+	response, err := t.roundTrip(r)
+	if requestCancelled(r, err) {
+		t.breaker.release()
+	} else {
+		t.breaker.record(kongFailure(r, response, err))
+	}
+	return response, err
+}
+
+func (t *adminTransport) roundTrip(r *http.Request) (*http.Response, error) {
+	if len(t.adminUrls) > 1 {
+		return t.failover(r)
+	}
+
+	return t.send(r)
+}
+
+// send sends r to its host once the throttle lets it through and there is a slot for it
+func (t *adminTransport) send(r *http.Request) (*http.Response, error) {
+	if t.throttle != nil {
+		if wait := t.throttle.reserve(); wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-timer.C:
+			case <-r.Context().Done():
+				timer.Stop()
+				return nil, r.Context().Err()
+			}
+		}
+	}
+
+	if t.maxConnsPerHost <= 0 {
+		return t.transport.RoundTrip(r)
+	}
+
+	slot := t.hostSlot(r.URL.Host)
+	select {
+	case slot <- struct{}{}:
+	case <-r.Context().Done():
+		return nil, r.Context().Err()
+	}
+
+	var once sync.Once
+	release := func() { once.Do(func() { <-slot }) }
+
+	response, err := t.transport.RoundTrip(r)
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	response.Body = &slotReleasingBody{ReadCloser: response.Body, release: release}
+	return response, nil
+}
@@ -0,0 +1,180 @@
+package kong
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/kevholditch/gokong"
+)
+
+func TestUserAgent(t *testing.T) {
+
+	if actual := userAgent(""); actual != "terraform-provider-kong/"+providerVersion {
+		t.Errorf("unexpected default user agent: %s", actual)
+	}
+
+	if actual := userAgent("ci-pipeline/42"); actual != "terraform-provider-kong/"+providerVersion+" ci-pipeline/42" {
+		t.Errorf("unexpected user agent with suffix: %s", actual)
+	}
+}
+
+func TestAdminTransportSetsUserAgent(t *testing.T) {
+
+	userAgents := make(chan string, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgents <- r.Header.Get("User-Agent")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"a-consumer"}`))
+	}))
+	defer server.Close()
+
+	client := newKongClient(&gokong.Config{HostAddress: server.URL})
+	client.transport = newAdminTransport(userAgent("ci-pipeline/42"), false)
+	expected := "terraform-provider-kong/" + providerVersion + " ci-pipeline/42"
+
+	if _, err := client.get("/consumers/a-consumer", nil); err != nil {
+		t.Fatalf("raw request failed: %v", err)
+	}
+	if actual := <-userAgents; actual != expected {
+		t.Errorf("expected raw request user agent %s but was %s", expected, actual)
+	}
+}
+
+func TestProviderConfigurationsHaveTheirOwnTransport(t *testing.T) {
+
+	userAgents := make(chan string, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgents <- r.Header.Get("User-Agent")
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	defaultTransport := http.DefaultTransport
+	var clients []*kongClient
+	for _, suffix := range []string{"alias-a", "alias-b"} {
+		d := schema.TestResourceDataRaw(t, Provider().(*schema.Provider).Schema, map[string]interface{}{
+			"kong_admin_uri":    server.URL,
+			"user_agent_suffix": suffix,
+		})
+		meta, err := providerConfigure(d)
+		if err != nil {
+			t.Fatalf("%s: could not configure provider: %v", suffix, err)
+		}
+		clients = append(clients, meta.(*kongClient))
+	}
+
+	// the alias configured first keeps its own settings once another one has been configured
+	for i, suffix := range []string{"alias-a", "alias-b"} {
+		if _, err := clients[i].get("/", nil); err != nil {
+			t.Fatalf("%s: request failed: %v", suffix, err)
+		}
+		if actual, expected := <-userAgents, userAgent(suffix); actual != expected {
+			t.Errorf("expected the request of %s to have user agent %s but was %s", suffix, expected, actual)
+		}
+	}
+
+	if http.DefaultTransport != defaultTransport {
+		t.Errorf("expected configuring the provider to leave http.DefaultTransport alone, got: %T", http.DefaultTransport)
+	}
+}
+
+func TestProviderConfiguresConnectionLimits(t *testing.T) {
+
+	for _, c := range []struct {
+		raw             map[string]interface{}
+		keepAlives      bool
+		maxIdleConns    int
+		maxConnsPerHost int
+	}{
+		{map[string]interface{}{}, false, 0, 0},
+		{map[string]interface{}{"max_idle_conns": 10, "max_conns_per_host": 4}, true, 10, 4},
+		{map[string]interface{}{"max_conns_per_host": 4}, false, 0, 4},
+	} {
+		d := schema.TestResourceDataRaw(t, Provider().(*schema.Provider).Schema, c.raw)
+		meta, err := providerConfigure(d)
+		if err != nil {
+			t.Fatalf("%v: could not configure provider: %v", c.raw, err)
+		}
+
+		adminTransport, ok := meta.(*kongClient).transport.(*adminTransport)
+		if !ok {
+			t.Fatalf("%v: expected the client to have an admin transport, got: %T", c.raw, meta.(*kongClient).transport)
+		}
+		transport := adminTransport.transport.(*http.Transport)
+
+		if transport.DisableKeepAlives == c.keepAlives || transport.MaxIdleConns != c.maxIdleConns || transport.MaxIdleConnsPerHost != c.maxIdleConns {
+			t.Errorf("%v: expected keep alives %t with %d idle connections, got keep alives %t with %d idle connections (%d per host)",
+				c.raw, c.keepAlives, c.maxIdleConns, !transport.DisableKeepAlives, transport.MaxIdleConns, transport.MaxIdleConnsPerHost)
+		}
+
+		if adminTransport.maxConnsPerHost != c.maxConnsPerHost {
+			t.Errorf("%v: expected at most %d connections per host, got: %d", c.raw, c.maxConnsPerHost, adminTransport.maxConnsPerHost)
+		}
+	}
+
+	if _, errors := validateConnectionLimit(-1, "max_idle_conns"); len(errors) != 1 {
+		t.Errorf("expected a negative connection limit to be rejected, got: %v", errors)
+	}
+}
+
+func TestAdminTransportLimitsConnectionsPerHost(t *testing.T) {
+
+	var lock sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		lock.Unlock()
+
+		time.Sleep(20 * time.Millisecond)
+
+		lock.Lock()
+		inFlight--
+		lock.Unlock()
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	transport := newAdminTransport(userAgent(""), false)
+	transport.limitConnections(2, 2)
+	client := &http.Client{Transport: transport}
+
+	var wg sync.WaitGroup
+	errors := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			response, err := client.Get(server.URL)
+			if err != nil {
+				errors <- err
+				return
+			}
+			ioutil.ReadAll(response.Body)
+			response.Body.Close()
+		}()
+	}
+	wg.Wait()
+	close(errors)
+
+	for err := range errors {
+		t.Errorf("request failed: %v", err)
+	}
+
+	if maxInFlight > 2 {
+		t.Errorf("expected at most 2 requests in flight, got: %d", maxInFlight)
+	}
+
+	if slots := len(transport.hostSlot(strings.TrimPrefix(server.URL, "http://"))); slots != 0 {
+		t.Errorf("expected every slot to be released once the responses were closed, %d are held", slots)
+	}
+}
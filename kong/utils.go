@@ -1,5 +1,10 @@
 package kong
 
+import (
+	"encoding/json"
+	"fmt"
+)
+
 var computedPluginProperties = []string{"created_at", "id", "consumer_id"}
 
 func contains(s []string, e string) bool {
@@ -11,3 +16,49 @@ func contains(s []string, e string) bool {
 
 	return false
 }
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
+
+// stringValue, intValue and boolValue return the zero value for a nil pointer, for the optional fields gokong returns
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+func intValue(value *int) int {
+	if value == nil {
+		return 0
+	}
+	return *value
+}
+
+func boolValue(value *bool) bool {
+	return boolValueOrDefault(value, false)
+}
+
+func boolValueOrDefault(value *bool, defaultValue bool) bool {
+	if value == nil {
+		return defaultValue
+	}
+	return *value
+}
+
+// requestString is the json a request is sent as, for errors. Printing the request itself shows the addresses of its
+// pointer fields (and of the gokong request it embeds) rather than their values.
+func requestString(request interface{}) string {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Sprintf("%+v", request)
+	}
+	return string(body)
+}